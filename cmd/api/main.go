@@ -2,10 +2,13 @@
 package main
 
 import (
+	"context"
 	"log"
 	"net/http"
+	"time"
 
 	"github.com/nouvadev/dropwise/internal/config"
+	"github.com/nouvadev/dropwise/internal/middleware"
 	"github.com/nouvadev/dropwise/internal/server"
 	"github.com/rs/cors"
 )
@@ -16,6 +19,11 @@ func main() {
 		log.Fatalf("Error loading configuration: %v", err)
 	}
 
+	// Drop events published by any process, including the worker, reach this server's event streams
+	if err := cfg.Events.Listen(context.Background(), cfg.DB_URL); err != nil {
+		log.Printf("Warning: not listening for drop events from other processes: %v", err)
+	}
+
 	mux := server.NewRouter(cfg)
 	// Configure CORS
 	c := cors.New(cors.Options{
@@ -23,15 +31,65 @@ func main() {
 		AllowedOrigins: []string{"https://dropwise.vercel.app", "http://localhost:5173"},
 
 		// İzin verilen HTTP metodları
-		AllowedMethods: []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodDelete, http.MethodOptions},
+		AllowedMethods: []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete, http.MethodOptions},
 
 		// İzin verilen HTTP header'ları
-		AllowedHeaders: []string{"Authorization", "Content-Type"},
+		AllowedHeaders: []string{"Authorization", "Content-Type", "X-Workspace-ID", "X-Timezone"},
 
 		// Tarayıcının preflight (OPTIONS) cevabını cache'lemesi için süre (saniye)
 		MaxAge: 86400,
 	})
-	handler := c.Handler(mux)
+	// Maintenance mode sits inside CORS so browsers can read its 503 responses.
+	// Login and the admin toggle stay writable so maintenance mode can be turned off again,
+	// and POST endpoints that only read stay available like any GET.
+	handler := c.Handler(middleware.MaintenanceMiddleware(middleware.MaintenanceConfig{
+		Enabled:    cfg.Maintenance.Enabled,
+		RetryAfter: cfg.MaintenanceRetryAfter,
+		ExemptPaths: []string{
+			"/api/v1/auth/login",
+			"/api/v1/admin/maintenance",
+			"/api/v1/auth/introspect",
+			"/api/v1/drops/batch-get",
+		},
+	}, middleware.BodyLoggingMiddleware(middleware.BodyLoggingConfig{
+		Enabled:  cfg.DebugLogBodies,
+		MaxBytes: cfg.DebugLogBodiesMaxBytes,
+		// Credentials and the signed email webhook are never logged, whatever the field names
+		ExcludedPrefixes: []string{"/api/v1/auth/", "/api/v1/inbound/"},
+	}, middleware.APIVersionMiddleware(middleware.PrettyJSONMiddleware(cfg.Debug, mux)))))
+
+	// Security headers wrap the CORS handler so preflight responses get them too
+	handler = middleware.SecurityHeadersMiddleware(middleware.SecurityHeadersConfig{
+		HSTSMaxAge:            cfg.HSTSMaxAge,
+		ContentSecurityPolicy: cfg.ContentSecurityPolicy,
+	}, handler)
+
+	// The client IP is resolved once per request for the audit log
+	clientIPResolver := middleware.NewClientIPResolver(cfg.TrustedProxies)
+	handler = middleware.ClientIPMiddleware(clientIPResolver, handler)
+
+	// Oversized URLs are rejected before any handler parses their query
+	handler = middleware.URLLengthMiddleware(middleware.URLLengthConfig{
+		MaxURLLength:        cfg.MaxURLLength,
+		MaxQueryParamLength: cfg.MaxQueryParamLength,
+	}, handler)
+
+	// With Redis configured the limit is shared by every instance instead of kept per process
+	var rateLimitStore middleware.RateLimitStore
+	if cfg.Redis != nil {
+		if err := cfg.Redis.Ping(context.Background()).Err(); err != nil {
+			log.Printf("Warning: Redis is not reachable yet, rate limiting is skipped until it is: %v", err)
+		}
+		rateLimitStore = middleware.NewRedisRateLimitStore(cfg.Redis, cfg.RateLimitPerMinute, time.Minute)
+	}
+
+	// The global rate limit is the outermost layer so abusive clients are turned away first
+	handler = middleware.GlobalRateLimitMiddleware(middleware.RateLimitConfig{
+		Enabled:           cfg.RateLimitEnabled,
+		RequestsPerMinute: cfg.RateLimitPerMinute,
+		Resolver:          clientIPResolver,
+		Store:             rateLimitStore,
+	}, handler)
 
 	log.Printf("Starting server on port %s", cfg.Port)
 
@@ -18,11 +18,12 @@ func main() {
 
 	// Call the core worker logic directly for command-line simulation
 	// Pass a background context
-	processedCount, err := worker.ProcessDropsLogic(context.Background(), cfg)
+	summary, err := worker.ProcessDropsLogic(context.Background(), cfg)
 	if err != nil {
 		log.Printf("Worker simulation finished with error: %v", err)
 	} else {
-		log.Printf("Worker simulation finished. Drops processed: %d", processedCount)
+		log.Printf("Worker simulation finished. Drops processed: %d, failed: %d, remaining: %d, send rate: %.2f emails/s, degraded: %t",
+			summary.ProcessedCount, summary.FailedCount, summary.RemainingCount, summary.SendRate(), summary.Degraded)
 	}
 
 	log.Println("Dropwise Worker Process (Simulation) finished.")
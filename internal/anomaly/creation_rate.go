@@ -0,0 +1,150 @@
+package anomaly
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nouvadev/dropwise/internal/audit"
+	"github.com/nouvadev/dropwise/internal/email"
+)
+
+// alertTimeout bounds sending a single admin alert.
+const alertTimeout = 10 * time.Second
+
+// CreationRateConfig controls CreationMonitor.
+type CreationRateConfig struct {
+	Threshold  int           // Drops a user may create within Window before being flagged, 0 disables the monitor
+	Window     time.Duration // Length of the sliding window
+	AlertEmail string        // Address alerted when a user is flagged, empty for audit log only
+}
+
+// CreationMonitor flags users who create drops unusually fast, which may point to a
+// compromised account or a runaway script. It never blocks a creation; a flagged user
+// gets an audit entry and, when configured, an email to the admin, at most once per window.
+//
+// Counts are kept in memory with a sliding window counter: the current fixed window's
+// count plus the previous window's, weighted by how much of it still overlaps. Each API
+// process counts only the requests it serves.
+type CreationMonitor struct {
+	cfg    CreationRateConfig
+	audit  *audit.Logger
+	sender email.Sender
+
+	mu        sync.Mutex
+	users     map[uuid.UUID]*creationCounter
+	lastSweep time.Time
+}
+
+type creationCounter struct {
+	windowStart time.Time
+	count       int
+	prevCount   int
+	flaggedAt   time.Time
+}
+
+// NewCreationMonitor creates a CreationMonitor recording to auditLogger and sending alerts through sender.
+func NewCreationMonitor(cfg CreationRateConfig, auditLogger *audit.Logger, sender email.Sender) *CreationMonitor {
+	return &CreationMonitor{
+		cfg:       cfg,
+		audit:     auditLogger,
+		sender:    sender,
+		users:     make(map[uuid.UUID]*creationCounter),
+		lastSweep: time.Now(),
+	}
+}
+
+// Observe counts created new drops for userID and flags the user when that takes them over
+// the threshold. A nil or disabled CreationMonitor does nothing.
+func (m *CreationMonitor) Observe(ctx context.Context, userID uuid.UUID, created int) {
+	if m == nil || m.cfg.Threshold <= 0 || m.cfg.Window <= 0 || created <= 0 {
+		return
+	}
+
+	rate, flag := m.add(userID, created, time.Now())
+	if !flag {
+		return
+	}
+
+	log.Printf("Anomaly: User %s created about %d drops in the last %v (threshold %d)", userID, rate, m.cfg.Window, m.cfg.Threshold)
+	m.audit.Record(ctx, audit.ActionDropCreationRateExceeded, userID, audit.Metadata{
+		Details: map[string]any{
+			"estimated_count": rate,
+			"threshold":       m.cfg.Threshold,
+			"window_seconds":  int(m.cfg.Window.Seconds()),
+		},
+	})
+	if m.cfg.AlertEmail != "" && m.sender != nil {
+		m.sendAlert(ctx, userID, rate)
+	}
+}
+
+// add records created drops for userID at now. It returns the user's estimated count over
+// the sliding window and whether the user should be flagged now.
+func (m *CreationMonitor) add(userID uuid.UUID, created int, now time.Time) (int, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.sweep(now)
+
+	c, ok := m.users[userID]
+	if !ok {
+		c = &creationCounter{windowStart: now}
+		m.users[userID] = c
+	}
+	if elapsed := now.Sub(c.windowStart); elapsed >= m.cfg.Window {
+		// Roll forward; the previous window only counts if it immediately precedes the new one
+		c.prevCount = 0
+		if elapsed < 2*m.cfg.Window {
+			c.prevCount = c.count
+		}
+		c.windowStart = c.windowStart.Add(elapsed.Truncate(m.cfg.Window))
+		c.count = 0
+	}
+	c.count += created
+
+	overlap := 1 - float64(now.Sub(c.windowStart))/float64(m.cfg.Window)
+	rate := c.count + int(float64(c.prevCount)*overlap)
+	if rate <= m.cfg.Threshold {
+		return rate, false
+	}
+	if !c.flaggedAt.IsZero() && now.Sub(c.flaggedAt) < m.cfg.Window {
+		return rate, false // Already flagged for this burst
+	}
+	c.flaggedAt = now
+	return rate, true
+}
+
+// sweep drops counters idle for two windows, at most once per window.
+func (m *CreationMonitor) sweep(now time.Time) {
+	if now.Sub(m.lastSweep) < m.cfg.Window {
+		return
+	}
+	m.lastSweep = now
+	for userID, c := range m.users {
+		if now.Sub(c.windowStart) >= 2*m.cfg.Window {
+			delete(m.users, userID)
+		}
+	}
+}
+
+// sendAlert emails the admin in the background, so a slow mail server never delays the request.
+func (m *CreationMonitor) sendAlert(ctx context.Context, userID uuid.UUID, rate int) {
+	msg := email.Message{
+		To:      m.cfg.AlertEmail,
+		Subject: "Dropwise: unusual drop creation rate",
+		Body: fmt.Sprintf("User %s created about %d drops in the last %v, above the threshold of %d.\n"+
+			"This may be a compromised account or a runaway script. See the audit log for details.\n",
+			userID, rate, m.cfg.Window, m.cfg.Threshold),
+	}
+	sendCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), alertTimeout)
+	go func() {
+		defer cancel()
+		if err := m.sender.Send(sendCtx, msg); err != nil {
+			log.Printf("Anomaly: Error sending creation rate alert for user %s: %v", userID, err)
+		}
+	}()
+}
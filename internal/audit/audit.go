@@ -0,0 +1,90 @@
+package audit
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	db "github.com/nouvadev/dropwise/internal/database/sqlc"
+)
+
+// Audited actions.
+const (
+	ActionSignup                   = "auth.signup"
+	ActionLogin                    = "auth.login"
+	ActionLoginFailed              = "auth.login_failed"
+	ActionAccountExported          = "account.exported"
+	ActionAccountDeleted           = "account.deleted"
+	ActionDropDeleted              = "drop.deleted"
+	ActionDropCreationRateExceeded = "drop.creation_rate_exceeded"
+	ActionMaintenanceToggled       = "admin.maintenance_toggled"
+)
+
+// writeTimeout bounds a single audit write so a slow database cannot pile up goroutines.
+const writeTimeout = 5 * time.Second
+
+// sourceIPKey is the context key holding the client IP of the current request.
+type sourceIPKey struct{}
+
+// WithSourceIP returns a copy of ctx carrying the client IP recorded with audit entries.
+func WithSourceIP(ctx context.Context, ip string) context.Context {
+	return context.WithValue(ctx, sourceIPKey{}, ip)
+}
+
+// Metadata describes what an audited action was applied to.
+type Metadata struct {
+	Target  string         // What was acted on, e.g. a drop ID
+	Details map[string]any // Extra action-specific fields, stored as JSON
+}
+
+// Logger writes audit entries to the audit_log table.
+type Logger struct {
+	db      *db.Queries
+	enabled bool
+}
+
+// NewLogger creates a Logger writing through queries. A disabled Logger records nothing.
+func NewLogger(queries *db.Queries, enabled bool) *Logger {
+	return &Logger{db: queries, enabled: enabled}
+}
+
+// Record appends an entry for action by userID (uuid.Nil for anonymous actions) to the
+// audit log. The write happens in the background so it never delays or fails the action
+// being audited; errors are only logged. The source IP is taken from ctx (see WithSourceIP).
+// A nil or disabled Logger does nothing.
+func (l *Logger) Record(ctx context.Context, action string, userID uuid.UUID, md Metadata) {
+	if l == nil || !l.enabled {
+		return
+	}
+
+	details := md.Details
+	if details == nil {
+		details = map[string]any{}
+	}
+	metadata, err := json.Marshal(details)
+	if err != nil {
+		log.Printf("Audit: Error encoding metadata for %s by %s: %v", action, userID, err)
+		metadata = []byte("{}")
+	}
+	sourceIP, _ := ctx.Value(sourceIPKey{}).(string)
+
+	params := db.CreateAuditLogEntryParams{
+		ActorUuid: uuid.NullUUID{UUID: userID, Valid: userID != uuid.Nil},
+		Action:    action,
+		Target:    sql.NullString{String: md.Target, Valid: md.Target != ""},
+		SourceIp:  sql.NullString{String: sourceIP, Valid: sourceIP != ""},
+		Metadata:  metadata,
+	}
+
+	// The request context is cancelled once the response is written, so the write gets its own deadline.
+	writeCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), writeTimeout)
+	go func() {
+		defer cancel()
+		if err := l.db.CreateAuditLogEntry(writeCtx, params); err != nil {
+			log.Printf("Audit: Error recording %s by %s (target %q): %v", action, userID, md.Target, err)
+		}
+	}()
+}
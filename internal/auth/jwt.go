@@ -1,6 +1,8 @@
 package auth
 
 import (
+	"crypto/rsa"
+	"errors"
 	"fmt"
 	"time"
 
@@ -8,21 +10,106 @@ import (
 	"github.com/google/uuid"
 )
 
+// Supported values for JWT_ALGO.
+const (
+	AlgHS256 = "HS256"
+	AlgRS256 = "RS256"
+)
+
+// ErrNoSigningKey is returned when generating a token on a deployment that can only verify them.
+var ErrNoSigningKey = errors.New("no JWT signing key configured")
+
 // Claims defines the structure of the JWT claims.
-// It includes the standard RegisteredClaims and a custom UserID claim.
+// It includes the standard RegisteredClaims, a custom UserID claim and
+// the optional active WorkspaceID (absent for the personal space).
 type Claims struct {
-	UserID uuid.UUID `json:"user_id"`
+	UserID      uuid.UUID  `json:"user_id"`
+	WorkspaceID *uuid.UUID `json:"workspace_id,omitempty"`
 	jwt.RegisteredClaims
 }
 
+// JWTKeys holds the algorithm and keys used to sign and verify tokens.
+// With HS256 a shared secret does both. With RS256 tokens are signed with the private key
+// and verified with the public key, so a deployment that only verifies tokens issued by
+// another service needs just the public key.
+type JWTKeys struct {
+	Algorithm  string
+	Secret     []byte          // HS256
+	PrivateKey *rsa.PrivateKey // RS256, nil when this deployment only verifies tokens
+	PublicKey  *rsa.PublicKey  // RS256
+}
+
+// NewHS256Keys returns keys signing and verifying tokens with a shared secret.
+func NewHS256Keys(secret string) JWTKeys {
+	return JWTKeys{Algorithm: AlgHS256, Secret: []byte(secret)}
+}
+
+// NewRS256Keys parses PEM-encoded RSA keys for RS256. The private key may be empty on a
+// verify-only deployment; the public key may be empty when the private key is given.
+func NewRS256Keys(privateKeyPEM, publicKeyPEM string) (JWTKeys, error) {
+	keys := JWTKeys{Algorithm: AlgRS256}
+	if privateKeyPEM != "" {
+		privateKey, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(privateKeyPEM))
+		if err != nil {
+			return JWTKeys{}, fmt.Errorf("invalid RSA private key: %w", err)
+		}
+		keys.PrivateKey = privateKey
+		keys.PublicKey = &privateKey.PublicKey
+	}
+	if publicKeyPEM != "" {
+		publicKey, err := jwt.ParseRSAPublicKeyFromPEM([]byte(publicKeyPEM))
+		if err != nil {
+			return JWTKeys{}, fmt.Errorf("invalid RSA public key: %w", err)
+		}
+		if keys.PrivateKey != nil && !keys.PrivateKey.PublicKey.Equal(publicKey) {
+			return JWTKeys{}, errors.New("RSA public key does not match the private key")
+		}
+		keys.PublicKey = publicKey
+	}
+	if keys.PublicKey == nil {
+		return JWTKeys{}, errors.New("RS256 needs a private or public key")
+	}
+	return keys, nil
+}
+
+// signing returns the signing method and key for the configured algorithm.
+func (k JWTKeys) signing() (jwt.SigningMethod, interface{}, error) {
+	switch k.Algorithm {
+	case AlgHS256:
+		return jwt.SigningMethodHS256, k.Secret, nil
+	case AlgRS256:
+		if k.PrivateKey == nil {
+			return nil, nil, ErrNoSigningKey
+		}
+		return jwt.SigningMethodRS256, k.PrivateKey, nil
+	default:
+		return nil, nil, fmt.Errorf("unsupported JWT algorithm %q", k.Algorithm)
+	}
+}
+
+// verifyingKey returns the key tokens are verified with.
+func (k JWTKeys) verifyingKey() interface{} {
+	if k.Algorithm == AlgRS256 {
+		return k.PublicKey
+	}
+	return k.Secret
+}
+
 // GenerateJWT creates a new JWT string for a given user ID.
-// It signs the token using HS256 algorithm with the provided secret key and sets an expiration time.
-func GenerateJWT(userID uuid.UUID, secretKey string, expirationDuration time.Duration) (string, error) {
+// It signs the token with the configured algorithm and keys and sets an expiration time.
+func GenerateJWT(userID uuid.UUID, keys JWTKeys, expirationDuration time.Duration) (string, error) {
+	return GenerateWorkspaceJWT(userID, nil, keys, expirationDuration)
+}
+
+// GenerateWorkspaceJWT creates a new JWT string for a given user ID with an active workspace.
+// A nil workspaceID produces a token scoped to the user's personal space.
+func GenerateWorkspaceJWT(userID uuid.UUID, workspaceID *uuid.UUID, keys JWTKeys, expirationDuration time.Duration) (string, error) {
 	expirationTime := time.Now().Add(expirationDuration)
 
 	// Create the claims
 	claims := &Claims{
-		UserID: userID,
+		UserID:      userID,
+		WorkspaceID: workspaceID,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(expirationTime),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
@@ -31,11 +118,14 @@ func GenerateJWT(userID uuid.UUID, secretKey string, expirationDuration time.Dur
 		},
 	}
 
-	// Create the token using HS256 signing method
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	method, key, err := keys.signing()
+	if err != nil {
+		return "", fmt.Errorf("failed to sign token: %w", err)
+	}
 
-	// Sign the token with the secret key
-	tokenString, err := token.SignedString([]byte(secretKey))
+	// Create the token and sign it
+	token := jwt.NewWithClaims(method, claims)
+	tokenString, err := token.SignedString(key)
 	if err != nil {
 		return "", fmt.Errorf("failed to sign token: %w", err)
 	}
@@ -45,18 +135,19 @@ func GenerateJWT(userID uuid.UUID, secretKey string, expirationDuration time.Dur
 
 // ValidateJWT parses and validates a JWT string.
 // It checks the signature, expiration, and other standard claims.
+// Only tokens whose alg header is the configured algorithm are accepted, so an RS256
+// public key can never be used as an HMAC secret (algorithm confusion).
 // It returns the custom Claims if the token is valid, otherwise an error.
-func ValidateJWT(tokenString string, secretKey string) (*Claims, error) {
+func ValidateJWT(tokenString string, keys JWTKeys) (*Claims, error) {
 	claims := &Claims{}
 
 	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-		// Ensure the signing method is what we expect (HS256)
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+		// Ensure the signing method is the configured one
+		if token.Method.Alg() != keys.Algorithm {
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 		}
-		// Return the secret key for validation
-		return []byte(secretKey), nil
-	})
+		return keys.verifyingKey(), nil
+	}, jwt.WithValidMethods([]string{keys.Algorithm}))
 
 	if err != nil {
 		// This will catch errors like expired tokens, malformed tokens, signature mismatch, etc.
@@ -72,3 +163,29 @@ func ValidateJWT(tokenString string, secretKey string) (*Claims, error) {
 	// Token is valid, return the claims
 	return claims, nil
 }
+
+// Reasons a token can fail validation, as reported by TokenErrorReason.
+const (
+	TokenExpired      = "expired"
+	TokenNotYetValid  = "not_yet_valid"
+	TokenBadSignature = "bad_signature"
+	TokenMalformed    = "malformed"
+	TokenInvalid      = "invalid"
+)
+
+// TokenErrorReason classifies an error returned by ValidateJWT into one of the Token* reasons.
+// A token signed with another algorithm than JWT_ALGO counts as a bad signature.
+func TokenErrorReason(err error) string {
+	switch {
+	case errors.Is(err, jwt.ErrTokenMalformed):
+		return TokenMalformed
+	case errors.Is(err, jwt.ErrTokenSignatureInvalid), errors.Is(err, jwt.ErrTokenUnverifiable):
+		return TokenBadSignature
+	case errors.Is(err, jwt.ErrTokenExpired):
+		return TokenExpired
+	case errors.Is(err, jwt.ErrTokenNotValidYet), errors.Is(err, jwt.ErrTokenUsedBeforeIssued):
+		return TokenNotYetValid
+	default:
+		return TokenInvalid
+	}
+}
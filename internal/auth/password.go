@@ -1,23 +1,197 @@
 package auth
 
 import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
 	"golang.org/x/crypto/bcrypt"
 )
 
+// Supported values for PASSWORD_HASH_ALGO.
+const (
+	AlgoBcrypt   = "bcrypt"
+	AlgoArgon2id = "argon2id"
+)
+
+// ErrUnknownHashFormat is returned when a stored hash was not produced by any supported algorithm.
+var ErrUnknownHashFormat = errors.New("unknown password hash format")
+
+// Hasher hashes and verifies passwords with one algorithm.
+// Hashes are self-describing (bcrypt's $2a$ prefix, argon2id's PHC string) so a stored hash
+// can always be verified by the algorithm that produced it, whatever the current default is.
+type Hasher interface {
+	// Hash returns the encoded hash of password.
+	Hash(password string) (string, error)
+	// Verify reports whether password matches a hash produced by this hasher.
+	Verify(password, hash string) (bool, error)
+	// Owns reports whether hash was produced by this algorithm.
+	Owns(hash string) bool
+	// NeedsRehash reports whether a hash this hasher owns was made with weaker parameters
+	// than the hasher's current ones.
+	NeedsRehash(hash string) bool
+}
+
+// NewHasher returns the hasher for a PASSWORD_HASH_ALGO value.
+func NewHasher(algo string) (Hasher, error) {
+	switch strings.ToLower(strings.TrimSpace(algo)) {
+	case "", AlgoBcrypt:
+		return BcryptHasher{Cost: bcrypt.DefaultCost}, nil
+	case AlgoArgon2id:
+		return DefaultArgon2idHasher(), nil
+	default:
+		return nil, fmt.Errorf("unsupported password hash algorithm %q, expected %s or %s", algo, AlgoBcrypt, AlgoArgon2id)
+	}
+}
+
+// knownHashers are tried in order to find the algorithm of a stored hash.
+var knownHashers = []Hasher{BcryptHasher{Cost: bcrypt.DefaultCost}, DefaultArgon2idHasher()}
+
+// VerifyPassword checks password against a stored hash of any supported algorithm.
+func VerifyPassword(password, hash string) (bool, error) {
+	for _, h := range knownHashers {
+		if h.Owns(hash) {
+			return h.Verify(password, hash)
+		}
+	}
+	return false, ErrUnknownHashFormat
+}
+
+// NeedsRehash reports whether a stored hash should be replaced by one from preferred,
+// either because another algorithm produced it or because its parameters are outdated.
+func NeedsRehash(preferred Hasher, hash string) bool {
+	return !preferred.Owns(hash) || preferred.NeedsRehash(hash)
+}
+
 // HashPassword generates a bcrypt hash for the given password.
 // The cost parameter for bcrypt.GenerateFromPassword defaults to bcrypt.DefaultCost (10),
 // which is generally a good balance between security and performance.
 func HashPassword(password string) (string, error) {
-	hashedBytes, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	return BcryptHasher{Cost: bcrypt.DefaultCost}.Hash(password)
+}
+
+// CheckPasswordHash compares a plain-text password with a stored hash of any supported algorithm.
+// It returns true if the password and hash match, false otherwise.
+func CheckPasswordHash(password, hash string) bool {
+	ok, err := VerifyPassword(password, hash)
+	return err == nil && ok
+}
+
+// BcryptHasher hashes passwords with bcrypt.
+type BcryptHasher struct {
+	Cost int
+}
+
+// Hash implements Hasher.
+func (b BcryptHasher) Hash(password string) (string, error) {
+	hashedBytes, err := bcrypt.GenerateFromPassword([]byte(password), b.Cost)
 	if err != nil {
 		return "", err
 	}
 	return string(hashedBytes), nil
 }
 
-// CheckPasswordHash compares a plain-text password with a stored bcrypt hash.
-// It returns true if the password and hash match, false otherwise.
-func CheckPasswordHash(password, hash string) bool {
+// Verify implements Hasher.
+func (b BcryptHasher) Verify(password, hash string) (bool, error) {
 	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
-	return err == nil // err is nil on success (match), and an error on failure (mismatch or other bcrypt error)
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// Owns implements Hasher.
+func (b BcryptHasher) Owns(hash string) bool {
+	return strings.HasPrefix(hash, "$2a$") || strings.HasPrefix(hash, "$2b$") || strings.HasPrefix(hash, "$2y$")
+}
+
+// NeedsRehash implements Hasher.
+func (b BcryptHasher) NeedsRehash(hash string) bool {
+	cost, err := bcrypt.Cost([]byte(hash))
+	return err != nil || cost < b.Cost
+}
+
+// Argon2idHasher hashes passwords with argon2id, encoded in the PHC string format
+// $argon2id$v=19$m=<memory KiB>,t=<iterations>,p=<parallelism>$<salt>$<hash>.
+type Argon2idHasher struct {
+	Memory      uint32 // KiB
+	Iterations  uint32
+	Parallelism uint8
+	SaltLength  uint32
+	KeyLength   uint32
+}
+
+// DefaultArgon2idHasher returns an argon2id hasher with the parameters recommended by RFC 9106
+// for memory-constrained environments.
+func DefaultArgon2idHasher() Argon2idHasher {
+	return Argon2idHasher{Memory: 64 * 1024, Iterations: 3, Parallelism: 4, SaltLength: 16, KeyLength: 32}
+}
+
+// Hash implements Hasher.
+func (a Argon2idHasher) Hash(password string) (string, error) {
+	salt := make([]byte, a.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("failed to generate salt: %w", err)
+	}
+	key := argon2.IDKey([]byte(password), salt, a.Iterations, a.Memory, a.Parallelism, a.KeyLength)
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, a.Memory, a.Iterations, a.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+// Verify implements Hasher. The parameters stored in the hash are used, not the hasher's own.
+func (a Argon2idHasher) Verify(password, hash string) (bool, error) {
+	params, salt, key, err := decodeArgon2id(hash)
+	if err != nil {
+		return false, err
+	}
+	candidate := argon2.IDKey([]byte(password), salt, params.Iterations, params.Memory, params.Parallelism, uint32(len(key)))
+	return subtle.ConstantTimeCompare(candidate, key) == 1, nil
+}
+
+// Owns implements Hasher.
+func (a Argon2idHasher) Owns(hash string) bool {
+	return strings.HasPrefix(hash, "$argon2id$")
+}
+
+// NeedsRehash implements Hasher.
+func (a Argon2idHasher) NeedsRehash(hash string) bool {
+	params, _, _, err := decodeArgon2id(hash)
+	return err != nil || params.Memory < a.Memory || params.Iterations < a.Iterations || params.Parallelism < a.Parallelism
+}
+
+// decodeArgon2id parses a PHC-encoded argon2id hash.
+func decodeArgon2id(hash string) (Argon2idHasher, []byte, []byte, error) {
+	var params Argon2idHasher
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return params, nil, nil, ErrUnknownHashFormat
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return params, nil, nil, fmt.Errorf("unsupported argon2 version in hash: %s", parts[2])
+	}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Iterations, &params.Parallelism); err != nil {
+		return params, nil, nil, fmt.Errorf("invalid argon2 parameters in hash: %w", err)
+	}
+	if params.Memory == 0 || params.Iterations == 0 || params.Parallelism == 0 {
+		return params, nil, nil, fmt.Errorf("invalid argon2 parameters in hash: %s", parts[3])
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return params, nil, nil, fmt.Errorf("invalid argon2 salt in hash: %w", err)
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return params, nil, nil, fmt.Errorf("invalid argon2 key in hash: %v", err)
+	}
+	params.SaltLength = uint32(len(salt))
+	params.KeyLength = uint32(len(key))
+	return params, salt, key, nil
 }
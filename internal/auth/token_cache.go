@@ -0,0 +1,99 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"sync"
+	"time"
+)
+
+// TokenCache remembers the claims of recently validated tokens so a client sending the same
+// token on every request doesn't have its signature verified each time. Entries live for at
+// most the cache TTL and never past the token's own expiry. Tokens are keyed by their SHA-256
+// hash, so the cache holds no usable credentials. It is safe for concurrent use.
+//
+// A nil TokenCache caches nothing.
+type TokenCache struct {
+	ttl        time.Duration
+	maxEntries int
+
+	mu      sync.Mutex
+	entries map[[sha256.Size]byte]cachedClaims
+}
+
+type cachedClaims struct {
+	claims    Claims
+	expiresAt time.Time
+}
+
+// NewTokenCache creates a TokenCache holding up to maxEntries tokens for ttl each. It returns
+// nil, a cache that stores nothing, when ttl or maxEntries is 0 or less.
+func NewTokenCache(ttl time.Duration, maxEntries int) *TokenCache {
+	if ttl <= 0 || maxEntries <= 0 {
+		return nil
+	}
+	return &TokenCache{
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		entries:    make(map[[sha256.Size]byte]cachedClaims),
+	}
+}
+
+// Validate returns the claims of tokenString like ValidateJWT, reusing the result of an
+// earlier validation of the same token while it is cached.
+func (c *TokenCache) Validate(tokenString string, keys JWTKeys) (*Claims, error) {
+	if c == nil {
+		return ValidateJWT(tokenString, keys)
+	}
+	key := sha256.Sum256([]byte(tokenString))
+	now := time.Now()
+
+	c.mu.Lock()
+	e, ok := c.entries[key]
+	if ok && !now.Before(e.expiresAt) {
+		delete(c.entries, key)
+		ok = false
+	}
+	c.mu.Unlock()
+	if ok {
+		claims := e.claims
+		return &claims, nil
+	}
+
+	claims, err := ValidateJWT(tokenString, keys)
+	if err != nil {
+		return nil, err
+	}
+	expiresAt := now.Add(c.ttl)
+	if claims.ExpiresAt != nil && claims.ExpiresAt.Time.Before(expiresAt) {
+		expiresAt = claims.ExpiresAt.Time
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.entries) >= c.maxEntries {
+		c.evict(now)
+	}
+	c.entries[key] = cachedClaims{claims: *claims, expiresAt: expiresAt}
+	return claims, nil
+}
+
+// evict makes room for one entry: expired entries are removed and, if the cache is
+// still full, the entry that would expire soonest, which has the least cache life left.
+func (c *TokenCache) evict(now time.Time) {
+	var (
+		soonest    [sha256.Size]byte
+		soonestExp time.Time
+	)
+	for key, e := range c.entries {
+		if !now.Before(e.expiresAt) {
+			delete(c.entries, key)
+			continue
+		}
+		if soonestExp.IsZero() || e.expiresAt.Before(soonestExp) {
+			soonest, soonestExp = key, e.expiresAt
+		}
+	}
+	if len(c.entries) >= c.maxEntries {
+		delete(c.entries, soonest)
+	}
+}
@@ -0,0 +1,100 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestTokenCacheEvictsSoonestExpiring(t *testing.T) {
+	keys := NewHS256Keys("test-secret")
+	c := NewTokenCache(time.Hour, 2)
+
+	token := func(lifetime time.Duration) string {
+		t.Helper()
+		s, err := GenerateJWT(uuid.New(), keys, lifetime)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return s
+	}
+	shortLived := token(time.Minute)
+	longLived := token(2 * time.Hour)
+	newest := token(3 * time.Hour)
+
+	for _, s := range []string{longLived, shortLived, newest} {
+		if _, err := c.Validate(s, keys); err != nil {
+			t.Fatalf("Validate() error = %v", err)
+		}
+	}
+
+	cached := func(s string) bool {
+		_, ok := c.entries[sha256.Sum256([]byte(s))]
+		return ok
+	}
+	if len(c.entries) != 2 {
+		t.Fatalf("cache holds %d entries, want 2", len(c.entries))
+	}
+	if cached(shortLived) {
+		t.Error("the soonest-expiring token is still cached")
+	}
+	if !cached(longLived) || !cached(newest) {
+		t.Error("a longer-lived token was evicted")
+	}
+}
+
+func TestTokenCacheEvictsExpiredFirst(t *testing.T) {
+	c := NewTokenCache(time.Hour, 2)
+	now := time.Now()
+	c.entries[[sha256.Size]byte{1}] = cachedClaims{expiresAt: now.Add(-time.Second)}
+	c.entries[[sha256.Size]byte{2}] = cachedClaims{expiresAt: now.Add(time.Minute)}
+
+	c.evict(now)
+	if _, ok := c.entries[[sha256.Size]byte{2}]; !ok || len(c.entries) != 1 {
+		t.Errorf("evict() left %d entries, want only the unexpired one", len(c.entries))
+	}
+}
+
+func TestTokenCacheRejectsInvalidTokens(t *testing.T) {
+	keys := NewHS256Keys("test-secret")
+	c := NewTokenCache(time.Hour, 10)
+	s, err := GenerateJWT(uuid.New(), NewHS256Keys("other-secret"), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for range 2 {
+		if _, err := c.Validate(s, keys); err == nil {
+			t.Fatal("Validate() accepted a token signed with another key")
+		}
+	}
+	if len(c.entries) != 0 {
+		t.Errorf("cache holds %d entries after invalid tokens, want 0", len(c.entries))
+	}
+}
+
+// BenchmarkValidate compares validating the same token with and without the cache.
+func BenchmarkValidate(b *testing.B) {
+	keys := NewHS256Keys("benchmark-secret")
+	token, err := GenerateJWT(uuid.New(), keys, time.Hour)
+	if err != nil {
+		b.Fatal(err)
+	}
+	benchmarks := []struct {
+		name  string
+		cache *TokenCache
+	}{
+		{"uncached", nil},
+		{"cached", NewTokenCache(time.Minute, 10000)},
+	}
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			for b.Loop() {
+				if _, err := bm.cache.Validate(token, keys); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
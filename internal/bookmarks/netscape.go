@@ -0,0 +1,138 @@
+package bookmarks
+
+import (
+	"errors"
+	"html"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// ErrNotBookmarksFile is returned when a document has no bookmark links at all.
+var ErrNotBookmarksFile = errors.New("no bookmarks found, expected a Netscape bookmarks HTML export")
+
+var (
+	// Only the elements that carry structure matter: folders (<H3>), their contents (<DL>) and links (<A>).
+	elementRe = regexp.MustCompile(`(?is)<(/?)(dl|h3|a)\b([^>]*)>`)
+	attrRe    = regexp.MustCompile(`(?is)([a-z_:-]+)\s*=\s*("([^"]*)"|'([^']*)')`)
+	tagRe     = regexp.MustCompile(`<[^>]*>`)
+)
+
+// Bookmark is a link found in a bookmarks export.
+type Bookmark struct {
+	URL     string
+	Title   string
+	Folders []string // Enclosing folder names, outermost first
+	Tags    []string // From the TAGS attribute written by Pocket and Firefox
+}
+
+// ParseNetscape extracts the links from a Netscape bookmarks file, the HTML format browsers
+// and Pocket export. Browser root folders such as the bookmarks toolbar are left out of
+// Folders. Links that aren't http or https (javascript:, place: and the like) are skipped.
+func ParseNetscape(doc string) ([]Bookmark, error) {
+	var (
+		bookmarks     []Bookmark
+		folders       []string // One entry per open <DL>, "" for unnamed or root folders
+		pendingFolder string   // Name of the last <H3>, applied to the <DL> that follows it
+		sawLink       bool
+	)
+
+	for pos := 0; ; {
+		loc := elementRe.FindStringSubmatchIndex(doc[pos:])
+		if loc == nil {
+			break
+		}
+		closing := doc[pos+loc[2]:pos+loc[3]] == "/"
+		name := strings.ToLower(doc[pos+loc[4] : pos+loc[5]])
+		attrs := parseAttributes(doc[pos+loc[6] : pos+loc[7]])
+		pos += loc[1]
+
+		switch {
+		case name == "dl" && !closing:
+			folders = append(folders, pendingFolder)
+			pendingFolder = ""
+		case name == "dl" && closing:
+			if len(folders) > 0 {
+				folders = folders[:len(folders)-1]
+			}
+		case name == "h3" && !closing:
+			text, end := elementText(doc, pos, "h3")
+			pos = end
+			pendingFolder = cleanText(text)
+			if attrs["personal_toolbar_folder"] == "true" || attrs["unfiled_bookmarks_folder"] == "true" {
+				pendingFolder = ""
+			}
+		case name == "a" && !closing:
+			text, end := elementText(doc, pos, "a")
+			pos = end
+			sawLink = true
+
+			link := strings.TrimSpace(html.UnescapeString(attrs["href"]))
+			if u, err := url.Parse(link); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				continue
+			}
+			bookmarks = append(bookmarks, Bookmark{
+				URL:     link,
+				Title:   cleanText(text),
+				Folders: nonEmpty(folders),
+				Tags:    splitTags(html.UnescapeString(attrs["tags"])),
+			})
+		}
+	}
+
+	if !sawLink {
+		return nil, ErrNotBookmarksFile
+	}
+	return bookmarks, nil
+}
+
+// elementText returns the text from pos up to the closing tag of element, and the
+// position after that tag. An unclosed element runs to the end of the document.
+func elementText(doc string, pos int, element string) (string, int) {
+	end := strings.Index(strings.ToLower(doc[pos:]), "</"+element)
+	if end < 0 {
+		return doc[pos:], len(doc)
+	}
+	return doc[pos : pos+end], pos + end
+}
+
+// parseAttributes returns the lower-cased attribute names of a tag mapped to their values.
+func parseAttributes(tag string) map[string]string {
+	attrs := make(map[string]string)
+	for _, m := range attrRe.FindAllStringSubmatch(tag, -1) {
+		value := m[3]
+		if value == "" {
+			value = m[4]
+		}
+		attrs[strings.ToLower(m[1])] = value
+	}
+	return attrs
+}
+
+// cleanText strips any markup, unescapes HTML entities and collapses whitespace.
+func cleanText(s string) string {
+	s = tagRe.ReplaceAllString(s, " ")
+	return strings.Join(strings.Fields(html.UnescapeString(s)), " ")
+}
+
+// nonEmpty returns a copy of names without the empty entries.
+func nonEmpty(names []string) []string {
+	var out []string
+	for _, name := range names {
+		if name != "" {
+			out = append(out, name)
+		}
+	}
+	return out
+}
+
+// splitTags splits a comma-separated TAGS attribute.
+func splitTags(raw string) []string {
+	var tags []string
+	for _, tag := range strings.Split(raw, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
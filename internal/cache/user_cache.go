@@ -0,0 +1,147 @@
+package cache
+
+import (
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UserCache is an in-process cache of per-user values that expire after a TTL.
+// Each user can hold several values under different keys (e.g. one per page of a list),
+// and Invalidate drops all of them at once. It is safe for concurrent use.
+//
+// Values computed while an invalidation happens must not be stored, or stale data would
+// outlive the change. Callers therefore take Generation before loading a value and pass it
+// to Set, which ignores the value if the user was invalidated in between. A load is assumed
+// to take at most maxLoad, so invalidations older than that plus the TTL are forgotten.
+//
+// A nil UserCache caches nothing.
+type UserCache[V any] struct {
+	ttl     time.Duration
+	maxLoad time.Duration
+
+	mu          sync.Mutex
+	entries     map[uuid.UUID]map[string]entry[V]
+	generations map[uuid.UUID]generation // Bumped by Invalidate
+	nextGen     uint64
+	lastSweep   time.Time
+}
+
+type entry[V any] struct {
+	value     V
+	expiresAt time.Time
+}
+
+type generation struct {
+	n             uint64
+	invalidatedAt time.Time
+}
+
+// NewUserCache creates a UserCache whose values live for ttl, for values that take at most
+// maxLoad to load, such as the longest request timeout. It returns nil, a cache that stores
+// nothing, when ttl is 0 or less.
+func NewUserCache[V any](ttl, maxLoad time.Duration) *UserCache[V] {
+	if ttl <= 0 {
+		return nil
+	}
+	return &UserCache[V]{
+		ttl:         ttl,
+		maxLoad:     maxLoad,
+		entries:     make(map[uuid.UUID]map[string]entry[V]),
+		generations: make(map[uuid.UUID]generation),
+		lastSweep:   time.Now(),
+	}
+}
+
+// TTL returns how long values are cached, 0 for a nil cache.
+func (c *UserCache[V]) TTL() time.Duration {
+	if c == nil {
+		return 0
+	}
+	return c.ttl
+}
+
+// Get returns the user's unexpired value stored under key.
+func (c *UserCache[V]) Get(userID uuid.UUID, key string) (V, bool) {
+	var zero V
+	if c == nil {
+		return zero, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[userID][key]
+	if !ok || !time.Now().Before(e.expiresAt) {
+		return zero, false
+	}
+	return e.value, true
+}
+
+// Generation returns the user's current generation, to be passed to Set.
+func (c *UserCache[V]) Generation(userID uuid.UUID) uint64 {
+	if c == nil {
+		return 0
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.generations[userID].n
+}
+
+// Set stores value for the user under key, unless the user has been invalidated since
+// generation was taken.
+func (c *UserCache[V]) Set(userID uuid.UUID, key string, value V, generation uint64) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	c.sweep(now)
+	if c.generations[userID].n != generation {
+		return
+	}
+	if c.entries[userID] == nil {
+		c.entries[userID] = make(map[string]entry[V])
+	}
+	c.entries[userID][key] = entry[V]{value: value, expiresAt: now.Add(c.ttl)}
+}
+
+// Invalidate drops every value cached for the user.
+func (c *UserCache[V]) Invalidate(userID uuid.UUID) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.entries, userID)
+	c.nextGen++
+	c.generations[userID] = generation{n: c.nextGen, invalidatedAt: time.Now()}
+}
+
+// sweep removes expired values, at most once per TTL. A forgotten generation reads as 0
+// again, which would let through a Set that took its generation before the invalidation,
+// so generations are only forgotten once no load started before them can still be running.
+func (c *UserCache[V]) sweep(now time.Time) {
+	if now.Sub(c.lastSweep) < c.ttl {
+		return
+	}
+	c.lastSweep = now
+	for userID, values := range c.entries {
+		for key, e := range values {
+			if !now.Before(e.expiresAt) {
+				delete(values, key)
+			}
+		}
+		if len(values) == 0 {
+			delete(c.entries, userID)
+		}
+	}
+	for userID, g := range c.generations {
+		if now.Sub(g.invalidatedAt) > c.ttl+c.maxLoad {
+			delete(c.generations, userID)
+		}
+	}
+}
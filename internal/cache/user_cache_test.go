@@ -0,0 +1,160 @@
+package cache
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestUserCacheGetSet(t *testing.T) {
+	c := NewUserCache[string](time.Minute, time.Second)
+	alice, bob := uuid.New(), uuid.New()
+
+	c.Set(alice, "page1", "alice's tags", c.Generation(alice))
+	if got, ok := c.Get(alice, "page1"); !ok || got != "alice's tags" {
+		t.Errorf("Get(alice, page1) = %q, %v, want the stored value", got, ok)
+	}
+	if _, ok := c.Get(alice, "page2"); ok {
+		t.Error("Get(alice, page2) found a value that was never stored")
+	}
+	if _, ok := c.Get(bob, "page1"); ok {
+		t.Error("Get(bob, page1) found another user's value")
+	}
+
+	c.Invalidate(alice)
+	if _, ok := c.Get(alice, "page1"); ok {
+		t.Error("Get() found a value after Invalidate()")
+	}
+}
+
+func TestUserCacheExpires(t *testing.T) {
+	c := NewUserCache[int](20*time.Millisecond, time.Second)
+	userID := uuid.New()
+
+	c.Set(userID, "key", 1, c.Generation(userID))
+	if _, ok := c.Get(userID, "key"); !ok {
+		t.Fatal("Get() missed a fresh value")
+	}
+	time.Sleep(30 * time.Millisecond)
+	if _, ok := c.Get(userID, "key"); ok {
+		t.Error("Get() returned a value past its TTL")
+	}
+}
+
+func TestUserCacheSetAfterInvalidateIsIgnored(t *testing.T) {
+	c := NewUserCache[string](time.Minute, time.Second)
+	userID := uuid.New()
+
+	// A request takes the generation and starts loading...
+	generation := c.Generation(userID)
+	// ...a write invalidates the user meanwhile...
+	c.Invalidate(userID)
+	// ...and the request then tries to cache what it loaded before the write
+	c.Set(userID, "key", "stale", generation)
+
+	if got, ok := c.Get(userID, "key"); ok {
+		t.Errorf("Get() = %q, want the stale value to be dropped", got)
+	}
+
+	c.Set(userID, "key", "fresh", c.Generation(userID))
+	if got, ok := c.Get(userID, "key"); !ok || got != "fresh" {
+		t.Errorf("Get() = %q, %v, want the value loaded after the invalidation", got, ok)
+	}
+}
+
+// TestUserCacheConcurrentUse is meant for go test -race. Readers load a version number,
+// writers bump it and invalidate; a cached value must never be older than the version
+// that was current when the last invalidation finished.
+func TestUserCacheConcurrentUse(t *testing.T) {
+	c := NewUserCache[int](time.Minute, time.Second)
+	userID := uuid.New()
+
+	var (
+		versionMu sync.Mutex
+		version   int
+	)
+	load := func() int {
+		versionMu.Lock()
+		defer versionMu.Unlock()
+		return version
+	}
+
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			key := strconv.Itoa(i % 2)
+			for range 500 {
+				if _, ok := c.Get(userID, key); ok {
+					continue
+				}
+				generation := c.Generation(userID)
+				c.Set(userID, key, load(), generation)
+			}
+		}()
+	}
+	for range 2 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 200 {
+				versionMu.Lock()
+				version++
+				versionMu.Unlock()
+				c.Invalidate(userID)
+			}
+		}()
+	}
+	wg.Wait()
+
+	current := load()
+	for _, key := range []string{"0", "1"} {
+		if got, ok := c.Get(userID, key); ok && got != current {
+			t.Errorf("Get(%s) = %d after all writes finished, want %d or nothing", key, got, current)
+		}
+	}
+}
+
+func TestUserCacheForgetsOldGenerations(t *testing.T) {
+	c := NewUserCache[int](20*time.Millisecond, 100*time.Millisecond)
+	alice, bob := uuid.New(), uuid.New()
+	generations := func() int {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		return len(c.generations)
+	}
+
+	c.Invalidate(alice)
+	// Past the TTL, so Set sweeps, but a load started before the invalidation may still run
+	time.Sleep(40 * time.Millisecond)
+	c.Set(bob, "key", 1, c.Generation(bob))
+	if got := generations(); got != 1 {
+		t.Fatalf("%d generations kept within TTL plus maxLoad, want 1", got)
+	}
+
+	time.Sleep(120 * time.Millisecond)
+	c.Set(bob, "key", 1, c.Generation(bob))
+	if got := generations(); got != 0 {
+		t.Errorf("%d generations kept past TTL plus maxLoad, want 0", got)
+	}
+	if got := c.Generation(alice); got != 0 {
+		t.Errorf("Generation(alice) = %d after it was forgotten, want 0", got)
+	}
+}
+
+func TestNilUserCache(t *testing.T) {
+	c := NewUserCache[int](0, time.Second)
+	if c != nil {
+		t.Fatal("NewUserCache(0) is not nil")
+	}
+	userID := uuid.New()
+	c.Set(userID, "key", 1, c.Generation(userID))
+	if _, ok := c.Get(userID, "key"); ok {
+		t.Error("nil cache returned a value")
+	}
+	c.Invalidate(userID)
+}
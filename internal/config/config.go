@@ -1,33 +1,212 @@
 package config
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"log" // Using log for consistency
+	"net/mail"
+	"net/netip"
+	"net/url"
 	"os"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
+	_ "time/tzdata" // Embedded IANA database, so time zones load on hosts without one
 
+	"github.com/google/uuid"
 	"github.com/joho/godotenv"
 	_ "github.com/lib/pq" // PostgreSQL driver
+	"github.com/nouvadev/dropwise/internal/anomaly"
+	"github.com/nouvadev/dropwise/internal/audit"
+	"github.com/nouvadev/dropwise/internal/auth"
+	"github.com/nouvadev/dropwise/internal/cache"
 	db "github.com/nouvadev/dropwise/internal/database/sqlc"
+	"github.com/nouvadev/dropwise/internal/email"
+	"github.com/nouvadev/dropwise/internal/events"
+	"github.com/nouvadev/dropwise/internal/metadata"
+	"github.com/nouvadev/dropwise/internal/schedule"
+	"github.com/redis/go-redis/v9"
 )
 
 var (
 	dbOnce        sync.Once
 	globalDBConn  *sql.DB     // Holds the global connection pool
 	globalQueries *db.Queries // Holds the global sqlc Queries instance
-	initConfigErr error       // To store any error during one-time initialization
+	// The read replica's pool (DB_REPLICA_URL), and Queries running on it that fall back to
+	// the primary. Without a replica globalReadQueries is globalQueries.
+	globalReplicaConn *sql.DB
+	globalReadQueries *db.Queries
+	initConfigErr     error // To store any error during one-time initialization
+
+	smtpWarningOnce sync.Once // LoadConfig can run more than once per process; warn about missing SMTP once
 )
 
+// MaxJWTExpiration caps the lifetime of access tokens set with JWT_EXPIRATION_MINUTES.
+// Startup fails above it, so a typo can't issue tokens that are effectively permanent.
+// Sessions that must outlive it should use refresh tokens, not longer-lived access tokens.
+const MaxJWTExpiration = 7 * 24 * time.Hour
+
 // APIConfig holds application-wide configurations.
 type APIConfig struct {
-	DB            *db.Queries
+	DB     *db.Queries
+	DBConn *sql.DB // Underlying connection pool, used to run transactions
+	// DBRead runs list and search queries on the read replica (DB_REPLICA_URL), falling back
+	// to the primary while the replica is unreachable. Replicas lag behind, so reads that must
+	// see the request's own writes use DB. Without a replica DBRead is DB.
+	DBRead        *db.Queries
 	Port          string
-	DB_URL        string // Storing for reference, actual connection is globalDBConn
-	JWTSecret     string
+	DB_URL        string       // Storing for reference, actual connection is globalDBConn
+	JWTKeys       auth.JWTKeys // Algorithm (JWT_ALGO) and keys used to sign and verify tokens
 	JWTExpiration time.Duration
+	// JWTCache skips re-verifying the signature of a token seen within JWT_CACHE_TTL, holding
+	// up to JWT_CACHE_MAX_ENTRIES tokens. Nil (the default) verifies every request.
+	JWTCache *auth.TokenCache
+
+	// PasswordHasher hashes new passwords (PASSWORD_HASH_ALGO). Existing hashes of any
+	// supported algorithm still verify; with PasswordRehashOnLogin they are upgraded on login.
+	PasswordHasher        auth.Hasher
+	PasswordRehashOnLogin bool
+
+	// DefaultTimezone (DEFAULT_TIMEZONE) applies to users without a timezone preference.
+	DefaultTimezone *time.Location
+
+	// Debug enables development-only conveniences such as ?pretty=true JSON output.
+	Debug bool
+	// DebugLogBodies (DEBUG_LOG_BODIES, only honoured with DEBUG) logs request and response
+	// bodies, redacted and cut off at DebugLogBodiesMaxBytes (DEBUG_LOG_BODIES_MAX_BYTES).
+	DebugLogBodies         bool
+	DebugLogBodiesMaxBytes int
+
+	// Request logging: successful (2xx) requests are logged 1 in LogSampleRate,
+	// everything else and anything slower than LogSlowThreshold is always logged.
+	LogSampleRate    int
+	LogSlowThreshold time.Duration
+
+	// Request timeouts, chosen per route in the router: ReadRequestTimeout for simple reads,
+	// LongRequestTimeout for bulk, import and export endpoints, RequestTimeout for the rest.
+	RequestTimeout     time.Duration
+	ReadRequestTimeout time.Duration
+	LongRequestTimeout time.Duration
+
+	// EnforceJSONContentType makes write endpoints reject bodies not sent as application/json.
+	EnforceJSONContentType bool
+
+	// Security headers: HSTS is opt-in (HSTS_MAX_AGE_SECONDS > 0) since it is only safe behind HTTPS.
+	HSTSMaxAge            time.Duration
+	ContentSecurityPolicy string
+
+	// URL limits, applied to every route: MaxURLLength (MAX_URL_LENGTH) for the whole request
+	// target and MaxQueryParamLength (MAX_QUERY_PARAM_LENGTH) for each query parameter. 0 disables one.
+	MaxURLLength        int
+	MaxQueryParamLength int
+
+	// Global per-IP rate limit, applied to every route. TrustedProxies lists the ranges whose
+	// X-Forwarded-For header is believed when resolving the client IP.
+	RateLimitEnabled   bool
+	RateLimitPerMinute int
+	TrustedProxies     []netip.Prefix
+
+	// IntrospectRateLimitPerMinute (INTROSPECT_RATE_LIMIT_PER_MINUTE) is the tighter per-IP
+	// limit of POST /api/v1/auth/introspect. 0 leaves only the global limit.
+	IntrospectRateLimitPerMinute int
+
+	// Redis (REDIS_URL) is shared by every API instance; when set the rate limit counters
+	// live there instead of in each process. Nil when REDIS_URL is unset.
+	Redis *redis.Client
+
+	// MetadataFetcher limits the server-side fetch of page titles and excerpts for new drops.
+	MetadataFetcher metadata.FetcherConfig
+
+	// DueWindow widens the worker's notion of "due" by a grace window (SEND_GRACE_WINDOW)
+	// and guards against resending a drop too soon (MIN_RESEND_INTERVAL).
+	DueWindow schedule.DueWindow
+
+	// WorkerMaxSendsPerRun (WORKER_MAX_SENDS_PER_RUN) caps how many users one worker run
+	// sends to, so a large backlog is drained over several runs. 0 means no cap.
+	WorkerMaxSendsPerRun int
+
+	// MaxSendCount (MAX_SEND_COUNT) is how many times a drop is sent before it graduates and
+	// leaves the due queue. Permanent drops never graduate. 0 means no cap.
+	MaxSendCount int
+
+	// ReviewLockDuration (REVIEW_LOCK_DURATION) is how long a review session holds the drops
+	// it hands out before the worker and other sessions may take them again.
+	ReviewLockDuration time.Duration
+
+	// WorkerAlert flags worker runs in which too many sends failed.
+	WorkerAlert WorkerAlertConfig
+
+	// WorkerTriggerSecret (WORKER_TRIGGER_SECRET) must be sent in the X-Worker-Secret header
+	// to trigger the worker over HTTP. Only with DEBUG=true may it be left unset.
+	WorkerTriggerSecret string
+
+	// Outgoing email used by the worker. EmailThrottle paces deliveries to stay under provider limits.
+	SMTP          email.SMTPConfig
+	EmailThrottle email.ThrottleConfig
+	// EmailSelfTest (EMAIL_SELF_TEST) is one of the email.SelfTest* modes: whether the worker
+	// checks the SMTP server when it starts, and whether a failure stops it.
+	EmailSelfTest string
+
+	// EmailTemplates renders drop reminders, from EMAIL_TEMPLATE_DIR where it has a template
+	// and the built-in defaults otherwise. AppBaseURL (APP_BASE_URL) is passed to the templates.
+	EmailTemplates *email.Templates
+	AppBaseURL     string
+
+	// Events carries drop change notifications to the clients streaming /api/v1/drops/events.
+	// They go through Postgres so the worker's events reach the API server's clients.
+	Events *events.Broker
+
+	// Audit records security-sensitive actions in the audit_log table (AUDIT_LOG_ENABLED).
+	Audit *audit.Logger
+
+	// TagsCache holds tag listings per user for TAGS_CACHE_TTL; drop changes invalidate it.
+	// TagsMaxAge (TAGS_MAX_AGE) is the max-age clients are allowed to cache a listing for.
+	TagsCache  *cache.UserCache[TagsListing]
+	TagsMaxAge time.Duration
+	// TagsMaxResults (TAGS_MAX_RESULTS) caps the tags returned by one list page or search.
+	TagsMaxResults int
+
+	// FuzzySearchThreshold (FUZZY_SEARCH_THRESHOLD) is the trigram similarity, 0 to 1, a drop
+	// needs to be returned by a fuzzy drop search. Lower values tolerate more typos.
+	FuzzySearchThreshold float64
+
+	// DropCreationMonitor flags users creating drops faster than DROP_CREATION_ALERT_THRESHOLD
+	// per DROP_CREATION_ALERT_WINDOW, optionally alerting ADMIN_ALERT_EMAIL.
+	DropCreationMonitor *anomaly.CreationMonitor
+
+	// InboundEmailSigningKey (INBOUND_EMAIL_SIGNING_KEY) verifies the Mailgun webhook behind
+	// POST /api/v1/inbound/email. The endpoint is disabled when it is empty.
+	InboundEmailSigningKey string
+	// InboundEmailAddress (INBOUND_EMAIL_ADDRESS) is the lower-cased address Mailgun receives
+	// forwarded mail on. Each user gets it with their secret token after a "+".
+	InboundEmailAddress string
+
+	// AllowedEmailDomains (ALLOWED_EMAIL_DOMAINS) restricts signups to these lower-cased
+	// email domains. Empty allows every domain.
+	AllowedEmailDomains map[string]bool
+
+	// AdminUserIDs lists the users (ADMIN_USER_IDS) allowed to call /api/v1/admin endpoints.
+	AdminUserIDs map[uuid.UUID]bool
+
+	// Maintenance puts the API in read-only mode; MaintenanceRetryAfter is what rejected
+	// clients are told to wait before retrying.
+	Maintenance           *MaintenanceMode
+	MaintenanceRetryAfter time.Duration
+}
+
+// WorkerAlertConfig decides when a worker run counts as degraded and who hears about it.
+type WorkerAlertConfig struct {
+	FailureRatio float64 // Failed share of a run's sends above which it is degraded, 0 disables (WORKER_ALERT_FAILURE_RATIO)
+	MinAttempts  int     // Runs with fewer sends are never degraded, so one failure isn't an outage (WORKER_ALERT_MIN_SENDS)
+	WebhookURL   string  // Optional URL posted to for a degraded run (WORKER_ALERT_WEBHOOK_URL)
+}
+
+// TagsListing is one cached page of a user's tag list.
+type TagsListing struct {
+	Tags  []db.ListTagsForUserRow
+	Total int64
 }
 
 // initializeGlobalDB is responsible for setting up the database connection pool and queries object.
@@ -46,7 +225,7 @@ func initializeGlobalDB() {
 		return
 	}
 
-	conn, err := sql.Open("postgres", dbURL)
+	conn, err := sql.Open("postgres", withUTCTimezone(dbURL))
 	if err != nil {
 		initConfigErr = fmt.Errorf("cannot open database connection: %w", err)
 		log.Println(initConfigErr) // Log the error
@@ -59,7 +238,7 @@ func initializeGlobalDB() {
 	conn.SetConnMaxLifetime(5 * time.Minute)
 	conn.SetConnMaxIdleTime(1 * time.Minute)
 
-	err = conn.Ping()
+	err = pingWithRetry(conn, getEnvInt("DB_CONNECT_MAX_ATTEMPTS", 5), getEnvDuration("DB_CONNECT_RETRY_INTERVAL", time.Second))
 	if err != nil {
 		conn.Close() // Close the connection if ping fails
 		initConfigErr = fmt.Errorf("cannot connect to database (ping failed): %w", err)
@@ -70,6 +249,83 @@ func initializeGlobalDB() {
 	globalDBConn = conn
 	globalQueries = db.New(globalDBConn)
 	log.Println("Database connection pool initialized successfully.")
+
+	globalReadQueries = globalQueries
+	if replicaURL := os.Getenv("DB_REPLICA_URL"); replicaURL != "" {
+		initializeReplicaDB(replicaURL)
+	}
+}
+
+// initializeReplicaDB sets up the read replica's connection pool. A replica that can't be
+// reached at startup is left out, sending every read to the primary, rather than stopping
+// the service.
+func initializeReplicaDB(replicaURL string) {
+	conn, err := sql.Open("postgres", withUTCTimezone(replicaURL))
+	if err != nil {
+		log.Printf("Cannot open read replica connection, reading from the primary: %v", err)
+		return
+	}
+
+	conn.SetMaxOpenConns(5)
+	conn.SetMaxIdleConns(2)
+	conn.SetConnMaxLifetime(5 * time.Minute)
+	conn.SetConnMaxIdleTime(1 * time.Minute)
+
+	if err := conn.Ping(); err != nil {
+		conn.Close()
+		log.Printf("Cannot connect to read replica (ping failed), reading from the primary: %v", err)
+		return
+	}
+
+	globalReplicaConn = conn
+	globalReadQueries = db.New(&replicaDB{replica: conn, primary: globalDBConn})
+	log.Println("Read replica connection pool initialized successfully.")
+}
+
+// maxDBConnectRetryInterval caps the exponential backoff between database connection attempts.
+const maxDBConnectRetryInterval = 30 * time.Second
+
+// pingWithRetry pings the database up to maxAttempts times, waiting interval after the first
+// failure and doubling the wait after each one, so the service can start before the database
+// is ready. It returns the last error once every attempt has failed.
+func pingWithRetry(conn *sql.DB, maxAttempts int, interval time.Duration) error {
+	var err error
+	for attempt := 1; ; attempt++ {
+		if err = conn.Ping(); err == nil {
+			if attempt > 1 {
+				log.Printf("Connected to database on attempt %d/%d.", attempt, maxAttempts)
+			}
+			return nil
+		}
+		if attempt >= maxAttempts {
+			return err
+		}
+		log.Printf("Database not reachable (attempt %d/%d), retrying in %v: %v", attempt, maxAttempts, interval, err)
+		time.Sleep(interval)
+		interval = min(interval*2, maxDBConnectRetryInterval)
+	}
+}
+
+// withUTCTimezone forces the session time zone to UTC so that timestamps read from
+// TIMESTAMPTZ columns come back as UTC regardless of the server's default time zone.
+// An explicit timezone already present in the connection string is left untouched.
+func withUTCTimezone(dbURL string) string {
+	if strings.HasPrefix(dbURL, "postgres://") || strings.HasPrefix(dbURL, "postgresql://") {
+		u, err := url.Parse(dbURL)
+		if err != nil {
+			return dbURL // Let sql.Open report the malformed URL
+		}
+		q := u.Query()
+		if q.Get("timezone") == "" {
+			q.Set("timezone", "UTC")
+			u.RawQuery = q.Encode()
+		}
+		return u.String()
+	}
+	if strings.Contains(dbURL, "timezone=") {
+		return dbURL
+	}
+	return dbURL + " timezone=UTC" // key=value connection string
 }
 
 // GetDBQueries returns the initialized sqlc Queries object, ensuring one-time initialization.
@@ -107,9 +363,9 @@ func LoadConfig() (*APIConfig, error) {
 	}
 
 	// Load JWT Configuration
-	jwtSecret := os.Getenv("JWT_SECRET")
-	if jwtSecret == "" {
-		return nil, fmt.Errorf("JWT_SECRET environment variable not set")
+	jwtKeys, err := loadJWTKeys()
+	if err != nil {
+		return nil, err
 	}
 
 	jwtExpMinutesStr := os.Getenv("JWT_EXPIRATION_MINUTES")
@@ -119,18 +375,499 @@ func LoadConfig() (*APIConfig, error) {
 		jwtExpMinutes = 60 // Default to 60 minutes
 	}
 	jwtExpiration := time.Duration(jwtExpMinutes) * time.Minute
+	if jwtExpiration > MaxJWTExpiration {
+		err := fmt.Errorf("JWT_EXPIRATION_MINUTES is %d (%v), above the maximum token lifetime of %v; use refresh tokens for longer sessions",
+			jwtExpMinutes, jwtExpiration, MaxJWTExpiration)
+		log.Println(err)
+		return nil, err
+	}
+
+	jwtCache := auth.NewTokenCache(getEnvDuration("JWT_CACHE_TTL", 0), getEnvInt("JWT_CACHE_MAX_ENTRIES", 10000))
+
+	defaultTimezoneName := os.Getenv("DEFAULT_TIMEZONE")
+	if defaultTimezoneName == "" {
+		defaultTimezoneName = "UTC"
+	}
+	defaultTimezone, err := schedule.LoadTimezone(defaultTimezoneName)
+	if err != nil {
+		return nil, fmt.Errorf("invalid DEFAULT_TIMEZONE '%s': %w", defaultTimezoneName, err)
+	}
+
+	// Load password hashing configuration
+	passwordHashAlgo := os.Getenv("PASSWORD_HASH_ALGO")
+	passwordHasher, err := auth.NewHasher(passwordHashAlgo)
+	if err != nil {
+		log.Printf("PASSWORD_HASH_ALGO invalid ('%s'), defaulting to %s. Error: %v", passwordHashAlgo, auth.AlgoBcrypt, err)
+		passwordHasher, _ = auth.NewHasher(auth.AlgoBcrypt)
+	}
+	passwordRehashOnLogin := getEnvBool("PASSWORD_REHASH_ON_LOGIN", true)
+
+	// Load request logging configuration
+	logSampleRate := getEnvInt("LOG_SAMPLE_RATE", 1) // Log every request by default
+	logSlowMs := getEnvInt("LOG_SLOW_REQUEST_MS", 1000)
+
+	debug := getEnvBool("DEBUG", false)
+	debugLogBodies := getEnvBool("DEBUG_LOG_BODIES", false)
+	if debugLogBodies && !debug {
+		log.Println("DEBUG_LOG_BODIES is set without DEBUG=true, request and response bodies will not be logged.")
+		debugLogBodies = false
+	}
+	debugLogBodiesMaxBytes := getEnvInt("DEBUG_LOG_BODIES_MAX_BYTES", 4096)
+
+	enforceJSONContentType := getEnvBool("ENFORCE_JSON_CONTENT_TYPE", true)
+
+	// Load security header configuration
+	hstsMaxAgeSeconds := getEnvInt("HSTS_MAX_AGE_SECONDS", 0) // Disabled unless explicitly set
+	contentSecurityPolicy, ok := os.LookupEnv("CONTENT_SECURITY_POLICY")
+	if !ok {
+		contentSecurityPolicy = "default-src 'none'; frame-ancestors 'none'" // The API only serves JSON
+	}
+
+	// Load request timeout configuration
+	requestTimeout := getEnvDuration("REQUEST_TIMEOUT", 30*time.Second)
+	readRequestTimeout := getEnvDuration("READ_REQUEST_TIMEOUT", 10*time.Second)
+	longRequestTimeout := getEnvDuration("LONG_REQUEST_TIMEOUT", 2*time.Minute)
+
+	// Load URL length limits
+	maxURLLength := getEnvNonNegativeInt("MAX_URL_LENGTH", 4096)
+	maxQueryParamLength := getEnvNonNegativeInt("MAX_QUERY_PARAM_LENGTH", 1024)
+
+	// Load rate limiting configuration
+	rateLimitEnabled := getEnvBool("RATE_LIMIT_ENABLED", true)
+	rateLimitPerMinute := getEnvInt("RATE_LIMIT_PER_MINUTE", 100)
+	trustedProxies := getEnvPrefixes("TRUSTED_PROXY_CIDRS")
+	introspectRateLimitPerMinute := getEnvNonNegativeInt("INTROSPECT_RATE_LIMIT_PER_MINUTE", 10)
+	var redisClient *redis.Client
+	if redisURL := strings.TrimSpace(os.Getenv("REDIS_URL")); redisURL != "" {
+		redisOpts, err := redis.ParseURL(redisURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid REDIS_URL: %w", err)
+		}
+		redisClient = redis.NewClient(redisOpts)
+	}
+
+	// Load metadata fetcher guardrails
+	fetcherCfg := metadata.DefaultFetcherConfig()
+	fetcherCfg.Timeout = time.Duration(getEnvInt("METADATA_FETCH_TIMEOUT_MS", int(fetcherCfg.Timeout/time.Millisecond))) * time.Millisecond
+	fetcherCfg.MaxBodyBytes = int64(getEnvInt("METADATA_FETCH_MAX_BYTES", int(fetcherCfg.MaxBodyBytes)))
+	fetcherCfg.MaxRedirects = getEnvNonNegativeInt("METADATA_FETCH_MAX_REDIRECTS", fetcherCfg.MaxRedirects)
+	fetcherCfg.Retries = getEnvNonNegativeInt("METADATA_FETCH_RETRIES", fetcherCfg.Retries)
+	fetcherCfg.BlockedCIDRs = getEnvPrefixes("METADATA_FETCH_BLOCKED_CIDRS")
+
+	// Load due-drop selection configuration
+	dueWindow := schedule.DueWindow{
+		GraceWindow:       getEnvDuration("SEND_GRACE_WINDOW", 0),
+		MinResendInterval: getEnvDuration("MIN_RESEND_INTERVAL", 0),
+	}
+	workerMaxSendsPerRun := getEnvNonNegativeInt("WORKER_MAX_SENDS_PER_RUN", 0)
+	maxSendCount := getEnvNonNegativeInt("MAX_SEND_COUNT", 0)
+	reviewLockDuration := getEnvDuration("REVIEW_LOCK_DURATION", 30*time.Minute)
+	if reviewLockDuration == 0 {
+		log.Println("REVIEW_LOCK_DURATION must be positive, defaulting to 30m.")
+		reviewLockDuration = 30 * time.Minute
+	}
+	workerAlert := WorkerAlertConfig{
+		FailureRatio: getEnvRatio("WORKER_ALERT_FAILURE_RATIO", 0.5),
+		MinAttempts:  getEnvInt("WORKER_ALERT_MIN_SENDS", 5),
+		WebhookURL:   strings.TrimSpace(os.Getenv("WORKER_ALERT_WEBHOOK_URL")),
+	}
+	if workerAlert.WebhookURL != "" {
+		u, err := url.Parse(workerAlert.WebhookURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("invalid WORKER_ALERT_WEBHOOK_URL: must be an http or https URL")
+		}
+	}
+	workerTriggerSecret := strings.TrimSpace(os.Getenv("WORKER_TRIGGER_SECRET"))
+
+	// Load email configuration
+	smtpCfg := email.SMTPConfig{
+		Host:     os.Getenv("SMTP_HOST"), // Sending is simulated when unset
+		Port:     getEnvInt("SMTP_PORT", 587),
+		Username: os.Getenv("SMTP_USERNAME"),
+		Password: os.Getenv("SMTP_PASSWORD"),
+		From:     os.Getenv("SMTP_FROM"),
+	}
+	if smtpCfg.From == "" {
+		smtpCfg.From = "Dropwise <no-reply@dropwise.app>"
+	}
+	if !smtpCfg.Configured() {
+		if getEnvBool("EMAIL_REQUIRED", false) {
+			return nil, fmt.Errorf("EMAIL_REQUIRED is set but SMTP_HOST is empty")
+		}
+		smtpWarningOnce.Do(func() {
+			log.Println("Warning: SMTP_HOST is not set, so emails are only logged. Due drops are still marked as sent and rescheduled.")
+		})
+	}
+	emailSelfTest := strings.ToLower(strings.TrimSpace(os.Getenv("EMAIL_SELF_TEST")))
+	switch emailSelfTest {
+	case "":
+		emailSelfTest = email.SelfTestOff
+	case email.SelfTestOff, email.SelfTestWarn, email.SelfTestFail:
+	default:
+		return nil, fmt.Errorf("invalid EMAIL_SELF_TEST: must be %s, %s or %s", email.SelfTestOff, email.SelfTestWarn, email.SelfTestFail)
+	}
+	throttleCfg := email.DefaultThrottleConfig()
+	throttleCfg.RatePerSecond = getEnvNonNegativeInt("EMAIL_SEND_RATE_PER_SECOND", throttleCfg.RatePerSecond)
+	throttleCfg.Concurrency = getEnvInt("EMAIL_SEND_CONCURRENCY", throttleCfg.Concurrency)
+	throttleCfg.MaxRetries = getEnvNonNegativeInt("EMAIL_SEND_MAX_RETRIES", throttleCfg.MaxRetries)
+	throttleCfg.Backoff = time.Duration(getEnvInt("EMAIL_RATE_LIMIT_BACKOFF_MS", int(throttleCfg.Backoff/time.Millisecond))) * time.Millisecond
+	emailTemplates, err := email.LoadTemplates(strings.TrimSpace(os.Getenv("EMAIL_TEMPLATE_DIR")))
+	if err != nil {
+		return nil, fmt.Errorf("invalid EMAIL_TEMPLATE_DIR: %w", err)
+	}
+	appBaseURL := strings.TrimRight(strings.TrimSpace(os.Getenv("APP_BASE_URL")), "/")
+
+	auditLogEnabled := getEnvBool("AUDIT_LOG_ENABLED", true)
+	allowedEmailDomains := getEnvDomains("ALLOWED_EMAIL_DOMAINS")
+	inboundEmailSigningKey := strings.TrimSpace(os.Getenv("INBOUND_EMAIL_SIGNING_KEY"))
+	inboundEmailAddress, err := parseInboundEmailAddress(os.Getenv("INBOUND_EMAIL_ADDRESS"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid INBOUND_EMAIL_ADDRESS: %w", err)
+	}
+	if inboundEmailSigningKey != "" && inboundEmailAddress == "" {
+		return nil, fmt.Errorf("INBOUND_EMAIL_SIGNING_KEY is set but INBOUND_EMAIL_ADDRESS is empty")
+	}
+
+	// Load tag list caching configuration
+	tagsCacheTTL := getEnvDuration("TAGS_CACHE_TTL", 30*time.Second)
+	tagsMaxAge := getEnvDuration("TAGS_MAX_AGE", 0)
+	tagsMaxResults := getEnvInt("TAGS_MAX_RESULTS", 200)
+
+	// Load drop search configuration
+	fuzzySearchThreshold := getEnvRatio("FUZZY_SEARCH_THRESHOLD", 0.3)
+
+	// Load drop creation anomaly detection configuration
+	creationRateCfg := anomaly.CreationRateConfig{
+		Threshold:  getEnvNonNegativeInt("DROP_CREATION_ALERT_THRESHOLD", 100),
+		Window:     getEnvDuration("DROP_CREATION_ALERT_WINDOW", 10*time.Minute),
+		AlertEmail: strings.TrimSpace(os.Getenv("ADMIN_ALERT_EMAIL")),
+	}
+	auditLogger := audit.NewLogger(queries, auditLogEnabled)
+
+	// Load admin and maintenance mode configuration
+	adminUserIDs := getEnvUUIDs("ADMIN_USER_IDS")
+	// The mode is shared through the database; MAINTENANCE_MODE=true turns it on at startup,
+	// otherwise the stored mode is kept so restarting one instance doesn't turn it off for all.
+	maintenance := NewMaintenanceMode(queries, getEnvDuration("MAINTENANCE_CACHE_TTL", 5*time.Second))
+	if getEnvBool("MAINTENANCE_MODE", false) {
+		if _, err := maintenance.Set(context.Background(), true, "MAINTENANCE_MODE"); err != nil {
+			return nil, fmt.Errorf("failed to enable maintenance mode: %w", err)
+		}
+	}
+	maintenanceRetryAfter := time.Duration(getEnvInt("MAINTENANCE_RETRY_AFTER_SECONDS", 300)) * time.Second
 
 	return &APIConfig{
 		DB:            queries,
+		DBConn:        globalDBConn,
+		DBRead:        globalReadQueries,
 		Port:          port,
 		DB_URL:        dbURL,
-		JWTSecret:     jwtSecret,
+		JWTKeys:       jwtKeys,
 		JWTExpiration: jwtExpiration,
+		JWTCache:      jwtCache,
+		Debug:         debug,
+
+		DebugLogBodies:         debugLogBodies,
+		DebugLogBodiesMaxBytes: debugLogBodiesMaxBytes,
+
+		DefaultTimezone: defaultTimezone,
+
+		PasswordHasher:        passwordHasher,
+		PasswordRehashOnLogin: passwordRehashOnLogin,
+
+		LogSampleRate:    logSampleRate,
+		LogSlowThreshold: time.Duration(logSlowMs) * time.Millisecond,
+
+		RequestTimeout:     requestTimeout,
+		ReadRequestTimeout: readRequestTimeout,
+		LongRequestTimeout: longRequestTimeout,
+
+		EnforceJSONContentType: enforceJSONContentType,
+
+		HSTSMaxAge:            time.Duration(hstsMaxAgeSeconds) * time.Second,
+		ContentSecurityPolicy: contentSecurityPolicy,
+
+		MaxURLLength:        maxURLLength,
+		MaxQueryParamLength: maxQueryParamLength,
+
+		RateLimitEnabled:   rateLimitEnabled,
+		RateLimitPerMinute: rateLimitPerMinute,
+		TrustedProxies:     trustedProxies,
+
+		IntrospectRateLimitPerMinute: introspectRateLimitPerMinute,
+
+		Redis: redisClient,
+
+		MetadataFetcher: fetcherCfg,
+
+		DueWindow:            dueWindow,
+		WorkerMaxSendsPerRun: workerMaxSendsPerRun,
+		MaxSendCount:         maxSendCount,
+		ReviewLockDuration:   reviewLockDuration,
+		WorkerAlert:          workerAlert,
+		WorkerTriggerSecret:  workerTriggerSecret,
+
+		SMTP:          smtpCfg,
+		EmailThrottle: throttleCfg,
+		EmailSelfTest: emailSelfTest,
+
+		EmailTemplates: emailTemplates,
+		AppBaseURL:     appBaseURL,
+
+		Events: events.NewPostgresBroker(globalDBConn),
+
+		Audit: auditLogger,
+
+		TagsCache:      cache.NewUserCache[TagsListing](tagsCacheTTL, max(requestTimeout, readRequestTimeout, longRequestTimeout)),
+		TagsMaxAge:     tagsMaxAge,
+		TagsMaxResults: tagsMaxResults,
+
+		FuzzySearchThreshold: fuzzySearchThreshold,
+
+		DropCreationMonitor: anomaly.NewCreationMonitor(creationRateCfg, auditLogger, email.NewSender(smtpCfg)),
+
+		InboundEmailSigningKey: inboundEmailSigningKey,
+		InboundEmailAddress:    inboundEmailAddress,
+
+		AllowedEmailDomains: allowedEmailDomains,
+
+		AdminUserIDs: adminUserIDs,
+
+		Maintenance:           maintenance,
+		MaintenanceRetryAfter: maintenanceRetryAfter,
 	}, nil
 }
 
-// CloseDB closes the global database connection pool.
+// UserLocation returns the time zone of a user with the given timezone preference,
+// falling back to DefaultTimezone when it is unset or no longer valid.
+func (cfg *APIConfig) UserLocation(timezone sql.NullString) *time.Location {
+	if timezone.Valid {
+		loc, err := schedule.LoadTimezone(timezone.String)
+		if err == nil {
+			return loc
+		}
+		log.Printf("Stored timezone '%s' is invalid, using %s: %v", timezone.String, cfg.DefaultTimezone, err)
+	}
+	if cfg.DefaultTimezone == nil {
+		return time.UTC
+	}
+	return cfg.DefaultTimezone
+}
+
+// EmailDomainAllowed reports whether an account may be registered with the email address.
+// Domains are compared case-insensitively; every domain is allowed when no allowlist is set.
+func (cfg *APIConfig) EmailDomainAllowed(email string) bool {
+	if len(cfg.AllowedEmailDomains) == 0 {
+		return true
+	}
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return false
+	}
+	return cfg.AllowedEmailDomains[normalizeDomain(email[at+1:])]
+}
+
+// loadJWTKeys loads the JWT algorithm and keys. HS256 (the default) needs JWT_SECRET;
+// RS256 needs JWT_PRIVATE_KEY to issue tokens and/or JWT_PUBLIC_KEY to verify them.
+func loadJWTKeys() (auth.JWTKeys, error) {
+	algo := strings.ToUpper(strings.TrimSpace(os.Getenv("JWT_ALGO")))
+	switch algo {
+	case "", auth.AlgHS256:
+		jwtSecret := os.Getenv("JWT_SECRET")
+		if jwtSecret == "" {
+			return auth.JWTKeys{}, fmt.Errorf("JWT_SECRET environment variable not set")
+		}
+		return auth.NewHS256Keys(jwtSecret), nil
+	case auth.AlgRS256:
+		privateKey, err := getEnvPEM("JWT_PRIVATE_KEY")
+		if err != nil {
+			return auth.JWTKeys{}, err
+		}
+		publicKey, err := getEnvPEM("JWT_PUBLIC_KEY")
+		if err != nil {
+			return auth.JWTKeys{}, err
+		}
+		keys, err := auth.NewRS256Keys(privateKey, publicKey)
+		if err != nil {
+			return auth.JWTKeys{}, fmt.Errorf("invalid JWT_PRIVATE_KEY/JWT_PUBLIC_KEY: %w", err)
+		}
+		if keys.PrivateKey == nil {
+			log.Println("JWT_PRIVATE_KEY not set, tokens can be verified but not issued.")
+		}
+		return keys, nil
+	default:
+		return auth.JWTKeys{}, fmt.Errorf("unsupported JWT_ALGO '%s', expected %s or %s", algo, auth.AlgHS256, auth.AlgRS256)
+	}
+}
+
+// getEnvPEM reads a PEM-encoded key from the environment. The variable holds either the
+// PEM block itself or the path of a file containing it.
+func getEnvPEM(key string) (string, error) {
+	v := strings.TrimSpace(os.Getenv(key))
+	if v == "" || strings.HasPrefix(v, "-----BEGIN") {
+		return strings.ReplaceAll(v, `\n`, "\n"), nil // Allow single-line values with escaped newlines
+	}
+	contents, err := os.ReadFile(v)
+	if err != nil {
+		return "", fmt.Errorf("cannot read %s file: %w", key, err)
+	}
+	return string(contents), nil
+}
+
+// getEnvInt reads a positive integer from the environment, falling back to def
+// when the variable is unset or invalid.
+func getEnvInt(key string, def int) int {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	parsed, err := strconv.Atoi(v)
+	if err != nil || parsed <= 0 {
+		log.Printf("%s invalid ('%s'), defaulting to %d. Error: %v", key, v, def, err)
+		return def
+	}
+	return parsed
+}
+
+// getEnvNonNegativeInt is like getEnvInt but also accepts zero.
+func getEnvNonNegativeInt(key string, def int) int {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	parsed, err := strconv.Atoi(v)
+	if err != nil || parsed < 0 {
+		log.Printf("%s invalid ('%s'), defaulting to %d. Error: %v", key, v, def, err)
+		return def
+	}
+	return parsed
+}
+
+// getEnvRatio reads a number between 0 and 1 from the environment,
+// falling back to def when the variable is unset or invalid.
+func getEnvRatio(key string, def float64) float64 {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	parsed, err := strconv.ParseFloat(v, 64)
+	if err != nil || !(parsed >= 0 && parsed <= 1) { // Also rejects NaN
+		log.Printf("%s invalid ('%s'), defaulting to %g. Error: %v", key, v, def, err)
+		return def
+	}
+	return parsed
+}
+
+// getEnvDuration reads a non-negative Go duration (e.g. "5m") from the environment,
+// falling back to def when the variable is unset or invalid.
+func getEnvDuration(key string, def time.Duration) time.Duration {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	parsed, err := time.ParseDuration(v)
+	if err != nil || parsed < 0 {
+		log.Printf("%s invalid ('%s'), defaulting to %v. Error: %v", key, v, def, err)
+		return def
+	}
+	return parsed
+}
+
+// getEnvPrefixes reads a comma-separated list of CIDR ranges from the environment.
+// Invalid entries are logged and skipped.
+func getEnvPrefixes(key string) []netip.Prefix {
+	var prefixes []netip.Prefix
+	for _, part := range strings.Split(os.Getenv(key), ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		prefix, err := netip.ParsePrefix(part)
+		if err != nil {
+			log.Printf("%s contains an invalid CIDR ('%s'), ignoring it. Error: %v", key, part, err)
+			continue
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+	return prefixes
+}
+
+// getEnvUUIDs reads a comma-separated set of UUIDs from the environment.
+// Invalid entries are logged and skipped.
+func getEnvUUIDs(key string) map[uuid.UUID]bool {
+	ids := make(map[uuid.UUID]bool)
+	for _, part := range strings.Split(os.Getenv(key), ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		id, err := uuid.Parse(part)
+		if err != nil {
+			log.Printf("%s contains an invalid UUID ('%s'), ignoring it. Error: %v", key, part, err)
+			continue
+		}
+		ids[id] = true
+	}
+	return ids
+}
+
+// getEnvDomains reads a comma-separated set of domain names from the environment,
+// normalized with normalizeDomain. A leading "@" is accepted and ignored.
+func getEnvDomains(key string) map[string]bool {
+	domains := make(map[string]bool)
+	for _, part := range strings.Split(os.Getenv(key), ",") {
+		if domain := normalizeDomain(strings.TrimPrefix(strings.TrimSpace(part), "@")); domain != "" {
+			domains[domain] = true
+		}
+	}
+	return domains
+}
+
+// parseInboundEmailAddress validates INBOUND_EMAIL_ADDRESS and returns it lower-cased,
+// or "" when it is empty. Its local part can't hold a "+", which separates the user tokens.
+func parseInboundEmailAddress(raw string) (string, error) {
+	if strings.TrimSpace(raw) == "" {
+		return "", nil
+	}
+	addr, err := mail.ParseAddress(raw)
+	if err != nil {
+		return "", err
+	}
+	local, _, _ := strings.Cut(addr.Address, "@")
+	if strings.Contains(local, "+") {
+		return "", fmt.Errorf("%q must not contain a \"+\" before the @", addr.Address)
+	}
+	return strings.ToLower(addr.Address), nil
+}
+
+// normalizeDomain lower-cases a domain name and drops surrounding spaces and a trailing dot.
+func normalizeDomain(domain string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(domain)), ".")
+}
+
+// getEnvBool reads a boolean from the environment, falling back to def
+// when the variable is unset or invalid.
+func getEnvBool(key string, def bool) bool {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	parsed, err := strconv.ParseBool(v)
+	if err != nil {
+		log.Printf("%s invalid ('%s'), defaulting to %t. Error: %v", key, v, def, err)
+		return def
+	}
+	return parsed
+}
+
+// CloseDB closes the global database connection pools.
 func CloseDB() {
+	if globalReplicaConn != nil {
+		log.Println("Closing read replica connection pool.")
+		if err := globalReplicaConn.Close(); err != nil {
+			log.Printf("Error closing read replica connection pool: %v\n", err)
+		}
+	}
 	if globalDBConn != nil {
 		log.Println("Closing database connection pool.")
 		err := globalDBConn.Close()
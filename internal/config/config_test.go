@@ -0,0 +1,137 @@
+package config
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	db "github.com/nouvadev/dropwise/internal/database/sqlc"
+	"github.com/nouvadev/dropwise/internal/testdb"
+)
+
+func TestEmailDomainAllowed(t *testing.T) {
+	tests := []struct {
+		name    string
+		domains string // ALLOWED_EMAIL_DOMAINS
+		email   string
+		want    bool
+	}{
+		{"unconfigured allows any domain", "", "someone@anywhere.io", true},
+		{"allowed domain", "example.com,example.org", "ada@example.org", true},
+		{"disallowed domain", "example.com", "ada@example.net", false},
+		{"domain case is ignored", "Example.COM", "ada@EXAMPLE.com", true},
+		{"leading @ and spaces in the setting", " @example.com , ", "ada@example.com", true},
+		{"trailing dot on the address", "example.com", "ada@example.com.", true},
+		{"subdomain is not allowed", "example.com", "ada@mail.example.com", false},
+		{"listed subdomain is allowed", "example.com,mail.example.com", "ada@mail.example.com", true},
+		{"parent of an allowed subdomain", "mail.example.com", "ada@example.com", false},
+		{"lookalike suffix", "example.com", "ada@badexample.com", false},
+		{"last @ decides the domain", "example.com", "\"ada@evil.com\"@example.com", true},
+		{"missing @", "example.com", "example.com", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("ALLOWED_EMAIL_DOMAINS", tt.domains)
+			cfg := &APIConfig{AllowedEmailDomains: getEnvDomains("ALLOWED_EMAIL_DOMAINS")}
+			if got := cfg.EmailDomainAllowed(tt.email); got != tt.want {
+				t.Errorf("EmailDomainAllowed(%q) with %q = %v, want %v", tt.email, tt.domains, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithUTCTimezone(t *testing.T) {
+	tests := []struct {
+		name string
+		dsn  string
+		want string
+	}{
+		{"URL", "postgres://u:p@localhost:5432/dropwise?sslmode=disable", "postgres://u:p@localhost:5432/dropwise?sslmode=disable&timezone=UTC"},
+		{"postgresql URL", "postgresql://localhost/dropwise", "postgresql://localhost/dropwise?timezone=UTC"},
+		{"URL with a time zone", "postgres://localhost/dropwise?timezone=Asia%2FTokyo", "postgres://localhost/dropwise?timezone=Asia%2FTokyo"},
+		{"key=value", "host=localhost dbname=dropwise", "host=localhost dbname=dropwise timezone=UTC"},
+		{"key=value with a time zone", "host=localhost timezone=Asia/Tokyo", "host=localhost timezone=Asia/Tokyo"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := withUTCTimezone(tt.dsn); got != tt.want {
+				t.Errorf("withUTCTimezone(%q) = %q, want %q", tt.dsn, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseInboundEmailAddress(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     string
+		want    string
+		wantErr bool
+	}{
+		{"empty", "  ", "", false},
+		{"plain", "drops@in.example.com", "drops@in.example.com", false},
+		{"mixed case", "Drops@In.Example.com", "drops@in.example.com", false},
+		{"with a name", "Dropwise <drops@in.example.com>", "drops@in.example.com", false},
+		{"plus in local part", "drops+x@in.example.com", "", true},
+		{"not an address", "in.example.com", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseInboundEmailAddress(tt.raw)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseInboundEmailAddress(%q) error = %v, wantErr %v", tt.raw, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseInboundEmailAddress(%q) = %q, want %q", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCreatedDropRoundTripsAsUTC(t *testing.T) {
+	database := testdb.New(t)
+	ctx := context.Background()
+
+	// A session in another time zone shows the difference withUTCTimezone makes
+	for _, tt := range []struct {
+		name       string
+		dsn        string
+		wantOffset int
+	}{
+		{"UTC session", withUTCTimezone(database.URL), 0},
+		{"Tokyo session", withUTCTimezone(testdb.WithParam(database.URL, "timezone", "Asia/Tokyo")), 9 * 3600},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			conn, err := sql.Open("postgres", tt.dsn)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer conn.Close()
+			q := db.New(conn)
+
+			drop := testdb.CreateDrop(t, q, testdb.CreateUser(t, q), nil)
+
+			if _, offset := drop.AddedDate.Zone(); offset != tt.wantOffset {
+				t.Errorf("added_date offset = %ds, want %ds", offset, tt.wantOffset)
+			}
+			encoded, err := json.Marshal(drop.AddedDate)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if gotZ := strings.HasSuffix(string(encoded), `Z"`); gotZ != (tt.wantOffset == 0) {
+				t.Errorf("added_date encodes as %s", encoded)
+			}
+
+			// Whatever the session time zone, the instant is the same once read back
+			again, err := q.GetDrop(ctx, drop.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !again.AddedDate.Equal(drop.AddedDate) {
+				t.Errorf("added_date read back as %v, want %v", again.AddedDate, drop.AddedDate)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package config
+
+import (
+	"context"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	db "github.com/nouvadev/dropwise/internal/database/sqlc"
+)
+
+// maintenanceReadTimeout bounds how long a request waits on reading the shared mode.
+const maintenanceReadTimeout = 2 * time.Second
+
+// MaintenanceStore is where the maintenance mode is kept so every API instance shares it.
+// *db.Queries implements it.
+type MaintenanceStore interface {
+	GetMaintenanceMode(ctx context.Context) (bool, error)
+	SetMaintenanceMode(ctx context.Context, arg db.SetMaintenanceModeParams) (int64, error)
+}
+
+// MaintenanceMode is the runtime read-only switch. It is kept in a MaintenanceStore and
+// cached for cacheTTL, so a toggle reaches every instance within that time. Without a store
+// the mode lives in memory, which only suits a single instance.
+type MaintenanceMode struct {
+	store    MaintenanceStore
+	cacheTTL time.Duration
+
+	enabled    atomic.Bool
+	checkedAt  atomic.Int64 // Unix nanoseconds of the last read from store
+	refreshing sync.Mutex
+}
+
+// NewMaintenanceMode creates a MaintenanceMode backed by store, re-reading it at most once per cacheTTL.
+func NewMaintenanceMode(store MaintenanceStore, cacheTTL time.Duration) *MaintenanceMode {
+	return &MaintenanceMode{store: store, cacheTTL: cacheTTL}
+}
+
+// Enabled reports whether maintenance mode is on. It is safe to call on a nil MaintenanceMode.
+// When the cached value is older than the cache TTL, one caller re-reads the store while the
+// others keep using the cached value. If the store can't be read, the last known value is kept.
+func (m *MaintenanceMode) Enabled() bool {
+	if m == nil {
+		return false
+	}
+	if m.store != nil && time.Since(time.Unix(0, m.checkedAt.Load())) >= m.cacheTTL && m.refreshing.TryLock() {
+		defer m.refreshing.Unlock()
+		ctx, cancel := context.WithTimeout(context.Background(), maintenanceReadTimeout)
+		defer cancel()
+		enabled, err := m.store.GetMaintenanceMode(ctx)
+		if err != nil {
+			log.Printf("Warning: could not read maintenance mode, keeping the last known value: %v", err)
+		} else {
+			m.enabled.Store(enabled)
+		}
+		m.checkedAt.Store(time.Now().UnixNano())
+	}
+	return m.enabled.Load()
+}
+
+// Set turns maintenance mode on or off for every instance and logs the change along with
+// who made it. It reports whether the mode actually changed.
+func (m *MaintenanceMode) Set(ctx context.Context, enabled bool, actor string) (bool, error) {
+	changed := m.enabled.Swap(enabled) != enabled
+	if m.store != nil {
+		rows, err := m.store.SetMaintenanceMode(ctx, db.SetMaintenanceModeParams{
+			Enabled:   enabled,
+			UpdatedBy: actor,
+		})
+		if err != nil {
+			// Fall back to the store's value on the next check
+			m.checkedAt.Store(0)
+			return false, err
+		}
+		m.checkedAt.Store(time.Now().UnixNano())
+		changed = rows > 0
+	}
+	if !changed {
+		return false, nil
+	}
+	if enabled {
+		log.Printf("Maintenance mode enabled by %s, write requests will be rejected", actor)
+	} else {
+		log.Printf("Maintenance mode disabled by %s", actor)
+	}
+	return true, nil
+}
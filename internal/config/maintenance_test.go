@@ -0,0 +1,103 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	db "github.com/nouvadev/dropwise/internal/database/sqlc"
+)
+
+// fakeMaintenanceStore is an in-memory MaintenanceStore shared by several MaintenanceModes,
+// standing in for the database that API instances share.
+type fakeMaintenanceStore struct {
+	mu      sync.Mutex
+	enabled bool
+	reads   int
+	err     error
+}
+
+func (s *fakeMaintenanceStore) GetMaintenanceMode(ctx context.Context) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reads++
+	return s.enabled, s.err
+}
+
+func (s *fakeMaintenanceStore) SetMaintenanceMode(ctx context.Context, arg db.SetMaintenanceModeParams) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return 0, s.err
+	}
+	if s.enabled == arg.Enabled {
+		return 0, nil
+	}
+	s.enabled = arg.Enabled
+	return 1, nil
+}
+
+func TestMaintenanceModeSharedAcrossInstances(t *testing.T) {
+	store := &fakeMaintenanceStore{}
+	a := NewMaintenanceMode(store, 0)
+	b := NewMaintenanceMode(store, 0)
+
+	changed, err := a.Set(context.Background(), true, "test")
+	if err != nil || !changed {
+		t.Fatalf("Set(true) = %v, %v, want true, nil", changed, err)
+	}
+	if !b.Enabled() {
+		t.Error("other instance does not see maintenance mode enabled")
+	}
+
+	changed, err = b.Set(context.Background(), true, "test")
+	if err != nil || changed {
+		t.Errorf("Set(true) again = %v, %v, want false, nil", changed, err)
+	}
+
+	if _, err := b.Set(context.Background(), false, "test"); err != nil {
+		t.Fatal(err)
+	}
+	if a.Enabled() {
+		t.Error("first instance still sees maintenance mode enabled")
+	}
+}
+
+func TestMaintenanceModeCachesReads(t *testing.T) {
+	store := &fakeMaintenanceStore{enabled: true}
+	m := NewMaintenanceMode(store, time.Hour)
+
+	for range 5 {
+		if !m.Enabled() {
+			t.Fatal("Enabled() = false, want true")
+		}
+	}
+	if store.reads != 1 {
+		t.Errorf("store read %d times, want 1", store.reads)
+	}
+}
+
+func TestMaintenanceModeKeepsLastValueOnStoreError(t *testing.T) {
+	store := &fakeMaintenanceStore{enabled: true}
+	m := NewMaintenanceMode(store, 0)
+	if !m.Enabled() {
+		t.Fatal("Enabled() = false, want true")
+	}
+
+	store.err = errors.New("connection refused")
+	if !m.Enabled() {
+		t.Error("Enabled() = false after a store error, want the last known value")
+	}
+	if _, err := m.Set(context.Background(), false, "test"); err == nil {
+		t.Error("Set() error = nil, want the store error")
+	}
+}
+
+func TestMaintenanceModeNil(t *testing.T) {
+	var m *MaintenanceMode
+	if m.Enabled() {
+		t.Error("nil MaintenanceMode reports enabled")
+	}
+}
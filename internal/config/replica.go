@@ -0,0 +1,71 @@
+package config
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"log"
+	"net"
+
+	"github.com/lib/pq"
+)
+
+// replicaDB sends queries to a read replica, retrying them on the primary when the replica
+// can't be reached, so a replica outage slows reads down instead of failing them. It
+// implements db.DBTX for the read-only queries of APIConfig.DBRead.
+type replicaDB struct {
+	replica *sql.DB
+	primary *sql.DB
+}
+
+// ExecContext runs on the primary: statements without results are writes, which a
+// replica would refuse.
+func (r *replicaDB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return r.primary.ExecContext(ctx, query, args...)
+}
+
+func (r *replicaDB) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	stmt, err := r.replica.PrepareContext(ctx, query)
+	if r.shouldFallBack(ctx, err) {
+		return r.primary.PrepareContext(ctx, query)
+	}
+	return stmt, err
+}
+
+func (r *replicaDB) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	rows, err := r.replica.QueryContext(ctx, query, args...)
+	if r.shouldFallBack(ctx, err) {
+		return r.primary.QueryContext(ctx, query, args...)
+	}
+	return rows, err
+}
+
+func (r *replicaDB) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	row := r.replica.QueryRowContext(ctx, query, args...)
+	if r.shouldFallBack(ctx, row.Err()) {
+		return r.primary.QueryRowContext(ctx, query, args...)
+	}
+	return row
+}
+
+// shouldFallBack reports whether a replica query that failed with err should be retried on
+// the primary: the replica was unreachable or refused connections, and the request is still
+// live. Errors in the query itself would fail on the primary too and are returned as they are.
+func (r *replicaDB) shouldFallBack(ctx context.Context, err error) bool {
+	if err == nil || ctx.Err() != nil {
+		return false
+	}
+	var netErr net.Error
+	var pqErr *pq.Error
+	switch {
+	case errors.Is(err, driver.ErrBadConn), errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF), errors.As(err, &netErr):
+	case errors.As(err, &pqErr) && pqErr.Code.Class() == "57" && pqErr.Code != "57014":
+		// Operator intervention, e.g. a replica starting up or shutting down; 57014 is a cancelled query
+	default:
+		return false
+	}
+	log.Printf("Read replica unavailable, running the query on the primary: %v", err)
+	return true
+}
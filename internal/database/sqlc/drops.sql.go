@@ -8,29 +8,347 @@ package db
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/sqlc-dev/pqtype"
 )
 
+const clearDropDueDate = `-- name: ClearDropDueDate :one
+UPDATE drops
+SET next_send_date = scheduled_send_date, due_date = NULL, scheduled_send_date = NULL
+WHERE id = $1 AND user_uuid = $2 AND due_date IS NOT NULL
+RETURNING id, user_uuid, topic, url, user_notes, added_date, updated_at, status, last_sent_date, send_count, priority, excerpt, next_send_date, workspace_id, schedule, permanent, channel, metadata, due_date, scheduled_send_date, reviewing_until, ease_factor, interval_days
+`
+
+type ClearDropDueDateParams struct {
+	ID       uuid.UUID
+	UserUuid uuid.NullUUID
+}
+
+// Removes a drop's pinned due date and restores the next send its schedule had set.
+// Returns no row when the drop has no due date.
+func (q *Queries) ClearDropDueDate(ctx context.Context, arg ClearDropDueDateParams) (Drop, error) {
+	row := q.db.QueryRowContext(ctx, clearDropDueDate, arg.ID, arg.UserUuid)
+	var i Drop
+	err := row.Scan(
+		&i.ID,
+		&i.UserUuid,
+		&i.Topic,
+		&i.Url,
+		&i.UserNotes,
+		&i.AddedDate,
+		&i.UpdatedAt,
+		&i.Status,
+		&i.LastSentDate,
+		&i.SendCount,
+		&i.Priority,
+		&i.Excerpt,
+		&i.NextSendDate,
+		&i.WorkspaceID,
+		&i.Schedule,
+		&i.Permanent,
+		&i.Channel,
+		&i.Metadata,
+		&i.DueDate,
+		&i.ScheduledSendDate,
+		&i.ReviewingUntil,
+		&i.EaseFactor,
+		&i.IntervalDays,
+	)
+	return i, err
+}
+
+const completeDropReview = `-- name: CompleteDropReview :one
+UPDATE drops
+SET
+    status = $1,
+    last_sent_date = $2,
+    send_count = $3,
+    next_send_date = $4,
+    ease_factor = $5,
+    interval_days = $6,
+    due_date = NULL,
+    scheduled_send_date = NULL,
+    reviewing_until = NULL
+WHERE id = $7
+  AND user_uuid = $8
+  AND reviewing_until IS NOT NULL
+  AND status IN ('new', 'sent')
+RETURNING id, user_uuid, topic, url, user_notes, added_date, updated_at, status, last_sent_date, send_count, priority, excerpt, next_send_date, workspace_id, schedule, permanent, channel, metadata, due_date, scheduled_send_date, reviewing_until, ease_factor, interval_days
+`
+
+type CompleteDropReviewParams struct {
+	Status       string
+	LastSentDate sql.NullTime
+	SendCount    int32
+	NextSendDate sql.NullTime
+	EaseFactor   float64
+	IntervalDays sql.NullInt32
+	ID           uuid.UUID
+	UserUuid     uuid.NullUUID
+}
+
+// Records the outcome of reviewing a drop in a review session and releases its lock, like a
+// send: last_sent_date is the review time and send_count the drop's new count, and the SM-2
+// state is stored. Returns no row when the drop isn't in a review session, e.g. because the
+// worker sent it meanwhile.
+func (q *Queries) CompleteDropReview(ctx context.Context, arg CompleteDropReviewParams) (Drop, error) {
+	row := q.db.QueryRowContext(ctx, completeDropReview,
+		arg.Status,
+		arg.LastSentDate,
+		arg.SendCount,
+		arg.NextSendDate,
+		arg.EaseFactor,
+		arg.IntervalDays,
+		arg.ID,
+		arg.UserUuid,
+	)
+	var i Drop
+	err := row.Scan(
+		&i.ID,
+		&i.UserUuid,
+		&i.Topic,
+		&i.Url,
+		&i.UserNotes,
+		&i.AddedDate,
+		&i.UpdatedAt,
+		&i.Status,
+		&i.LastSentDate,
+		&i.SendCount,
+		&i.Priority,
+		&i.Excerpt,
+		&i.NextSendDate,
+		&i.WorkspaceID,
+		&i.Schedule,
+		&i.Permanent,
+		&i.Channel,
+		&i.Metadata,
+		&i.DueDate,
+		&i.ScheduledSendDate,
+		&i.ReviewingUntil,
+		&i.EaseFactor,
+		&i.IntervalDays,
+	)
+	return i, err
+}
+
+const countDropsByDomain = `-- name: CountDropsByDomain :many
+SELECT domain, COUNT(*) AS count
+FROM (
+    SELECT regexp_replace(
+        lower(substring(url FROM '^[A-Za-z][A-Za-z0-9+.-]*://(?:[^/?#@]*@)?([^/?#:]+)')),
+        '^www\.', ''
+    ) AS domain
+    FROM drops
+    WHERE user_uuid = $1
+      AND workspace_id IS NOT DISTINCT FROM $2
+) hosts
+WHERE domain <> ''
+GROUP BY domain
+ORDER BY count DESC, domain
+LIMIT $3
+`
+
+type CountDropsByDomainParams struct {
+	UserUuid    uuid.NullUUID
+	WorkspaceID uuid.NullUUID
+	Limit       int32
+}
+
+type CountDropsByDomainRow struct {
+	Domain string
+	Count  int64
+}
+
+// Counts the user's drops in a workspace per site, most saved first. The domain is the
+// lower-cased host of the drop's URL without a leading "www."; URLs without a host are left out.
+func (q *Queries) CountDropsByDomain(ctx context.Context, arg CountDropsByDomainParams) ([]CountDropsByDomainRow, error) {
+	rows, err := q.db.QueryContext(ctx, countDropsByDomain, arg.UserUuid, arg.WorkspaceID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []CountDropsByDomainRow
+	for rows.Next() {
+		var i CountDropsByDomainRow
+		if err := rows.Scan(&i.Domain, &i.Count); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const countDropsByStatus = `-- name: CountDropsByStatus :many
+SELECT status, COUNT(*) AS count
+FROM drops
+WHERE user_uuid = $1
+  AND workspace_id IS NOT DISTINCT FROM $2
+GROUP BY status
+ORDER BY status
+`
+
+type CountDropsByStatusParams struct {
+	UserUuid    uuid.NullUUID
+	WorkspaceID uuid.NullUUID
+}
+
+type CountDropsByStatusRow struct {
+	Status string
+	Count  int64
+}
+
+// Counts the user's drops in a workspace per status.
+func (q *Queries) CountDropsByStatus(ctx context.Context, arg CountDropsByStatusParams) ([]CountDropsByStatusRow, error) {
+	rows, err := q.db.QueryContext(ctx, countDropsByStatus, arg.UserUuid, arg.WorkspaceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []CountDropsByStatusRow
+	for rows.Next() {
+		var i CountDropsByStatusRow
+		if err := rows.Scan(&i.Status, &i.Count); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const countDueDrops = `-- name: CountDueDrops :one
+SELECT COUNT(*)
+FROM drops d
+WHERE (
+    (d.status = 'new' AND (d.next_send_date IS NULL OR d.next_send_date <= $1::timestamptz))
+    OR (d.status = 'sent' AND d.next_send_date <= $1::timestamptz)
+  )
+  AND (d.last_sent_date IS NULL OR d.last_sent_date <= $2::timestamptz)
+  AND d.user_uuid IS NOT NULL
+  AND (d.reviewing_until IS NULL OR d.reviewing_until <= NOW())
+  AND NOT EXISTS ( -- Users who paused their reminders are skipped
+    SELECT 1 FROM user_preferences p
+    WHERE p.user_uuid = d.user_uuid
+      AND p.reminders_paused
+      AND (p.paused_until IS NULL OR p.paused_until > NOW())
+  )
+  AND NOT EXISTS ( -- So are users who reached their daily_drop_limit for the day
+    SELECT 1 FROM user_preferences p
+    WHERE p.user_uuid = d.user_uuid
+      AND p.sends_today >= p.daily_drop_limit
+      AND p.sends_reset_at > NOW()
+  )
+`
+
+type CountDueDropsParams struct {
+	DueBefore      time.Time
+	LastSentBefore time.Time
+}
+
+// Counts the due drops of every user the worker would serve, using the same criteria as
+// ListUserUUIDsWithDueDrops. Each run sends one per user, so this is the worker's backlog.
+func (q *Queries) CountDueDrops(ctx context.Context, arg CountDueDropsParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countDueDrops, arg.DueBefore, arg.LastSentBefore)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countDueDropsByUserUUID = `-- name: CountDueDropsByUserUUID :one
+SELECT COUNT(*)
+FROM drops
+WHERE user_uuid = $1
+  AND workspace_id IS NOT DISTINCT FROM $2
+  AND (
+    (status = 'new' AND (next_send_date IS NULL OR next_send_date <= $3::timestamptz))
+    OR (status = 'sent' AND next_send_date <= $3::timestamptz)
+  )
+  AND (last_sent_date IS NULL OR last_sent_date <= $4::timestamptz)
+  AND (reviewing_until IS NULL OR reviewing_until <= NOW())
+`
+
+type CountDueDropsByUserUUIDParams struct {
+	UserUuid       uuid.NullUUID
+	WorkspaceID    uuid.NullUUID
+	DueBefore      time.Time
+	LastSentBefore time.Time
+}
+
+// Counts the user's drops in a workspace that are due, using the same criteria as GetDueDropsByUserUUID.
+func (q *Queries) CountDueDropsByUserUUID(ctx context.Context, arg CountDueDropsByUserUUIDParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countDueDropsByUserUUID,
+		arg.UserUuid,
+		arg.WorkspaceID,
+		arg.DueBefore,
+		arg.LastSentBefore,
+	)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countUserActivity = `-- name: CountUserActivity :one
+SELECT (COUNT(*) + COUNT(last_sent_date) + COUNT(*) FILTER (WHERE status = 'archived'))::bigint
+FROM drops
+WHERE user_uuid = $1
+`
+
+// Counts the entries of the user's activity timeline (see ListUserActivity).
+func (q *Queries) CountUserActivity(ctx context.Context, userUuid uuid.NullUUID) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countUserActivity, userUuid)
+	var column_1 int64
+	err := row.Scan(&column_1)
+	return column_1, err
+}
+
 const createDrop = `-- name: CreateDrop :one
 INSERT INTO drops (
     user_uuid, -- Changed from user_id
     topic,
     url,
     user_notes,
-    priority
+    priority,
+    excerpt,
+    workspace_id,
+    schedule,
+    next_send_date,
+    permanent,
+    channel,
+    metadata
 ) VALUES (
-    $1, $2, $3, $4, $5
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12
 )
-RETURNING id, user_uuid, topic, url, user_notes, added_date, updated_at, status, last_sent_date, send_count, priority
+RETURNING id, user_uuid, topic, url, user_notes, added_date, updated_at, status, last_sent_date, send_count, priority, excerpt, next_send_date, workspace_id, schedule, permanent, channel, metadata, due_date, scheduled_send_date, reviewing_until, ease_factor, interval_days
 `
 
 type CreateDropParams struct {
-	UserUuid  uuid.NullUUID
-	Topic     string
-	Url       string
-	UserNotes sql.NullString
-	Priority  sql.NullInt32
+	UserUuid     uuid.NullUUID
+	Topic        string
+	Url          string
+	UserNotes    sql.NullString
+	Priority     sql.NullInt32
+	Excerpt      sql.NullString
+	WorkspaceID  uuid.NullUUID
+	Schedule     sql.NullString
+	NextSendDate sql.NullTime
+	Permanent    bool
+	Channel      string
+	Metadata     json.RawMessage
 }
 
 func (q *Queries) CreateDrop(ctx context.Context, arg CreateDropParams) (Drop, error) {
@@ -40,6 +358,13 @@ func (q *Queries) CreateDrop(ctx context.Context, arg CreateDropParams) (Drop, e
 		arg.Url,
 		arg.UserNotes,
 		arg.Priority,
+		arg.Excerpt,
+		arg.WorkspaceID,
+		arg.Schedule,
+		arg.NextSendDate,
+		arg.Permanent,
+		arg.Channel,
+		arg.Metadata,
 	)
 	var i Drop
 	err := row.Scan(
@@ -54,6 +379,18 @@ func (q *Queries) CreateDrop(ctx context.Context, arg CreateDropParams) (Drop, e
 		&i.LastSentDate,
 		&i.SendCount,
 		&i.Priority,
+		&i.Excerpt,
+		&i.NextSendDate,
+		&i.WorkspaceID,
+		&i.Schedule,
+		&i.Permanent,
+		&i.Channel,
+		&i.Metadata,
+		&i.DueDate,
+		&i.ScheduledSendDate,
+		&i.ReviewingUntil,
+		&i.EaseFactor,
+		&i.IntervalDays,
 	)
 	return i, err
 }
@@ -73,8 +410,66 @@ func (q *Queries) DeleteDrop(ctx context.Context, arg DeleteDropParams) error {
 	return err
 }
 
+const forecastDueDropsByDay = `-- name: ForecastDueDropsByDay :many
+SELECT GREATEST(
+        COALESCE((next_send_date AT TIME ZONE $1::text)::date, $2::date),
+        $2::date
+    )::date AS day,
+    COUNT(*) AS count
+FROM drops
+WHERE user_uuid = $3
+  AND (
+    (status = 'new' AND (next_send_date IS NULL OR next_send_date < $4::timestamptz))
+    OR (status = 'sent' AND next_send_date < $4::timestamptz)
+  )
+GROUP BY day
+ORDER BY day
+`
+
+type ForecastDueDropsByDayParams struct {
+	Tz       string
+	FromDate time.Time
+	UserUuid uuid.NullUUID
+	Until    time.Time
+}
+
+type ForecastDueDropsByDayRow struct {
+	Day   time.Time
+	Count int64
+}
+
+// Counts the user's drops in every workspace that fall due on each local day, in time zone tz,
+// up to until. Overdue drops and new drops without a date count toward from_date (today).
+func (q *Queries) ForecastDueDropsByDay(ctx context.Context, arg ForecastDueDropsByDayParams) ([]ForecastDueDropsByDayRow, error) {
+	rows, err := q.db.QueryContext(ctx, forecastDueDropsByDay,
+		arg.Tz,
+		arg.FromDate,
+		arg.UserUuid,
+		arg.Until,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ForecastDueDropsByDayRow
+	for rows.Next() {
+		var i ForecastDueDropsByDayRow
+		if err := rows.Scan(&i.Day, &i.Count); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getDrop = `-- name: GetDrop :one
-SELECT id, user_uuid, topic, url, user_notes, added_date, updated_at, status, last_sent_date, send_count, priority FROM drops
+SELECT id, user_uuid, topic, url, user_notes, added_date, updated_at, status, last_sent_date, send_count, priority, excerpt, next_send_date, workspace_id, schedule, permanent, channel, metadata, due_date, scheduled_send_date, reviewing_until, ease_factor, interval_days FROM drops
 WHERE id = $1
 `
 
@@ -93,29 +488,87 @@ func (q *Queries) GetDrop(ctx context.Context, id uuid.UUID) (Drop, error) {
 		&i.LastSentDate,
 		&i.SendCount,
 		&i.Priority,
+		&i.Excerpt,
+		&i.NextSendDate,
+		&i.WorkspaceID,
+		&i.Schedule,
+		&i.Permanent,
+		&i.Channel,
+		&i.Metadata,
+		&i.DueDate,
+		&i.ScheduledSendDate,
+		&i.ReviewingUntil,
+		&i.EaseFactor,
+		&i.IntervalDays,
 	)
 	return i, err
 }
 
-const getDueDropsByUserUUID = `-- name: GetDueDropsByUserUUID :many
-SELECT id, user_uuid, topic, url, user_notes, added_date, updated_at, status, last_sent_date, send_count, priority
-FROM drops
-WHERE user_uuid = $1 -- Changed from user_id
-  AND status = 'new'
-ORDER BY priority DESC, added_date ASC
-LIMIT $2
+const getDropByURLForUser = `-- name: GetDropByURLForUser :one
+SELECT id, user_uuid, topic, url, user_notes, added_date, updated_at, status, last_sent_date, send_count, priority, excerpt, next_send_date, workspace_id, schedule, permanent, channel, metadata, due_date, scheduled_send_date, reviewing_until, ease_factor, interval_days FROM drops
+WHERE url = $1
+  AND user_uuid = $2
+  AND workspace_id IS NOT DISTINCT FROM $3
+ORDER BY added_date DESC
+LIMIT 1
 `
 
-type GetDueDropsByUserUUIDParams struct {
-	UserUuid uuid.NullUUID
-	Limit    int32
+type GetDropByURLForUserParams struct {
+	Url         string
+	UserUuid    uuid.NullUUID
+	WorkspaceID uuid.NullUUID
 }
 
-// Selects drops that are due to be sent for a specific user.
-// Drops are considered due if their status is 'new'.
-// They are ordered by priority (descending) and then by added_date (ascending).
-func (q *Queries) GetDueDropsByUserUUID(ctx context.Context, arg GetDueDropsByUserUUIDParams) ([]Drop, error) {
-	rows, err := q.db.QueryContext(ctx, getDueDropsByUserUUID, arg.UserUuid, arg.Limit)
+// Finds the user's drop with the given URL in a workspace. URLs aren't unique, so the most
+// recently added drop wins.
+func (q *Queries) GetDropByURLForUser(ctx context.Context, arg GetDropByURLForUserParams) (Drop, error) {
+	row := q.db.QueryRowContext(ctx, getDropByURLForUser, arg.Url, arg.UserUuid, arg.WorkspaceID)
+	var i Drop
+	err := row.Scan(
+		&i.ID,
+		&i.UserUuid,
+		&i.Topic,
+		&i.Url,
+		&i.UserNotes,
+		&i.AddedDate,
+		&i.UpdatedAt,
+		&i.Status,
+		&i.LastSentDate,
+		&i.SendCount,
+		&i.Priority,
+		&i.Excerpt,
+		&i.NextSendDate,
+		&i.WorkspaceID,
+		&i.Schedule,
+		&i.Permanent,
+		&i.Channel,
+		&i.Metadata,
+		&i.DueDate,
+		&i.ScheduledSendDate,
+		&i.ReviewingUntil,
+		&i.EaseFactor,
+		&i.IntervalDays,
+	)
+	return i, err
+}
+
+const getDropsByIDs = `-- name: GetDropsByIDs :many
+SELECT id, user_uuid, topic, url, user_notes, added_date, updated_at, status, last_sent_date, send_count, priority, excerpt, next_send_date, workspace_id, schedule, permanent, channel, metadata, due_date, scheduled_send_date, reviewing_until, ease_factor, interval_days FROM drops
+WHERE id = ANY($1::uuid[])
+  AND user_uuid = $2
+  AND workspace_id IS NOT DISTINCT FROM $3
+`
+
+type GetDropsByIDsParams struct {
+	Ids         []uuid.UUID
+	UserUuid    uuid.NullUUID
+	WorkspaceID uuid.NullUUID
+}
+
+// Fetches the user's drops in a workspace among the given IDs.
+// IDs that don't exist or belong to someone else are left out.
+func (q *Queries) GetDropsByIDs(ctx context.Context, arg GetDropsByIDsParams) ([]Drop, error) {
+	rows, err := q.db.QueryContext(ctx, getDropsByIDs, pq.Array(arg.Ids), arg.UserUuid, arg.WorkspaceID)
 	if err != nil {
 		return nil, err
 	}
@@ -135,6 +588,18 @@ func (q *Queries) GetDueDropsByUserUUID(ctx context.Context, arg GetDueDropsByUs
 			&i.LastSentDate,
 			&i.SendCount,
 			&i.Priority,
+			&i.Excerpt,
+			&i.NextSendDate,
+			&i.WorkspaceID,
+			&i.Schedule,
+			&i.Permanent,
+			&i.Channel,
+			&i.Metadata,
+			&i.DueDate,
+			&i.ScheduledSendDate,
+			&i.ReviewingUntil,
+			&i.EaseFactor,
+			&i.IntervalDays,
 		); err != nil {
 			return nil, err
 		}
@@ -149,14 +614,23 @@ func (q *Queries) GetDueDropsByUserUUID(ctx context.Context, arg GetDueDropsByUs
 	return items, nil
 }
 
-const listDropsByUserUUID = `-- name: ListDropsByUserUUID :many
-SELECT id, user_uuid, topic, url, user_notes, added_date, updated_at, status, last_sent_date, send_count, priority FROM drops
-WHERE user_uuid = $1 -- Changed from user_id
-ORDER BY added_date DESC
+const getDropsUpdatedSince = `-- name: GetDropsUpdatedSince :many
+SELECT id, user_uuid, topic, url, user_notes, added_date, updated_at, status, last_sent_date, send_count, priority, excerpt, next_send_date, workspace_id, schedule, permanent, channel, metadata, due_date, scheduled_send_date, reviewing_until, ease_factor, interval_days FROM drops
+WHERE user_uuid = $1
+  AND workspace_id IS NOT DISTINCT FROM $2 -- NULL selects the personal space
+  AND updated_at > $3
+ORDER BY updated_at, id
 `
 
-func (q *Queries) ListDropsByUserUUID(ctx context.Context, userUuid uuid.NullUUID) ([]Drop, error) {
-	rows, err := q.db.QueryContext(ctx, listDropsByUserUUID, userUuid)
+type GetDropsUpdatedSinceParams struct {
+	UserUuid    uuid.NullUUID
+	WorkspaceID uuid.NullUUID
+	Since       time.Time
+}
+
+// Drops created or changed after a point in time, oldest change first, for delta sync.
+func (q *Queries) GetDropsUpdatedSince(ctx context.Context, arg GetDropsUpdatedSinceParams) ([]Drop, error) {
+	rows, err := q.db.QueryContext(ctx, getDropsUpdatedSince, arg.UserUuid, arg.WorkspaceID, arg.Since)
 	if err != nil {
 		return nil, err
 	}
@@ -176,6 +650,18 @@ func (q *Queries) ListDropsByUserUUID(ctx context.Context, userUuid uuid.NullUUI
 			&i.LastSentDate,
 			&i.SendCount,
 			&i.Priority,
+			&i.Excerpt,
+			&i.NextSendDate,
+			&i.WorkspaceID,
+			&i.Schedule,
+			&i.Permanent,
+			&i.Channel,
+			&i.Metadata,
+			&i.DueDate,
+			&i.ScheduledSendDate,
+			&i.ReviewingUntil,
+			&i.EaseFactor,
+			&i.IntervalDays,
 		); err != nil {
 			return nil, err
 		}
@@ -190,26 +676,74 @@ func (q *Queries) ListDropsByUserUUID(ctx context.Context, userUuid uuid.NullUUI
 	return items, nil
 }
 
-const listUserUUIDsWithDueDrops = `-- name: ListUserUUIDsWithDueDrops :many
-SELECT DISTINCT user_uuid -- Changed from user_id
+const getDueDropsByUserUUID = `-- name: GetDueDropsByUserUUID :many
+SELECT id, user_uuid, topic, url, user_notes, added_date, updated_at, status, last_sent_date, send_count, priority, excerpt, next_send_date, workspace_id, schedule, permanent, channel, metadata, due_date, scheduled_send_date, reviewing_until, ease_factor, interval_days
 FROM drops
-WHERE status = 'new'
-  AND user_uuid IS NOT NULL
+WHERE user_uuid = $1 -- Changed from user_id
+  AND (
+    (status = 'new' AND (next_send_date IS NULL OR next_send_date <= $2::timestamptz))
+    OR (status = 'sent' AND next_send_date <= $2::timestamptz)
+  )
+  AND (last_sent_date IS NULL OR last_sent_date <= $3::timestamptz)
+  AND (reviewing_until IS NULL OR reviewing_until <= NOW()) -- Drops in a review session are left to it
+ORDER BY COALESCE(priority, 0) DESC, COALESCE(next_send_date, added_date) ASC, added_date ASC
+LIMIT $4
 `
 
-func (q *Queries) ListUserUUIDsWithDueDrops(ctx context.Context) ([]uuid.NullUUID, error) {
-	rows, err := q.db.QueryContext(ctx, listUserUUIDsWithDueDrops)
+type GetDueDropsByUserUUIDParams struct {
+	UserUuid       uuid.NullUUID
+	DueBefore      time.Time
+	LastSentBefore time.Time
+	Limit          int32
+}
+
+// Selects drops that are due to be sent for a specific user.
+// Drops are considered due if they are 'new' or 'sent' and their next_send_date is before due_before
+// (a 'new' drop without a next_send_date is due immediately), unless they were sent after last_sent_before
+// or a review session holds them. They are ordered by priority (highest first, a NULL priority counts as 0), then by how overdue
+// they are (earliest next_send_date, or added_date when unscheduled), then by added_date.
+func (q *Queries) GetDueDropsByUserUUID(ctx context.Context, arg GetDueDropsByUserUUIDParams) ([]Drop, error) {
+	rows, err := q.db.QueryContext(ctx, getDueDropsByUserUUID,
+		arg.UserUuid,
+		arg.DueBefore,
+		arg.LastSentBefore,
+		arg.Limit,
+	)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []uuid.NullUUID
+	var items []Drop
 	for rows.Next() {
-		var user_uuid uuid.NullUUID
-		if err := rows.Scan(&user_uuid); err != nil {
+		var i Drop
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserUuid,
+			&i.Topic,
+			&i.Url,
+			&i.UserNotes,
+			&i.AddedDate,
+			&i.UpdatedAt,
+			&i.Status,
+			&i.LastSentDate,
+			&i.SendCount,
+			&i.Priority,
+			&i.Excerpt,
+			&i.NextSendDate,
+			&i.WorkspaceID,
+			&i.Schedule,
+			&i.Permanent,
+			&i.Channel,
+			&i.Metadata,
+			&i.DueDate,
+			&i.ScheduledSendDate,
+			&i.ReviewingUntil,
+			&i.EaseFactor,
+			&i.IntervalDays,
+		); err != nil {
 			return nil, err
 		}
-		items = append(items, user_uuid)
+		items = append(items, i)
 	}
 	if err := rows.Close(); err != nil {
 		return nil, err
@@ -220,25 +754,25 @@ func (q *Queries) ListUserUUIDsWithDueDrops(ctx context.Context) ([]uuid.NullUUI
 	return items, nil
 }
 
-const markDropAsSent = `-- name: MarkDropAsSent :one
-UPDATE drops
-SET
-    status = 'sent',
-    last_sent_date = $2, -- $2 will be the timestamp when it was sent
-    send_count = send_count + 1
-    -- updated_at is handled by the database trigger
-WHERE id = $1 -- $1 will be the drop's ID
-RETURNING id, user_uuid, topic, url, user_notes, added_date, updated_at, status, last_sent_date, send_count, priority
+const getNextUpcomingDrop = `-- name: GetNextUpcomingDrop :one
+SELECT id, user_uuid, topic, url, user_notes, added_date, updated_at, status, last_sent_date, send_count, priority, excerpt, next_send_date, workspace_id, schedule, permanent, channel, metadata, due_date, scheduled_send_date, reviewing_until, ease_factor, interval_days
+FROM drops
+WHERE user_uuid = $1
+  AND status IN ('new', 'sent')
+  AND next_send_date > $2::timestamptz
+ORDER BY next_send_date ASC, COALESCE(priority, 0) DESC
+LIMIT 1
 `
 
-type MarkDropAsSentParams struct {
-	ID           uuid.UUID
-	LastSentDate sql.NullTime
+type GetNextUpcomingDropParams struct {
+	UserUuid  uuid.NullUUID
+	DueBefore time.Time
 }
 
-// Updates a drop's status to 'sent', sets the last_sent_date, and increments the send_count.
-func (q *Queries) MarkDropAsSent(ctx context.Context, arg MarkDropAsSentParams) (Drop, error) {
-	row := q.db.QueryRowContext(ctx, markDropAsSent, arg.ID, arg.LastSentDate)
+// Selects the user's drop that comes up next among those not due before due_before,
+// preferring the higher priority when two are scheduled for the same time.
+func (q *Queries) GetNextUpcomingDrop(ctx context.Context, arg GetNextUpcomingDropParams) (Drop, error) {
+	row := q.db.QueryRowContext(ctx, getNextUpcomingDrop, arg.UserUuid, arg.DueBefore)
 	var i Drop
 	err := row.Scan(
 		&i.ID,
@@ -252,49 +786,365 @@ func (q *Queries) MarkDropAsSent(ctx context.Context, arg MarkDropAsSentParams)
 		&i.LastSentDate,
 		&i.SendCount,
 		&i.Priority,
+		&i.Excerpt,
+		&i.NextSendDate,
+		&i.WorkspaceID,
+		&i.Schedule,
+		&i.Permanent,
+		&i.Channel,
+		&i.Metadata,
+		&i.DueDate,
+		&i.ScheduledSendDate,
+		&i.ReviewingUntil,
+		&i.EaseFactor,
+		&i.IntervalDays,
 	)
 	return i, err
 }
 
-const updateDrop = `-- name: UpdateDrop :one
-UPDATE drops
-SET
-    topic = COALESCE($3, topic),
-    url = COALESCE($4, url),
-    user_notes = COALESCE($5, user_notes),
-    priority = COALESCE($6, priority),
-    status = COALESCE($7, status)
-    -- updated_at is handled by the database trigger
-WHERE id = $1 AND user_uuid = $2 -- Changed from user_id
-RETURNING id, user_uuid, topic, url, user_notes, added_date, updated_at, status, last_sent_date, send_count, priority
+const hasDueDropsByUserUUID = `-- name: HasDueDropsByUserUUID :one
+SELECT EXISTS (
+    SELECT 1 FROM drops
+    WHERE user_uuid = $1
+      AND (
+        (status = 'new' AND (next_send_date IS NULL OR next_send_date <= $2::timestamptz))
+        OR (status = 'sent' AND next_send_date <= $2::timestamptz)
+      )
+      AND (last_sent_date IS NULL OR last_sent_date <= $3::timestamptz)
+      AND (reviewing_until IS NULL OR reviewing_until <= NOW())
+)
 `
 
-type UpdateDropParams struct {
-	ID        uuid.UUID
-	UserUuid  uuid.NullUUID
-	Topic     sql.NullString
-	Url       sql.NullString
-	UserNotes sql.NullString
-	Priority  sql.NullInt32
-	Status    sql.NullString
+type HasDueDropsByUserUUIDParams struct {
+	UserUuid       uuid.NullUUID
+	DueBefore      time.Time
+	LastSentBefore time.Time
 }
 
-func (q *Queries) UpdateDrop(ctx context.Context, arg UpdateDropParams) (Drop, error) {
-	row := q.db.QueryRowContext(ctx, updateDrop,
-		arg.ID,
-		arg.UserUuid,
-		arg.Topic,
-		arg.Url,
-		arg.UserNotes,
-		arg.Priority,
-		arg.Status,
-	)
-	var i Drop
-	err := row.Scan(
-		&i.ID,
-		&i.UserUuid,
-		&i.Topic,
-		&i.Url,
+// Reports whether the user has any due drop in any workspace, using the same criteria as GetDueDropsByUserUUID.
+func (q *Queries) HasDueDropsByUserUUID(ctx context.Context, arg HasDueDropsByUserUUIDParams) (bool, error) {
+	row := q.db.QueryRowContext(ctx, hasDueDropsByUserUUID, arg.UserUuid, arg.DueBefore, arg.LastSentBefore)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
+const listAllDropsByUserUUID = `-- name: ListAllDropsByUserUUID :many
+SELECT id, user_uuid, topic, url, user_notes, added_date, updated_at, status, last_sent_date, send_count, priority, excerpt, next_send_date, workspace_id, schedule, permanent, channel, metadata, due_date, scheduled_send_date, reviewing_until, ease_factor, interval_days FROM drops
+WHERE user_uuid = $1
+ORDER BY added_date
+`
+
+// Every drop the user owns, across the personal space and all workspaces.
+func (q *Queries) ListAllDropsByUserUUID(ctx context.Context, userUuid uuid.NullUUID) ([]Drop, error) {
+	rows, err := q.db.QueryContext(ctx, listAllDropsByUserUUID, userUuid)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Drop
+	for rows.Next() {
+		var i Drop
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserUuid,
+			&i.Topic,
+			&i.Url,
+			&i.UserNotes,
+			&i.AddedDate,
+			&i.UpdatedAt,
+			&i.Status,
+			&i.LastSentDate,
+			&i.SendCount,
+			&i.Priority,
+			&i.Excerpt,
+			&i.NextSendDate,
+			&i.WorkspaceID,
+			&i.Schedule,
+			&i.Permanent,
+			&i.Channel,
+			&i.Metadata,
+			&i.DueDate,
+			&i.ScheduledSendDate,
+			&i.ReviewingUntil,
+			&i.EaseFactor,
+			&i.IntervalDays,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listDropsByUserUUID = `-- name: ListDropsByUserUUID :many
+SELECT id, user_uuid, topic, url, user_notes, added_date, updated_at, status, last_sent_date, send_count, priority, excerpt, next_send_date, workspace_id, schedule, permanent, channel, metadata, due_date, scheduled_send_date, reviewing_until, ease_factor, interval_days FROM drops
+WHERE user_uuid = $1 -- Changed from user_id
+  AND workspace_id IS NOT DISTINCT FROM $2 -- NULL selects the personal space
+  AND ($3::uuid IS NULL
+       OR id IN (SELECT drops_id FROM collection_drops WHERE collection_id = $3))
+  AND ($4::jsonb IS NULL OR metadata @> $4::jsonb)
+ORDER BY added_date DESC
+`
+
+type ListDropsByUserUUIDParams struct {
+	UserUuid     uuid.NullUUID
+	WorkspaceID  uuid.NullUUID
+	CollectionID uuid.NullUUID
+	Metadata     pqtype.NullRawMessage
+}
+
+func (q *Queries) ListDropsByUserUUID(ctx context.Context, arg ListDropsByUserUUIDParams) ([]Drop, error) {
+	rows, err := q.db.QueryContext(ctx, listDropsByUserUUID,
+		arg.UserUuid,
+		arg.WorkspaceID,
+		arg.CollectionID,
+		arg.Metadata,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Drop
+	for rows.Next() {
+		var i Drop
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserUuid,
+			&i.Topic,
+			&i.Url,
+			&i.UserNotes,
+			&i.AddedDate,
+			&i.UpdatedAt,
+			&i.Status,
+			&i.LastSentDate,
+			&i.SendCount,
+			&i.Priority,
+			&i.Excerpt,
+			&i.NextSendDate,
+			&i.WorkspaceID,
+			&i.Schedule,
+			&i.Permanent,
+			&i.Channel,
+			&i.Metadata,
+			&i.DueDate,
+			&i.ScheduledSendDate,
+			&i.ReviewingUntil,
+			&i.EaseFactor,
+			&i.IntervalDays,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listExistingDropURLs = `-- name: ListExistingDropURLs :many
+SELECT DISTINCT url FROM drops
+WHERE url = ANY($1::text[])
+  AND user_uuid = $2
+  AND workspace_id IS NOT DISTINCT FROM $3
+`
+
+type ListExistingDropURLsParams struct {
+	Urls        []string
+	UserUuid    uuid.NullUUID
+	WorkspaceID uuid.NullUUID
+}
+
+// Returns which of the given URLs the user already has a drop for in a workspace.
+func (q *Queries) ListExistingDropURLs(ctx context.Context, arg ListExistingDropURLsParams) ([]string, error) {
+	rows, err := q.db.QueryContext(ctx, listExistingDropURLs, pq.Array(arg.Urls), arg.UserUuid, arg.WorkspaceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var url string
+		if err := rows.Scan(&url); err != nil {
+			return nil, err
+		}
+		items = append(items, url)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUserActivity = `-- name: ListUserActivity :many
+SELECT a.type::text AS type, a.drop_id, a.topic, a.workspace_id, a.occurred_at::timestamptz AS occurred_at
+FROM (
+    SELECT 'added' AS type, id AS drop_id, topic, workspace_id, added_date AS occurred_at
+    FROM drops WHERE user_uuid = $1
+    UNION ALL
+    SELECT 'sent', id, topic, workspace_id, last_sent_date
+    FROM drops WHERE user_uuid = $1 AND last_sent_date IS NOT NULL
+    UNION ALL
+    SELECT 'archived', id, topic, workspace_id, updated_at
+    FROM drops WHERE user_uuid = $1 AND status = 'archived'
+) a
+ORDER BY a.occurred_at DESC, a.drop_id ASC, a.type ASC
+LIMIT $2 OFFSET $3
+`
+
+type ListUserActivityParams struct {
+	UserUuid uuid.NullUUID
+	Limit    int32
+	Offset   int32
+}
+
+type ListUserActivityRow struct {
+	Type        string
+	DropID      uuid.UUID
+	Topic       string
+	WorkspaceID uuid.NullUUID
+	OccurredAt  time.Time
+}
+
+// Builds the user's activity timeline from drop timestamps, newest first. Each drop gives an
+// 'added' entry, a 'sent' entry for its latest send and, while archived, an 'archived' entry.
+// No completion time is stored, so 'archived' is dated by the drop's last update.
+func (q *Queries) ListUserActivity(ctx context.Context, arg ListUserActivityParams) ([]ListUserActivityRow, error) {
+	rows, err := q.db.QueryContext(ctx, listUserActivity, arg.UserUuid, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListUserActivityRow
+	for rows.Next() {
+		var i ListUserActivityRow
+		if err := rows.Scan(
+			&i.Type,
+			&i.DropID,
+			&i.Topic,
+			&i.WorkspaceID,
+			&i.OccurredAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUserUUIDsWithDueDrops = `-- name: ListUserUUIDsWithDueDrops :many
+SELECT d.user_uuid
+FROM drops d
+WHERE (
+    (d.status = 'new' AND (d.next_send_date IS NULL OR d.next_send_date <= $1::timestamptz))
+    OR (d.status = 'sent' AND d.next_send_date <= $1::timestamptz)
+  )
+  AND (d.last_sent_date IS NULL OR d.last_sent_date <= $2::timestamptz)
+  AND d.user_uuid IS NOT NULL
+  AND (d.reviewing_until IS NULL OR d.reviewing_until <= NOW())
+  AND NOT EXISTS ( -- Users who paused their reminders are skipped
+    SELECT 1 FROM user_preferences p
+    WHERE p.user_uuid = d.user_uuid
+      AND p.reminders_paused
+      AND (p.paused_until IS NULL OR p.paused_until > NOW())
+  )
+  AND NOT EXISTS ( -- So are users who reached their daily_drop_limit for the day
+    SELECT 1 FROM user_preferences p
+    WHERE p.user_uuid = d.user_uuid
+      AND p.sends_today >= p.daily_drop_limit
+      AND p.sends_reset_at > NOW()
+  )
+GROUP BY d.user_uuid
+ORDER BY (SELECT MAX(s.last_sent_date) FROM drops s WHERE s.user_uuid = d.user_uuid) ASC NULLS FIRST, d.user_uuid
+`
+
+type ListUserUUIDsWithDueDropsParams struct {
+	DueBefore      time.Time
+	LastSentBefore time.Time
+}
+
+// Users who were sent something longest ago (or never) come first, so a run that
+// can only serve some of them doesn't keep favouring the same users.
+func (q *Queries) ListUserUUIDsWithDueDrops(ctx context.Context, arg ListUserUUIDsWithDueDropsParams) ([]uuid.NullUUID, error) {
+	rows, err := q.db.QueryContext(ctx, listUserUUIDsWithDueDrops, arg.DueBefore, arg.LastSentBefore)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []uuid.NullUUID
+	for rows.Next() {
+		var user_uuid uuid.NullUUID
+		if err := rows.Scan(&user_uuid); err != nil {
+			return nil, err
+		}
+		items = append(items, user_uuid)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const markDropAsSent = `-- name: MarkDropAsSent :one
+UPDATE drops
+SET
+    status = $4, -- $4 is 'sent', or 'graduated'/'archived' for the drop's last send
+    last_sent_date = $2, -- $2 will be the timestamp when it was sent
+    send_count = send_count + 1,
+    next_send_date = $3, -- $3 is the next repetition, NULL once the schedule is finished
+    due_date = NULL, -- A pinned due date only applies to one send
+    scheduled_send_date = NULL,
+    reviewing_until = NULL -- A review session that outlived its lock can't complete the drop any more
+    -- updated_at is handled by the database trigger
+WHERE id = $1 -- $1 will be the drop's ID
+RETURNING id, user_uuid, topic, url, user_notes, added_date, updated_at, status, last_sent_date, send_count, priority, excerpt, next_send_date, workspace_id, schedule, permanent, channel, metadata, due_date, scheduled_send_date, reviewing_until, ease_factor, interval_days
+`
+
+type MarkDropAsSentParams struct {
+	ID           uuid.UUID
+	LastSentDate sql.NullTime
+	NextSendDate sql.NullTime
+	Status       string
+}
+
+// Updates a drop's status to 'sent' (or 'graduated' once it reached the send cap, or 'archived'
+// for users with auto_archive_after_send), sets the last_sent_date, increments the send_count,
+// and stores when the next repetition is due.
+func (q *Queries) MarkDropAsSent(ctx context.Context, arg MarkDropAsSentParams) (Drop, error) {
+	row := q.db.QueryRowContext(ctx, markDropAsSent,
+		arg.ID,
+		arg.LastSentDate,
+		arg.NextSendDate,
+		arg.Status,
+	)
+	var i Drop
+	err := row.Scan(
+		&i.ID,
+		&i.UserUuid,
+		&i.Topic,
+		&i.Url,
 		&i.UserNotes,
 		&i.AddedDate,
 		&i.UpdatedAt,
@@ -302,6 +1152,623 @@ func (q *Queries) UpdateDrop(ctx context.Context, arg UpdateDropParams) (Drop, e
 		&i.LastSentDate,
 		&i.SendCount,
 		&i.Priority,
+		&i.Excerpt,
+		&i.NextSendDate,
+		&i.WorkspaceID,
+		&i.Schedule,
+		&i.Permanent,
+		&i.Channel,
+		&i.Metadata,
+		&i.DueDate,
+		&i.ScheduledSendDate,
+		&i.ReviewingUntil,
+		&i.EaseFactor,
+		&i.IntervalDays,
 	)
 	return i, err
 }
+
+const patchDrop = `-- name: PatchDrop :one
+UPDATE drops
+SET
+    topic = CASE WHEN $1::boolean THEN $2 ELSE topic END,
+    url = CASE WHEN $3::boolean THEN $4 ELSE url END,
+    user_notes = CASE WHEN $5::boolean THEN $6 ELSE user_notes END,
+    priority = CASE WHEN $7::boolean THEN $8 ELSE priority END,
+    status = CASE WHEN $9::boolean THEN $10 ELSE status END,
+    schedule = CASE WHEN $11::boolean THEN $12 ELSE schedule END,
+    next_send_date = CASE WHEN $13::boolean THEN $14 ELSE next_send_date END,
+    permanent = CASE WHEN $15::boolean THEN $16 ELSE permanent END,
+    channel = CASE WHEN $17::boolean THEN $18 ELSE channel END,
+    metadata = CASE WHEN $19::boolean THEN $20 ELSE metadata END
+WHERE id = $21 AND user_uuid = $22
+  AND (NOT $9::boolean OR status = $23)
+RETURNING id, user_uuid, topic, url, user_notes, added_date, updated_at, status, last_sent_date, send_count, priority, excerpt, next_send_date, workspace_id, schedule, permanent, channel, metadata, due_date, scheduled_send_date, reviewing_until, ease_factor, interval_days
+`
+
+type PatchDropParams struct {
+	SetTopic        bool
+	Topic           string
+	SetUrl          bool
+	Url             string
+	SetUserNotes    bool
+	UserNotes       sql.NullString
+	SetPriority     bool
+	Priority        sql.NullInt32
+	SetStatus       bool
+	Status          string
+	SetSchedule     bool
+	Schedule        sql.NullString
+	SetNextSendDate bool
+	NextSendDate    sql.NullTime
+	SetPermanent    bool
+	Permanent       bool
+	SetChannel      bool
+	Channel         string
+	SetMetadata     bool
+	Metadata        json.RawMessage
+	ID              uuid.UUID
+	UserUuid        uuid.NullUUID
+	ExpectedStatus  string
+}
+
+// Writes the editable fields of a drop that a merge patch sets, leaving the others as they
+// are now, so a patch can't write back values the worker or another request changed since
+// the drop was read. A status change only applies while the drop still has expected_status,
+// the status the transition was checked against; otherwise no row is returned.
+func (q *Queries) PatchDrop(ctx context.Context, arg PatchDropParams) (Drop, error) {
+	row := q.db.QueryRowContext(ctx, patchDrop,
+		arg.SetTopic,
+		arg.Topic,
+		arg.SetUrl,
+		arg.Url,
+		arg.SetUserNotes,
+		arg.UserNotes,
+		arg.SetPriority,
+		arg.Priority,
+		arg.SetStatus,
+		arg.Status,
+		arg.SetSchedule,
+		arg.Schedule,
+		arg.SetNextSendDate,
+		arg.NextSendDate,
+		arg.SetPermanent,
+		arg.Permanent,
+		arg.SetChannel,
+		arg.Channel,
+		arg.SetMetadata,
+		arg.Metadata,
+		arg.ID,
+		arg.UserUuid,
+		arg.ExpectedStatus,
+	)
+	var i Drop
+	err := row.Scan(
+		&i.ID,
+		&i.UserUuid,
+		&i.Topic,
+		&i.Url,
+		&i.UserNotes,
+		&i.AddedDate,
+		&i.UpdatedAt,
+		&i.Status,
+		&i.LastSentDate,
+		&i.SendCount,
+		&i.Priority,
+		&i.Excerpt,
+		&i.NextSendDate,
+		&i.WorkspaceID,
+		&i.Schedule,
+		&i.Permanent,
+		&i.Channel,
+		&i.Metadata,
+		&i.DueDate,
+		&i.ScheduledSendDate,
+		&i.ReviewingUntil,
+		&i.EaseFactor,
+		&i.IntervalDays,
+	)
+	return i, err
+}
+
+const resetDropSchedule = `-- name: ResetDropSchedule :one
+UPDATE drops
+SET
+    send_count = 0,
+    next_send_date = $3,
+    interval_days = NULL,
+    due_date = NULL,
+    scheduled_send_date = NULL
+WHERE id = $1 AND user_uuid = $2
+RETURNING id, user_uuid, topic, url, user_notes, added_date, updated_at, status, last_sent_date, send_count, priority, excerpt, next_send_date, workspace_id, schedule, permanent, channel, metadata, due_date, scheduled_send_date, reviewing_until, ease_factor, interval_days
+`
+
+type ResetDropScheduleParams struct {
+	ID           uuid.UUID
+	UserUuid     uuid.NullUUID
+	NextSendDate sql.NullTime
+}
+
+// Restarts a drop's repetition schedule: the send count goes back to zero
+// and the next send is set to the given time. A pinned due date is dropped, and the
+// next review with an outcome starts from the first interval again.
+func (q *Queries) ResetDropSchedule(ctx context.Context, arg ResetDropScheduleParams) (Drop, error) {
+	row := q.db.QueryRowContext(ctx, resetDropSchedule, arg.ID, arg.UserUuid, arg.NextSendDate)
+	var i Drop
+	err := row.Scan(
+		&i.ID,
+		&i.UserUuid,
+		&i.Topic,
+		&i.Url,
+		&i.UserNotes,
+		&i.AddedDate,
+		&i.UpdatedAt,
+		&i.Status,
+		&i.LastSentDate,
+		&i.SendCount,
+		&i.Priority,
+		&i.Excerpt,
+		&i.NextSendDate,
+		&i.WorkspaceID,
+		&i.Schedule,
+		&i.Permanent,
+		&i.Channel,
+		&i.Metadata,
+		&i.DueDate,
+		&i.ScheduledSendDate,
+		&i.ReviewingUntil,
+		&i.EaseFactor,
+		&i.IntervalDays,
+	)
+	return i, err
+}
+
+const reviewDrop = `-- name: ReviewDrop :one
+UPDATE drops
+SET
+    status = $1,
+    last_sent_date = $2,
+    send_count = $3,
+    next_send_date = $4,
+    ease_factor = $5,
+    interval_days = $6,
+    due_date = NULL,
+    scheduled_send_date = NULL,
+    reviewing_until = NULL
+WHERE id = $7
+  AND user_uuid = $8
+  AND status IN ('new', 'sent')
+RETURNING id, user_uuid, topic, url, user_notes, added_date, updated_at, status, last_sent_date, send_count, priority, excerpt, next_send_date, workspace_id, schedule, permanent, channel, metadata, due_date, scheduled_send_date, reviewing_until, ease_factor, interval_days
+`
+
+type ReviewDropParams struct {
+	Status       string
+	LastSentDate sql.NullTime
+	SendCount    int32
+	NextSendDate sql.NullTime
+	EaseFactor   float64
+	IntervalDays sql.NullInt32
+	ID           uuid.UUID
+	UserUuid     uuid.NullUUID
+}
+
+// Records the outcome of reviewing a drop outside a review session, the same way as
+// CompleteDropReview. Any review lock is released. Returns no row unless the drop is
+// 'new' or 'sent'.
+func (q *Queries) ReviewDrop(ctx context.Context, arg ReviewDropParams) (Drop, error) {
+	row := q.db.QueryRowContext(ctx, reviewDrop,
+		arg.Status,
+		arg.LastSentDate,
+		arg.SendCount,
+		arg.NextSendDate,
+		arg.EaseFactor,
+		arg.IntervalDays,
+		arg.ID,
+		arg.UserUuid,
+	)
+	var i Drop
+	err := row.Scan(
+		&i.ID,
+		&i.UserUuid,
+		&i.Topic,
+		&i.Url,
+		&i.UserNotes,
+		&i.AddedDate,
+		&i.UpdatedAt,
+		&i.Status,
+		&i.LastSentDate,
+		&i.SendCount,
+		&i.Priority,
+		&i.Excerpt,
+		&i.NextSendDate,
+		&i.WorkspaceID,
+		&i.Schedule,
+		&i.Permanent,
+		&i.Channel,
+		&i.Metadata,
+		&i.DueDate,
+		&i.ScheduledSendDate,
+		&i.ReviewingUntil,
+		&i.EaseFactor,
+		&i.IntervalDays,
+	)
+	return i, err
+}
+
+const setDropDueDate = `-- name: SetDropDueDate :one
+UPDATE drops
+SET
+    scheduled_send_date = CASE WHEN due_date IS NULL THEN next_send_date ELSE scheduled_send_date END,
+    due_date = $1,
+    next_send_date = $2
+WHERE id = $3 AND user_uuid = $4
+RETURNING id, user_uuid, topic, url, user_notes, added_date, updated_at, status, last_sent_date, send_count, priority, excerpt, next_send_date, workspace_id, schedule, permanent, channel, metadata, due_date, scheduled_send_date, reviewing_until, ease_factor, interval_days
+`
+
+type SetDropDueDateParams struct {
+	DueDate      sql.NullTime
+	NextSendDate sql.NullTime
+	ID           uuid.UUID
+	UserUuid     uuid.NullUUID
+}
+
+// Pins a drop's next send to due_date, sent from next_send_date. The date the schedule had
+// set is kept in scheduled_send_date, also when a pinned drop is pinned again.
+func (q *Queries) SetDropDueDate(ctx context.Context, arg SetDropDueDateParams) (Drop, error) {
+	row := q.db.QueryRowContext(ctx, setDropDueDate,
+		arg.DueDate,
+		arg.NextSendDate,
+		arg.ID,
+		arg.UserUuid,
+	)
+	var i Drop
+	err := row.Scan(
+		&i.ID,
+		&i.UserUuid,
+		&i.Topic,
+		&i.Url,
+		&i.UserNotes,
+		&i.AddedDate,
+		&i.UpdatedAt,
+		&i.Status,
+		&i.LastSentDate,
+		&i.SendCount,
+		&i.Priority,
+		&i.Excerpt,
+		&i.NextSendDate,
+		&i.WorkspaceID,
+		&i.Schedule,
+		&i.Permanent,
+		&i.Channel,
+		&i.Metadata,
+		&i.DueDate,
+		&i.ScheduledSendDate,
+		&i.ReviewingUntil,
+		&i.EaseFactor,
+		&i.IntervalDays,
+	)
+	return i, err
+}
+
+const setDropNextSendDate = `-- name: SetDropNextSendDate :one
+UPDATE drops
+SET next_send_date = $3, due_date = NULL, scheduled_send_date = NULL
+WHERE id = $1 AND user_uuid = $2
+RETURNING id, user_uuid, topic, url, user_notes, added_date, updated_at, status, last_sent_date, send_count, priority, excerpt, next_send_date, workspace_id, schedule, permanent, channel, metadata, due_date, scheduled_send_date, reviewing_until, ease_factor, interval_days
+`
+
+type SetDropNextSendDateParams struct {
+	ID           uuid.UUID
+	UserUuid     uuid.NullUUID
+	NextSendDate sql.NullTime
+}
+
+// Overrides when a drop is next sent without touching its send count. A pinned due date is dropped.
+func (q *Queries) SetDropNextSendDate(ctx context.Context, arg SetDropNextSendDateParams) (Drop, error) {
+	row := q.db.QueryRowContext(ctx, setDropNextSendDate, arg.ID, arg.UserUuid, arg.NextSendDate)
+	var i Drop
+	err := row.Scan(
+		&i.ID,
+		&i.UserUuid,
+		&i.Topic,
+		&i.Url,
+		&i.UserNotes,
+		&i.AddedDate,
+		&i.UpdatedAt,
+		&i.Status,
+		&i.LastSentDate,
+		&i.SendCount,
+		&i.Priority,
+		&i.Excerpt,
+		&i.NextSendDate,
+		&i.WorkspaceID,
+		&i.Schedule,
+		&i.Permanent,
+		&i.Channel,
+		&i.Metadata,
+		&i.DueDate,
+		&i.ScheduledSendDate,
+		&i.ReviewingUntil,
+		&i.EaseFactor,
+		&i.IntervalDays,
+	)
+	return i, err
+}
+
+const shiftDropSchedules = `-- name: ShiftDropSchedules :many
+UPDATE drops
+SET next_send_date = next_send_date + make_interval(secs => $1::float8)
+WHERE user_uuid = $2
+  AND status IN ('new', 'sent')
+  AND next_send_date IS NOT NULL
+  AND due_date IS NULL
+RETURNING id, user_uuid, topic, url, user_notes, added_date, updated_at, status, last_sent_date, send_count, priority, excerpt, next_send_date, workspace_id, schedule, permanent, channel, metadata, due_date, scheduled_send_date, reviewing_until, ease_factor, interval_days
+`
+
+type ShiftDropSchedulesParams struct {
+	Seconds  float64
+	UserUuid uuid.NullUUID
+}
+
+// Moves every pending send of the user's drops later by the given number of seconds,
+// in all workspaces. Drops without a pending send or pinned to a due date are left alone.
+func (q *Queries) ShiftDropSchedules(ctx context.Context, arg ShiftDropSchedulesParams) ([]Drop, error) {
+	rows, err := q.db.QueryContext(ctx, shiftDropSchedules, arg.Seconds, arg.UserUuid)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Drop
+	for rows.Next() {
+		var i Drop
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserUuid,
+			&i.Topic,
+			&i.Url,
+			&i.UserNotes,
+			&i.AddedDate,
+			&i.UpdatedAt,
+			&i.Status,
+			&i.LastSentDate,
+			&i.SendCount,
+			&i.Priority,
+			&i.Excerpt,
+			&i.NextSendDate,
+			&i.WorkspaceID,
+			&i.Schedule,
+			&i.Permanent,
+			&i.Channel,
+			&i.Metadata,
+			&i.DueDate,
+			&i.ScheduledSendDate,
+			&i.ReviewingUntil,
+			&i.EaseFactor,
+			&i.IntervalDays,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const startDropReview = `-- name: StartDropReview :many
+UPDATE drops
+SET reviewing_until = $1
+WHERE id IN (
+    SELECT d.id FROM drops d
+    WHERE d.user_uuid = $2
+      AND d.workspace_id IS NOT DISTINCT FROM $3
+      AND (
+        (d.status = 'new' AND (d.next_send_date IS NULL OR d.next_send_date <= $4::timestamptz))
+        OR (d.status = 'sent' AND d.next_send_date <= $4::timestamptz)
+      )
+      AND (d.last_sent_date IS NULL OR d.last_sent_date <= $5::timestamptz)
+      AND (d.reviewing_until IS NULL OR d.reviewing_until <= NOW())
+    ORDER BY COALESCE(d.priority, 0) DESC, COALESCE(d.next_send_date, d.added_date) ASC, d.added_date ASC
+    LIMIT $6
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING id, user_uuid, topic, url, user_notes, added_date, updated_at, status, last_sent_date, send_count, priority, excerpt, next_send_date, workspace_id, schedule, permanent, channel, metadata, due_date, scheduled_send_date, reviewing_until, ease_factor, interval_days
+`
+
+type StartDropReviewParams struct {
+	ReviewingUntil sql.NullTime
+	UserUuid       uuid.NullUUID
+	WorkspaceID    uuid.NullUUID
+	DueBefore      time.Time
+	LastSentBefore time.Time
+	Limit          int32
+}
+
+// Locks up to limit of the user's due drops in a workspace for a review session until
+// reviewing_until and returns them, using the same criteria and order as GetDueDropsByUserUUID.
+// Drops another session holds are skipped; SKIP LOCKED keeps two sessions started at the
+// same moment from taking the same drops.
+func (q *Queries) StartDropReview(ctx context.Context, arg StartDropReviewParams) ([]Drop, error) {
+	rows, err := q.db.QueryContext(ctx, startDropReview,
+		arg.ReviewingUntil,
+		arg.UserUuid,
+		arg.WorkspaceID,
+		arg.DueBefore,
+		arg.LastSentBefore,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Drop
+	for rows.Next() {
+		var i Drop
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserUuid,
+			&i.Topic,
+			&i.Url,
+			&i.UserNotes,
+			&i.AddedDate,
+			&i.UpdatedAt,
+			&i.Status,
+			&i.LastSentDate,
+			&i.SendCount,
+			&i.Priority,
+			&i.Excerpt,
+			&i.NextSendDate,
+			&i.WorkspaceID,
+			&i.Schedule,
+			&i.Permanent,
+			&i.Channel,
+			&i.Metadata,
+			&i.DueDate,
+			&i.ScheduledSendDate,
+			&i.ReviewingUntil,
+			&i.EaseFactor,
+			&i.IntervalDays,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateDrop = `-- name: UpdateDrop :one
+UPDATE drops
+SET
+    topic = COALESCE($3, topic),
+    url = COALESCE($4, url),
+    user_notes = COALESCE($5, user_notes),
+    priority = COALESCE($6, priority),
+    status = COALESCE($7, status),
+    schedule = NULLIF(COALESCE($8, schedule), ''),
+    next_send_date = COALESCE($9, next_send_date),
+    permanent = COALESCE($10, permanent),
+    channel = COALESCE($11, channel),
+    metadata = COALESCE($12, metadata)
+    -- updated_at is handled by the database trigger
+WHERE id = $1 AND user_uuid = $2 -- Changed from user_id
+RETURNING id, user_uuid, topic, url, user_notes, added_date, updated_at, status, last_sent_date, send_count, priority, excerpt, next_send_date, workspace_id, schedule, permanent, channel, metadata, due_date, scheduled_send_date, reviewing_until, ease_factor, interval_days
+`
+
+type UpdateDropParams struct {
+	ID           uuid.UUID
+	UserUuid     uuid.NullUUID
+	Topic        sql.NullString
+	Url          sql.NullString
+	UserNotes    sql.NullString
+	Priority     sql.NullInt32
+	Status       sql.NullString
+	Schedule     sql.NullString
+	NextSendDate sql.NullTime
+	Permanent    sql.NullBool
+	Channel      sql.NullString
+	Metadata     pqtype.NullRawMessage
+}
+
+// An empty schedule clears the drop's cron schedule.
+func (q *Queries) UpdateDrop(ctx context.Context, arg UpdateDropParams) (Drop, error) {
+	row := q.db.QueryRowContext(ctx, updateDrop,
+		arg.ID,
+		arg.UserUuid,
+		arg.Topic,
+		arg.Url,
+		arg.UserNotes,
+		arg.Priority,
+		arg.Status,
+		arg.Schedule,
+		arg.NextSendDate,
+		arg.Permanent,
+		arg.Channel,
+		arg.Metadata,
+	)
+	var i Drop
+	err := row.Scan(
+		&i.ID,
+		&i.UserUuid,
+		&i.Topic,
+		&i.Url,
+		&i.UserNotes,
+		&i.AddedDate,
+		&i.UpdatedAt,
+		&i.Status,
+		&i.LastSentDate,
+		&i.SendCount,
+		&i.Priority,
+		&i.Excerpt,
+		&i.NextSendDate,
+		&i.WorkspaceID,
+		&i.Schedule,
+		&i.Permanent,
+		&i.Channel,
+		&i.Metadata,
+		&i.DueDate,
+		&i.ScheduledSendDate,
+		&i.ReviewingUntil,
+		&i.EaseFactor,
+		&i.IntervalDays,
+	)
+	return i, err
+}
+
+const updateDropsStatus = `-- name: UpdateDropsStatus :many
+UPDATE drops
+SET status = $1
+WHERE id = ANY($2::uuid[])
+  AND user_uuid = $3
+  AND workspace_id IS NOT DISTINCT FROM $4
+  AND status = ANY($5::text[])
+RETURNING id
+`
+
+type UpdateDropsStatusParams struct {
+	Status       string
+	Ids          []uuid.UUID
+	UserUuid     uuid.NullUUID
+	WorkspaceID  uuid.NullUUID
+	FromStatuses []string
+}
+
+// Sets the status of several of the user's drops at once and returns the IDs that were updated.
+// Only drops whose current status is in from_statuses are changed.
+func (q *Queries) UpdateDropsStatus(ctx context.Context, arg UpdateDropsStatusParams) ([]uuid.UUID, error) {
+	rows, err := q.db.QueryContext(ctx, updateDropsStatus,
+		arg.Status,
+		pq.Array(arg.Ids),
+		arg.UserUuid,
+		arg.WorkspaceID,
+		pq.Array(arg.FromStatuses),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -7,8 +7,10 @@ package db
 
 import (
 	"context"
+	"database/sql"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
 const addTagToDrop = `-- name: AddTagToDrop :exec
@@ -29,6 +31,227 @@ func (q *Queries) AddTagToDrop(ctx context.Context, arg AddTagToDropParams) erro
 	return err
 }
 
+const assignTagToDrops = `-- name: AssignTagToDrops :many
+WITH assigned AS (
+    INSERT INTO drops_item_tags (drops_id, tag_id)
+    SELECT d.id, $1
+    FROM drops d
+    WHERE d.id = ANY($2::uuid[])
+      AND d.user_uuid = $3
+      AND d.workspace_id IS NOT DISTINCT FROM $4
+    ON CONFLICT (drops_id, tag_id) DO NOTHING
+    RETURNING drops_id
+)
+UPDATE drops
+SET updated_at = NOW()
+WHERE id IN (SELECT drops_id FROM assigned)
+RETURNING id
+`
+
+type AssignTagToDropsParams struct {
+	TagID       int32
+	DropIds     []uuid.UUID
+	UserUuid    uuid.NullUUID
+	WorkspaceID uuid.NullUUID
+}
+
+// Associates a tag with those of the given drops that belong to the user in a workspace,
+// skipping drops that already carry it. The drops that got the tag are touched so sync
+// clients pick up the change, and their IDs are returned.
+func (q *Queries) AssignTagToDrops(ctx context.Context, arg AssignTagToDropsParams) ([]uuid.UUID, error) {
+	rows, err := q.db.QueryContext(ctx, assignTagToDrops,
+		arg.TagID,
+		pq.Array(arg.DropIds),
+		arg.UserUuid,
+		arg.WorkspaceID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const countTagDropsByStatusForUser = `-- name: CountTagDropsByStatusForUser :many
+SELECT d.status, COUNT(*) AS count
+FROM drops d
+JOIN drops_item_tags dit ON d.id = dit.drops_id
+WHERE dit.tag_id = $1 AND d.user_uuid = $2
+  AND d.workspace_id IS NOT DISTINCT FROM $3
+GROUP BY d.status
+ORDER BY d.status
+`
+
+type CountTagDropsByStatusForUserParams struct {
+	TagID       int32
+	UserUuid    uuid.NullUUID
+	WorkspaceID uuid.NullUUID
+}
+
+type CountTagDropsByStatusForUserRow struct {
+	Status string
+	Count  int64
+}
+
+// Counts the given user's drops carrying a tag, grouped by status.
+func (q *Queries) CountTagDropsByStatusForUser(ctx context.Context, arg CountTagDropsByStatusForUserParams) ([]CountTagDropsByStatusForUserRow, error) {
+	rows, err := q.db.QueryContext(ctx, countTagDropsByStatusForUser, arg.TagID, arg.UserUuid, arg.WorkspaceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []CountTagDropsByStatusForUserRow
+	for rows.Next() {
+		var i CountTagDropsByStatusForUserRow
+		if err := rows.Scan(&i.Status, &i.Count); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const fuzzySearchDrops = `-- name: FuzzySearchDrops :many
+SELECT d.id, d.user_uuid, d.topic, d.url, d.user_notes, d.added_date, d.updated_at, d.status, d.last_sent_date, d.send_count, d.priority, d.excerpt, d.next_send_date, d.workspace_id, d.schedule, d.permanent, d.channel, d.metadata, d.due_date, d.scheduled_send_date, d.reviewing_until, d.ease_factor, d.interval_days, s.score::float8 AS score
+FROM drops d
+CROSS JOIN LATERAL (
+    SELECT GREATEST(
+        word_similarity($1::text, d.topic),
+        COALESCE((
+            SELECT MAX(similarity($1::text, t.name))
+            FROM drops_item_tags dit
+            JOIN tags t ON t.id = dit.tag_id
+            WHERE dit.drops_id = d.id
+        ), 0)
+    ) AS score
+) s
+WHERE d.user_uuid = $2
+  AND d.workspace_id IS NOT DISTINCT FROM $3
+  AND s.score >= $4::float8
+ORDER BY s.score DESC, d.added_date DESC
+LIMIT $5
+`
+
+type FuzzySearchDropsParams struct {
+	Query       string
+	UserUuid    uuid.NullUUID
+	WorkspaceID uuid.NullUUID
+	MinScore    float64
+	Limit       int32
+}
+
+type FuzzySearchDropsRow struct {
+	Drop  Drop
+	Score float64
+}
+
+// Finds the user's drops in a workspace whose topic or tags are similar to query, so typos
+// still match. score is the better of the topic's word similarity and its best tag's
+// similarity to query (pg_trgm, 0 to 1). Drops scoring below min_score are left out and
+// the rest are ranked by score.
+func (q *Queries) FuzzySearchDrops(ctx context.Context, arg FuzzySearchDropsParams) ([]FuzzySearchDropsRow, error) {
+	rows, err := q.db.QueryContext(ctx, fuzzySearchDrops,
+		arg.Query,
+		arg.UserUuid,
+		arg.WorkspaceID,
+		arg.MinScore,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []FuzzySearchDropsRow
+	for rows.Next() {
+		var i FuzzySearchDropsRow
+		if err := rows.Scan(
+			&i.Drop.ID,
+			&i.Drop.UserUuid,
+			&i.Drop.Topic,
+			&i.Drop.Url,
+			&i.Drop.UserNotes,
+			&i.Drop.AddedDate,
+			&i.Drop.UpdatedAt,
+			&i.Drop.Status,
+			&i.Drop.LastSentDate,
+			&i.Drop.SendCount,
+			&i.Drop.Priority,
+			&i.Drop.Excerpt,
+			&i.Drop.NextSendDate,
+			&i.Drop.WorkspaceID,
+			&i.Drop.Schedule,
+			&i.Drop.Permanent,
+			&i.Drop.Channel,
+			&i.Drop.Metadata,
+			&i.Drop.DueDate,
+			&i.Drop.ScheduledSendDate,
+			&i.Drop.ReviewingUntil,
+			&i.Drop.EaseFactor,
+			&i.Drop.IntervalDays,
+			&i.Score,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getTagStatsForUser = `-- name: GetTagStatsForUser :one
+SELECT
+    COUNT(*) AS drop_count,
+    COALESCE(SUM(d.send_count), 0)::bigint AS total_sends
+FROM drops d
+JOIN drops_item_tags dit ON d.id = dit.drops_id
+WHERE dit.tag_id = $1 AND d.user_uuid = $2
+  AND d.workspace_id IS NOT DISTINCT FROM $3
+`
+
+type GetTagStatsForUserParams struct {
+	TagID       int32
+	UserUuid    uuid.NullUUID
+	WorkspaceID uuid.NullUUID
+}
+
+type GetTagStatsForUserRow struct {
+	DropCount  int64
+	TotalSends int64
+}
+
+// Aggregates the number of drops and total sends for a tag, limited to the given user's drops.
+func (q *Queries) GetTagStatsForUser(ctx context.Context, arg GetTagStatsForUserParams) (GetTagStatsForUserRow, error) {
+	row := q.db.QueryRowContext(ctx, getTagStatsForUser, arg.TagID, arg.UserUuid, arg.WorkspaceID)
+	var i GetTagStatsForUserRow
+	err := row.Scan(&i.DropCount, &i.TotalSends)
+	return i, err
+}
+
 const getTagsForDrop = `-- name: GetTagsForDrop :many
 SELECT t.id, t.name
 FROM tags t
@@ -61,6 +284,204 @@ func (q *Queries) GetTagsForDrop(ctx context.Context, dropsID uuid.UUID) ([]Tag,
 	return items, nil
 }
 
+const getTagsForDrops = `-- name: GetTagsForDrops :many
+SELECT dit.drops_id, t.id, t.name
+FROM tags t
+JOIN drops_item_tags dit ON t.id = dit.tag_id
+WHERE dit.drops_id = ANY($1::uuid[])
+ORDER BY dit.drops_id, t.name
+`
+
+type GetTagsForDropsRow struct {
+	DropsID uuid.UUID
+	ID      int32
+	Name    string
+}
+
+// Retrieves the tags of several drops in one query, avoiding a query per drop.
+func (q *Queries) GetTagsForDrops(ctx context.Context, dropIds []uuid.UUID) ([]GetTagsForDropsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getTagsForDrops, pq.Array(dropIds))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetTagsForDropsRow
+	for rows.Next() {
+		var i GetTagsForDropsRow
+		if err := rows.Scan(&i.DropsID, &i.ID, &i.Name); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listDropsGroupedByTag = `-- name: ListDropsGroupedByTag :many
+SELECT d.id, d.user_uuid, d.topic, d.url, d.user_notes, d.added_date, d.updated_at, d.status, d.last_sent_date, d.send_count, d.priority, d.excerpt, d.next_send_date, d.workspace_id, d.schedule, d.permanent, d.channel, d.metadata, d.due_date, d.scheduled_send_date, d.reviewing_until, d.ease_factor, d.interval_days, g.tag_name
+FROM (
+    SELECT d.id AS drop_id, t.name AS tag_name,
+        ROW_NUMBER() OVER (PARTITION BY t.name ORDER BY d.added_date DESC, d.id) AS rank
+    FROM drops d
+    LEFT JOIN drops_item_tags dit ON dit.drops_id = d.id
+    LEFT JOIN tags t ON t.id = dit.tag_id
+    WHERE d.user_uuid = $1
+      AND d.workspace_id IS NOT DISTINCT FROM $2
+) g
+JOIN drops d ON d.id = g.drop_id
+WHERE g.rank <= $3
+ORDER BY g.tag_name NULLS LAST, d.added_date DESC, d.id
+`
+
+type ListDropsGroupedByTagParams struct {
+	UserUuid    uuid.NullUUID
+	WorkspaceID uuid.NullUUID
+	PerGroup    int64
+}
+
+type ListDropsGroupedByTagRow struct {
+	Drop    Drop
+	TagName sql.NullString
+}
+
+// Lists the user's drops in a workspace under each of their tags, newest first, keeping at
+// most per_group drops per tag. A drop with several tags is returned once per tag; untagged
+// drops come with a NULL tag name.
+func (q *Queries) ListDropsGroupedByTag(ctx context.Context, arg ListDropsGroupedByTagParams) ([]ListDropsGroupedByTagRow, error) {
+	rows, err := q.db.QueryContext(ctx, listDropsGroupedByTag, arg.UserUuid, arg.WorkspaceID, arg.PerGroup)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListDropsGroupedByTagRow
+	for rows.Next() {
+		var i ListDropsGroupedByTagRow
+		if err := rows.Scan(
+			&i.Drop.ID,
+			&i.Drop.UserUuid,
+			&i.Drop.Topic,
+			&i.Drop.Url,
+			&i.Drop.UserNotes,
+			&i.Drop.AddedDate,
+			&i.Drop.UpdatedAt,
+			&i.Drop.Status,
+			&i.Drop.LastSentDate,
+			&i.Drop.SendCount,
+			&i.Drop.Priority,
+			&i.Drop.Excerpt,
+			&i.Drop.NextSendDate,
+			&i.Drop.WorkspaceID,
+			&i.Drop.Schedule,
+			&i.Drop.Permanent,
+			&i.Drop.Channel,
+			&i.Drop.Metadata,
+			&i.Drop.DueDate,
+			&i.Drop.ScheduledSendDate,
+			&i.Drop.ReviewingUntil,
+			&i.Drop.EaseFactor,
+			&i.Drop.IntervalDays,
+			&i.TagName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRelatedDrops = `-- name: ListRelatedDrops :many
+SELECT d.id, d.user_uuid, d.topic, d.url, d.user_notes, d.added_date, d.updated_at, d.status, d.last_sent_date, d.send_count, d.priority, d.excerpt, d.next_send_date, d.workspace_id, d.schedule, d.permanent, d.channel, d.metadata, d.due_date, d.scheduled_send_date, d.reviewing_until, d.ease_factor, d.interval_days, COUNT(*) AS shared_tags
+FROM drops d
+JOIN drops_item_tags dit ON d.id = dit.drops_id
+WHERE dit.tag_id IN (
+    SELECT src.tag_id FROM drops_item_tags src WHERE src.drops_id = $1
+  )
+  AND d.id <> $1
+  AND d.user_uuid = $2
+  AND d.workspace_id IS NOT DISTINCT FROM $3
+GROUP BY d.id
+ORDER BY shared_tags DESC, d.added_date DESC
+LIMIT $4
+`
+
+type ListRelatedDropsParams struct {
+	DropID      uuid.UUID
+	UserUuid    uuid.NullUUID
+	WorkspaceID uuid.NullUUID
+	Limit       int32
+}
+
+type ListRelatedDropsRow struct {
+	Drop       Drop
+	SharedTags int64
+}
+
+// Lists the user's other drops in the same workspace that share tags with the given drop,
+// ranked by the number of shared tags.
+func (q *Queries) ListRelatedDrops(ctx context.Context, arg ListRelatedDropsParams) ([]ListRelatedDropsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listRelatedDrops,
+		arg.DropID,
+		arg.UserUuid,
+		arg.WorkspaceID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListRelatedDropsRow
+	for rows.Next() {
+		var i ListRelatedDropsRow
+		if err := rows.Scan(
+			&i.Drop.ID,
+			&i.Drop.UserUuid,
+			&i.Drop.Topic,
+			&i.Drop.Url,
+			&i.Drop.UserNotes,
+			&i.Drop.AddedDate,
+			&i.Drop.UpdatedAt,
+			&i.Drop.Status,
+			&i.Drop.LastSentDate,
+			&i.Drop.SendCount,
+			&i.Drop.Priority,
+			&i.Drop.Excerpt,
+			&i.Drop.NextSendDate,
+			&i.Drop.WorkspaceID,
+			&i.Drop.Schedule,
+			&i.Drop.Permanent,
+			&i.Drop.Channel,
+			&i.Drop.Metadata,
+			&i.Drop.DueDate,
+			&i.Drop.ScheduledSendDate,
+			&i.Drop.ReviewingUntil,
+			&i.Drop.EaseFactor,
+			&i.Drop.IntervalDays,
+			&i.SharedTags,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const removeAllTagsFromDrop = `-- name: RemoveAllTagsFromDrop :exec
 DELETE FROM drops_item_tags
 WHERE drops_id = $1
@@ -88,3 +509,165 @@ func (q *Queries) RemoveTagFromDrop(ctx context.Context, arg RemoveTagFromDropPa
 	_, err := q.db.ExecContext(ctx, removeTagFromDrop, arg.DropsID, arg.TagID)
 	return err
 }
+
+const searchDrops = `-- name: SearchDrops :many
+SELECT d.id, d.user_uuid, d.topic, d.url, d.user_notes, d.added_date, d.updated_at, d.status, d.last_sent_date, d.send_count, d.priority, d.excerpt, d.next_send_date, d.workspace_id, d.schedule, d.permanent, d.channel, d.metadata, d.due_date, d.scheduled_send_date, d.reviewing_until, d.ease_factor, d.interval_days
+FROM drops d
+WHERE d.user_uuid = $1
+  AND d.workspace_id IS NOT DISTINCT FROM $2
+  AND (
+    d.topic ILIKE $3::text
+    OR EXISTS (
+      SELECT 1 FROM drops_item_tags dit
+      JOIN tags t ON t.id = dit.tag_id
+      WHERE dit.drops_id = d.id AND t.name ILIKE $3::text
+    )
+  )
+ORDER BY d.topic ILIKE $3::text DESC, d.added_date DESC
+LIMIT $4
+`
+
+type SearchDropsParams struct {
+	UserUuid    uuid.NullUUID
+	WorkspaceID uuid.NullUUID
+	Pattern     string
+	Limit       int32
+}
+
+type SearchDropsRow struct {
+	Drop Drop
+}
+
+// Finds the user's drops in a workspace whose topic, or the name of one of whose tags,
+// contains query. pattern is query as an ILIKE pattern ('%query%' with wildcards escaped).
+// Topic matches rank before drops matching only by tag, newest first.
+func (q *Queries) SearchDrops(ctx context.Context, arg SearchDropsParams) ([]SearchDropsRow, error) {
+	rows, err := q.db.QueryContext(ctx, searchDrops,
+		arg.UserUuid,
+		arg.WorkspaceID,
+		arg.Pattern,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SearchDropsRow
+	for rows.Next() {
+		var i SearchDropsRow
+		if err := rows.Scan(
+			&i.Drop.ID,
+			&i.Drop.UserUuid,
+			&i.Drop.Topic,
+			&i.Drop.Url,
+			&i.Drop.UserNotes,
+			&i.Drop.AddedDate,
+			&i.Drop.UpdatedAt,
+			&i.Drop.Status,
+			&i.Drop.LastSentDate,
+			&i.Drop.SendCount,
+			&i.Drop.Priority,
+			&i.Drop.Excerpt,
+			&i.Drop.NextSendDate,
+			&i.Drop.WorkspaceID,
+			&i.Drop.Schedule,
+			&i.Drop.Permanent,
+			&i.Drop.Channel,
+			&i.Drop.Metadata,
+			&i.Drop.DueDate,
+			&i.Drop.ScheduledSendDate,
+			&i.Drop.ReviewingUntil,
+			&i.Drop.EaseFactor,
+			&i.Drop.IntervalDays,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const unassignTagFromDrops = `-- name: UnassignTagFromDrops :many
+WITH unassigned AS (
+    DELETE FROM drops_item_tags dit
+    USING drops d
+    WHERE dit.drops_id = d.id
+      AND dit.tag_id = $1
+      AND ($2::bool OR d.id = ANY($3::uuid[]))
+      AND d.user_uuid = $4
+      AND d.workspace_id IS NOT DISTINCT FROM $5
+    RETURNING dit.drops_id
+)
+UPDATE drops
+SET updated_at = NOW()
+WHERE id IN (SELECT drops_id FROM unassigned)
+RETURNING id
+`
+
+type UnassignTagFromDropsParams struct {
+	TagID       int32
+	AllDrops    bool
+	DropIds     []uuid.UUID
+	UserUuid    uuid.NullUUID
+	WorkspaceID uuid.NullUUID
+}
+
+// Removes a tag from those of the given drops that belong to the user in a workspace, or with
+// all_drops from every such drop, skipping drops without it. The drops that lost the tag are
+// touched so sync clients pick up the change, and their IDs are returned.
+func (q *Queries) UnassignTagFromDrops(ctx context.Context, arg UnassignTagFromDropsParams) ([]uuid.UUID, error) {
+	rows, err := q.db.QueryContext(ctx, unassignTagFromDrops,
+		arg.TagID,
+		arg.AllDrops,
+		pq.Array(arg.DropIds),
+		arg.UserUuid,
+		arg.WorkspaceID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const userHasTag = `-- name: UserHasTag :one
+SELECT EXISTS (
+    SELECT 1
+    FROM drops_item_tags dit
+    JOIN drops d ON d.id = dit.drops_id
+    WHERE dit.tag_id = $1 AND d.user_uuid = $2
+)
+`
+
+type UserHasTagParams struct {
+	TagID    int32
+	UserUuid uuid.NullUUID
+}
+
+// Reports whether any of the user's drops, in any workspace, carries the tag.
+func (q *Queries) UserHasTag(ctx context.Context, arg UserHasTagParams) (bool, error) {
+	row := q.db.QueryRowContext(ctx, userHasTag, arg.TagID, arg.UserUuid)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
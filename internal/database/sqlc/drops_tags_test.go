@@ -0,0 +1,93 @@
+package db_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/google/uuid"
+	db "github.com/nouvadev/dropwise/internal/database/sqlc"
+	"github.com/nouvadev/dropwise/internal/testdb"
+)
+
+func TestFuzzySearchDrops(t *testing.T) {
+	database := testdb.New(t)
+	q := database.Queries
+	ctx := context.Background()
+	alice := testdb.CreateUser(t, q)
+	bob := testdb.CreateUser(t, q)
+	workspace, err := q.CreateWorkspace(ctx, db.CreateWorkspaceParams{Name: "Team", OwnerUuid: alice})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	withTopic := func(topic string) func(*db.CreateDropParams) {
+		return func(p *db.CreateDropParams) { p.Topic = topic }
+	}
+	goroutines := testdb.CreateDrop(t, q, alice, withTopic("Goroutine patterns in Go"))
+	tagged := testdb.CreateDrop(t, q, alice, withTopic("Notes from the meetup"))
+	tag, err := q.CreateTag(ctx, "concurrency")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := q.AddTagToDrop(ctx, db.AddTagToDropParams{DropsID: tagged.ID, TagID: tag.ID}); err != nil {
+		t.Fatal(err)
+	}
+	testdb.CreateDrop(t, q, alice, withTopic("Baking sourdough bread"))
+	testdb.CreateDrop(t, q, bob, withTopic("Goroutine leaks"))
+	testdb.CreateDrop(t, q, alice, func(p *db.CreateDropParams) {
+		p.Topic = "Goroutine scheduling"
+		p.WorkspaceID = uuid.NullUUID{UUID: workspace.ID, Valid: true}
+	})
+
+	tests := []struct {
+		name  string
+		query string
+		want  []uuid.UUID
+	}{
+		{"misspelled topic word", "goroutien", []uuid.UUID{goroutines.ID}},
+		{"misspelled and capitalized", "GORUTINE", []uuid.UUID{goroutines.ID}},
+		{"misspelled tag", "concurency", []uuid.UUID{tagged.ID}},
+		{"nothing similar", "kubernetes", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, err := q.FuzzySearchDrops(ctx, db.FuzzySearchDropsParams{
+				Query:    tt.query,
+				UserUuid: uuid.NullUUID{UUID: alice, Valid: true},
+				MinScore: 0.3,
+				Limit:    10,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []uuid.UUID
+			var names []string
+			for _, row := range rows {
+				got = append(got, row.Drop.ID)
+				names = append(names, row.Drop.Topic)
+				if row.Score < 0.3 || row.Score > 1 {
+					t.Errorf("drop %q scored %v, want between the threshold and 1", row.Drop.Topic, row.Score)
+				}
+			}
+			// Bob's drop and the workspace drop also match, but are out of scope
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("FuzzySearchDrops(%q) = %v, want %d drop(s) from alice's personal space", tt.query, names, len(tt.want))
+			}
+		})
+	}
+
+	rows, err := q.FuzzySearchDrops(ctx, db.FuzzySearchDropsParams{
+		Query:       "goroutien",
+		UserUuid:    uuid.NullUUID{UUID: alice, Valid: true},
+		WorkspaceID: uuid.NullUUID{UUID: workspace.ID, Valid: true},
+		MinScore:    0.3,
+		Limit:       10,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 1 || rows[0].Drop.Topic != "Goroutine scheduling" {
+		t.Errorf("FuzzySearchDrops in the workspace = %d rows, want only the workspace's drop", len(rows))
+	}
+}
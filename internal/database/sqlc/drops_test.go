@@ -0,0 +1,142 @@
+package db_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	db "github.com/nouvadev/dropwise/internal/database/sqlc"
+	"github.com/nouvadev/dropwise/internal/testdb"
+)
+
+func TestGetDueDropsByUserUUIDPrefersPriority(t *testing.T) {
+	database := testdb.New(t)
+	q := database.Queries
+	ctx := context.Background()
+	userID := testdb.CreateUser(t, q)
+	now := time.Now().UTC()
+
+	drop := func(topic string, priority sql.NullInt32, nextSend time.Time) db.Drop {
+		return testdb.CreateDrop(t, q, userID, func(p *db.CreateDropParams) {
+			p.Topic = topic
+			p.Priority = priority
+			p.NextSendDate = sql.NullTime{Time: nextSend, Valid: true}
+		})
+	}
+	high := drop("high, due just now", sql.NullInt32{Int32: 5, Valid: true}, now.Add(-time.Minute))
+	low := drop("low, long overdue", sql.NullInt32{Int32: 1, Valid: true}, now.Add(-30*24*time.Hour))
+	unset := drop("no priority, most overdue", sql.NullInt32{}, now.Add(-60*24*time.Hour))
+	drop("highest, not due yet", sql.NullInt32{Int32: 9, Valid: true}, now.Add(time.Hour))
+
+	due, err := q.GetDueDropsByUserUUID(ctx, db.GetDueDropsByUserUUIDParams{
+		UserUuid:       uuid.NullUUID{UUID: userID, Valid: true},
+		DueBefore:      now,
+		LastSentBefore: now,
+		Limit:          1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(due) != 1 || due[0].ID != high.ID {
+		t.Fatalf("GetDueDropsByUserUUID(limit 1) = %v, want the high priority drop", topics(due))
+	}
+
+	due, err = q.GetDueDropsByUserUUID(ctx, db.GetDueDropsByUserUUIDParams{
+		UserUuid:       uuid.NullUUID{UUID: userID, Valid: true},
+		DueBefore:      now,
+		LastSentBefore: now,
+		Limit:          10,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []uuid.UUID{high.ID, low.ID, unset.ID}
+	if len(due) != len(want) {
+		t.Fatalf("GetDueDropsByUserUUID() = %v, want 3 due drops", topics(due))
+	}
+	for i, id := range want {
+		if due[i].ID != id {
+			t.Errorf("GetDueDropsByUserUUID() = %v, want high, low, then no priority", topics(due))
+			break
+		}
+	}
+}
+
+// topics lists the drops' topics, for failure messages.
+func topics(drops []db.Drop) []string {
+	names := make([]string, len(drops))
+	for i, d := range drops {
+		names[i] = d.Topic
+	}
+	return names
+}
+
+func TestPatchDropKeepsChangesMadeSinceTheRead(t *testing.T) {
+	database := testdb.New(t)
+	q := database.Queries
+	ctx := context.Background()
+	userID := testdb.CreateUser(t, q)
+	owner := uuid.NullUUID{UUID: userID, Valid: true}
+	now := time.Now().UTC().Truncate(time.Second)
+
+	read := testdb.CreateDrop(t, q, userID, func(p *db.CreateDropParams) {
+		p.NextSendDate = sql.NullTime{Time: now.Add(-time.Hour), Valid: true}
+	})
+	// The worker sends the drop after the patch read it
+	sent, err := q.MarkDropAsSent(ctx, db.MarkDropAsSentParams{
+		ID:           read.ID,
+		LastSentDate: sql.NullTime{Time: now, Valid: true},
+		NextSendDate: sql.NullTime{Time: now.Add(72 * time.Hour), Valid: true},
+		Status:       "sent",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		params     db.PatchDropParams
+		wantErr    error
+		wantTopic  string
+		wantStatus string
+	}{
+		{
+			name:       "topic only",
+			params:     db.PatchDropParams{SetTopic: true, Topic: "Renamed"},
+			wantTopic:  "Renamed",
+			wantStatus: "sent",
+		},
+		{
+			name:    "status checked against the stale status",
+			params:  db.PatchDropParams{SetStatus: true, Status: "archived", ExpectedStatus: read.Status},
+			wantErr: sql.ErrNoRows,
+		},
+		{
+			name:       "status checked against the current status",
+			params:     db.PatchDropParams{SetStatus: true, Status: "archived", ExpectedStatus: "sent"},
+			wantTopic:  "Renamed",
+			wantStatus: "archived",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.params.ID, tt.params.UserUuid = read.ID, owner
+			got, err := q.PatchDrop(ctx, tt.params)
+			if err != tt.wantErr {
+				t.Fatalf("PatchDrop() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got.Topic != tt.wantTopic || got.Status != tt.wantStatus {
+				t.Errorf("PatchDrop() = topic %q, status %q, want %q, %q", got.Topic, got.Status, tt.wantTopic, tt.wantStatus)
+			}
+			if !got.NextSendDate.Time.Equal(sent.NextSendDate.Time) || got.SendCount != sent.SendCount {
+				t.Errorf("PatchDrop() reset the send: next_send_date %v, send_count %d, want %v, %d",
+					got.NextSendDate.Time, got.SendCount, sent.NextSendDate.Time, sent.SendCount)
+			}
+		})
+	}
+}
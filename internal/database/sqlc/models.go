@@ -6,23 +6,68 @@ package db
 
 import (
 	"database/sql"
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+type AuditLog struct {
+	ID         int64
+	OccurredAt time.Time
+	ActorUuid  uuid.NullUUID
+	Action     string
+	Target     sql.NullString
+	SourceIp   sql.NullString
+	Metadata   json.RawMessage
+}
+
+type Collection struct {
+	ID          uuid.UUID
+	UserUuid    uuid.UUID
+	WorkspaceID uuid.NullUUID
+	Name        string
+	CreatedAt   time.Time
+	PublicSlug  sql.NullString
+}
+
+type CollectionDrop struct {
+	CollectionID uuid.UUID
+	DropsID      uuid.UUID
+	AddedAt      time.Time
+}
+
 type Drop struct {
-	ID           uuid.UUID
-	UserUuid     uuid.NullUUID
-	Topic        string
-	Url          string
-	UserNotes    sql.NullString
-	AddedDate    time.Time
-	UpdatedAt    time.Time
-	Status       string
-	LastSentDate sql.NullTime
-	SendCount    int32
-	Priority     sql.NullInt32
+	ID                uuid.UUID
+	UserUuid          uuid.NullUUID
+	Topic             string
+	Url               string
+	UserNotes         sql.NullString
+	AddedDate         time.Time
+	UpdatedAt         time.Time
+	Status            string
+	LastSentDate      sql.NullTime
+	SendCount         int32
+	Priority          sql.NullInt32
+	Excerpt           sql.NullString
+	NextSendDate      sql.NullTime
+	WorkspaceID       uuid.NullUUID
+	Schedule          sql.NullString
+	Permanent         bool
+	Channel           string
+	Metadata          json.RawMessage
+	DueDate           sql.NullTime
+	ScheduledSendDate sql.NullTime
+	ReviewingUntil    sql.NullTime
+	EaseFactor        float64
+	IntervalDays      sql.NullInt32
+}
+
+type DropTombstone struct {
+	DropID      uuid.UUID
+	UserUuid    uuid.UUID
+	WorkspaceID uuid.NullUUID
+	DeletedAt   time.Time
 }
 
 type DropsItemTag struct {
@@ -30,6 +75,27 @@ type DropsItemTag struct {
 	TagID   int32
 }
 
+type InboundEmailToken struct {
+	UserUuid  uuid.UUID
+	Token     string
+	CreatedAt time.Time
+}
+
+type MaintenanceMode struct {
+	ID        bool
+	Enabled   bool
+	UpdatedBy string
+	UpdatedAt time.Time
+}
+
+type ReviewStreak struct {
+	UserUuid       uuid.UUID
+	CurrentStreak  int32
+	LongestStreak  int32
+	LastReviewDate sql.NullTime
+	UpdatedAt      time.Time
+}
+
 type Tag struct {
 	ID   int32
 	Name string
@@ -42,3 +108,51 @@ type User struct {
 	CreatedAt      time.Time
 	UpdatedAt      time.Time
 }
+
+type UserPreference struct {
+	UserUuid             uuid.UUID
+	NotifyWhenCaughtUp   bool
+	CaughtUpWebhookUrl   sql.NullString
+	CaughtUpAt           sql.NullTime
+	UpdatedAt            time.Time
+	DefaultChannel       string
+	SlackWebhookUrl      sql.NullString
+	Timezone             sql.NullString
+	DailyDrop            bool
+	DailyDropHour        int32
+	FirstDropDate        sql.NullTime
+	RepetitionIntervals  []int32
+	RemindersPaused      bool
+	PausedAt             sql.NullTime
+	PausedUntil          sql.NullTime
+	AutoArchiveAfterSend bool
+	DailyDropLimit       sql.NullInt32
+	SendsToday           int32
+	SendsResetAt         sql.NullTime
+}
+
+type WorkerRun struct {
+	ID             int64
+	StartedAt      time.Time
+	DurationMs     int64
+	ProcessedCount int32
+	FailedCount    int32
+	RemainingCount int32
+	DailyDropCount int32
+	Degraded       bool
+	Error          sql.NullString
+}
+
+type Workspace struct {
+	ID        uuid.UUID
+	Name      string
+	OwnerUuid uuid.UUID
+	CreatedAt time.Time
+}
+
+type WorkspaceMember struct {
+	WorkspaceID uuid.UUID
+	UserUuid    uuid.UUID
+	Role        string
+	CreatedAt   time.Time
+}
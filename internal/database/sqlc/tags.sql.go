@@ -7,8 +7,32 @@ package db
 
 import (
 	"context"
+	"time"
+
+	"github.com/google/uuid"
 )
 
+const countTagsForUser = `-- name: CountTagsForUser :one
+SELECT COUNT(DISTINCT dit.tag_id)
+FROM drops_item_tags dit
+JOIN drops d ON d.id = dit.drops_id
+WHERE d.user_uuid = $1
+  AND d.workspace_id IS NOT DISTINCT FROM $2
+`
+
+type CountTagsForUserParams struct {
+	UserUuid    uuid.NullUUID
+	WorkspaceID uuid.NullUUID
+}
+
+// Counts the distinct tags used on the user's drops in a workspace.
+func (q *Queries) CountTagsForUser(ctx context.Context, arg CountTagsForUserParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countTagsForUser, arg.UserUuid, arg.WorkspaceID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createTag = `-- name: CreateTag :one
 INSERT INTO tags (name)
 VALUES ($1)
@@ -38,21 +62,219 @@ func (q *Queries) GetTagByName(ctx context.Context, name string) (Tag, error) {
 	return i, err
 }
 
-const listTags = `-- name: ListTags :many
-SELECT id, name FROM tags
-ORDER BY name
+const listRecentTagsForUser = `-- name: ListRecentTagsForUser :many
+SELECT t.id, t.name, MAX(d.updated_at)::timestamptz AS last_used_at
+FROM tags t
+JOIN drops_item_tags dit ON t.id = dit.tag_id
+JOIN drops d ON d.id = dit.drops_id
+WHERE d.user_uuid = $1
+  AND d.workspace_id IS NOT DISTINCT FROM $2
+GROUP BY t.id, t.name
+ORDER BY last_used_at DESC, t.name ASC
+LIMIT $3
+`
+
+type ListRecentTagsForUserParams struct {
+	UserUuid    uuid.NullUUID
+	WorkspaceID uuid.NullUUID
+	Limit       int32
+}
+
+type ListRecentTagsForUserRow struct {
+	ID         int32
+	Name       string
+	LastUsedAt time.Time
+}
+
+// Lists the tags on the user's drops in a workspace by when they were last used: the latest
+// update of any drop carrying the tag, which includes tagging it. Ties are broken by name.
+func (q *Queries) ListRecentTagsForUser(ctx context.Context, arg ListRecentTagsForUserParams) ([]ListRecentTagsForUserRow, error) {
+	rows, err := q.db.QueryContext(ctx, listRecentTagsForUser, arg.UserUuid, arg.WorkspaceID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListRecentTagsForUserRow
+	for rows.Next() {
+		var i ListRecentTagsForUserRow
+		if err := rows.Scan(&i.ID, &i.Name, &i.LastUsedAt); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTagCandidatesForDrop = `-- name: ListTagCandidatesForDrop :many
+SELECT t.id, t.name, COUNT(*) AS drop_count
+FROM tags t
+JOIN drops_item_tags dit ON t.id = dit.tag_id
+JOIN drops d ON d.id = dit.drops_id
+WHERE d.user_uuid = $1
+  AND d.workspace_id IS NOT DISTINCT FROM $2
+  AND t.id NOT IN (SELECT src.tag_id FROM drops_item_tags src WHERE src.drops_id = $3)
+GROUP BY t.id, t.name
+`
+
+type ListTagCandidatesForDropParams struct {
+	UserUuid    uuid.NullUUID
+	WorkspaceID uuid.NullUUID
+	DropID      uuid.UUID
+}
+
+type ListTagCandidatesForDropRow struct {
+	ID        int32
+	Name      string
+	DropCount int64
+}
+
+// Lists the tags on the user's drops in a workspace that the given drop doesn't carry yet,
+// with how many drops carry each. These are the tags that can be suggested for the drop.
+func (q *Queries) ListTagCandidatesForDrop(ctx context.Context, arg ListTagCandidatesForDropParams) ([]ListTagCandidatesForDropRow, error) {
+	rows, err := q.db.QueryContext(ctx, listTagCandidatesForDrop, arg.UserUuid, arg.WorkspaceID, arg.DropID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListTagCandidatesForDropRow
+	for rows.Next() {
+		var i ListTagCandidatesForDropRow
+		if err := rows.Scan(&i.ID, &i.Name, &i.DropCount); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTagsForUser = `-- name: ListTagsForUser :many
+SELECT t.id, t.name, COUNT(*) AS drop_count
+FROM tags t
+JOIN drops_item_tags dit ON t.id = dit.tag_id
+JOIN drops d ON d.id = dit.drops_id
+WHERE d.user_uuid = $1
+  AND d.workspace_id IS NOT DISTINCT FROM $2
+GROUP BY t.id, t.name
+ORDER BY
+    CASE WHEN $3::text = 'name' AND $4::text = 'asc' THEN t.name END ASC,
+    CASE WHEN $3::text = 'name' AND $4::text = 'desc' THEN t.name END DESC,
+    CASE WHEN $3::text = 'count' AND $4::text = 'asc' THEN COUNT(*) END ASC,
+    CASE WHEN $3::text = 'count' AND $4::text = 'desc' THEN COUNT(*) END DESC,
+    t.name ASC
+LIMIT $5 OFFSET $6
+`
+
+type ListTagsForUserParams struct {
+	UserUuid    uuid.NullUUID
+	WorkspaceID uuid.NullUUID
+	SortBy      string
+	SortOrder   string
+	Limit       int32
+	Offset      int32
+}
+
+type ListTagsForUserRow struct {
+	ID        int32
+	Name      string
+	DropCount int64
+}
+
+// Tags are shared between users, so listings must always go through the user's drops.
+// Lists the tags used on the user's drops in a workspace, with how many drops carry each.
+// sort_by is 'name' or 'count' and sort_order is 'asc' or 'desc'; ties are broken by name.
+func (q *Queries) ListTagsForUser(ctx context.Context, arg ListTagsForUserParams) ([]ListTagsForUserRow, error) {
+	rows, err := q.db.QueryContext(ctx, listTagsForUser,
+		arg.UserUuid,
+		arg.WorkspaceID,
+		arg.SortBy,
+		arg.SortOrder,
+		arg.Limit,
+		arg.Offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListTagsForUserRow
+	for rows.Next() {
+		var i ListTagsForUserRow
+		if err := rows.Scan(&i.ID, &i.Name, &i.DropCount); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const searchTagsForUser = `-- name: SearchTagsForUser :many
+SELECT t.id, t.name, COUNT(*) AS drop_count
+FROM tags t
+JOIN drops_item_tags dit ON t.id = dit.tag_id
+JOIN drops d ON d.id = dit.drops_id
+WHERE d.user_uuid = $1
+  AND d.workspace_id IS NOT DISTINCT FROM $2
+  AND t.name ILIKE $3::text
+GROUP BY t.id, t.name
+ORDER BY
+    lower(t.name) = lower($4::text) DESC,
+    COUNT(*) DESC,
+    strpos(lower(t.name), lower($4::text)) ASC,
+    t.name ASC
+LIMIT $5
 `
 
-func (q *Queries) ListTags(ctx context.Context) ([]Tag, error) {
-	rows, err := q.db.QueryContext(ctx, listTags)
+type SearchTagsForUserParams struct {
+	UserUuid    uuid.NullUUID
+	WorkspaceID uuid.NullUUID
+	Pattern     string
+	Query       string
+	Limit       int32
+}
+
+type SearchTagsForUserRow struct {
+	ID        int32
+	Name      string
+	DropCount int64
+}
+
+// Finds the tags on the user's drops in a workspace whose name contains query. pattern is
+// query as an ILIKE pattern ('%query%' with wildcards escaped). An exact match ranks first,
+// then the most used tags, so a capped result keeps the useful ones; ties go to names where
+// query appears earlier.
+func (q *Queries) SearchTagsForUser(ctx context.Context, arg SearchTagsForUserParams) ([]SearchTagsForUserRow, error) {
+	rows, err := q.db.QueryContext(ctx, searchTagsForUser,
+		arg.UserUuid,
+		arg.WorkspaceID,
+		arg.Pattern,
+		arg.Query,
+		arg.Limit,
+	)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []Tag
+	var items []SearchTagsForUserRow
 	for rows.Next() {
-		var i Tag
-		if err := rows.Scan(&i.ID, &i.Name); err != nil {
+		var i SearchTagsForUserRow
+		if err := rows.Scan(&i.ID, &i.Name, &i.DropCount); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
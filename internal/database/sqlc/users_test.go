@@ -0,0 +1,96 @@
+package db_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	db "github.com/nouvadev/dropwise/internal/database/sqlc"
+	"github.com/nouvadev/dropwise/internal/testdb"
+)
+
+func TestDeleteUserCascades(t *testing.T) {
+	database := testdb.New(t)
+	q := database.Queries
+	ctx := context.Background()
+	must := func(err error) {
+		t.Helper()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	alice := testdb.CreateUser(t, q)
+	bob := testdb.CreateUser(t, q)
+
+	// Give alice one of everything that hangs off a user
+	workspace, err := q.CreateWorkspace(ctx, db.CreateWorkspaceParams{Name: "Alice's", OwnerUuid: alice})
+	must(err)
+	must(q.AddWorkspaceMember(ctx, db.AddWorkspaceMemberParams{WorkspaceID: workspace.ID, UserUuid: alice, Role: "owner"}))
+	bobsWorkspace, err := q.CreateWorkspace(ctx, db.CreateWorkspaceParams{Name: "Bob's", OwnerUuid: bob})
+	must(err)
+	must(q.AddWorkspaceMember(ctx, db.AddWorkspaceMemberParams{WorkspaceID: bobsWorkspace.ID, UserUuid: alice, Role: "member"}))
+
+	drop := testdb.CreateDrop(t, q, alice, nil)
+	testdb.CreateDrop(t, q, alice, func(p *db.CreateDropParams) {
+		p.WorkspaceID = uuid.NullUUID{UUID: workspace.ID, Valid: true}
+	})
+	tag, err := q.CreateTag(ctx, "cascade")
+	must(err)
+	must(q.AddTagToDrop(ctx, db.AddTagToDropParams{DropsID: drop.ID, TagID: tag.ID}))
+	collection, err := q.CreateCollection(ctx, db.CreateCollectionParams{UserUuid: alice, Name: "Reading"})
+	must(err)
+	must(q.AddDropToCollection(ctx, db.AddDropToCollectionParams{CollectionID: collection.ID, DropsID: drop.ID}))
+	_, err = q.UpsertUserPreferences(ctx, db.UpsertUserPreferencesParams{UserUuid: alice, DefaultChannel: "email"})
+	must(err)
+	_, err = q.RecordReview(ctx, db.RecordReviewParams{UserUuid: alice, ReviewDate: time.Now().UTC()})
+	must(err)
+	deleted := testdb.CreateDrop(t, q, alice, nil)
+	must(q.DeleteDrop(ctx, db.DeleteDropParams{ID: deleted.ID, UserUuid: uuid.NullUUID{UUID: alice, Valid: true}}))
+
+	// Bob's data must survive
+	bobsDrop := testdb.CreateDrop(t, q, bob, nil)
+	must(q.AddTagToDrop(ctx, db.AddTagToDropParams{DropsID: bobsDrop.ID, TagID: tag.ID}))
+
+	rows, err := q.DeleteUser(ctx, alice)
+	must(err)
+	if rows != 1 {
+		t.Fatalf("DeleteUser() deleted %d rows, want 1", rows)
+	}
+
+	count := func(query string, args ...any) int {
+		t.Helper()
+		var n int
+		if err := database.Conn.QueryRowContext(ctx, query, args...).Scan(&n); err != nil {
+			t.Fatalf("%s: %v", query, err)
+		}
+		return n
+	}
+	tests := []struct {
+		table string
+		query string
+		args  []any
+		want  int
+	}{
+		{"drops", "SELECT COUNT(*) FROM drops WHERE user_uuid = $1", []any{alice}, 0},
+		{"drops_item_tags", "SELECT COUNT(*) FROM drops_item_tags WHERE drops_id = $1", []any{drop.ID}, 0},
+		{"collection_drops", "SELECT COUNT(*) FROM collection_drops WHERE drops_id = $1", []any{drop.ID}, 0},
+		{"collections", "SELECT COUNT(*) FROM collections WHERE user_uuid = $1", []any{alice}, 0},
+		{"user_preferences", "SELECT COUNT(*) FROM user_preferences WHERE user_uuid = $1", []any{alice}, 0},
+		{"review_streaks", "SELECT COUNT(*) FROM review_streaks WHERE user_uuid = $1", []any{alice}, 0},
+		{"drop_tombstones", "SELECT COUNT(*) FROM drop_tombstones WHERE user_uuid = $1", []any{alice}, 0},
+		{"owned workspaces", "SELECT COUNT(*) FROM workspaces WHERE owner_uuid = $1", []any{alice}, 0},
+		{"workspace_members", "SELECT COUNT(*) FROM workspace_members WHERE user_uuid = $1", []any{alice}, 0},
+		{"other user's drops", "SELECT COUNT(*) FROM drops WHERE user_uuid = $1", []any{bob}, 1},
+		{"other user's tag links", "SELECT COUNT(*) FROM drops_item_tags WHERE drops_id = $1", []any{bobsDrop.ID}, 1},
+		{"other user's workspace", "SELECT COUNT(*) FROM workspaces WHERE id = $1", []any{bobsWorkspace.ID}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.table, func(t *testing.T) {
+			if got := count(tt.query, tt.args...); got != tt.want {
+				t.Errorf("%s: %d rows left, want %d", tt.table, got, tt.want)
+			}
+		})
+	}
+}
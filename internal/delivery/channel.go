@@ -0,0 +1,47 @@
+package delivery
+
+// Delivery channels. A drop's channel is one of these or Default, which defers to the
+// user's preferred channel.
+const (
+	Email   = "email"
+	Slack   = "slack"
+	Both    = "both"
+	Default = "default"
+)
+
+// Channels lists the channels a user can prefer, in display order.
+var Channels = []string{Email, Slack, Both}
+
+// IsValid reports whether channel is a known channel. Default is only valid on drops.
+func IsValid(channel string, allowDefault bool) bool {
+	switch channel {
+	case Email, Slack, Both:
+		return true
+	case Default:
+		return allowDefault
+	default:
+		return false
+	}
+}
+
+// Resolve returns the channel a drop is delivered on: the drop's own channel unless it is
+// Default (or unset), in which case the user's preferred channel, falling back to Email.
+func Resolve(dropChannel, preferred string) string {
+	if dropChannel != "" && dropChannel != Default {
+		return dropChannel
+	}
+	if IsValid(preferred, false) {
+		return preferred
+	}
+	return Email
+}
+
+// IncludesEmail reports whether a resolved channel delivers by email.
+func IncludesEmail(channel string) bool {
+	return channel == Email || channel == Both
+}
+
+// IncludesSlack reports whether a resolved channel delivers to Slack.
+func IncludesSlack(channel string) bool {
+	return channel == Slack || channel == Both
+}
@@ -0,0 +1,49 @@
+package delivery
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/nouvadev/dropwise/internal/safehttp"
+)
+
+// SlackSender posts messages to Slack incoming webhooks.
+type SlackSender struct {
+	client *http.Client
+}
+
+// NewSlackSender creates a SlackSender. The client should refuse internal addresses
+// (see safehttp.NewClient) since webhook URLs are user-supplied.
+func NewSlackSender(client *http.Client) *SlackSender {
+	return &SlackSender{client: client}
+}
+
+// Send posts text, in Slack's mrkdwn format, to the incoming webhook at webhookURL.
+func (s *SlackSender) Send(ctx context.Context, webhookURL, text string) error {
+	body, err := json.Marshal(map[string]string{"text": text})
+	if err != nil {
+		return fmt.Errorf("failed to encode slack message: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to build request: %w", err)
+	}
+	if err := safehttp.CheckScheme(req.URL); err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "dropwise-api slack")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call slack webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("slack webhook returned status code %d", resp.StatusCode)
+	}
+	return nil
+}
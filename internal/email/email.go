@@ -0,0 +1,180 @@
+package email
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"log"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Message is an email to a single recipient. Body is the plain-text version; when HTML
+// is set too, the email is sent with both as alternatives.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+	HTML    string
+}
+
+// Sender delivers email.
+type Sender interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// SMTPConfig holds the settings of the outgoing mail server.
+// Sending is simulated (see NoopSender) when Host is empty.
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+}
+
+// Configured reports whether an SMTP server is set, so emails are actually sent.
+func (c SMTPConfig) Configured() bool {
+	return c.Host != ""
+}
+
+// NewSender returns an SMTP sender for cfg, or a NoopSender when no SMTP host is configured.
+func NewSender(cfg SMTPConfig) Sender {
+	if !cfg.Configured() {
+		return NoopSender{}
+	}
+	return &SMTPSender{cfg: cfg}
+}
+
+// SMTPSender sends email through an SMTP server, using STARTTLS when the server offers it.
+type SMTPSender struct {
+	cfg SMTPConfig
+}
+
+// Send implements Sender. SMTP errors are returned as *textproto.Error so callers can inspect the reply code.
+func (s *SMTPSender) Send(ctx context.Context, msg Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	var auth smtp.Auth
+	if s.cfg.Username != "" {
+		auth = smtp.PlainAuth("", s.cfg.Username, s.cfg.Password, s.cfg.Host)
+	}
+	addr := net.JoinHostPort(s.cfg.Host, strconv.Itoa(s.cfg.Port))
+	if err := smtp.SendMail(addr, auth, s.cfg.From, []string{msg.To}, s.format(msg)); err != nil {
+		return fmt.Errorf("failed to send email to %s: %w", msg.To, err)
+	}
+	return nil
+}
+
+// Self-test modes (EMAIL_SELF_TEST): whether the worker checks the SMTP server with
+// VerifySMTP when it starts, and whether a failed check stops it or is only logged.
+const (
+	SelfTestOff  = "off"
+	SelfTestWarn = "warn"
+	SelfTestFail = "fail"
+)
+
+// VerifySMTP checks that the SMTP server in cfg is reachable and accepts the configured
+// credentials, without sending anything: it greets the server, switches to TLS when the
+// server offers STARTTLS (as Send does), authenticates when a username is set, and ends
+// with NOOP and QUIT. The deadline of ctx bounds the whole exchange.
+func VerifySMTP(ctx context.Context, cfg SMTPConfig) error {
+	addr := net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port))
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to connect to SMTP server %s: %w", addr, err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	c, err := smtp.NewClient(conn, cfg.Host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("SMTP server %s did not greet: %w", addr, err)
+	}
+	defer c.Close()
+
+	if err := c.Hello("localhost"); err != nil {
+		return fmt.Errorf("SMTP server %s rejected EHLO: %w", addr, err)
+	}
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(&tls.Config{ServerName: cfg.Host}); err != nil {
+			return fmt.Errorf("STARTTLS with SMTP server %s failed: %w", addr, err)
+		}
+	}
+	if cfg.Username != "" {
+		if ok, _ := c.Extension("AUTH"); !ok {
+			return fmt.Errorf("SMTP server %s does not support authentication, but a username is configured", addr)
+		}
+		if err := c.Auth(smtp.PlainAuth("", cfg.Username, cfg.Password, cfg.Host)); err != nil {
+			return fmt.Errorf("SMTP authentication as %s failed: %w", cfg.Username, err)
+		}
+	}
+	if err := c.Noop(); err != nil {
+		return fmt.Errorf("SMTP server %s rejected NOOP: %w", addr, err)
+	}
+	return c.Quit()
+}
+
+// format renders msg as an RFC 5322 message.
+func (s *SMTPSender) format(msg Message) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", s.cfg.From)
+	fmt.Fprintf(&b, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&b, "Subject: %s\r\n", stripNewlines(msg.Subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().UTC().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	if msg.HTML == "" {
+		b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+		b.WriteString(crlf(msg.Body))
+		return []byte(b.String())
+	}
+
+	// Both versions are quoted-printable so long HTML lines stay within SMTP's line limit
+	mw := multipart.NewWriter(&b)
+	fmt.Fprintf(&b, "Content-Type: multipart/alternative; boundary=%s\r\n\r\n", mw.Boundary())
+	for _, part := range []struct{ contentType, body string }{
+		{"text/plain; charset=UTF-8", msg.Body},
+		{"text/html; charset=UTF-8", msg.HTML},
+	} {
+		pw, _ := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		qw := quotedprintable.NewWriter(pw)
+		qw.Write([]byte(crlf(part.body)))
+		qw.Close()
+	}
+	mw.Close()
+	return []byte(b.String())
+}
+
+// crlf converts line endings to the CRLF SMTP requires.
+func crlf(s string) string {
+	return strings.ReplaceAll(s, "\n", "\r\n")
+}
+
+// stripNewlines keeps user-controlled text from injecting extra headers.
+func stripNewlines(s string) string {
+	return strings.NewReplacer("\r", " ", "\n", " ").Replace(s)
+}
+
+// NoopSender only logs the messages it is given and reports them as sent, so drops still
+// advance their schedule. It is used when no SMTP server is configured.
+type NoopSender struct{}
+
+// Send implements Sender.
+func (NoopSender) Send(ctx context.Context, msg Message) error {
+	log.Printf("Email: SMTP is not configured, would send email to %s with subject '%s'", msg.To, msg.Subject)
+	return ctx.Err()
+}
@@ -0,0 +1,124 @@
+package email
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	texttemplate "text/template"
+)
+
+// Names of the reminder templates, both in the embedded defaults and in EMAIL_TEMPLATE_DIR.
+const (
+	reminderSubjectTemplate = "reminder_subject.tmpl"
+	reminderTextTemplate    = "reminder.txt.tmpl"
+	reminderHTMLTemplate    = "reminder.html.tmpl"
+)
+
+//go:embed templates/*.tmpl
+var defaultTemplates embed.FS
+
+// ReminderData is what the reminder templates are executed with.
+type ReminderData struct {
+	Drop    ReminderDrop
+	BaseURL string // APP_BASE_URL, empty when unset
+}
+
+// ReminderDrop holds the drop fields available to the reminder templates.
+// Fields the drop doesn't have are empty.
+type ReminderDrop struct {
+	ID        string
+	Topic     string
+	URL       string
+	Excerpt   string
+	UserNotes string
+	Priority  int32
+	SendCount int32 // Sends before this one
+
+	// UserNotesHTML is UserNotes rendered from markdown and sanitized, for HTML templates.
+	UserNotesHTML htmltemplate.HTML
+}
+
+// Templates renders the reminder emails sent for due drops.
+type Templates struct {
+	subject *texttemplate.Template
+	text    *texttemplate.Template
+	html    *htmltemplate.Template
+}
+
+// LoadTemplates parses the reminder templates. Each template is read from dir when the file
+// exists there and otherwise comes from the embedded defaults; an empty dir uses only the
+// defaults. Every template is also rendered once with sample data, so a template referring
+// to a field that doesn't exist fails here rather than when the first reminder is sent.
+func LoadTemplates(dir string) (*Templates, error) {
+	read := func(name string) (string, error) {
+		if dir != "" {
+			content, err := os.ReadFile(filepath.Join(dir, name))
+			if err == nil {
+				return string(content), nil
+			}
+			if !errors.Is(err, fs.ErrNotExist) {
+				return "", err
+			}
+		}
+		content, err := defaultTemplates.ReadFile("templates/" + name)
+		return string(content), err
+	}
+
+	var t Templates
+	for _, name := range []string{reminderSubjectTemplate, reminderTextTemplate, reminderHTMLTemplate} {
+		content, err := read(name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read email template %s: %w", name, err)
+		}
+		switch name {
+		case reminderSubjectTemplate:
+			t.subject, err = texttemplate.New(name).Option("missingkey=error").Parse(content)
+		case reminderTextTemplate:
+			t.text, err = texttemplate.New(name).Option("missingkey=error").Parse(content)
+		case reminderHTMLTemplate:
+			t.html, err = htmltemplate.New(name).Option("missingkey=error").Parse(content)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid email template %s: %w", name, err)
+		}
+	}
+
+	sample := ReminderData{
+		Drop: ReminderDrop{
+			ID: "00000000-0000-0000-0000-000000000000", Topic: "Sample", URL: "https://example.com",
+			Excerpt: "Sample excerpt", UserNotes: "Sample notes", Priority: 1, SendCount: 1,
+			UserNotesHTML: "<p>Sample notes</p>",
+		},
+		BaseURL: "https://example.com",
+	}
+	if _, err := t.Reminder("user@example.com", sample); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// Reminder renders the reminder email for a drop.
+func (t *Templates) Reminder(to string, data ReminderData) (Message, error) {
+	var subject, text, html bytes.Buffer
+	if err := t.subject.Execute(&subject, data); err != nil {
+		return Message{}, fmt.Errorf("failed to render email template %s: %w", reminderSubjectTemplate, err)
+	}
+	if err := t.text.Execute(&text, data); err != nil {
+		return Message{}, fmt.Errorf("failed to render email template %s: %w", reminderTextTemplate, err)
+	}
+	if err := t.html.Execute(&html, data); err != nil {
+		return Message{}, fmt.Errorf("failed to render email template %s: %w", reminderHTMLTemplate, err)
+	}
+	return Message{
+		To:      to,
+		Subject: strings.TrimSpace(subject.String()),
+		Body:    text.String(),
+		HTML:    html.String(),
+	}, nil
+}
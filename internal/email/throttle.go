@@ -0,0 +1,128 @@
+package email
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/textproto"
+	"sync"
+	"time"
+)
+
+// ThrottleConfig paces deliveries so the mail provider's rate limits are not tripped.
+type ThrottleConfig struct {
+	RatePerSecond int           // Maximum sends started per second, 0 for no limit
+	Concurrency   int           // Maximum sends in flight at once
+	MaxRetries    int           // Retries of a send rejected with a rate-limit reply
+	Backoff       time.Duration // Pause after the first rate-limit reply, doubled on each retry
+}
+
+// DefaultThrottleConfig returns the configuration used when nothing is configured.
+func DefaultThrottleConfig() ThrottleConfig {
+	return ThrottleConfig{
+		RatePerSecond: 10,
+		Concurrency:   4,
+		MaxRetries:    3,
+		Backoff:       2 * time.Second,
+	}
+}
+
+// ThrottledSender wraps a Sender with a send rate limit and bounded concurrency.
+// When the provider answers with a transient 4xx reply (see IsRateLimited) every
+// send through the ThrottledSender pauses for the backoff before the message is retried.
+type ThrottledSender struct {
+	next  Sender
+	cfg   ThrottleConfig
+	slots chan struct{}
+
+	mu          sync.Mutex
+	nextSlot    time.Time // Earliest start of the next send under the rate limit
+	pausedUntil time.Time // Set after a rate-limit reply
+}
+
+// NewThrottledSender creates a ThrottledSender delivering through next.
+func NewThrottledSender(next Sender, cfg ThrottleConfig) *ThrottledSender {
+	if cfg.Concurrency < 1 {
+		cfg.Concurrency = 1
+	}
+	return &ThrottledSender{next: next, cfg: cfg, slots: make(chan struct{}, cfg.Concurrency)}
+}
+
+// Send implements Sender. It blocks until the message may be sent under the configured limits.
+func (t *ThrottledSender) Send(ctx context.Context, msg Message) error {
+	select {
+	case t.slots <- struct{}{}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	defer func() { <-t.slots }()
+
+	backoff := t.cfg.Backoff
+	for attempt := 0; ; attempt++ {
+		if err := sleepCtx(ctx, t.reserve()); err != nil {
+			return err
+		}
+
+		err := t.next.Send(ctx, msg)
+		if err == nil || !IsRateLimited(err) || attempt >= t.cfg.MaxRetries {
+			return err
+		}
+
+		log.Printf("Email: Provider rate limited the send to %s, backing off for %v: %v", msg.To, backoff, err)
+		t.pause(backoff)
+		backoff *= 2
+	}
+}
+
+// reserve claims the next send slot and returns how long to wait for it.
+func (t *ThrottledSender) reserve() time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+	start := now
+	if t.pausedUntil.After(start) {
+		start = t.pausedUntil
+	}
+	if t.cfg.RatePerSecond > 0 {
+		if t.nextSlot.After(start) {
+			start = t.nextSlot
+		}
+		t.nextSlot = start.Add(time.Second / time.Duration(t.cfg.RatePerSecond))
+	}
+	return start.Sub(now)
+}
+
+// pause holds back every send for d.
+func (t *ThrottledSender) pause(d time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if until := time.Now().Add(d); until.After(t.pausedUntil) {
+		t.pausedUntil = until
+	}
+}
+
+// IsRateLimited reports whether err is an SMTP reply providers use for throttling:
+// 421 (service not available, try later) or 450-452 (temporary failure).
+func IsRateLimited(err error) bool {
+	var smtpErr *textproto.Error
+	if !errors.As(err, &smtpErr) {
+		return false
+	}
+	return smtpErr.Code == 421 || (smtpErr.Code >= 450 && smtpErr.Code <= 452)
+}
+
+// sleepCtx waits for d or until ctx is done.
+func sleepCtx(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,107 @@
+package events
+
+import (
+	"database/sql"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Event types published when a user's drops change.
+const (
+	DropCreated = "drop.created"
+	DropUpdated = "drop.updated"
+	DropDeleted = "drop.deleted"
+	DropSent    = "drop.sent"
+)
+
+// subscriberBuffer is how many events a slow subscriber may fall behind before events are dropped.
+const subscriberBuffer = 16
+
+// Event describes a change to one of a user's drops.
+// It only identifies the drop; clients fetch it again to see the new state.
+type Event struct {
+	Type        string        `json:"type"`
+	DropID      uuid.UUID     `json:"drop_id"`
+	WorkspaceID uuid.NullUUID `json:"-"`
+	OccurredAt  time.Time     `json:"occurred_at"`
+}
+
+// Broker is a pub/sub of drop events keyed by user ID.
+// A Broker from NewBroker only reaches subscribers in the same process. One from
+// NewPostgresBroker publishes through Postgres NOTIFY, so events from a worker running
+// as a separate process reach the clients connected to the API server that calls Listen.
+type Broker struct {
+	mu   sync.Mutex
+	subs map[uuid.UUID]map[chan Event]struct{}
+
+	// conn is set for a Postgres-backed Broker
+	conn *sql.DB
+}
+
+// NewBroker creates an empty in-process Broker.
+func NewBroker() *Broker {
+	return &Broker{subs: make(map[uuid.UUID]map[chan Event]struct{})}
+}
+
+// Subscribe registers a subscriber for a user's events.
+// The returned function unsubscribes and closes the channel; it must be called once the
+// subscriber is done.
+func (b *Broker) Subscribe(userID uuid.UUID) (<-chan Event, func()) {
+	ch := make(chan Event, subscriberBuffer)
+
+	b.mu.Lock()
+	if b.subs[userID] == nil {
+		b.subs[userID] = make(map[chan Event]struct{})
+	}
+	b.subs[userID][ch] = struct{}{}
+	b.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			b.mu.Lock()
+			delete(b.subs[userID], ch)
+			if len(b.subs[userID]) == 0 {
+				delete(b.subs, userID)
+			}
+			b.mu.Unlock()
+			close(ch)
+		})
+	}
+}
+
+// Publish delivers an event to every subscriber of the user without blocking.
+// Subscribers whose buffer is full miss the event. A nil Broker discards it.
+// A Postgres-backed Broker sends it through NOTIFY instead, and falls back to
+// delivering it in-process if that fails.
+func (b *Broker) Publish(userID uuid.UUID, event Event) {
+	if b == nil {
+		return
+	}
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now().UTC()
+	}
+	if b.conn != nil {
+		err := b.notify(userID, event)
+		if err == nil {
+			return
+		}
+		log.Printf("Warning: could not publish %s event for drop %s through Postgres, delivering it in-process only: %v", event.Type, event.DropID, err)
+	}
+	b.deliver(userID, event)
+}
+
+// deliver hands an event to the user's subscribers in this process.
+func (b *Broker) deliver(userID uuid.UUID, event Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for ch := range b.subs[userID] {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package events
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nouvadev/dropwise/internal/testdb"
+)
+
+func TestBrokerDeliversToTheUsersSubscribers(t *testing.T) {
+	b := NewBroker()
+	alice, bob := uuid.New(), uuid.New()
+	aliceEvents, unsubscribe := b.Subscribe(alice)
+	defer unsubscribe()
+	bobEvents, unsubscribeBob := b.Subscribe(bob)
+	defer unsubscribeBob()
+
+	dropID := uuid.New()
+	b.Publish(alice, Event{Type: DropCreated, DropID: dropID})
+
+	select {
+	case ev := <-aliceEvents:
+		if ev.DropID != dropID || ev.Type != DropCreated || ev.OccurredAt.IsZero() {
+			t.Errorf("got %+v, want a %s event for drop %s with a timestamp", ev, DropCreated, dropID)
+		}
+	default:
+		t.Fatal("subscriber got no event")
+	}
+	select {
+	case ev := <-bobEvents:
+		t.Errorf("another user's subscriber got %+v", ev)
+	default:
+	}
+}
+
+func TestPostgresBrokerReachesOtherProcesses(t *testing.T) {
+	database := testdb.New(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// api stands in for the API server, worker for a worker running as its own process
+	api := NewPostgresBroker(database.Conn)
+	if err := api.Listen(ctx, database.URL); err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	worker := NewPostgresBroker(database.Conn)
+
+	userID := uuid.New()
+	stream, unsubscribe := api.Subscribe(userID)
+	defer unsubscribe()
+
+	want := Event{
+		Type:        DropSent,
+		DropID:      uuid.New(),
+		WorkspaceID: uuid.NullUUID{UUID: uuid.New(), Valid: true},
+		OccurredAt:  time.Date(2026, 5, 1, 9, 0, 0, 0, time.UTC),
+	}
+	// The listener connects in the background, so publish until it starts receiving
+	deadline := time.After(10 * time.Second)
+	for {
+		worker.Publish(userID, want)
+		select {
+		case got := <-stream:
+			if got.Type != want.Type || got.DropID != want.DropID || got.WorkspaceID != want.WorkspaceID || !got.OccurredAt.Equal(want.OccurredAt) {
+				t.Errorf("got %+v, want %+v", got, want)
+			}
+			return
+		case <-time.After(200 * time.Millisecond):
+		case <-deadline:
+			t.Fatal("event published by another broker never arrived")
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package events
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const (
+	// pgChannel is the Postgres NOTIFY channel drop events travel on.
+	pgChannel = "drop_events"
+	// pgNotifyTimeout bounds how long Publish waits on Postgres.
+	pgNotifyTimeout = 2 * time.Second
+	// pgPingInterval is how often an idle listener checks its connection is still alive.
+	pgPingInterval = 90 * time.Second
+)
+
+// pgMessage is the NOTIFY payload for an event. Event leaves WorkspaceID out of its JSON,
+// so it is carried separately.
+type pgMessage struct {
+	UserID      uuid.UUID     `json:"user_id"`
+	Event       Event         `json:"event"`
+	WorkspaceID uuid.NullUUID `json:"workspace_id"`
+}
+
+// NewPostgresBroker creates a Broker that publishes events through Postgres NOTIFY on conn,
+// so every process sharing the database can pick them up with Listen.
+func NewPostgresBroker(conn *sql.DB) *Broker {
+	b := NewBroker()
+	b.conn = conn
+	return b
+}
+
+// notify sends an event to every listening process.
+func (b *Broker) notify(userID uuid.UUID, event Event) error {
+	payload, err := json.Marshal(pgMessage{UserID: userID, Event: event, WorkspaceID: event.WorkspaceID})
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), pgNotifyTimeout)
+	defer cancel()
+	_, err = b.conn.ExecContext(ctx, "SELECT pg_notify($1, $2)", pgChannel, string(payload))
+	return err
+}
+
+// Listen delivers events published by any process on the database at dsn to this process's
+// subscribers until ctx is done. It reconnects on its own after losing the connection;
+// events published while it is disconnected are lost.
+func (b *Broker) Listen(ctx context.Context, dsn string) error {
+	listener := pq.NewListener(dsn, time.Second, time.Minute, func(ev pq.ListenerEventType, err error) {
+		if err != nil {
+			log.Printf("Warning: drop events listener: %v", err)
+		}
+	})
+	if err := listener.Listen(pgChannel); err != nil {
+		listener.Close()
+		return err
+	}
+
+	go func() {
+		defer listener.Close()
+		ping := time.NewTicker(pgPingInterval)
+		defer ping.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case n := <-listener.Notify:
+				if n == nil {
+					// The connection was re-established
+					continue
+				}
+				var msg pgMessage
+				if err := json.Unmarshal([]byte(n.Extra), &msg); err != nil {
+					log.Printf("Warning: ignoring malformed drop event: %v", err)
+					continue
+				}
+				msg.Event.WorkspaceID = msg.WorkspaceID
+				b.deliver(msg.UserID, msg.Event)
+			case <-ping.C:
+				go listener.Ping()
+			}
+		}
+	}()
+	return nil
+}
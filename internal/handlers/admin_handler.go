@@ -0,0 +1,254 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nouvadev/dropwise/internal/audit"
+	"github.com/nouvadev/dropwise/internal/config"
+	db "github.com/nouvadev/dropwise/internal/database/sqlc"
+	"github.com/nouvadev/dropwise/internal/middleware"
+	"github.com/nouvadev/dropwise/internal/server/httputils"
+)
+
+// AdminHandler handles HTTP requests for operator-only endpoints.
+type AdminHandler struct {
+	APIConfig *config.APIConfig
+}
+
+// NewAdminHandler creates a new AdminHandler.
+func NewAdminHandler(apiCfg *config.APIConfig) *AdminHandler {
+	return &AdminHandler{APIConfig: apiCfg}
+}
+
+const (
+	defaultAuditLogPageSize = 50
+	maxAuditLogPageSize     = 500
+
+	defaultWorkerRunsPageSize = 50
+	maxWorkerRunsPageSize     = 500
+)
+
+// AuditLogEntryResponse defines the structure for audit log entries.
+type AuditLogEntryResponse struct {
+	ID         int64           `json:"id"`
+	OccurredAt time.Time       `json:"occurred_at"`
+	ActorID    *uuid.UUID      `json:"actor_id"`
+	Action     string          `json:"action"`
+	Target     *string         `json:"target"`
+	SourceIP   *string         `json:"source_ip"`
+	Metadata   json.RawMessage `json:"metadata"`
+}
+
+// WorkerRunResponse defines the structure for worker run history entries.
+type WorkerRunResponse struct {
+	ID             int64     `json:"id"`
+	StartedAt      time.Time `json:"started_at"`
+	DurationMs     int64     `json:"duration_ms"`
+	ProcessedCount int32     `json:"processed_count"`
+	FailedCount    int32     `json:"failed_count"`
+	RemainingCount int32     `json:"remaining_count"` // Due drops left after the run
+	DailyDropCount int32     `json:"daily_drop_count"`
+	Degraded       bool      `json:"degraded"`
+	Error          *string   `json:"error"` // Set when the run stopped on a critical error
+}
+
+// MaintenanceRequest defines the expected request body for toggling maintenance mode.
+type MaintenanceRequest struct {
+	Enabled *bool `json:"enabled"`
+}
+
+// MaintenanceResponse reports the current maintenance mode.
+type MaintenanceResponse struct {
+	Enabled           bool `json:"enabled"`
+	RetryAfterSeconds int  `json:"retry_after_seconds"`
+}
+
+// GetMaintenanceHandler reports whether maintenance mode is on.
+// GET /api/v1/admin/maintenance
+func (h *AdminHandler) GetMaintenanceHandler(w http.ResponseWriter, r *http.Request) {
+	httputils.RespondWithJSON(w, http.StatusOK, h.maintenanceResponse())
+}
+
+// SetMaintenanceHandler turns maintenance mode on or off without a restart.
+// PUT /api/v1/admin/maintenance
+func (h *AdminHandler) SetMaintenanceHandler(w http.ResponseWriter, r *http.Request) {
+	userUUID, ok := r.Context().Value(middleware.UserIDKey).(uuid.UUID)
+	if !ok {
+		httputils.RespondWithError(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	var req MaintenanceRequest
+	if !httputils.DecodeJSONBody(w, r, &req) {
+		return
+	}
+	if req.Enabled == nil {
+		httputils.RespondWithError(w, http.StatusBadRequest, "enabled is required")
+		return
+	}
+
+	changed, err := h.APIConfig.Maintenance.Set(r.Context(), *req.Enabled, "admin "+userUUID.String())
+	if err != nil {
+		log.Printf("Error setting maintenance mode: %v", err)
+		httputils.RespondWithServerError(w, err, "Could not update maintenance mode")
+		return
+	}
+	if changed {
+		h.APIConfig.Audit.Record(r.Context(), audit.ActionMaintenanceToggled, userUUID, audit.Metadata{
+			Details: map[string]any{"enabled": *req.Enabled},
+		})
+	}
+	httputils.RespondWithJSON(w, http.StatusOK, h.maintenanceResponse())
+}
+
+func (h *AdminHandler) maintenanceResponse() MaintenanceResponse {
+	return MaintenanceResponse{
+		Enabled:           h.APIConfig.Maintenance.Enabled(),
+		RetryAfterSeconds: int(h.APIConfig.MaintenanceRetryAfter.Seconds()),
+	}
+}
+
+// ListAuditLogHandler lists audit log entries, newest first.
+// GET /api/v1/admin/audit-log?actor_id=&action=&since=&until=&limit=&offset=
+// since and until are RFC 3339 timestamps; since is inclusive and until exclusive.
+func (h *AdminHandler) ListAuditLogHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filters := db.CountAuditLogEntriesParams{}
+
+	if v := query.Get("actor_id"); v != "" {
+		actorID, err := uuid.Parse(v)
+		if err != nil {
+			httputils.RespondWithError(w, http.StatusBadRequest, "Invalid actor_id format: "+err.Error())
+			return
+		}
+		filters.ActorUuid = uuid.NullUUID{UUID: actorID, Valid: true}
+	}
+	if v := query.Get("action"); v != "" {
+		filters.Action = sql.NullString{String: v, Valid: true}
+	}
+	for _, bound := range []struct {
+		name string
+		dst  *sql.NullTime
+	}{{"since", &filters.Since}, {"until", &filters.Until}} {
+		v := query.Get(bound.name)
+		if v == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			httputils.RespondWithError(w, http.StatusBadRequest, "Invalid "+bound.name+" value, expected an RFC 3339 timestamp")
+			return
+		}
+		*bound.dst = sql.NullTime{Time: t, Valid: true}
+	}
+
+	limit, offset, err := httputils.ParsePagination(r, defaultAuditLogPageSize, maxAuditLogPageSize)
+	if err != nil {
+		httputils.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	entries, err := h.APIConfig.DB.ListAuditLogEntries(r.Context(), db.ListAuditLogEntriesParams{
+		ActorUuid: filters.ActorUuid,
+		Action:    filters.Action,
+		Since:     filters.Since,
+		Until:     filters.Until,
+		Limit:     int32(limit),
+		Offset:    int32(offset),
+	})
+	if err != nil {
+		log.Printf("Error fetching audit log entries: %v", err)
+		httputils.RespondWithServerError(w, err, "Failed to fetch audit log: "+err.Error())
+		return
+	}
+
+	total, err := h.APIConfig.DB.CountAuditLogEntries(r.Context(), filters)
+	if err != nil {
+		log.Printf("Error counting audit log entries: %v", err)
+		httputils.RespondWithServerError(w, err, "Failed to fetch audit log: "+err.Error())
+		return
+	}
+
+	responses := make([]AuditLogEntryResponse, 0, len(entries))
+	for _, entry := range entries {
+		responses = append(responses, toAuditLogEntryResponse(entry))
+	}
+	httputils.RespondWithJSON(w, http.StatusOK, httputils.PaginatedResponse{
+		Data:       responses,
+		Pagination: httputils.Pagination{Limit: limit, Offset: offset, Total: total},
+	})
+}
+
+func toAuditLogEntryResponse(entry db.AuditLog) AuditLogEntryResponse {
+	resp := AuditLogEntryResponse{
+		ID:         entry.ID,
+		OccurredAt: entry.OccurredAt.UTC(),
+		Action:     entry.Action,
+		Metadata:   entry.Metadata,
+	}
+	if entry.ActorUuid.Valid {
+		resp.ActorID = &entry.ActorUuid.UUID
+	}
+	if entry.Target.Valid {
+		resp.Target = &entry.Target.String
+	}
+	if entry.SourceIp.Valid {
+		resp.SourceIP = &entry.SourceIp.String
+	}
+	return resp
+}
+
+// ListWorkerRunsHandler lists the worker's runs, newest first, to tell whether and how it
+// has been running.
+// GET /api/v1/admin/worker-runs?limit=&offset=
+func (h *AdminHandler) ListWorkerRunsHandler(w http.ResponseWriter, r *http.Request) {
+	limit, offset, err := httputils.ParsePagination(r, defaultWorkerRunsPageSize, maxWorkerRunsPageSize)
+	if err != nil {
+		httputils.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	runs, err := h.APIConfig.DB.ListWorkerRuns(r.Context(), db.ListWorkerRunsParams{
+		Limit:  int32(limit),
+		Offset: int32(offset),
+	})
+	if err != nil {
+		log.Printf("Error fetching worker runs: %v", err)
+		httputils.RespondWithServerError(w, err, "Failed to fetch worker runs: "+err.Error())
+		return
+	}
+
+	total, err := h.APIConfig.DB.CountWorkerRuns(r.Context())
+	if err != nil {
+		log.Printf("Error counting worker runs: %v", err)
+		httputils.RespondWithServerError(w, err, "Failed to fetch worker runs: "+err.Error())
+		return
+	}
+
+	responses := make([]WorkerRunResponse, 0, len(runs))
+	for _, run := range runs {
+		resp := WorkerRunResponse{
+			ID:             run.ID,
+			StartedAt:      run.StartedAt.UTC(),
+			DurationMs:     run.DurationMs,
+			ProcessedCount: run.ProcessedCount,
+			FailedCount:    run.FailedCount,
+			RemainingCount: run.RemainingCount,
+			DailyDropCount: run.DailyDropCount,
+			Degraded:       run.Degraded,
+		}
+		if run.Error.Valid {
+			resp.Error = &run.Error.String
+		}
+		responses = append(responses, resp)
+	}
+	httputils.RespondWithJSON(w, http.StatusOK, httputils.PaginatedResponse{
+		Data:       responses,
+		Pagination: httputils.Pagination{Limit: limit, Offset: offset, Total: total},
+	})
+}
@@ -2,7 +2,6 @@ package handlers
 
 import (
 	"database/sql"
-	"encoding/json"
 	"log"
 	"net/http"
 	"strings"
@@ -10,6 +9,7 @@ import (
 	"unicode/utf8" // For more robust validation if needed
 
 	"github.com/google/uuid"
+	"github.com/nouvadev/dropwise/internal/audit"
 	"github.com/nouvadev/dropwise/internal/auth"
 	"github.com/nouvadev/dropwise/internal/config"
 	db "github.com/nouvadev/dropwise/internal/database/sqlc"
@@ -63,8 +63,8 @@ func toUserResponseFromCreate(dbUser db.CreateUserRow) UserResponse {
 	return UserResponse{
 		ID:        dbUser.ID,
 		Email:     dbUser.Email,
-		CreatedAt: dbUser.CreatedAt,
-		UpdatedAt: dbUser.UpdatedAt,
+		CreatedAt: dbUser.CreatedAt.UTC(),
+		UpdatedAt: dbUser.UpdatedAt.UTC(),
 	}
 }
 
@@ -79,8 +79,7 @@ func (h *AuthHandler) SignupHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var req SignupUserRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		httputils.RespondWithError(w, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+	if !httputils.DecodeJSONBody(w, r, &req) {
 		return
 	}
 	defer r.Body.Close()
@@ -93,6 +92,11 @@ func (h *AuthHandler) SignupHandler(w http.ResponseWriter, r *http.Request) {
 		httputils.RespondWithError(w, http.StatusBadRequest, "Valid email is required")
 		return
 	}
+	if !h.APIConfig.EmailDomainAllowed(req.Email) {
+		log.Printf("Registration rejected: email domain of %s is not in ALLOWED_EMAIL_DOMAINS", req.Email)
+		httputils.RespondWithError(w, http.StatusForbidden, "Registration is restricted to approved email domains")
+		return
+	}
 	if utf8.RuneCountInString(req.Password) < 8 { // Example: minimum 8 characters
 		httputils.RespondWithError(w, http.StatusBadRequest, "Password must be at least 8 characters long")
 		return
@@ -111,13 +115,13 @@ func (h *AuthHandler) SignupHandler(w http.ResponseWriter, r *http.Request) {
 	if err != sql.ErrNoRows {
 		// An actual database error occurred
 		log.Printf("Error checking for existing user %s: %v", req.Email, err)
-		httputils.RespondWithError(w, http.StatusInternalServerError, "Database error while checking user existence")
+		httputils.RespondWithServerError(w, err, "Database error while checking user existence")
 		return
 	}
 	// sql.ErrNoRows means user does not exist, which is what we want.
 
 	// Hash the password
-	hashedPassword, err := auth.HashPassword(req.Password)
+	hashedPassword, err := h.APIConfig.PasswordHasher.Hash(req.Password)
 	if err != nil {
 		log.Printf("Error hashing password for %s: %v", req.Email, err)
 		httputils.RespondWithError(w, http.StatusInternalServerError, "Failed to process password")
@@ -136,11 +140,14 @@ func (h *AuthHandler) SignupHandler(w http.ResponseWriter, r *http.Request) {
 		// or other database errors.
 		log.Printf("Error creating user %s in database: %v", req.Email, err)
 		// Consider checking for pq.Error unique_violation if using lib/pq directly for more specific error.
-		httputils.RespondWithError(w, http.StatusInternalServerError, "Failed to create user")
+		httputils.RespondWithServerError(w, err, "Failed to create user")
 		return
 	}
 
 	log.Printf("Successfully signed up user with email: %s, ID: %s", createdUserRow.Email, createdUserRow.ID)
+	h.APIConfig.Audit.Record(r.Context(), audit.ActionSignup, createdUserRow.ID, audit.Metadata{
+		Details: map[string]any{"email": createdUserRow.Email},
+	})
 	response := toUserResponseFromCreate(createdUserRow)
 	httputils.RespondWithJSON(w, http.StatusCreated, response)
 }
@@ -154,8 +161,7 @@ func (h *AuthHandler) LoginHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var req LoginUserRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		httputils.RespondWithError(w, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+	if !httputils.DecodeJSONBody(w, r, &req) {
 		return
 	}
 	defer r.Body.Close()
@@ -184,25 +190,35 @@ func (h *AuthHandler) LoginHandler(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		if err == sql.ErrNoRows {
 			log.Printf("Login failed: user with email %s not found", req.Email)
+			h.APIConfig.Audit.Record(r.Context(), audit.ActionLoginFailed, uuid.Nil, audit.Metadata{
+				Details: map[string]any{"email": req.Email, "reason": "unknown_email"},
+			})
 			httputils.RespondWithError(w, http.StatusUnauthorized, "Invalid email or password")
 			return
 		}
 		log.Printf("Database error fetching user %s for login: %v", req.Email, err)
-		httputils.RespondWithError(w, http.StatusInternalServerError, "Database error during login")
+		httputils.RespondWithServerError(w, err, "Database error during login")
 		return
 	}
 
 	// Verify password
 	if !auth.CheckPasswordHash(req.Password, user.HashedPassword) {
 		log.Printf("Login failed: invalid password for user %s", req.Email)
+		h.APIConfig.Audit.Record(r.Context(), audit.ActionLoginFailed, user.ID, audit.Metadata{
+			Details: map[string]any{"email": req.Email, "reason": "invalid_password"},
+		})
 		httputils.RespondWithError(w, http.StatusUnauthorized, "Invalid email or password")
 		return
 	}
 
+	if h.APIConfig.PasswordRehashOnLogin && auth.NeedsRehash(h.APIConfig.PasswordHasher, user.HashedPassword) {
+		h.rehashPassword(r, user.ID, req.Password)
+	}
+
 	// Login successful, generate JWT
 	log.Printf("User %s (ID: %s) credentials verified. Generating JWT.", user.Email, user.ID)
 
-	tokenString, err := auth.GenerateJWT(user.ID, h.APIConfig.JWTSecret, h.APIConfig.JWTExpiration)
+	tokenString, err := auth.GenerateJWT(user.ID, h.APIConfig.JWTKeys, h.APIConfig.JWTExpiration)
 	if err != nil {
 		log.Printf("Error generating JWT for user %s (ID: %s): %v", user.Email, user.ID, err)
 		httputils.RespondWithError(w, http.StatusInternalServerError, "Failed to generate authentication token")
@@ -210,6 +226,7 @@ func (h *AuthHandler) LoginHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	log.Printf("JWT generated successfully for user %s (ID: %s)", user.Email, user.ID)
+	h.APIConfig.Audit.Record(r.Context(), audit.ActionLogin, user.ID, audit.Metadata{})
 	response := LoginResponse{
 		Token:  tokenString,
 		UserID: user.ID,
@@ -217,3 +234,23 @@ func (h *AuthHandler) LoginHandler(w http.ResponseWriter, r *http.Request) {
 	}
 	httputils.RespondWithJSON(w, http.StatusOK, response)
 }
+
+// rehashPassword replaces a user's stored hash with one from the configured hasher.
+// It runs after a successful login, the only time the plain-text password is known.
+// Failures are only logged since the old hash keeps working.
+func (h *AuthHandler) rehashPassword(r *http.Request, userID uuid.UUID, password string) {
+	newHash, err := h.APIConfig.PasswordHasher.Hash(password)
+	if err != nil {
+		log.Printf("Error re-hashing password for user %s: %v", userID, err)
+		return
+	}
+	err = h.APIConfig.DB.UpdateUserPassword(r.Context(), db.UpdateUserPasswordParams{
+		ID:             userID,
+		HashedPassword: newHash,
+	})
+	if err != nil {
+		log.Printf("Error storing re-hashed password for user %s: %v", userID, err)
+		return
+	}
+	log.Printf("Upgraded password hash for user %s", userID)
+}
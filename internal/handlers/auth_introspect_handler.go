@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nouvadev/dropwise/internal/auth"
+	"github.com/nouvadev/dropwise/internal/server/httputils"
+)
+
+// tokenErrorDescriptions explains each reason a token can be rejected for. The text is fixed
+// so nothing about the keys or the parser leaks into the response.
+var tokenErrorDescriptions = map[string]string{
+	auth.TokenExpired:      "The token has expired",
+	auth.TokenNotYetValid:  "The token is not valid yet",
+	auth.TokenBadSignature: "The token's signature does not verify with this server's key and algorithm",
+	auth.TokenMalformed:    "The token is not a well-formed JWT",
+	auth.TokenInvalid:      "The token is invalid",
+}
+
+// IntrospectTokenRequest defines the optional request body for token introspection.
+type IntrospectTokenRequest struct {
+	Token string `json:"token"`
+}
+
+// IntrospectTokenResponse describes a token, in the spirit of RFC 7662. Active tokens carry
+// their claims; inactive ones carry the reason they were rejected.
+type IntrospectTokenResponse struct {
+	Active           bool       `json:"active"`
+	UserID           *uuid.UUID `json:"user_id,omitempty"`
+	WorkspaceID      *uuid.UUID `json:"workspace_id,omitempty"`
+	Issuer           string     `json:"issuer,omitempty"`
+	IssuedAt         *time.Time `json:"issued_at,omitempty"`
+	ExpiresAt        *time.Time `json:"expires_at,omitempty"`
+	Error            string     `json:"error,omitempty"` // expired, not_yet_valid, bad_signature, malformed or invalid
+	ErrorDescription string     `json:"error_description,omitempty"`
+}
+
+// IntrospectTokenHandler validates a token and returns its claims, or why it is not valid.
+// The token is read from the body's "token" field, or else from the Authorization header.
+// An invalid token is still answered with 200, with active set to false.
+// POST /api/v1/auth/introspect
+func (h *AuthHandler) IntrospectTokenHandler(w http.ResponseWriter, r *http.Request) {
+	var req IntrospectTokenRequest
+	if r.ContentLength != 0 {
+		if !httputils.DecodeJSONBody(w, r, &req) {
+			return
+		}
+		defer r.Body.Close()
+	}
+
+	tokenString := strings.TrimSpace(req.Token)
+	if tokenString == "" {
+		bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || strings.TrimSpace(bearer) == "" {
+			httputils.RespondWithError(w, http.StatusBadRequest, "A token is required, in the request body or as 'Authorization: Bearer TOKEN'")
+			return
+		}
+		tokenString = strings.TrimSpace(bearer)
+	}
+
+	claims, err := auth.ValidateJWT(tokenString, h.APIConfig.JWTKeys)
+	if err != nil {
+		reason := auth.TokenErrorReason(err)
+		httputils.RespondWithJSON(w, http.StatusOK, IntrospectTokenResponse{
+			Error:            reason,
+			ErrorDescription: tokenErrorDescriptions[reason],
+		})
+		return
+	}
+
+	response := IntrospectTokenResponse{
+		Active:      true,
+		UserID:      &claims.UserID,
+		WorkspaceID: claims.WorkspaceID,
+		Issuer:      claims.Issuer,
+	}
+	if claims.IssuedAt != nil {
+		t := claims.IssuedAt.UTC()
+		response.IssuedAt = &t
+	}
+	if claims.ExpiresAt != nil {
+		t := claims.ExpiresAt.UTC()
+		response.ExpiresAt = &t
+	}
+	httputils.RespondWithJSON(w, http.StatusOK, response)
+}
@@ -0,0 +1,393 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nouvadev/dropwise/internal/config"
+	db "github.com/nouvadev/dropwise/internal/database/sqlc"
+	"github.com/nouvadev/dropwise/internal/middleware"
+	"github.com/nouvadev/dropwise/internal/server/httputils"
+)
+
+// CollectionsHandler handles HTTP requests for collections.
+type CollectionsHandler struct {
+	APIConfig *config.APIConfig
+}
+
+// NewCollectionsHandler creates a new CollectionsHandler.
+func NewCollectionsHandler(apiCfg *config.APIConfig) *CollectionsHandler {
+	return &CollectionsHandler{APIConfig: apiCfg}
+}
+
+// CreateCollectionRequest defines the expected request body for creating a collection.
+type CreateCollectionRequest struct {
+	Name string `json:"name"`
+}
+
+// CollectionResponse defines the structure for collection responses.
+type CollectionResponse struct {
+	ID         uuid.UUID `json:"id"`
+	Name       string    `json:"name"`
+	DropCount  int64     `json:"drop_count"`
+	CreatedAt  time.Time `json:"created_at"`
+	PublicSlug *string   `json:"public_slug"` // Set while the collection is published, see PublishCollectionHandler
+}
+
+// CreateCollectionHandler creates a collection in the active workspace.
+// POST /api/v1/collections
+func (h *CollectionsHandler) CreateCollectionHandler(w http.ResponseWriter, r *http.Request) {
+	userUUID, ok := r.Context().Value(middleware.UserIDKey).(uuid.UUID)
+	if !ok {
+		httputils.RespondWithError(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	var req CreateCollectionRequest
+	if !httputils.DecodeJSONBody(w, r, &req) {
+		return
+	}
+	defer r.Body.Close()
+
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		httputils.RespondWithError(w, http.StatusBadRequest, "Collection name cannot be empty")
+		return
+	}
+
+	collection, err := h.APIConfig.DB.CreateCollection(r.Context(), db.CreateCollectionParams{
+		UserUuid:    userUUID,
+		WorkspaceID: middleware.GetWorkspaceIDFromContext(r),
+		Name:        req.Name,
+	})
+	if err != nil {
+		log.Printf("Error creating collection for UserUUID %s: %v", userUUID, err)
+		httputils.RespondWithServerError(w, err, "Failed to create collection: "+err.Error())
+		return
+	}
+
+	log.Printf("Successfully created collection %s for UserUUID: %s", collection.ID, userUUID)
+	httputils.RespondWithJSON(w, http.StatusCreated, CollectionResponse{
+		ID:        collection.ID,
+		Name:      collection.Name,
+		CreatedAt: collection.CreatedAt.UTC(),
+	})
+}
+
+// ListCollectionsHandler lists the user's collections in the active workspace.
+// GET /api/v1/collections
+func (h *CollectionsHandler) ListCollectionsHandler(w http.ResponseWriter, r *http.Request) {
+	userUUID, ok := r.Context().Value(middleware.UserIDKey).(uuid.UUID)
+	if !ok {
+		httputils.RespondWithError(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	collections, err := h.APIConfig.DBRead.ListCollectionsForUser(r.Context(), db.ListCollectionsForUserParams{
+		UserUuid:    userUUID,
+		WorkspaceID: middleware.GetWorkspaceIDFromContext(r),
+	})
+	if err != nil {
+		log.Printf("Error fetching collections for UserUUID %s: %v", userUUID, err)
+		httputils.RespondWithServerError(w, err, "Failed to fetch collections: "+err.Error())
+		return
+	}
+
+	response := make([]CollectionResponse, 0, len(collections))
+	for _, c := range collections {
+		var publicSlug *string
+		if c.PublicSlug.Valid {
+			publicSlug = &c.PublicSlug.String
+		}
+		response = append(response, CollectionResponse{
+			ID:         c.ID,
+			Name:       c.Name,
+			DropCount:  c.DropCount,
+			CreatedAt:  c.CreatedAt.UTC(),
+			PublicSlug: publicSlug,
+		})
+	}
+
+	httputils.RespondWithJSON(w, http.StatusOK, response)
+}
+
+// DeleteCollectionHandler deletes a collection. The drops in it are not deleted.
+// DELETE /api/v1/collections/{id}
+func (h *CollectionsHandler) DeleteCollectionHandler(w http.ResponseWriter, r *http.Request) {
+	userUUID, ok := r.Context().Value(middleware.UserIDKey).(uuid.UUID)
+	if !ok {
+		httputils.RespondWithError(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	collection, ok := h.getOwnedCollection(w, r, userUUID)
+	if !ok {
+		return
+	}
+
+	if _, err := h.APIConfig.DB.DeleteCollection(r.Context(), db.DeleteCollectionParams{
+		ID:       collection.ID,
+		UserUuid: userUUID,
+	}); err != nil {
+		log.Printf("Error deleting collection %s: %v", collection.ID, err)
+		httputils.RespondWithServerError(w, err, "Failed to delete collection: "+err.Error())
+		return
+	}
+
+	log.Printf("Successfully deleted collection %s for UserUUID: %s", collection.ID, userUUID)
+	httputils.RespondWithJSON(w, http.StatusNoContent, nil)
+}
+
+// AddDropToCollectionHandler puts a drop into a collection. Adding it twice is a no-op.
+// POST /api/v1/collections/{id}/drops/{dropId}
+func (h *CollectionsHandler) AddDropToCollectionHandler(w http.ResponseWriter, r *http.Request) {
+	userUUID, ok := r.Context().Value(middleware.UserIDKey).(uuid.UUID)
+	if !ok {
+		httputils.RespondWithError(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	collection, ok := h.getOwnedCollection(w, r, userUUID)
+	if !ok {
+		return
+	}
+	dropID, ok := h.getCollectionDropID(w, r, userUUID, collection)
+	if !ok {
+		return
+	}
+
+	if err := h.APIConfig.DB.AddDropToCollection(r.Context(), db.AddDropToCollectionParams{
+		CollectionID: collection.ID,
+		DropsID:      dropID,
+	}); err != nil {
+		log.Printf("Error adding drop %s to collection %s: %v", dropID, collection.ID, err)
+		httputils.RespondWithServerError(w, err, "Failed to add drop to collection: "+err.Error())
+		return
+	}
+
+	httputils.RespondWithJSON(w, http.StatusNoContent, nil)
+}
+
+// RemoveDropFromCollectionHandler takes a drop out of a collection.
+// DELETE /api/v1/collections/{id}/drops/{dropId}
+func (h *CollectionsHandler) RemoveDropFromCollectionHandler(w http.ResponseWriter, r *http.Request) {
+	userUUID, ok := r.Context().Value(middleware.UserIDKey).(uuid.UUID)
+	if !ok {
+		httputils.RespondWithError(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	collection, ok := h.getOwnedCollection(w, r, userUUID)
+	if !ok {
+		return
+	}
+	dropID, err := uuid.Parse(r.PathValue("dropId"))
+	if err != nil {
+		httputils.RespondWithError(w, http.StatusBadRequest, "Invalid Drop ID format: "+err.Error())
+		return
+	}
+
+	removed, err := h.APIConfig.DB.RemoveDropFromCollection(r.Context(), db.RemoveDropFromCollectionParams{
+		CollectionID: collection.ID,
+		DropsID:      dropID,
+	})
+	if err != nil {
+		log.Printf("Error removing drop %s from collection %s: %v", dropID, collection.ID, err)
+		httputils.RespondWithServerError(w, err, "Failed to remove drop from collection: "+err.Error())
+		return
+	}
+	if removed == 0 {
+		httputils.RespondWithError(w, http.StatusNotFound, "Drop is not in this collection")
+		return
+	}
+
+	httputils.RespondWithJSON(w, http.StatusNoContent, nil)
+}
+
+// getOwnedCollection parses the {id} path value and loads the collection, checking it
+// belongs to userUUID and to the active workspace.
+// On failure it writes the appropriate error response and returns false.
+func (h *CollectionsHandler) getOwnedCollection(w http.ResponseWriter, r *http.Request, userUUID uuid.UUID) (db.Collection, bool) {
+	collectionID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		httputils.RespondWithError(w, http.StatusBadRequest, "Invalid Collection ID format: "+err.Error())
+		return db.Collection{}, false
+	}
+
+	collection, err := h.APIConfig.DB.GetCollection(r.Context(), collectionID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			httputils.RespondWithError(w, http.StatusNotFound, "Collection not found")
+		} else {
+			log.Printf("Error fetching collection %s from database: %v", collectionID, err)
+			httputils.RespondWithServerError(w, err, "Failed to fetch collection: "+err.Error())
+		}
+		return db.Collection{}, false
+	}
+
+	if collection.UserUuid != userUUID || !sameWorkspace(collection.WorkspaceID, middleware.GetWorkspaceIDFromContext(r)) {
+		httputils.RespondWithError(w, http.StatusNotFound, "Collection not found")
+		return db.Collection{}, false
+	}
+	return collection, true
+}
+
+// getCollectionDropID parses the {dropId} path value and checks the drop belongs to userUUID
+// and lives in the same workspace as the collection.
+// On failure it writes the appropriate error response and returns false.
+func (h *CollectionsHandler) getCollectionDropID(w http.ResponseWriter, r *http.Request, userUUID uuid.UUID, collection db.Collection) (uuid.UUID, bool) {
+	dropID, err := uuid.Parse(r.PathValue("dropId"))
+	if err != nil {
+		httputils.RespondWithError(w, http.StatusBadRequest, "Invalid Drop ID format: "+err.Error())
+		return uuid.Nil, false
+	}
+
+	drop, err := h.APIConfig.DB.GetDrop(r.Context(), dropID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			httputils.RespondWithError(w, http.StatusNotFound, "Drop not found")
+		} else {
+			log.Printf("Error fetching drop %s from database: %v", dropID, err)
+			httputils.RespondWithServerError(w, err, "Failed to fetch drop: "+err.Error())
+		}
+		return uuid.Nil, false
+	}
+	if !drop.UserUuid.Valid || drop.UserUuid.UUID != userUUID || !dropInWorkspace(drop, collection.WorkspaceID) {
+		httputils.RespondWithError(w, http.StatusNotFound, "Drop not found")
+		return uuid.Nil, false
+	}
+	return drop.ID, true
+}
+
+// maxPublicCollectionDrops caps the drops returned for a published collection, since
+// anyone with the link can request it.
+const maxPublicCollectionDrops = 1000
+
+// PublishedCollectionResponse tells the owner where a published collection can be read.
+type PublishedCollectionResponse struct {
+	PublicSlug string `json:"public_slug"`
+	PublicPath string `json:"public_path"`
+}
+
+// PublicCollectionResponse is a published collection as anyone with its slug sees it.
+// It deliberately carries no IDs and nothing about the owner.
+type PublicCollectionResponse struct {
+	Name  string               `json:"name"`
+	Drops []PublicDropResponse `json:"drops"`
+}
+
+// PublicDropResponse is the read-only view of a drop in a published collection.
+type PublicDropResponse struct {
+	Topic string  `json:"topic"`
+	URL   string  `json:"url"`
+	Notes *string `json:"notes"`
+}
+
+// PublishCollectionHandler makes a collection readable without signing in, through a random
+// slug. Publishing a collection that is already published returns its existing slug.
+// POST /api/v1/collections/{id}/publish
+func (h *CollectionsHandler) PublishCollectionHandler(w http.ResponseWriter, r *http.Request) {
+	userUUID, ok := r.Context().Value(middleware.UserIDKey).(uuid.UUID)
+	if !ok {
+		httputils.RespondWithError(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	collection, ok := h.getOwnedCollection(w, r, userUUID)
+	if !ok {
+		return
+	}
+
+	published, err := h.APIConfig.DB.PublishCollection(r.Context(), db.PublishCollectionParams{
+		PublicSlug: strings.ToLower(rand.Text()), // Over 128 random bits, so slugs can't be guessed
+		ID:         collection.ID,
+		UserUuid:   userUUID,
+	})
+	if err != nil {
+		log.Printf("Error publishing collection %s: %v", collection.ID, err)
+		httputils.RespondWithServerError(w, err, "Failed to publish collection: "+err.Error())
+		return
+	}
+
+	log.Printf("Collection %s published by UserUUID: %s", collection.ID, userUUID)
+	httputils.RespondWithJSON(w, http.StatusOK, PublishedCollectionResponse{
+		PublicSlug: published.PublicSlug.String,
+		PublicPath: "/api/v1/public/collections/" + published.PublicSlug.String,
+	})
+}
+
+// UnpublishCollectionHandler makes a published collection private again. Its slug stops
+// working for good; publishing it again gives it a new one.
+// DELETE /api/v1/collections/{id}/publish
+func (h *CollectionsHandler) UnpublishCollectionHandler(w http.ResponseWriter, r *http.Request) {
+	userUUID, ok := r.Context().Value(middleware.UserIDKey).(uuid.UUID)
+	if !ok {
+		httputils.RespondWithError(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	collection, ok := h.getOwnedCollection(w, r, userUUID)
+	if !ok {
+		return
+	}
+
+	unpublished, err := h.APIConfig.DB.UnpublishCollection(r.Context(), db.UnpublishCollectionParams{
+		ID:       collection.ID,
+		UserUuid: userUUID,
+	})
+	if err != nil {
+		log.Printf("Error unpublishing collection %s: %v", collection.ID, err)
+		httputils.RespondWithServerError(w, err, "Failed to unpublish collection: "+err.Error())
+		return
+	}
+	if unpublished == 0 {
+		httputils.RespondWithError(w, http.StatusNotFound, "Collection is not published")
+		return
+	}
+
+	log.Printf("Collection %s unpublished by UserUUID: %s", collection.ID, userUUID)
+	httputils.RespondWithJSON(w, http.StatusNoContent, nil)
+}
+
+// PublicCollectionHandler returns a published collection's name and drops, read-only and
+// without authentication. Unknown and unpublished slugs both get 404.
+// GET /api/v1/public/collections/{slug}
+func (h *CollectionsHandler) PublicCollectionHandler(w http.ResponseWriter, r *http.Request) {
+	slug := r.PathValue("slug")
+	collection, err := h.APIConfig.DB.GetCollectionByPublicSlug(r.Context(), sql.NullString{String: slug, Valid: true})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			httputils.RespondWithError(w, http.StatusNotFound, "Collection not found")
+		} else {
+			log.Printf("Error fetching published collection from database: %v", err)
+			httputils.RespondWithServerError(w, err, "Failed to fetch collection: "+err.Error())
+		}
+		return
+	}
+
+	drops, err := h.APIConfig.DBRead.ListPublicCollectionDrops(r.Context(), db.ListPublicCollectionDropsParams{
+		CollectionID: collection.ID,
+		Limit:        maxPublicCollectionDrops,
+	})
+	if err != nil {
+		log.Printf("Error fetching drops of published collection %s: %v", collection.ID, err)
+		httputils.RespondWithServerError(w, err, "Failed to fetch collection drops: "+err.Error())
+		return
+	}
+
+	response := PublicCollectionResponse{Name: collection.Name, Drops: make([]PublicDropResponse, 0, len(drops))}
+	for _, d := range drops {
+		var notes *string
+		if d.UserNotes.Valid {
+			notes = &d.UserNotes.String
+		}
+		response.Drops = append(response.Drops, PublicDropResponse{Topic: d.Topic, URL: d.Url, Notes: notes})
+	}
+
+	httputils.RespondWithJSON(w, http.StatusOK, response)
+}
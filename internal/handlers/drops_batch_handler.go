@@ -0,0 +1,208 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/google/uuid"
+	db "github.com/nouvadev/dropwise/internal/database/sqlc"
+	"github.com/nouvadev/dropwise/internal/events"
+	"github.com/nouvadev/dropwise/internal/middleware"
+	"github.com/nouvadev/dropwise/internal/server/httputils"
+)
+
+// maxBatchSize caps the number of drop IDs accepted by batch endpoints.
+const maxBatchSize = 100
+
+// BatchStatusRequest defines the expected request body for updating the status of several drops.
+type BatchStatusRequest struct {
+	IDs    []uuid.UUID `json:"ids"`
+	Status string      `json:"status"`
+}
+
+// BatchStatusResponse reports which drops were updated.
+// SkippedIDs lists requested IDs that do not exist or are not owned by the user.
+// A batch in which any owned drop can't take the new status (see dropStatusTransitions) is refused with 409.
+type BatchStatusResponse struct {
+	UpdatedCount int         `json:"updated_count"`
+	SkippedIDs   []uuid.UUID `json:"skipped_ids"`
+}
+
+// BatchStatusHandler sets the status of several of the user's drops in one statement.
+// POST /api/v1/drops/batch-status
+func (h *DropsHandler) BatchStatusHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		httputils.RespondWithError(w, http.StatusMethodNotAllowed, "Only POST method is allowed")
+		return
+	}
+
+	userUUID, ok := r.Context().Value(middleware.UserIDKey).(uuid.UUID)
+	if !ok {
+		log.Printf("BatchStatusHandler: UserID not found in context or not a UUID for path %s", r.URL.Path)
+		httputils.RespondWithError(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	var req BatchStatusRequest
+	if !httputils.DecodeJSONBody(w, r, &req) {
+		return
+	}
+	defer r.Body.Close()
+
+	if len(req.IDs) == 0 {
+		httputils.RespondWithError(w, http.StatusBadRequest, "At least one drop ID is required")
+		return
+	}
+	if len(req.IDs) > maxBatchSize {
+		httputils.RespondWithError(w, http.StatusBadRequest, "Too many drop IDs, the maximum is 100")
+		return
+	}
+	if !validDropStatuses[req.Status] {
+		httputils.RespondWithError(w, http.StatusBadRequest, invalidDropStatusMessage)
+		return
+	}
+
+	log.Printf("Attempting to set status '%s' on %d drops for UserUUID: %s", req.Status, len(req.IDs), userUUID)
+
+	// Refuse the whole batch if any owned drop can't make the change
+	current, err := h.APIConfig.DB.GetDropsByIDs(r.Context(), db.GetDropsByIDsParams{
+		Ids:         req.IDs,
+		UserUuid:    uuid.NullUUID{UUID: userUUID, Valid: true},
+		WorkspaceID: middleware.GetWorkspaceIDFromContext(r),
+	})
+	if err != nil {
+		log.Printf("Error fetching drops by IDs for UserUUID %s: %v", userUUID, err)
+		httputils.RespondWithServerError(w, err, "Failed to update drops: "+err.Error())
+		return
+	}
+	for _, drop := range current {
+		if !canTransitionDropStatus(drop.Status, req.Status) {
+			respondIllegalStatusTransition(w, drop.ID, drop.Status, req.Status)
+			return
+		}
+	}
+
+	// A single UPDATE statement runs in its own transaction, so either all owned drops change or none do.
+	// The status guard skips drops whose status changed since they were checked.
+	updatedIDs, err := h.APIConfig.DB.UpdateDropsStatus(r.Context(), db.UpdateDropsStatusParams{
+		Status:       req.Status,
+		Ids:          req.IDs,
+		UserUuid:     uuid.NullUUID{UUID: userUUID, Valid: true},
+		WorkspaceID:  middleware.GetWorkspaceIDFromContext(r),
+		FromStatuses: statusesTransitioningTo(req.Status),
+	})
+	if err != nil {
+		log.Printf("Error updating drop statuses: %v", err)
+		httputils.RespondWithServerError(w, err, "Failed to update drops: "+err.Error())
+		return
+	}
+
+	workspaceID := middleware.GetWorkspaceIDFromContext(r)
+	updated := make(map[uuid.UUID]bool, len(updatedIDs))
+	for _, id := range updatedIDs {
+		updated[id] = true
+		h.APIConfig.Events.Publish(userUUID, events.Event{Type: events.DropUpdated, DropID: id, WorkspaceID: workspaceID})
+	}
+	if len(updatedIDs) > 0 {
+		recordReview(r, h.APIConfig.DB, userUUID, req.Status)
+	}
+	skipped := []uuid.UUID{}
+	for _, id := range req.IDs {
+		if !updated[id] {
+			skipped = append(skipped, id)
+		}
+	}
+
+	log.Printf("Updated status of %d drops for UserUUID: %s (%d skipped)", len(updatedIDs), userUUID, len(skipped))
+	httputils.RespondWithJSON(w, http.StatusOK, BatchStatusResponse{
+		UpdatedCount: len(updatedIDs),
+		SkippedIDs:   skipped,
+	})
+}
+
+// BatchGetRequest defines the expected request body for fetching several drops.
+type BatchGetRequest struct {
+	IDs []uuid.UUID `json:"ids"`
+}
+
+// BatchGetResponse holds the requested drops in the order they were asked for.
+type BatchGetResponse struct {
+	Drops []DropResponse `json:"drops"`
+}
+
+// BatchGetDropsHandler fetches several of the user's drops with a single drop query and a
+// single tag query. IDs that don't exist, belong to someone else or live in another
+// workspace are left out; repeated IDs are returned once.
+// POST /api/v1/drops/batch-get
+func (h *DropsHandler) BatchGetDropsHandler(w http.ResponseWriter, r *http.Request) {
+	userUUID, ok := r.Context().Value(middleware.UserIDKey).(uuid.UUID)
+	if !ok {
+		log.Printf("BatchGetDropsHandler: UserID not found in context or not a UUID for path %s", r.URL.Path)
+		httputils.RespondWithError(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	var req BatchGetRequest
+	if !httputils.DecodeJSONBody(w, r, &req) {
+		return
+	}
+	defer r.Body.Close()
+
+	if len(req.IDs) == 0 {
+		httputils.RespondWithError(w, http.StatusBadRequest, "At least one drop ID is required")
+		return
+	}
+	if len(req.IDs) > maxBatchSize {
+		httputils.RespondWithError(w, http.StatusBadRequest, "Too many drop IDs, the maximum is 100")
+		return
+	}
+
+	drops, err := h.APIConfig.DB.GetDropsByIDs(r.Context(), db.GetDropsByIDsParams{
+		Ids:         req.IDs,
+		UserUuid:    uuid.NullUUID{UUID: userUUID, Valid: true},
+		WorkspaceID: middleware.GetWorkspaceIDFromContext(r),
+	})
+	if err != nil {
+		log.Printf("Error fetching drops by IDs for UserUUID %s: %v", userUUID, err)
+		httputils.RespondWithServerError(w, err, "Failed to fetch drops: "+err.Error())
+		return
+	}
+
+	byID := make(map[uuid.UUID]db.Drop, len(drops))
+	foundIDs := make([]uuid.UUID, 0, len(drops))
+	for _, drop := range drops {
+		byID[drop.ID] = drop
+		foundIDs = append(foundIDs, drop.ID)
+	}
+	tagsByDrop := h.tagNamesForDrops(r, foundIDs)
+
+	response := BatchGetResponse{Drops: make([]DropResponse, 0, len(drops))}
+	for _, id := range req.IDs {
+		drop, found := byID[id]
+		if !found {
+			continue
+		}
+		delete(byID, id) // Return repeated IDs once
+		response.Drops = append(response.Drops, toDropResponse(drop, tagsByDrop[id], r, h.APIConfig.MaxSendCount))
+	}
+
+	httputils.RespondWithJSON(w, http.StatusOK, response)
+}
+
+// tagNamesForDrops fetches the tag names of several drops in one query, keyed by drop ID.
+// Errors are logged and result in no tags so a response can still be returned.
+func (h *DropsHandler) tagNamesForDrops(r *http.Request, dropIDs []uuid.UUID) map[uuid.UUID][]string {
+	tagsByDrop := make(map[uuid.UUID][]string, len(dropIDs))
+	if len(dropIDs) == 0 {
+		return tagsByDrop
+	}
+	rows, err := h.APIConfig.DB.GetTagsForDrops(r.Context(), dropIDs)
+	if err != nil {
+		log.Printf("Error fetching tags for %d drops: %v", len(dropIDs), err)
+		return tagsByDrop
+	}
+	for _, row := range rows {
+		tagsByDrop[row.DropsID] = append(tagsByDrop[row.DropsID], row.Name)
+	}
+	return tagsByDrop
+}
@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"database/sql"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	db "github.com/nouvadev/dropwise/internal/database/sqlc"
+	"github.com/nouvadev/dropwise/internal/events"
+	"github.com/nouvadev/dropwise/internal/middleware"
+	"github.com/nouvadev/dropwise/internal/schedule"
+	"github.com/nouvadev/dropwise/internal/server/httputils"
+)
+
+// CloneDropHandler creates a copy of one of the user's drops in the same workspace.
+// The copy keeps the content, priority, tags and delivery settings but starts over as a
+// 'new' drop that has never been sent. A cron schedule is kept and restarts from now.
+// POST /api/v1/drops/{id}/clone
+func (h *DropsHandler) CloneDropHandler(w http.ResponseWriter, r *http.Request) {
+	userUUID, ok := r.Context().Value(middleware.UserIDKey).(uuid.UUID)
+	if !ok {
+		log.Printf("CloneDropHandler: UserID not found in context or not a UUID for path %s", r.URL.Path)
+		httputils.RespondWithError(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	source, ok := h.getOwnedDrop(w, r, userUUID)
+	if !ok {
+		return
+	}
+
+	params := db.CreateDropParams{
+		UserUuid:    source.UserUuid,
+		Topic:       source.Topic,
+		Url:         source.Url,
+		UserNotes:   source.UserNotes,
+		Priority:    source.Priority,
+		Excerpt:     source.Excerpt,
+		WorkspaceID: source.WorkspaceID,
+		Schedule:    source.Schedule,
+		Permanent:   source.Permanent,
+		Channel:     source.Channel,
+		Metadata:    source.Metadata,
+	}
+	if source.Schedule.Valid {
+		// The schedule was valid when stored, so a parse error here only leaves the clone due immediately
+		if cron, err := schedule.ParseCron(source.Schedule.String); err == nil {
+			if next, ok := cron.NextIn(time.Now(), middleware.GetUserTimezoneFromContext(r)); ok {
+				params.NextSendDate = sql.NullTime{Time: next, Valid: true}
+			}
+		}
+	}
+
+	tags, err := h.APIConfig.DB.GetTagsForDrop(r.Context(), source.ID)
+	if err != nil {
+		log.Printf("Error fetching tags of drop %s to clone: %v", source.ID, err)
+		httputils.RespondWithServerError(w, err, "Failed to clone drop: "+err.Error())
+		return
+	}
+
+	log.Printf("Attempting to clone drop %s for UserUUID: %s", source.ID, userUUID)
+
+	// The clone and its tags are created together or not at all.
+	tx, err := h.APIConfig.DBConn.BeginTx(r.Context(), nil)
+	if err != nil {
+		log.Printf("Error starting transaction for drop clone: %v", err)
+		httputils.RespondWithServerError(w, err, "Failed to clone drop")
+		return
+	}
+	defer tx.Rollback()
+	qtx := h.APIConfig.DB.WithTx(tx)
+
+	clone, err := qtx.CreateDrop(r.Context(), params)
+	if err != nil {
+		log.Printf("Error creating clone of drop %s: %v", source.ID, err)
+		httputils.RespondWithServerError(w, err, "Failed to clone drop: "+err.Error())
+		return
+	}
+
+	tagNames := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		err := qtx.AddTagToDrop(r.Context(), db.AddTagToDropParams{DropsID: clone.ID, TagID: tag.ID})
+		if err != nil {
+			log.Printf("Error copying tag '%s' (ID: %d) to clone %s: %v", tag.Name, tag.ID, clone.ID, err)
+			httputils.RespondWithServerError(w, err, "Failed to clone drop: "+err.Error())
+			return
+		}
+		tagNames = append(tagNames, tag.Name)
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("Error committing clone of drop %s: %v", source.ID, err)
+		httputils.RespondWithServerError(w, err, "Failed to clone drop")
+		return
+	}
+
+	log.Printf("Successfully cloned drop %s as %s", source.ID, clone.ID)
+	h.publishDropEvent(userUUID, events.DropCreated, clone)
+	h.APIConfig.DropCreationMonitor.Observe(r.Context(), userUUID, 1)
+	httputils.RespondWithJSON(w, http.StatusCreated, toDropResponse(clone, tagNames, r, h.APIConfig.MaxSendCount))
+}
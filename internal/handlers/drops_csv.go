@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nouvadev/dropwise/internal/server/httputils"
+)
+
+// dropsCSVHeader names the columns written by respondWithDropsCSV.
+var dropsCSVHeader = []string{
+	"id", "workspace_id", "topic", "url", "user_notes", "excerpt", "status", "priority", "tags",
+	"added_date", "updated_at", "last_sent_date", "next_send_date", "send_count", "schedule", "permanent", "channel", "metadata",
+}
+
+// respondWithDropsCSV writes drops as CSV, one row per drop. Tags are joined with "|",
+// and missing values are left empty. With fields set only those columns are written,
+// in their usual order; requested fields without a column are skipped.
+func respondWithDropsCSV(w http.ResponseWriter, drops []DropResponse, fields []string) {
+	records := make([][]string, 0, len(drops))
+	for _, d := range drops {
+		workspaceID := ""
+		if d.WorkspaceID != nil {
+			workspaceID = d.WorkspaceID.String()
+		}
+		priority := ""
+		if d.Priority != nil {
+			priority = strconv.Itoa(int(*d.Priority))
+		}
+		records = append(records, []string{
+			d.ID.String(),
+			workspaceID,
+			d.Topic,
+			d.URL,
+			csvString(d.UserNotes),
+			csvString(d.Excerpt),
+			d.Status,
+			priority,
+			strings.Join(d.Tags, "|"),
+			d.AddedDate.Format(time.RFC3339),
+			d.UpdatedAt.Format(time.RFC3339),
+			csvTime(d.LastSentDate),
+			csvTime(d.NextSendDate),
+			strconv.Itoa(int(d.SendCount)),
+			csvString(d.Schedule),
+			strconv.FormatBool(d.Permanent),
+			d.Channel,
+			string(d.Metadata),
+		})
+	}
+	header := dropsCSVHeader
+	if fields != nil {
+		header, records = selectCSVColumns(header, records, fields)
+	}
+	w.Header().Set("Content-Disposition", `attachment; filename="drops.csv"`)
+	httputils.RespondWithCSV(w, http.StatusOK, header, records)
+}
+
+// selectCSVColumns keeps only the columns named in fields.
+func selectCSVColumns(header []string, records [][]string, fields []string) ([]string, [][]string) {
+	var keep []int
+	for i, column := range header {
+		if slices.Contains(fields, column) {
+			keep = append(keep, i)
+		}
+	}
+	selectRow := func(row []string) []string {
+		selected := make([]string, 0, len(keep))
+		for _, i := range keep {
+			selected = append(selected, row[i])
+		}
+		return selected
+	}
+	for i, record := range records {
+		records[i] = selectRow(record)
+	}
+	return selectRow(header), records
+}
+
+// csvString returns *s, or "" for nil.
+func csvString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// csvTime formats *t as RFC 3339, or returns "" for nil.
+func csvTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+	db "github.com/nouvadev/dropwise/internal/database/sqlc"
+	"github.com/nouvadev/dropwise/internal/middleware"
+	"github.com/nouvadev/dropwise/internal/server/httputils"
+)
+
+const (
+	defaultDomainsLimit = 50
+	maxDomainsLimit     = 500
+)
+
+// DomainCountResponse is one entry of the domains summary.
+type DomainCountResponse struct {
+	Domain string `json:"domain"`
+	Count  int64  `json:"count"`
+}
+
+// DomainsHandler returns the sites the user's drops in the active workspace were saved
+// from, with how many drops each, most saved first. Hosts are extracted from the URLs in SQL.
+// GET /api/v1/drops/domains?limit=
+func (h *DropsHandler) DomainsHandler(w http.ResponseWriter, r *http.Request) {
+	userUUID, ok := r.Context().Value(middleware.UserIDKey).(uuid.UUID)
+	if !ok {
+		httputils.RespondWithError(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	limit := defaultDomainsLimit
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 || n > maxDomainsLimit {
+			httputils.RespondWithError(w, http.StatusBadRequest, "limit must be between 1 and 500")
+			return
+		}
+		limit = n
+	}
+
+	rows, err := h.APIConfig.DBRead.CountDropsByDomain(r.Context(), db.CountDropsByDomainParams{
+		UserUuid:    uuid.NullUUID{UUID: userUUID, Valid: true},
+		WorkspaceID: middleware.GetWorkspaceIDFromContext(r),
+		Limit:       int32(limit),
+	})
+	if err != nil {
+		log.Printf("Error counting drops by domain for UserUUID %s: %v", userUUID, err)
+		httputils.RespondWithServerError(w, err, "Failed to fetch domains: "+err.Error())
+		return
+	}
+
+	response := make([]DomainCountResponse, 0, len(rows))
+	for _, row := range rows {
+		response = append(response, DomainCountResponse{Domain: row.Domain, Count: row.Count})
+	}
+	httputils.RespondWithJSON(w, http.StatusOK, response)
+}
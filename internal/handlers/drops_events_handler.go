@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	db "github.com/nouvadev/dropwise/internal/database/sqlc"
+	"github.com/nouvadev/dropwise/internal/events"
+	"github.com/nouvadev/dropwise/internal/middleware"
+	"github.com/nouvadev/dropwise/internal/server/httputils"
+)
+
+// eventStreamHeartbeat is how often a comment is sent on an idle stream so proxies keep it open.
+const eventStreamHeartbeat = 30 * time.Second
+
+// DropEventsHandler streams changes to the user's drops in the active workspace as
+// server-sent events. Each event is named after its type (e.g. drop.updated) and its
+// data is the JSON-encoded events.Event.
+// GET /api/v1/drops/events
+func (h *DropsHandler) DropEventsHandler(w http.ResponseWriter, r *http.Request) {
+	userUUID, ok := r.Context().Value(middleware.UserIDKey).(uuid.UUID)
+	if !ok {
+		httputils.RespondWithError(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+	workspaceID := middleware.GetWorkspaceIDFromContext(r)
+
+	rc := http.NewResponseController(w)
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no") // Stop nginx from buffering the stream
+	if err := rc.Flush(); err != nil {
+		// Without flushing, events would sit in a buffer until the connection closes.
+		if errors.Is(err, http.ErrNotSupported) {
+			for _, header := range []string{"Cache-Control", "Connection", "X-Accel-Buffering"} {
+				w.Header().Del(header)
+			}
+			httputils.RespondWithError(w, http.StatusNotImplemented, "Streaming is not supported by this server, poll GET /api/v1/drops instead")
+		}
+		return
+	}
+
+	stream, unsubscribe := h.APIConfig.Events.Subscribe(userUUID)
+	defer unsubscribe()
+
+	log.Printf("Drop event stream opened for UserUUID: %s", userUUID)
+	defer log.Printf("Drop event stream closed for UserUUID: %s", userUUID)
+
+	// Tell the browser how long to wait before reconnecting
+	if _, err := fmt.Fprint(w, "retry: 5000\n\n"); err != nil {
+		return
+	}
+	rc.Flush()
+
+	heartbeat := time.NewTicker(eventStreamHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+		case event, ok := <-stream:
+			if !ok {
+				return
+			}
+			if !sameWorkspace(event.WorkspaceID, workspaceID) {
+				continue
+			}
+			data, err := json.Marshal(event)
+			if err != nil {
+				log.Printf("Error encoding drop event for UserUUID %s: %v", userUUID, err)
+				continue
+			}
+			if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, data); err != nil {
+				return
+			}
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+// publishDropEvent notifies the user's event streams that a drop changed. Every change that
+// can affect the user's tags (creating, retagging or deleting a drop) goes through here, so
+// it also invalidates their cached tag listings.
+func (h *DropsHandler) publishDropEvent(userUUID uuid.UUID, eventType string, drop db.Drop) {
+	h.APIConfig.TagsCache.Invalidate(userUUID)
+	h.APIConfig.Events.Publish(userUUID, events.Event{
+		Type:        eventType,
+		DropID:      drop.ID,
+		WorkspaceID: drop.WorkspaceID,
+	})
+}
@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+	db "github.com/nouvadev/dropwise/internal/database/sqlc"
+	"github.com/nouvadev/dropwise/internal/middleware"
+	"github.com/nouvadev/dropwise/internal/server/httputils"
+)
+
+// untaggedHeading is the section ExportDropsHandler lists drops without tags under.
+const untaggedHeading = "Untagged"
+
+// markdownLinkText escapes the characters that would end or break a Markdown link text.
+var markdownLinkText = strings.NewReplacer(`\`, `\\`, `[`, `\[`, `]`, `\]`)
+
+// markdownLinkDestination percent-encodes the characters that would end a Markdown link destination.
+var markdownLinkDestination = strings.NewReplacer(" ", "%20", "(", "%28", ")", "%29", "<", "%3C", ">", "%3E")
+
+// ExportDropsHandler downloads the user's drops in the active workspace as a Markdown
+// document for pasting into a notes app, one "- [topic](url) — notes" bullet per drop.
+// Drops are grouped under a heading per tag (a drop with several tags is listed under
+// each) or, with ?group_by=status, per status. ?collection_id= and ?metadata.<key>=
+// filter the drops as they do for ListDropsHandler.
+// GET /api/v1/drops/export?format=markdown&group_by=tag|status
+func (h *DropsHandler) ExportDropsHandler(w http.ResponseWriter, r *http.Request) {
+	userUUID, ok := r.Context().Value(middleware.UserIDKey).(uuid.UUID)
+	if !ok {
+		httputils.RespondWithError(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	query := r.URL.Query()
+	if format := query.Get("format"); format != "markdown" {
+		httputils.RespondWithError(w, http.StatusBadRequest, "Unsupported format, expected format=markdown")
+		return
+	}
+	groupBy := query.Get("group_by")
+	if groupBy == "" {
+		groupBy = "tag"
+	}
+	if groupBy != "tag" && groupBy != "status" {
+		httputils.RespondWithError(w, http.StatusBadRequest, "group_by must be tag or status")
+		return
+	}
+	metadataFilter, err := parseDropMetadataFilter(query)
+	if err != nil {
+		httputils.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	var collectionID uuid.NullUUID
+	if v := query.Get("collection_id"); v != "" {
+		parsed, err := uuid.Parse(v)
+		if err != nil {
+			httputils.RespondWithError(w, http.StatusBadRequest, "Invalid collection_id format: "+err.Error())
+			return
+		}
+		collectionID = uuid.NullUUID{UUID: parsed, Valid: true}
+	}
+
+	drops, err := h.APIConfig.DBRead.ListDropsByUserUUID(r.Context(), db.ListDropsByUserUUIDParams{
+		UserUuid:     uuid.NullUUID{UUID: userUUID, Valid: true},
+		WorkspaceID:  middleware.GetWorkspaceIDFromContext(r),
+		CollectionID: collectionID,
+		Metadata:     metadataFilter,
+	})
+	if err != nil {
+		log.Printf("Error fetching drops of UserUUID %s for Markdown export: %v", userUUID, err)
+		httputils.RespondWithServerError(w, err, "Failed to export drops: "+err.Error())
+		return
+	}
+
+	var sections []string
+	grouped := make(map[string][]db.Drop)
+	if groupBy == "status" {
+		for _, drop := range drops {
+			grouped[drop.Status] = append(grouped[drop.Status], drop)
+		}
+		for _, status := range knownDropStatuses {
+			if len(grouped[status]) > 0 {
+				sections = append(sections, status)
+			}
+		}
+	} else {
+		dropIDs := make([]uuid.UUID, len(drops))
+		for i, drop := range drops {
+			dropIDs[i] = drop.ID
+		}
+		tagRows, err := h.APIConfig.DBRead.GetTagsForDrops(r.Context(), dropIDs)
+		if err != nil {
+			log.Printf("Error fetching tags of UserUUID %s for Markdown export: %v", userUUID, err)
+			httputils.RespondWithServerError(w, err, "Failed to export drops: "+err.Error())
+			return
+		}
+		tagsByDrop := make(map[uuid.UUID][]string)
+		for _, row := range tagRows {
+			tagsByDrop[row.DropsID] = append(tagsByDrop[row.DropsID], row.Name)
+		}
+		var untagged []db.Drop
+		for _, drop := range drops {
+			if len(tagsByDrop[drop.ID]) == 0 {
+				untagged = append(untagged, drop)
+				continue
+			}
+			for _, tag := range tagsByDrop[drop.ID] {
+				if len(grouped[tag]) == 0 {
+					sections = append(sections, tag)
+				}
+				grouped[tag] = append(grouped[tag], drop)
+			}
+		}
+		sort.Strings(sections)
+		if len(untagged) > 0 {
+			// Listed last, after the tags sorted by name, unless a tag took the heading
+			if len(grouped[untaggedHeading]) == 0 {
+				sections = append(sections, untaggedHeading)
+			}
+			grouped[untaggedHeading] = append(grouped[untaggedHeading], untagged...)
+		}
+	}
+
+	log.Printf("Exporting %d drops of UserUUID %s as Markdown grouped by %s", len(drops), userUUID, groupBy)
+	w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="drops.md"`)
+	w.WriteHeader(http.StatusOK)
+
+	out := bufio.NewWriter(w)
+	fmt.Fprintln(out, "# Dropwise drops")
+	for _, section := range sections {
+		fmt.Fprintf(out, "\n## %s\n\n", section)
+		for _, drop := range grouped[section] {
+			writeMarkdownDrop(out, drop)
+		}
+	}
+	if err := out.Flush(); err != nil {
+		log.Printf("Error writing Markdown export of UserUUID %s: %v", userUUID, err)
+	}
+}
+
+// writeMarkdownDrop writes drop as a "- [topic](url) — notes" bullet. Further lines of
+// multi-line notes are indented so they stay part of the bullet.
+func writeMarkdownDrop(out *bufio.Writer, drop db.Drop) {
+	fmt.Fprintf(out, "- [%s](%s)", markdownLinkText.Replace(drop.Topic), markdownLinkDestination.Replace(drop.Url))
+	if drop.UserNotes.Valid {
+		if notes := strings.TrimSpace(drop.UserNotes.String); notes != "" {
+			fmt.Fprintf(out, " — %s", strings.ReplaceAll(notes, "\n", "\n  "))
+		}
+	}
+	fmt.Fprintln(out)
+}
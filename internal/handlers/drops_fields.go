@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/nouvadev/dropwise/internal/middleware"
+	"github.com/nouvadev/dropwise/internal/server/httputils"
+)
+
+// dropResponseFields holds, per API version, the JSON names of the top-level drop fields
+// ?fields= may select.
+var dropResponseFields = map[int]map[string]bool{
+	middleware.APIVersion1: jsonFieldNames(reflect.TypeFor[DropResponse]()),
+	middleware.APIVersion2: jsonFieldNames(reflect.TypeFor[DropResponseV2]()),
+}
+
+// jsonFieldNames returns the names the fields of struct type t are serialized under.
+func jsonFieldNames(t reflect.Type) map[string]bool {
+	names := make(map[string]bool, t.NumField())
+	for i := range t.NumField() {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			names[name] = true
+		}
+	}
+	return names
+}
+
+// parseDropFields reads the comma-separated ?fields= list restricting which drop fields
+// are returned, named as in the request's API version. It returns nil when the parameter is
+// absent, meaning every field.
+// On an unknown field it writes a 400 response and returns false.
+func parseDropFields(w http.ResponseWriter, r *http.Request) ([]string, bool) {
+	if !r.URL.Query().Has("fields") {
+		return nil, true
+	}
+	known := dropResponseFields[middleware.GetAPIVersionFromContext(r)]
+	var fields []string
+	for _, field := range strings.Split(r.URL.Query().Get("fields"), ",") {
+		field = strings.TrimSpace(field)
+		if field == "" || slices.Contains(fields, field) {
+			continue
+		}
+		if !known[field] {
+			httputils.RespondWithError(w, http.StatusBadRequest, "Unknown field in fields: "+field)
+			return nil, false
+		}
+		fields = append(fields, field)
+	}
+	if len(fields) == 0 {
+		httputils.RespondWithError(w, http.StatusBadRequest, "fields must name at least one field")
+		return nil, false
+	}
+	return fields, true
+}
+
+// projectDrop returns drop reduced to the given fields, or drop itself when fields is nil.
+func projectDrop(drop DropResponse, fields []string) (any, error) {
+	if fields == nil {
+		return drop, nil
+	}
+	encoded, err := json.Marshal(drop)
+	if err != nil {
+		return nil, err
+	}
+	var all map[string]json.RawMessage
+	if err := json.Unmarshal(encoded, &all); err != nil {
+		return nil, err
+	}
+	projected := make(map[string]json.RawMessage, len(fields))
+	for _, field := range fields {
+		projected[field] = all[field]
+	}
+	return projected, nil
+}
+
+// projectDrops applies projectDrop to every drop in the list.
+func projectDrops(drops []DropResponse, fields []string) (any, error) {
+	if fields == nil {
+		return drops, nil
+	}
+	projected := make([]any, 0, len(drops))
+	for _, drop := range drops {
+		p, err := projectDrop(drop, fields)
+		if err != nil {
+			return nil, err
+		}
+		projected = append(projected, p)
+	}
+	return projected, nil
+}
@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+	db "github.com/nouvadev/dropwise/internal/database/sqlc"
+	"github.com/nouvadev/dropwise/internal/middleware"
+	"github.com/nouvadev/dropwise/internal/server/httputils"
+)
+
+const (
+	defaultDropsPerTagGroup = 20
+	maxDropsPerTagGroup     = 100
+)
+
+// untaggedGroup is the key drops without tags are listed under by GroupedByTagHandler.
+const untaggedGroup = "_untagged"
+
+// GroupedByTagHandler lists the user's drops in the active workspace grouped by tag, for
+// board views: {"tag name": [drops...], "_untagged": [...]}. A drop with several tags is
+// listed in each of their groups. Each group holds its newest per_group drops.
+// GET /api/v1/drops/grouped-by-tag?per_group=
+func (h *DropsHandler) GroupedByTagHandler(w http.ResponseWriter, r *http.Request) {
+	userUUID, ok := r.Context().Value(middleware.UserIDKey).(uuid.UUID)
+	if !ok {
+		httputils.RespondWithError(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	perGroup := defaultDropsPerTagGroup
+	if raw := r.URL.Query().Get("per_group"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 || n > maxDropsPerTagGroup {
+			httputils.RespondWithError(w, http.StatusBadRequest, "per_group must be between 1 and 100")
+			return
+		}
+		perGroup = n
+	}
+
+	rows, err := h.APIConfig.DBRead.ListDropsGroupedByTag(r.Context(), db.ListDropsGroupedByTagParams{
+		UserUuid:    uuid.NullUUID{UUID: userUUID, Valid: true},
+		WorkspaceID: middleware.GetWorkspaceIDFromContext(r),
+		PerGroup:    int64(perGroup),
+	})
+	if err != nil {
+		log.Printf("Error fetching drops grouped by tag for UserUUID %s: %v", userUUID, err)
+		httputils.RespondWithServerError(w, err, "Failed to fetch drops: "+err.Error())
+		return
+	}
+
+	var dropIDs []uuid.UUID
+	seen := make(map[uuid.UUID]bool)
+	for _, row := range rows {
+		if !seen[row.Drop.ID] {
+			seen[row.Drop.ID] = true
+			dropIDs = append(dropIDs, row.Drop.ID)
+		}
+	}
+	tagsByDrop := h.tagNamesForDrops(r, dropIDs)
+
+	groups := make(map[string][]DropResponse)
+	for _, row := range rows {
+		group := untaggedGroup
+		if row.TagName.Valid {
+			group = row.TagName.String
+		}
+		groups[group] = append(groups[group], toDropResponse(row.Drop, tagsByDrop[row.Drop.ID], r, h.APIConfig.MaxSendCount))
+	}
+	httputils.RespondWithJSON(w, http.StatusOK, groups)
+}
@@ -3,26 +3,89 @@ package handlers
 import (
 	"database/sql"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
 	"net/http"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/nouvadev/dropwise/internal/audit"
 	"github.com/nouvadev/dropwise/internal/config"
 	db "github.com/nouvadev/dropwise/internal/database/sqlc"
+	"github.com/nouvadev/dropwise/internal/delivery"
+	"github.com/nouvadev/dropwise/internal/events"
+	"github.com/nouvadev/dropwise/internal/markdown"
+	"github.com/nouvadev/dropwise/internal/metadata"
 	"github.com/nouvadev/dropwise/internal/middleware" // Ensure middleware is imported
+	"github.com/nouvadev/dropwise/internal/schedule"
 	"github.com/nouvadev/dropwise/internal/server/httputils"
+	"github.com/sqlc-dev/pqtype"
 )
 
+// knownDropStatuses lists the statuses a drop can have, in display order.
+var knownDropStatuses = []string{"new", "sent", "archived", "snoozed", "graduated"}
+
+// validDropStatuses is the allowlist of statuses a client may set on a drop.
+var validDropStatuses = func() map[string]bool {
+	valid := make(map[string]bool, len(knownDropStatuses))
+	for _, status := range knownDropStatuses {
+		valid[status] = true
+	}
+	return valid
+}()
+
+const invalidDropStatusMessage = "Invalid status value. Allowed: new, sent, archived, snoozed, graduated."
+
+// dropStatusTransitions is the graph of status changes a client may make, keyed by the
+// current status. Setting a drop's current status again is always allowed.
+//   - new drops can be marked sent (reviewed by hand), snoozed or archived
+//   - sent drops can be snoozed or archived; the worker keeps resending them on schedule
+//   - snoozed and archived drops go back to new to rejoin the queue
+//   - only the worker graduates drops (at MAX_SEND_COUNT); they can be archived or restarted
+var dropStatusTransitions = map[string][]string{
+	"new":       {"sent", "snoozed", "archived"},
+	"sent":      {"snoozed", "archived"},
+	"snoozed":   {"new", "archived"},
+	"archived":  {"new"},
+	"graduated": {"new", "archived"},
+}
+
+// canTransitionDropStatus reports whether a client may change a drop's status from one value to another.
+func canTransitionDropStatus(from, to string) bool {
+	return from == to || slices.Contains(dropStatusTransitions[from], to)
+}
+
+// statusesTransitioningTo returns the statuses a drop may have to be given status to.
+func statusesTransitioningTo(to string) []string {
+	var from []string
+	for _, status := range knownDropStatuses {
+		if canTransitionDropStatus(status, to) {
+			from = append(from, status)
+		}
+	}
+	return from
+}
+
+// respondIllegalStatusTransition writes a 409 response naming the refused status change.
+func respondIllegalStatusTransition(w http.ResponseWriter, dropID uuid.UUID, from, to string) {
+	httputils.RespondWithError(w, http.StatusConflict, fmt.Sprintf("Illegal status transition for drop %s: %s -> %s", dropID, from, to))
+}
+
+const invalidDropChannelMessage = "Invalid channel value. Allowed: default, email, slack, both."
+
 // DropsHandler handles HTTP requests for drops.
 type DropsHandler struct {
 	APIConfig *config.APIConfig
+	Fetcher   *metadata.Fetcher
 }
 
 // NewDropsHandler creates a new DropsHandler.
 func NewDropsHandler(apiCfg *config.APIConfig) *DropsHandler {
-	return &DropsHandler{APIConfig: apiCfg}
+	return &DropsHandler{APIConfig: apiCfg, Fetcher: metadata.NewFetcher(apiCfg.MetadataFetcher)}
 }
 
 // CreateDropRequest defines the expected request body for creating a drop.
@@ -31,7 +94,12 @@ type CreateDropRequest struct {
 	URL       string   `json:"url"`
 	UserNotes string   `json:"user_notes,omitempty"`
 	Priority  *int32   `json:"priority,omitempty"`
+	Schedule  string   `json:"schedule,omitempty"` // Optional cron expression, see schedule.CronSyntaxHelp
+	Permanent bool     `json:"permanent,omitempty"`
+	Channel   string   `json:"channel,omitempty"` // Delivery channel override, "default" follows the user's preference
 	Tags      []string `json:"tags,omitempty"`
+
+	Metadata json.RawMessage `json:"metadata,omitempty"` // Free-form JSON object, see parseDropMetadata
 }
 
 // UpdateDropRequest defines the expected request body for updating a drop.
@@ -40,35 +108,99 @@ type UpdateDropRequest struct {
 	URL       *string   `json:"url,omitempty"`
 	UserNotes *string   `json:"user_notes,omitempty"`
 	Priority  *int32    `json:"priority,omitempty"`
-	Status    *string   `json:"status,omitempty"` // e.g., "new", "sent", "archived"
+	Status    *string   `json:"status,omitempty"`   // e.g., "new", "sent", "archived"
+	Schedule  *string   `json:"schedule,omitempty"` // An empty string removes the schedule
+	Permanent *bool     `json:"permanent,omitempty"`
+	Channel   *string   `json:"channel,omitempty"`
 	Tags      *[]string `json:"tags,omitempty"`
+
+	Metadata json.RawMessage `json:"metadata,omitempty"` // Replaces the metadata; null clears it
 }
 
 // DropResponse defines the structure for drop responses.
 type DropResponse struct {
 	ID           uuid.UUID  `json:"id"`
+	WorkspaceID  *uuid.UUID `json:"workspace_id"`
 	Topic        string     `json:"topic"`
 	URL          string     `json:"url"`
 	UserNotes    *string    `json:"user_notes"` // Removed omitempty
+	Excerpt      *string    `json:"excerpt"`
 	AddedDate    time.Time  `json:"added_date"`
 	UpdatedAt    time.Time  `json:"updated_at"`
 	Status       string     `json:"status"`
 	LastSentDate *time.Time `json:"last_sent_date"` // Removed omitempty
 	SendCount    int32      `json:"send_count"`
-	Priority     *int32     `json:"priority"` // Removed omitempty
-	Tags         []string   `json:"tags"`     // Removed omitempty
+	MaxSendCount *int       `json:"max_send_count"` // Sends before the drop graduates, null without a cap or for permanent drops
+	NextSendDate *time.Time `json:"next_send_date"`
+	DueDate      *string    `json:"due_date"` // YYYY-MM-DD the next send is pinned to, see DropDueDateHandler
+	EaseFactor   float64    `json:"ease_factor"`
+	IntervalDays *int32     `json:"interval_days"` // Days waited after the last review with an outcome, null before the first
+	Priority     *int32     `json:"priority"`      // Removed omitempty
+	Schedule     *string    `json:"schedule"`
+	Permanent    bool       `json:"permanent"`
+	Channel      string     `json:"channel"`
+	Tags         []string   `json:"tags"` // Removed omitempty
+
+	Metadata json.RawMessage `json:"metadata"`
+
+	// Only with ?render=html: the notes rendered from markdown and sanitized
+	UserNotesHTML *string `json:"user_notes_html,omitempty"`
+	// Only with ?include=collections: the user's collections containing the drop
+	Collections []DropCollectionResponse `json:"collections,omitzero"`
+
+	// Computed in the request's time zone (see middleware.TimezoneMiddleware)
+	NextSendLocalDate *string `json:"next_send_local_date"` // YYYY-MM-DD
+	DueToday          bool    `json:"due_today"`
+
+	apiVersion int // Picks the shape MarshalJSON writes
 }
 
-// toDropResponse converts a db.Drop and its tag names to a DropResponse.
-func toDropResponse(drop db.Drop, tagNames []string) DropResponse { // Ensure tagNames is actually []string
+// toDropResponse converts a db.Drop and its tag names to a DropResponse for request r.
+// Timestamps stay in UTC; the local fields are computed in the request's time zone, and the
+// response serializes in the API version the request negotiated (see drops_versions.go).
+// maxSendCount is the MAX_SEND_COUNT send cap, 0 when there is none.
+func toDropResponse(drop db.Drop, tagNames []string, r *http.Request, maxSendCount int) DropResponse { // Ensure tagNames is actually []string
+	loc := middleware.GetTimezoneFromContext(r)
+
 	var userNotes *string
 	if drop.UserNotes.Valid {
 		userNotes = &drop.UserNotes.String
 	}
 
+	var excerpt *string
+	if drop.Excerpt.Valid {
+		excerpt = &drop.Excerpt.String
+	}
+
 	var lastSentDate *time.Time
 	if drop.LastSentDate.Valid {
-		lastSentDate = &drop.LastSentDate.Time
+		sent := drop.LastSentDate.Time.UTC()
+		lastSentDate = &sent
+	}
+
+	var workspaceID *uuid.UUID
+	if drop.WorkspaceID.Valid {
+		workspaceID = &drop.WorkspaceID.UUID
+	}
+
+	var nextSendDate *time.Time
+	var nextSendLocalDate *string
+	if drop.NextSendDate.Valid {
+		next := drop.NextSendDate.Time.UTC()
+		nextSendDate = &next
+		localDate := next.In(loc).Format(time.DateOnly)
+		nextSendLocalDate = &localDate
+	}
+
+	var dueDate *string
+	if drop.DueDate.Valid {
+		date := drop.DueDate.Time.Format(time.DateOnly)
+		dueDate = &date
+	}
+
+	var intervalDays *int32
+	if drop.IntervalDays.Valid {
+		intervalDays = &drop.IntervalDays.Int32
 	}
 
 	var priority *int32
@@ -76,28 +208,222 @@ func toDropResponse(drop db.Drop, tagNames []string) DropResponse { // Ensure ta
 		priority = &drop.Priority.Int32
 	}
 
+	var dropSchedule *string
+	if drop.Schedule.Valid {
+		dropSchedule = &drop.Schedule.String
+	}
+
+	var sendCap *int
+	if maxSendCount > 0 && !drop.Permanent {
+		sendCap = &maxSendCount
+	}
+
 	processedTags := tagNames
 	if processedTags == nil {
 		processedTags = []string{} // Ensures tags field is an empty array instead of null if no tags
 	}
 
+	dropMetadata := drop.Metadata
+	if len(dropMetadata) == 0 {
+		dropMetadata = emptyDropMetadata
+	}
+
 	return DropResponse{
 		ID:           drop.ID,
+		WorkspaceID:  workspaceID,
 		Topic:        drop.Topic,
 		URL:          drop.Url, // db.Drop uses 'Url', mapping to 'URL' in response
 		UserNotes:    userNotes,
-		AddedDate:    drop.AddedDate,
-		UpdatedAt:    drop.UpdatedAt,
+		Excerpt:      excerpt,
+		AddedDate:    drop.AddedDate.UTC(), // Always serialize timestamps in UTC (RFC3339 with a Z suffix)
+		UpdatedAt:    drop.UpdatedAt.UTC(),
 		Status:       drop.Status,
 		LastSentDate: lastSentDate,
 		SendCount:    drop.SendCount,
+		MaxSendCount: sendCap,
+		NextSendDate: nextSendDate,
+		DueDate:      dueDate,
+		EaseFactor:   drop.EaseFactor,
+		IntervalDays: intervalDays,
 		Priority:     priority,
+		Schedule:     dropSchedule,
+		Permanent:    drop.Permanent,
+		Channel:      drop.Channel,
 		Tags:         processedTags,
+
+		Metadata: dropMetadata,
+
+		NextSendLocalDate: nextSendLocalDate,
+		DueToday:          dropDueBy(drop, schedule.StartOfNextDay(time.Now(), loc)),
+
+		apiVersion: middleware.GetAPIVersionFromContext(r),
+	}
+}
+
+// dropDueBy reports whether a drop will be due before t, following the worker's rules:
+// a 'new' drop without a next send date is due immediately, archived and snoozed drops never are.
+func dropDueBy(drop db.Drop, t time.Time) bool {
+	switch drop.Status {
+	case "new":
+		return !drop.NextSendDate.Valid || drop.NextSendDate.Time.Before(t)
+	case "sent":
+		return drop.NextSendDate.Valid && drop.NextSendDate.Time.Before(t)
+	default:
+		return false
+	}
+}
+
+// dropTagNames fetches the names of the tags associated with a drop.
+// Errors are logged and result in an empty tag list so a response can still be returned.
+func (h *DropsHandler) dropTagNames(r *http.Request, dropID uuid.UUID) []string {
+	dbTags, err := h.APIConfig.DB.GetTagsForDrop(r.Context(), dropID)
+	if err != nil {
+		log.Printf("Error fetching tags for drop %s: %v", dropID, err)
+		return nil
+	}
+	tagNames := make([]string, 0, len(dbTags))
+	for _, tag := range dbTags {
+		tagNames = append(tagNames, tag.Name)
+	}
+	return tagNames
+}
+
+// attachTags associates the named tags with a drop, creating any tag that doesn't exist yet.
+// CreateTag is an upsert, so an existing tag (even one created concurrently) is returned rather
+// than failing on the unique constraint. Blank and duplicate names are skipped, and per-tag
+// errors are logged without aborting. It returns the names of the tags that were attached.
+func (h *DropsHandler) attachTags(r *http.Request, dropID uuid.UUID, tagNames []string) []string {
+	var attached []string
+	seen := make(map[string]bool, len(tagNames))
+	for _, tagName := range tagNames {
+		trimmedTagName := strings.TrimSpace(tagName)
+		if trimmedTagName == "" || seen[trimmedTagName] {
+			continue
+		}
+		seen[trimmedTagName] = true
+
+		tag, err := h.APIConfig.DB.CreateTag(r.Context(), trimmedTagName)
+		if err != nil {
+			log.Printf("Error creating/getting tag '%s' for drop %s: %v", trimmedTagName, dropID, err)
+			continue
+		}
+
+		err = h.APIConfig.DB.AddTagToDrop(r.Context(), db.AddTagToDropParams{
+			DropsID: dropID,
+			TagID:   tag.ID,
+		})
+		if err != nil {
+			log.Printf("Error associating tag '%s' (ID: %d) with drop '%s': %v", tag.Name, tag.ID, dropID, err)
+			continue
+		}
+		attached = append(attached, tag.Name)
+	}
+	return attached
+}
+
+// dropInWorkspace reports whether a drop belongs to the active workspace.
+// An invalid workspaceID stands for the personal space, which holds drops without a workspace.
+func dropInWorkspace(drop db.Drop, workspaceID uuid.NullUUID) bool {
+	return sameWorkspace(drop.WorkspaceID, workspaceID)
+}
+
+// sameWorkspace reports whether two workspace IDs refer to the same workspace,
+// treating two invalid IDs as the same personal space.
+func sameWorkspace(a, b uuid.NullUUID) bool {
+	return a.Valid == b.Valid && (!a.Valid || a.UUID == b.UUID)
+}
+
+// parseDropSchedule validates a cron schedule and returns the first time it fires
+// in the user's time zone loc. On failure it writes a 400 response and returns false.
+func parseDropSchedule(w http.ResponseWriter, expr string, loc *time.Location) (sql.NullTime, bool) {
+	cron, err := schedule.ParseCron(expr)
+	if err != nil {
+		httputils.RespondWithError(w, http.StatusBadRequest, "Invalid schedule: "+err.Error()+". "+schedule.CronSyntaxHelp)
+		return sql.NullTime{}, false
+	}
+	next, ok := cron.NextIn(time.Now(), loc)
+	if !ok {
+		httputils.RespondWithError(w, http.StatusBadRequest, "Invalid schedule: it never matches a date. "+schedule.CronSyntaxHelp)
+		return sql.NullTime{}, false
+	}
+	return sql.NullTime{Time: next, Valid: true}, true
+}
+
+// permanentRestartDate returns the next send date for a drop that is being made permanent
+// after its repetition sequence already ended, putting it back on the longest interval.
+// For any other drop it returns an invalid NullTime, leaving the schedule untouched.
+func permanentRestartDate(drop db.Drop, intervals schedule.Intervals) sql.NullTime {
+	if drop.Status != "sent" || drop.NextSendDate.Valid {
+		return sql.NullTime{}
+	}
+	from := time.Now()
+	if drop.LastSentDate.Valid {
+		from = drop.LastSentDate.Time
+	}
+	next, ok := intervals.NextSendDate(drop.SendCount+1, from, true)
+	if !ok {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: next, Valid: true}
+}
+
+// userIntervals loads the user's spaced-repetition sequence from their preferences.
+// On failure it writes a 500 response and returns false.
+func (h *DropsHandler) userIntervals(w http.ResponseWriter, r *http.Request, userUUID uuid.UUID) (schedule.Intervals, bool) {
+	prefs, err := getPreferences(r, h.APIConfig.DB, userUUID)
+	if err != nil {
+		log.Printf("Error fetching preferences for UserUUID %s: %v", userUUID, err)
+		httputils.RespondWithServerError(w, err, "Failed to fetch preferences: "+err.Error())
+		return nil, false
+	}
+	return schedule.NewIntervals(prefs.RepetitionIntervals), true
+}
+
+// getOwnedDrop parses the {id} path value and loads the drop, checking it belongs to userUUID
+// and to the active workspace.
+// On failure it writes the appropriate error response and returns false.
+func (h *DropsHandler) getOwnedDrop(w http.ResponseWriter, r *http.Request, userUUID uuid.UUID) (db.Drop, bool) {
+	dropIDStr := r.PathValue("id")
+	if dropIDStr == "" {
+		httputils.RespondWithError(w, http.StatusBadRequest, "Drop ID is required in the path")
+		return db.Drop{}, false
+	}
+
+	dropID, err := uuid.Parse(dropIDStr)
+	if err != nil {
+		httputils.RespondWithError(w, http.StatusBadRequest, "Invalid Drop ID format: "+err.Error())
+		return db.Drop{}, false
+	}
+
+	drop, err := h.APIConfig.DB.GetDrop(r.Context(), dropID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			httputils.RespondWithError(w, http.StatusNotFound, "Drop not found")
+		} else {
+			log.Printf("Error fetching drop %s from database: %v", dropID, err)
+			httputils.RespondWithServerError(w, err, "Failed to fetch drop: "+err.Error())
+		}
+		return db.Drop{}, false
 	}
+
+	if !drop.UserUuid.Valid || drop.UserUuid.UUID != userUUID {
+		log.Printf("Authorization failed: User %s attempted to access drop %s owned by %s",
+			userUUID.String(), drop.ID.String(), drop.UserUuid.UUID.String())
+		httputils.RespondWithError(w, http.StatusForbidden, "Access to this drop is forbidden")
+		return db.Drop{}, false
+	}
+
+	if !dropInWorkspace(drop, middleware.GetWorkspaceIDFromContext(r)) {
+		httputils.RespondWithError(w, http.StatusNotFound, "Drop not found")
+		return db.Drop{}, false
+	}
+
+	return drop, true
 }
 
 // CreateDropHandler handles the creation of a new drop.
 // POST /api/v1/drops
+// The page description is fetched from the URL as the excerpt unless ?fetch_metadata=false is given.
 func (h *DropsHandler) CreateDropHandler(w http.ResponseWriter, r *http.Request) {
 	userUUID, ok := r.Context().Value(middleware.UserIDKey).(uuid.UUID) // Changed to match other handlers
 	if !ok {
@@ -105,9 +431,18 @@ func (h *DropsHandler) CreateDropHandler(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	fetchMetadata := true
+	if v := r.URL.Query().Get("fetch_metadata"); v != "" {
+		parsed, err := strconv.ParseBool(v)
+		if err != nil {
+			httputils.RespondWithError(w, http.StatusBadRequest, "Invalid fetch_metadata value, expected true or false")
+			return
+		}
+		fetchMetadata = parsed
+	}
+
 	var req CreateDropRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		httputils.RespondWithError(w, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+	if !httputils.DecodeJSONBody(w, r, &req) {
 		return
 	}
 	defer r.Body.Close()
@@ -120,11 +455,42 @@ func (h *DropsHandler) CreateDropHandler(w http.ResponseWriter, r *http.Request)
 		httputils.RespondWithError(w, http.StatusBadRequest, "URL cannot be empty")
 		return
 	}
+	if req.Channel == "" {
+		req.Channel = delivery.Default
+	}
+	if !delivery.IsValid(req.Channel, true) {
+		httputils.RespondWithError(w, http.StatusBadRequest, invalidDropChannelMessage)
+		return
+	}
+	dropMetadata, err := parseDropMetadata(req.Metadata)
+	if err != nil {
+		httputils.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var pageMeta metadata.PageMetadata
+	if fetchMetadata {
+		fetched, err := h.Fetcher.Fetch(r.Context(), req.URL)
+		if err != nil {
+			// Metadata is best-effort; the drop is still created without it.
+			if errors.Is(err, metadata.ErrBlockedAddress) {
+				log.Printf("Refused to fetch metadata for URL %s, it points to a blocked address: %v", req.URL, err)
+			} else {
+				log.Printf("Could not fetch metadata for URL %s: %v", req.URL, err)
+			}
+		} else {
+			pageMeta = fetched
+		}
+	}
 
 	params := db.CreateDropParams{
-		UserUuid: uuid.NullUUID{UUID: userUUID, Valid: true},
-		Topic:    req.Topic,
-		Url:      req.URL,
+		UserUuid:    uuid.NullUUID{UUID: userUUID, Valid: true},
+		Topic:       req.Topic,
+		Url:         req.URL,
+		WorkspaceID: middleware.GetWorkspaceIDFromContext(r),
+		Permanent:   req.Permanent,
+		Channel:     req.Channel,
+		Metadata:    dropMetadata,
 	}
 
 	if req.UserNotes != "" {
@@ -139,62 +505,39 @@ func (h *DropsHandler) CreateDropHandler(w http.ResponseWriter, r *http.Request)
 		params.Priority = sql.NullInt32{Valid: false}
 	}
 
+	if pageMeta.Description != "" {
+		params.Excerpt = sql.NullString{String: pageMeta.Description, Valid: true}
+	}
+
+	if strings.TrimSpace(req.Schedule) != "" {
+		nextSendDate, ok := parseDropSchedule(w, req.Schedule, middleware.GetUserTimezoneFromContext(r))
+		if !ok {
+			return
+		}
+		params.Schedule = sql.NullString{String: strings.TrimSpace(req.Schedule), Valid: true}
+		params.NextSendDate = nextSendDate
+	}
+
 	log.Printf("Attempting to create drop for UserUUID: %s, Topic: %s", userUUID, params.Topic)
 
 	createdDrop, err := h.APIConfig.DB.CreateDrop(r.Context(), params)
 	if err != nil {
 		log.Printf("Error creating drop in database: %v", err)
-		httputils.RespondWithError(w, http.StatusInternalServerError, "Failed to create drop: "+err.Error())
+		httputils.RespondWithServerError(w, err, "Failed to create drop: "+err.Error())
 		return // Added missing return
 	}
 
 	// Handle Tags
-	var tagNamesForResponse []string
-	if len(req.Tags) > 0 {
-		for _, tagName := range req.Tags {
-			trimmedTagName := strings.TrimSpace(tagName)
-			if trimmedTagName == "" {
-				continue
-			}
-
-			// Attempt to find the tag or create it if it doesn't exist
-			tag, err := h.APIConfig.DB.GetTagByName(r.Context(), trimmedTagName)
-			if err != nil {
-				if err == sql.ErrNoRows {
-					log.Printf("Tag '%s' not found, creating new tag.", trimmedTagName)
-					createdTag, createErr := h.APIConfig.DB.CreateTag(r.Context(), trimmedTagName)
-					if createErr != nil {
-						log.Printf("Error creating tag '%s': %v", trimmedTagName, createErr)
-						// Decide if this should be a fatal error or just skip the tag
-						// For now, we'll skip this tag and continue with others.
-						continue
-					}
-					tag = createdTag
-				} else {
-					log.Printf("Error retrieving tag '%s': %v", trimmedTagName, err)
-					continue // Skip this tag
-				}
-			}
-
-			// Associate tag with the drop
-			err = h.APIConfig.DB.AddTagToDrop(r.Context(), db.AddTagToDropParams{ // Changed from AddDropTag to AddTagToDrop
-				DropsID: createdDrop.ID,
-				TagID:   tag.ID,
-			})
-			if err != nil {
-				log.Printf("Error associating tag '%s' (ID: %d) with drop '%s': %v", tag.Name, tag.ID, createdDrop.ID, err)
-				// Decide if this should be a fatal error. For now, log and continue.
-				// We might still want to add the tag name to the response if it was intended.
-			}
-			tagNamesForResponse = append(tagNamesForResponse, tag.Name)
-		}
-	}
+	tagNamesForResponse := h.attachTags(r, createdDrop.ID, req.Tags)
+	h.publishDropEvent(userUUID, events.DropCreated, createdDrop)
+	h.APIConfig.DropCreationMonitor.Observe(r.Context(), userUUID, 1)
 
-	response := toDropResponse(createdDrop, tagNamesForResponse)
+	response := toDropResponse(createdDrop, tagNamesForResponse, r, h.APIConfig.MaxSendCount)
 	httputils.RespondWithJSON(w, http.StatusCreated, response)
 }
 
 // GetDropHandler handles fetching a specific drop.
+// ?include=collections adds the collections it belongs to (see parseDropIncludes).
 // GET /api/v1/drops/{id}
 func (h *DropsHandler) GetDropHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
@@ -209,6 +552,26 @@ func (h *DropsHandler) GetDropHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	fields, ok := parseDropFields(w, r)
+	if !ok {
+		return
+	}
+
+	includes, ok := parseDropIncludes(w, r)
+	if !ok {
+		return
+	}
+
+	renderHTML := false
+	switch r.URL.Query().Get("render") {
+	case "":
+	case "html":
+		renderHTML = true
+	default:
+		httputils.RespondWithError(w, http.StatusBadRequest, "Invalid render value. Allowed: html.")
+		return
+	}
+
 	dropIDStr := r.PathValue("id")
 	if dropIDStr == "" {
 		httputils.RespondWithError(w, http.StatusBadRequest, "Drop ID is required in the path")
@@ -230,7 +593,7 @@ func (h *DropsHandler) GetDropHandler(w http.ResponseWriter, r *http.Request) {
 			httputils.RespondWithError(w, http.StatusNotFound, "Drop not found")
 		} else {
 			log.Printf("Error fetching drop from database: %v", err)
-			httputils.RespondWithError(w, http.StatusInternalServerError, "Failed to fetch drop: "+err.Error())
+			httputils.RespondWithServerError(w, err, "Failed to fetch drop: "+err.Error())
 		}
 		return
 	}
@@ -242,6 +605,11 @@ func (h *DropsHandler) GetDropHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !dropInWorkspace(drop, middleware.GetWorkspaceIDFromContext(r)) {
+		httputils.RespondWithError(w, http.StatusNotFound, "Drop not found")
+		return
+	}
+
 	tags, err := h.APIConfig.DB.GetTagsForDrop(r.Context(), drop.ID)
 	if err != nil {
 		log.Printf("Error fetching tags for drop %s: %v", drop.ID, err)
@@ -256,11 +624,89 @@ func (h *DropsHandler) GetDropHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	log.Printf("Successfully fetched drop with ID: %s and %d tags", drop.ID.String(), len(tagNamesForResponse))
-	response := toDropResponse(drop, tagNamesForResponse)
+	dropResponse := toDropResponse(drop, tagNamesForResponse, r, h.APIConfig.MaxSendCount)
+	if renderHTML && drop.UserNotes.Valid {
+		notesHTML, err := markdown.ToHTML(drop.UserNotes.String)
+		if err != nil {
+			log.Printf("Error rendering notes of drop %s as markdown: %v", drop.ID, err)
+			httputils.RespondWithServerError(w, err, "Failed to render notes: "+err.Error())
+			return
+		}
+		dropResponse.UserNotesHTML = &notesHTML
+	}
+	if includes.Collections {
+		dropResponses := []DropResponse{dropResponse}
+		if err := addDropCollections(r, h.APIConfig.DB, userUUID, dropResponses); err != nil {
+			log.Printf("Error fetching collections of drop %s: %v", drop.ID, err)
+			httputils.RespondWithServerError(w, err, "Failed to fetch drop collections: "+err.Error())
+			return
+		}
+		dropResponse = dropResponses[0]
+	}
+	response, err := projectDrop(dropResponse, fields)
+	if err != nil {
+		log.Printf("Error selecting fields of drop %s: %v", drop.ID, err)
+		httputils.RespondWithServerError(w, err, "Failed to fetch drop: "+err.Error())
+		return
+	}
+	httputils.RespondWithJSON(w, http.StatusOK, response)
+}
+
+// GetDropByURLHandler returns the user's drop saved with the given URL in the active
+// workspace, so clients can tell whether a page was already saved. The URL must match the
+// one the drop was created with.
+// ?fields= limits the drop to the named fields (see parseDropFields).
+// GET /api/v1/drops/by-url?url=
+func (h *DropsHandler) GetDropByURLHandler(w http.ResponseWriter, r *http.Request) {
+	userUUID, ok := r.Context().Value(middleware.UserIDKey).(uuid.UUID)
+	if !ok {
+		log.Printf("GetDropByURLHandler: UserID not found in context or not a UUID for path %s", r.URL.Path)
+		httputils.RespondWithError(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	fields, ok := parseDropFields(w, r)
+	if !ok {
+		return
+	}
+
+	dropURL := r.URL.Query().Get("url")
+	if strings.TrimSpace(dropURL) == "" {
+		httputils.RespondWithError(w, http.StatusBadRequest, "The url query parameter is required")
+		return
+	}
+
+	drop, err := h.APIConfig.DB.GetDropByURLForUser(r.Context(), db.GetDropByURLForUserParams{
+		Url:         dropURL,
+		UserUuid:    uuid.NullUUID{UUID: userUUID, Valid: true},
+		WorkspaceID: middleware.GetWorkspaceIDFromContext(r),
+	})
+	if err == sql.ErrNoRows {
+		httputils.RespondWithError(w, http.StatusNotFound, "Drop not found")
+		return
+	}
+	if err != nil {
+		log.Printf("Error fetching drop by URL for UserUUID %s: %v", userUUID, err)
+		httputils.RespondWithServerError(w, err, "Failed to fetch drop: "+err.Error())
+		return
+	}
+
+	response, err := projectDrop(toDropResponse(drop, h.tagNamesForDrops(r, []uuid.UUID{drop.ID})[drop.ID], r, h.APIConfig.MaxSendCount), fields)
+	if err != nil {
+		log.Printf("Error selecting fields of drop %s: %v", drop.ID, err)
+		httputils.RespondWithServerError(w, err, "Failed to fetch drop: "+err.Error())
+		return
+	}
 	httputils.RespondWithJSON(w, http.StatusOK, response)
 }
 
 // ListDropsHandler handles fetching all drops for the authenticated user.
+// ?collection_id= limits the list to the drops in one collection, ?metadata.<key>=
+// to drops with that metadata (see parseDropMetadataFilter).
+// ?updated_since= returns only the changes since then instead (see listDropChanges).
+// The list is returned as CSV when the Accept header prefers text/csv.
+// ?fields= limits each drop to the named fields (see parseDropFields), and
+// ?include=collections adds the collections each belongs to with one batched query.
 // GET /api/v1/drops
 func (h *DropsHandler) ListDropsHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
@@ -275,12 +721,72 @@ func (h *DropsHandler) ListDropsHandler(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	format := httputils.NegotiateFormat(w, r, httputils.FormatJSON, httputils.FormatCSV)
+	fields, ok := parseDropFields(w, r)
+	if !ok {
+		return
+	}
+	includes, ok := parseDropIncludes(w, r)
+	if !ok {
+		return
+	}
+	metadataFilter, err := parseDropMetadataFilter(r.URL.Query())
+	if err != nil {
+		httputils.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if v := r.URL.Query().Get("updated_since"); v != "" {
+		since, err := time.Parse(time.RFC3339Nano, v)
+		if err != nil {
+			httputils.RespondWithError(w, http.StatusBadRequest, "Invalid updated_since value, expected an RFC 3339 timestamp")
+			return
+		}
+		if r.URL.Query().Has("collection_id") {
+			httputils.RespondWithError(w, http.StatusBadRequest, "updated_since cannot be combined with collection_id")
+			return
+		}
+		if format == httputils.FormatCSV {
+			httputils.RespondWithError(w, http.StatusNotAcceptable, "updated_since is only available as application/json")
+			return
+		}
+		if fields != nil {
+			httputils.RespondWithError(w, http.StatusBadRequest, "updated_since cannot be combined with fields")
+			return
+		}
+		if metadataFilter.Valid {
+			httputils.RespondWithError(w, http.StatusBadRequest, "updated_since cannot be combined with metadata filters")
+			return
+		}
+		if includes.Collections {
+			httputils.RespondWithError(w, http.StatusBadRequest, "updated_since cannot be combined with include")
+			return
+		}
+		h.listDropChanges(w, r, userUUID, since)
+		return
+	}
+
+	var collectionID uuid.NullUUID
+	if v := r.URL.Query().Get("collection_id"); v != "" {
+		parsed, err := uuid.Parse(v)
+		if err != nil {
+			httputils.RespondWithError(w, http.StatusBadRequest, "Invalid collection_id format: "+err.Error())
+			return
+		}
+		collectionID = uuid.NullUUID{UUID: parsed, Valid: true}
+	}
+
 	log.Printf("Attempting to list drops for UserUUID: %s", userUUID.String())
 
-	drops, err := h.APIConfig.DB.ListDropsByUserUUID(r.Context(), uuid.NullUUID{UUID: userUUID, Valid: true})
+	drops, err := h.APIConfig.DBRead.ListDropsByUserUUID(r.Context(), db.ListDropsByUserUUIDParams{
+		UserUuid:     uuid.NullUUID{UUID: userUUID, Valid: true},
+		WorkspaceID:  middleware.GetWorkspaceIDFromContext(r),
+		CollectionID: collectionID,
+		Metadata:     metadataFilter,
+	})
 	if err != nil {
 		log.Printf("Error fetching drops from database for UserUUID %s: %v", userUUID.String(), err)
-		httputils.RespondWithError(w, http.StatusInternalServerError, "Failed to fetch drops: "+err.Error())
+		httputils.RespondWithServerError(w, err, "Failed to fetch drops: "+err.Error())
 		return
 	}
 
@@ -290,7 +796,7 @@ func (h *DropsHandler) ListDropsHandler(w http.ResponseWriter, r *http.Request)
 
 	dropResponses := make([]DropResponse, 0, len(drops))
 	for _, drop := range drops {
-		dbTags, err := h.APIConfig.DB.GetTagsForDrop(r.Context(), drop.ID)
+		dbTags, err := h.APIConfig.DBRead.GetTagsForDrop(r.Context(), drop.ID)
 		var tagNamesForDrop []string
 		if err != nil {
 			log.Printf("Error fetching tags for drop %s during list operation: %v. Proceeding with empty tags for this drop.", drop.ID, err)
@@ -300,11 +806,29 @@ func (h *DropsHandler) ListDropsHandler(w http.ResponseWriter, r *http.Request)
 				tagNamesForDrop = append(tagNamesForDrop, tag.Name) // Assuming db.Tag has a Name field
 			}
 		}
-		dropResponses = append(dropResponses, toDropResponse(drop, tagNamesForDrop))
+		dropResponses = append(dropResponses, toDropResponse(drop, tagNamesForDrop, r, h.APIConfig.MaxSendCount))
+	}
+
+	if includes.Collections && len(dropResponses) > 0 {
+		if err := addDropCollections(r, h.APIConfig.DBRead, userUUID, dropResponses); err != nil {
+			log.Printf("Error fetching collections of drops for UserUUID %s: %v", userUUID.String(), err)
+			httputils.RespondWithServerError(w, err, "Failed to fetch drop collections: "+err.Error())
+			return
+		}
 	}
 
 	log.Printf("Successfully fetched %d drops for UserUUID: %s", len(dropResponses), userUUID.String())
-	httputils.RespondWithJSON(w, http.StatusOK, dropResponses)
+	if format == httputils.FormatCSV {
+		respondWithDropsCSV(w, dropResponses, fields)
+		return
+	}
+	response, err := projectDrops(dropResponses, fields)
+	if err != nil {
+		log.Printf("Error selecting fields of drops for UserUUID %s: %v", userUUID.String(), err)
+		httputils.RespondWithServerError(w, err, "Failed to fetch drops: "+err.Error())
+		return
+	}
+	httputils.RespondWithJSON(w, http.StatusOK, response)
 }
 
 // UpdateDropHandler handles updating an existing drop.
@@ -335,8 +859,7 @@ func (h *DropsHandler) UpdateDropHandler(w http.ResponseWriter, r *http.Request)
 	}
 
 	var req UpdateDropRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		httputils.RespondWithError(w, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+	if !httputils.DecodeJSONBody(w, r, &req) {
 		return
 	}
 	defer r.Body.Close()
@@ -353,7 +876,7 @@ func (h *DropsHandler) UpdateDropHandler(w http.ResponseWriter, r *http.Request)
 			httputils.RespondWithError(w, http.StatusNotFound, "Drop not found")
 		} else {
 			log.Printf("Error checking drop existence before update: %v", err)
-			httputils.RespondWithError(w, http.StatusInternalServerError, "Failed to update drop: "+err.Error())
+			httputils.RespondWithServerError(w, err, "Failed to update drop: "+err.Error())
 		}
 		return
 	}
@@ -365,6 +888,11 @@ func (h *DropsHandler) UpdateDropHandler(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	if !dropInWorkspace(existingDrop, middleware.GetWorkspaceIDFromContext(r)) {
+		httputils.RespondWithError(w, http.StatusNotFound, "Drop not found")
+		return
+	}
+
 	params := db.UpdateDropParams{
 		ID:       dropID,
 		UserUuid: uuid.NullUUID{UUID: userUUID, Valid: true},
@@ -391,13 +919,52 @@ func (h *DropsHandler) UpdateDropHandler(w http.ResponseWriter, r *http.Request)
 		params.Priority = sql.NullInt32{Int32: *req.Priority, Valid: true}
 	}
 	if req.Status != nil {
-		validStatuses := map[string]bool{"new": true, "sent": true, "archived": true, "snoozed": true}
-		if !validStatuses[*req.Status] {
-			httputils.RespondWithError(w, http.StatusBadRequest, "Invalid status value. Allowed: new, sent, archived, snoozed.")
+		if !validDropStatuses[*req.Status] {
+			httputils.RespondWithError(w, http.StatusBadRequest, invalidDropStatusMessage)
+			return
+		}
+		if !canTransitionDropStatus(existingDrop.Status, *req.Status) {
+			respondIllegalStatusTransition(w, existingDrop.ID, existingDrop.Status, *req.Status)
 			return
 		}
 		params.Status = sql.NullString{String: *req.Status, Valid: true}
 	}
+	if req.Schedule != nil {
+		trimmed := strings.TrimSpace(*req.Schedule)
+		if trimmed != "" {
+			nextSendDate, ok := parseDropSchedule(w, trimmed, middleware.GetUserTimezoneFromContext(r))
+			if !ok {
+				return
+			}
+			params.NextSendDate = nextSendDate
+		}
+		params.Schedule = sql.NullString{String: trimmed, Valid: true} // Empty clears the schedule
+	}
+	if req.Permanent != nil {
+		params.Permanent = sql.NullBool{Bool: *req.Permanent, Valid: true}
+		if *req.Permanent && !params.NextSendDate.Valid {
+			intervals, ok := h.userIntervals(w, r, userUUID)
+			if !ok {
+				return
+			}
+			params.NextSendDate = permanentRestartDate(existingDrop, intervals)
+		}
+	}
+	if req.Channel != nil {
+		if !delivery.IsValid(*req.Channel, true) {
+			httputils.RespondWithError(w, http.StatusBadRequest, invalidDropChannelMessage)
+			return
+		}
+		params.Channel = sql.NullString{String: *req.Channel, Valid: true}
+	}
+	if req.Metadata != nil {
+		dropMetadata, err := parseDropMetadata(req.Metadata)
+		if err != nil {
+			httputils.RespondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		params.Metadata = pqtype.NullRawMessage{RawMessage: dropMetadata, Valid: true}
+	}
 
 	updatedDrop, err := h.APIConfig.DB.UpdateDrop(r.Context(), params)
 	if err != nil {
@@ -408,7 +975,7 @@ func (h *DropsHandler) UpdateDropHandler(w http.ResponseWriter, r *http.Request)
 			httputils.RespondWithError(w, http.StatusNotFound, "Drop not found or not authorized to update")
 		} else {
 			log.Printf("Error updating drop in database: %v", err)
-			httputils.RespondWithError(w, http.StatusInternalServerError, "Failed to update drop: "+err.Error())
+			httputils.RespondWithServerError(w, err, "Failed to update drop: "+err.Error())
 		}
 		return
 	}
@@ -421,26 +988,7 @@ func (h *DropsHandler) UpdateDropHandler(w http.ResponseWriter, r *http.Request)
 			// Continue to add new tags even if removal failed, though this might lead to duplicates if not handled.
 		}
 
-		if len(*req.Tags) > 0 {
-			for _, tagName := range *req.Tags {
-				trimmedTagName := strings.TrimSpace(tagName)
-				if trimmedTagName == "" {
-					continue
-				}
-				tag, err := h.APIConfig.DB.CreateTag(r.Context(), trimmedTagName)
-				if err != nil {
-					log.Printf("Error creating/getting tag '%s' for drop %s: %v", trimmedTagName, dropID, err)
-					continue
-				}
-				err = h.APIConfig.DB.AddTagToDrop(r.Context(), db.AddTagToDropParams{
-					DropsID: dropID,
-					TagID:   tag.ID,
-				})
-				if err != nil {
-					log.Printf("Error associating tag '%s' (ID: %d) with drop '%s': %v", trimmedTagName, tag.ID, dropID, err)
-				}
-			}
-		}
+		h.attachTags(r, dropID, *req.Tags)
 		log.Printf("Finished updating tags for drop ID: %s", dropID.String())
 	}
 
@@ -457,7 +1005,11 @@ func (h *DropsHandler) UpdateDropHandler(w http.ResponseWriter, r *http.Request)
 	}
 
 	log.Printf("Successfully updated drop with ID: %s and its tags", updatedDrop.ID.String())
-	response := toDropResponse(updatedDrop, finalTagNamesForResponse)
+	if updatedDrop.Status != existingDrop.Status {
+		recordReview(r, h.APIConfig.DB, userUUID, updatedDrop.Status)
+	}
+	h.publishDropEvent(userUUID, events.DropUpdated, updatedDrop)
+	response := toDropResponse(updatedDrop, finalTagNamesForResponse, r, h.APIConfig.MaxSendCount)
 	httputils.RespondWithJSON(w, http.StatusOK, response)
 }
 
@@ -497,7 +1049,7 @@ func (h *DropsHandler) DeleteDropHandler(w http.ResponseWriter, r *http.Request)
 			httputils.RespondWithError(w, http.StatusNotFound, "Drop not found")
 		} else {
 			log.Printf("Error checking drop existence before delete: %v", err)
-			httputils.RespondWithError(w, http.StatusInternalServerError, "Failed to delete drop: "+err.Error())
+			httputils.RespondWithServerError(w, err, "Failed to delete drop: "+err.Error())
 		}
 		return
 	}
@@ -509,6 +1061,11 @@ func (h *DropsHandler) DeleteDropHandler(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	if !dropInWorkspace(existingDrop, middleware.GetWorkspaceIDFromContext(r)) {
+		httputils.RespondWithError(w, http.StatusNotFound, "Drop not found")
+		return
+	}
+
 	// Assuming DeleteDrop in DB expects params for ID and UserUuid for row-level security/check
 	deleteParams := db.DeleteDropParams{
 		ID:       dropID,
@@ -522,11 +1079,16 @@ func (h *DropsHandler) DeleteDropHandler(w http.ResponseWriter, r *http.Request)
 			httputils.RespondWithError(w, http.StatusNotFound, "Drop not found or not authorized to delete")
 		} else {
 			log.Printf("Error deleting drop from database: %v", err)
-			httputils.RespondWithError(w, http.StatusInternalServerError, "Failed to delete drop: "+err.Error())
+			httputils.RespondWithServerError(w, err, "Failed to delete drop: "+err.Error())
 		}
 		return
 	}
 
 	log.Printf("Successfully deleted drop with ID: %s", dropID.String())
+	h.publishDropEvent(userUUID, events.DropDeleted, existingDrop)
+	h.APIConfig.Audit.Record(r.Context(), audit.ActionDropDeleted, userUUID, audit.Metadata{
+		Target:  dropID.String(),
+		Details: map[string]any{"url": existingDrop.Url},
+	})
 	httputils.RespondWithJSON(w, http.StatusNoContent, nil)
 }
@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/nouvadev/dropwise/internal/config"
+	db "github.com/nouvadev/dropwise/internal/database/sqlc"
+	"github.com/nouvadev/dropwise/internal/testdb"
+)
+
+func TestAttachTagsConcurrentlyCreatesOneTag(t *testing.T) {
+	apiCfg, database := newTestConfig(t)
+	h := &DropsHandler{APIConfig: apiCfg}
+	userID := testdb.CreateUser(t, database.Queries)
+
+	const workers = 20
+	drops := make([]db.Drop, workers)
+	for i := range drops {
+		drops[i] = testdb.CreateDrop(t, database.Queries, userID, nil)
+	}
+
+	var wg sync.WaitGroup
+	attached := make([][]string, workers)
+	for i, drop := range drops {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			attached[i] = h.attachTags(httptest.NewRequest("POST", "/api/v1/drops", nil), drop.ID, []string{"golang"})
+		}()
+	}
+	wg.Wait()
+
+	for i, names := range attached {
+		if len(names) != 1 || names[0] != "golang" {
+			t.Errorf("drop %d: attached %v, want [golang]", i, names)
+		}
+	}
+	var count int
+	if err := database.Conn.QueryRow("SELECT COUNT(*) FROM tags WHERE name = 'golang'").Scan(&count); err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 {
+		t.Errorf("%d tag rows named golang, want 1", count)
+	}
+}
+
+func TestCreateTagConcurrentlyReturnsTheSameTag(t *testing.T) {
+	_, database := newTestConfig(t)
+
+	const workers = 20
+	ids := make([]int32, workers)
+	errs := make([]error, workers)
+	var wg sync.WaitGroup
+	for i := range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			tag, err := database.Queries.CreateTag(context.Background(), "rust")
+			ids[i], errs[i] = tag.ID, err
+		}()
+	}
+	wg.Wait()
+
+	for i := range workers {
+		if errs[i] != nil {
+			t.Fatalf("CreateTag() error = %v", errs[i])
+		}
+		if ids[i] != ids[0] {
+			t.Errorf("CreateTag() returned tag %d and %d for the same name", ids[0], ids[i])
+		}
+	}
+}
+
+func TestCanTransitionDropStatus(t *testing.T) {
+	tests := []struct {
+		from, to string
+		want     bool
+	}{
+		{"new", "sent", true},
+		{"new", "archived", true},
+		{"sent", "snoozed", true},
+		{"snoozed", "new", true},
+		{"archived", "new", true},
+		{"graduated", "new", true},
+		{"graduated", "archived", true},
+		{"archived", "sent", false},
+		{"archived", "snoozed", false},
+		{"sent", "new", false},
+		{"graduated", "sent", false},
+		{"new", "graduated", false},
+		{"new", "new", true},
+		{"archived", "archived", true},
+		{"graduated", "graduated", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.from+"->"+tt.to, func(t *testing.T) {
+			if got := canTransitionDropStatus(tt.from, tt.to); got != tt.want {
+				t.Errorf("canTransitionDropStatus(%q, %q) = %v, want %v", tt.from, tt.to, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStatusesTransitioningTo(t *testing.T) {
+	got := statusesTransitioningTo("sent")
+	for _, status := range []string{"new", "sent"} {
+		if !slices.Contains(got, status) {
+			t.Errorf("statusesTransitioningTo(sent) = %v, missing %q", got, status)
+		}
+	}
+	for _, status := range []string{"archived", "graduated", "snoozed"} {
+		if slices.Contains(got, status) {
+			t.Errorf("statusesTransitioningTo(sent) = %v, includes %q", got, status)
+		}
+	}
+}
+
+func TestCreateDropRequiresTopic(t *testing.T) {
+	// No fetcher and no database: the topic must be rejected before either is used.
+	h := &DropsHandler{APIConfig: &config.APIConfig{}}
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"missing", `{"url": "https://example.com/article"}`},
+		{"empty", `{"topic": "", "url": "https://example.com/article"}`},
+		{"whitespace", `{"topic": "  ", "url": "https://example.com/article"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			h.CreateDropHandler(rec, authedRequest(http.MethodPost, "/api/v1/drops", strings.NewReader(tt.body), uuid.New()))
+			if rec.Code != http.StatusBadRequest {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusBadRequest, rec.Body)
+			}
+			if !strings.Contains(rec.Body.String(), "Topic cannot be empty") {
+				t.Errorf("body = %s, want the empty topic error", rec.Body)
+			}
+		})
+	}
+}
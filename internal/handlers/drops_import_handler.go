@@ -0,0 +1,216 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/nouvadev/dropwise/internal/bookmarks"
+	db "github.com/nouvadev/dropwise/internal/database/sqlc"
+	"github.com/nouvadev/dropwise/internal/delivery"
+	"github.com/nouvadev/dropwise/internal/events"
+	"github.com/nouvadev/dropwise/internal/middleware"
+	"github.com/nouvadev/dropwise/internal/server/httputils"
+)
+
+const (
+	// maxBookmarksFileBytes caps the size of an uploaded bookmarks file.
+	maxBookmarksFileBytes = 10 << 20
+	// maxImportedBookmarks caps how many bookmarks a single import may contain.
+	maxImportedBookmarks = 5000
+)
+
+// ImportBookmarksResponse summarizes a bookmarks import.
+type ImportBookmarksResponse struct {
+	Total             int `json:"total"`              // Links found in the file
+	ImportedCount     int `json:"imported_count"`     // Drops created
+	SkippedDuplicates int `json:"skipped_duplicates"` // Links already saved, or repeated in the file
+}
+
+// ImportBookmarksHandler creates drops from a Netscape bookmarks HTML file, the format
+// exported by browsers and Pocket. The file is sent either as the raw request body or as
+// the "file" field of a multipart form. Folder names and Pocket/Firefox tags become tags
+// on the drops. URLs the user already has a drop for in the workspace are skipped, and
+// the whole import is created in one transaction.
+// POST /api/v1/drops/import/bookmarks
+func (h *DropsHandler) ImportBookmarksHandler(w http.ResponseWriter, r *http.Request) {
+	userUUID, ok := r.Context().Value(middleware.UserIDKey).(uuid.UUID)
+	if !ok {
+		httputils.RespondWithError(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+	workspaceID := middleware.GetWorkspaceIDFromContext(r)
+
+	doc, ok := readBookmarksFile(w, r)
+	if !ok {
+		return
+	}
+
+	found, err := bookmarks.ParseNetscape(doc)
+	if err != nil {
+		httputils.RespondWithError(w, http.StatusBadRequest, "Invalid bookmarks file: "+err.Error())
+		return
+	}
+	if len(found) > maxImportedBookmarks {
+		httputils.RespondWithError(w, http.StatusRequestEntityTooLarge, "Too many bookmarks, at most 5000 can be imported at once")
+		return
+	}
+
+	summary := ImportBookmarksResponse{Total: len(found)}
+
+	// Drop links repeated in the file before asking which ones are already saved
+	unique := make([]bookmarks.Bookmark, 0, len(found))
+	urls := make([]string, 0, len(found))
+	seen := make(map[string]bool, len(found))
+	for _, b := range found {
+		if seen[b.URL] {
+			summary.SkippedDuplicates++
+			continue
+		}
+		seen[b.URL] = true
+		unique = append(unique, b)
+		urls = append(urls, b.URL)
+	}
+
+	existing, err := h.APIConfig.DB.ListExistingDropURLs(r.Context(), db.ListExistingDropURLsParams{
+		Urls:        urls,
+		UserUuid:    uuid.NullUUID{UUID: userUUID, Valid: true},
+		WorkspaceID: workspaceID,
+	})
+	if err != nil {
+		log.Printf("Error checking existing drop URLs for UserUUID %s: %v", userUUID, err)
+		httputils.RespondWithServerError(w, err, "Failed to import bookmarks: "+err.Error())
+		return
+	}
+	saved := make(map[string]bool, len(existing))
+	for _, u := range existing {
+		saved[u] = true
+	}
+
+	log.Printf("Attempting to import %d bookmarks for UserUUID: %s", len(unique)-len(existing), userUUID)
+
+	tx, err := h.APIConfig.DBConn.BeginTx(r.Context(), nil)
+	if err != nil {
+		log.Printf("Error starting transaction for bookmarks import: %v", err)
+		httputils.RespondWithServerError(w, err, "Failed to import bookmarks")
+		return
+	}
+	defer tx.Rollback()
+	qtx := h.APIConfig.DB.WithTx(tx)
+
+	tagIDs := make(map[string]int32) // Tags are shared, so each name is upserted once per import
+	var created []db.Drop
+	for _, b := range unique {
+		if saved[b.URL] {
+			summary.SkippedDuplicates++
+			continue
+		}
+
+		topic := b.Title
+		if topic == "" {
+			topic = b.URL
+		}
+		drop, err := qtx.CreateDrop(r.Context(), db.CreateDropParams{
+			UserUuid:    uuid.NullUUID{UUID: userUUID, Valid: true},
+			Topic:       topic,
+			Url:         b.URL,
+			WorkspaceID: workspaceID,
+			Channel:     delivery.Default,
+			Metadata:    emptyDropMetadata,
+		})
+		if err != nil {
+			log.Printf("Error creating drop for bookmark %s: %v", b.URL, err)
+			httputils.RespondWithServerError(w, err, "Failed to import bookmarks: "+err.Error())
+			return
+		}
+
+		attached := make(map[int32]bool)
+		for _, name := range append(b.Folders, b.Tags...) {
+			tagID, ok := tagIDs[name]
+			if !ok {
+				tag, err := qtx.CreateTag(r.Context(), name)
+				if err != nil {
+					log.Printf("Error creating/getting tag '%s' for bookmarks import: %v", name, err)
+					httputils.RespondWithServerError(w, err, "Failed to import bookmarks: "+err.Error())
+					return
+				}
+				tagID = tag.ID
+				tagIDs[name] = tagID
+			}
+			if attached[tagID] {
+				continue
+			}
+			attached[tagID] = true
+
+			if err := qtx.AddTagToDrop(r.Context(), db.AddTagToDropParams{DropsID: drop.ID, TagID: tagID}); err != nil {
+				log.Printf("Error associating tag '%s' (ID: %d) with drop %s: %v", name, tagID, drop.ID, err)
+				httputils.RespondWithServerError(w, err, "Failed to import bookmarks: "+err.Error())
+				return
+			}
+		}
+		created = append(created, drop)
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("Error committing bookmarks import for UserUUID %s: %v", userUUID, err)
+		httputils.RespondWithServerError(w, err, "Failed to import bookmarks")
+		return
+	}
+
+	for _, drop := range created {
+		h.publishDropEvent(userUUID, events.DropCreated, drop)
+	}
+	summary.ImportedCount = len(created)
+	h.APIConfig.DropCreationMonitor.Observe(r.Context(), userUUID, len(created))
+
+	log.Printf("Imported %d of %d bookmarks for UserUUID: %s", summary.ImportedCount, summary.Total, userUUID)
+	httputils.RespondWithJSON(w, http.StatusCreated, summary)
+}
+
+// readBookmarksFile reads the uploaded bookmarks file from a multipart form's "file" field
+// or, for any other content type, from the request body. It responds with an error and
+// returns false when the file is missing or too large.
+func readBookmarksFile(w http.ResponseWriter, r *http.Request) (string, bool) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxBookmarksFileBytes)
+	defer r.Body.Close()
+
+	var src io.Reader = r.Body
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType == "multipart/form-data" {
+		if err := r.ParseMultipartForm(maxBookmarksFileBytes); err != nil {
+			respondBookmarksReadError(w, err)
+			return "", false
+		}
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			httputils.RespondWithError(w, http.StatusBadRequest, "Missing \"file\" field in multipart form")
+			return "", false
+		}
+		defer file.Close()
+		src = file
+	}
+
+	data, err := io.ReadAll(src)
+	if err != nil {
+		respondBookmarksReadError(w, err)
+		return "", false
+	}
+	if strings.TrimSpace(string(data)) == "" {
+		httputils.RespondWithError(w, http.StatusBadRequest, "Bookmarks file is empty")
+		return "", false
+	}
+	return string(data), true
+}
+
+// respondBookmarksReadError reports a failure to read the uploaded file.
+func respondBookmarksReadError(w http.ResponseWriter, err error) {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		httputils.RespondWithError(w, http.StatusRequestEntityTooLarge, "Bookmarks file is too large, the limit is 10 MB")
+		return
+	}
+	httputils.RespondWithError(w, http.StatusBadRequest, "Could not read bookmarks file: "+err.Error())
+}
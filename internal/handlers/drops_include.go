@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+	db "github.com/nouvadev/dropwise/internal/database/sqlc"
+	"github.com/nouvadev/dropwise/internal/server/httputils"
+)
+
+// DropCollectionResponse names a collection a drop belongs to.
+type DropCollectionResponse struct {
+	ID   uuid.UUID `json:"id"`
+	Name string    `json:"name"`
+}
+
+// dropIncludes holds the related data ?include= asks to embed in drop responses.
+type dropIncludes struct {
+	Collections bool
+}
+
+// parseDropIncludes reads the comma-separated ?include= list of related data to embed in
+// drop responses. Without it drops come back as usual.
+// On an unknown value it writes a 400 response and returns false.
+func parseDropIncludes(w http.ResponseWriter, r *http.Request) (dropIncludes, bool) {
+	var includes dropIncludes
+	if !r.URL.Query().Has("include") {
+		return includes, true
+	}
+	for _, include := range strings.Split(r.URL.Query().Get("include"), ",") {
+		switch strings.TrimSpace(include) {
+		case "":
+		case "collections":
+			includes.Collections = true
+		default:
+			httputils.RespondWithError(w, http.StatusBadRequest, "Invalid include value. Allowed: collections.")
+			return includes, false
+		}
+	}
+	return includes, true
+}
+
+// addDropCollections fills in the collections of the user's drops with one query for all
+// of them. Drops in no collection get an empty list.
+func addDropCollections(r *http.Request, queries *db.Queries, userUUID uuid.UUID, drops []DropResponse) error {
+	dropIDs := make([]uuid.UUID, len(drops))
+	for i, drop := range drops {
+		dropIDs[i] = drop.ID
+	}
+	rows, err := queries.GetCollectionsForDrops(r.Context(), db.GetCollectionsForDropsParams{
+		DropIds:  dropIDs,
+		UserUuid: userUUID,
+	})
+	if err != nil {
+		return err
+	}
+
+	collectionsByDrop := make(map[uuid.UUID][]DropCollectionResponse, len(drops))
+	for _, row := range rows {
+		collectionsByDrop[row.DropsID] = append(collectionsByDrop[row.DropsID], DropCollectionResponse{ID: row.ID, Name: row.Name})
+	}
+	for i := range drops {
+		drops[i].Collections = collectionsByDrop[drops[i].ID]
+		if drops[i].Collections == nil {
+			drops[i].Collections = []DropCollectionResponse{}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/sqlc-dev/pqtype"
+)
+
+// maxDropMetadataBytes caps the size of a drop's metadata once compacted.
+const maxDropMetadataBytes = 4096
+
+// dropMetadataFilterPrefix starts the query parameters filtering drops by a metadata key.
+const dropMetadataFilterPrefix = "metadata."
+
+// emptyDropMetadata is stored for drops created without metadata and when it is cleared.
+var emptyDropMetadata = json.RawMessage(`{}`)
+
+// parseDropMetadata validates a metadata value sent by a client: it must be a JSON object of
+// at most maxDropMetadataBytes. null and an absent value become the empty object.
+// The returned object is compacted.
+func parseDropMetadata(raw json.RawMessage) (json.RawMessage, error) {
+	if len(raw) == 0 || isJSONNull(raw) {
+		return emptyDropMetadata, nil
+	}
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &object); err != nil || object == nil {
+		return nil, fmt.Errorf("metadata must be a JSON object")
+	}
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, raw); err != nil {
+		return nil, fmt.Errorf("metadata must be a JSON object")
+	}
+	if compact.Len() > maxDropMetadataBytes {
+		return nil, fmt.Errorf("metadata must be at most %d bytes", maxDropMetadataBytes)
+	}
+	return compact.Bytes(), nil
+}
+
+// mergeDropMetadata applies a metadata member of a merge patch to the current metadata,
+// following RFC 7386: nested objects are merged and members set to null are removed.
+// A null patch clears the metadata.
+func mergeDropMetadata(current, patch json.RawMessage) (json.RawMessage, error) {
+	if isJSONNull(patch) {
+		return emptyDropMetadata, nil
+	}
+	var patchValue any
+	if err := decodeJSONValue(patch, &patchValue); err != nil {
+		return nil, fmt.Errorf("metadata must be a JSON object")
+	}
+	if _, ok := patchValue.(map[string]any); !ok {
+		return nil, fmt.Errorf("metadata must be a JSON object")
+	}
+	var currentValue any
+	if len(current) > 0 {
+		if err := decodeJSONValue(current, &currentValue); err != nil {
+			return nil, err
+		}
+	}
+	merged, err := json.Marshal(mergePatch(currentValue, patchValue))
+	if err != nil {
+		return nil, err
+	}
+	return parseDropMetadata(merged)
+}
+
+// decodeJSONValue decodes raw into v, keeping numbers as json.Number so they are written
+// back exactly as they were sent.
+func decodeJSONValue(raw json.RawMessage, v *any) error {
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	return decoder.Decode(v)
+}
+
+// mergePatch is the MergePatch algorithm of RFC 7386 on decoded JSON values.
+func mergePatch(target, patch any) any {
+	patchObject, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+	targetObject, ok := target.(map[string]any)
+	if !ok {
+		targetObject = make(map[string]any, len(patchObject))
+	}
+	for name, value := range patchObject {
+		if value == nil {
+			delete(targetObject, name)
+		} else {
+			targetObject[name] = mergePatch(targetObject[name], value)
+		}
+	}
+	return targetObject
+}
+
+// parseDropMetadataFilter builds the containment filter for ?metadata.<key>=<value>
+// parameters; a drop matches when its metadata has every given top-level key with that
+// value. Values that are JSON numbers or booleans match as such, anything else as a string.
+// It returns an invalid NullRawMessage when no metadata parameter is present.
+func parseDropMetadataFilter(query url.Values) (pqtype.NullRawMessage, error) {
+	keys := make([]string, 0)
+	for param := range query {
+		if strings.HasPrefix(param, dropMetadataFilterPrefix) {
+			keys = append(keys, param)
+		}
+	}
+	if len(keys) == 0 {
+		return pqtype.NullRawMessage{}, nil
+	}
+	sort.Strings(keys)
+
+	filter := make(map[string]json.RawMessage, len(keys))
+	for _, param := range keys {
+		key := strings.TrimPrefix(param, dropMetadataFilterPrefix)
+		if key == "" {
+			return pqtype.NullRawMessage{}, fmt.Errorf("metadata filters need a key, as in ?metadata.source=value")
+		}
+		if len(query[param]) > 1 {
+			return pqtype.NullRawMessage{}, fmt.Errorf("%s can only be given once", param)
+		}
+		filter[key] = metadataFilterValue(query.Get(param))
+	}
+	encoded, err := json.Marshal(filter)
+	if err != nil {
+		return pqtype.NullRawMessage{}, err
+	}
+	return pqtype.NullRawMessage{RawMessage: encoded, Valid: true}, nil
+}
+
+// metadataFilterValue returns the JSON value a metadata filter parameter matches.
+func metadataFilterValue(v string) json.RawMessage {
+	decoder := json.NewDecoder(strings.NewReader(v))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err == nil && !decoder.More() {
+		switch value.(type) {
+		case json.Number, bool:
+			return json.RawMessage(v)
+		}
+	}
+	encoded, _ := json.Marshal(v)
+	return encoded
+}
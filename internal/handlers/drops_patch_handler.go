@@ -0,0 +1,231 @@
+package handlers
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+	db "github.com/nouvadev/dropwise/internal/database/sqlc"
+	"github.com/nouvadev/dropwise/internal/delivery"
+	"github.com/nouvadev/dropwise/internal/events"
+	"github.com/nouvadev/dropwise/internal/middleware"
+	"github.com/nouvadev/dropwise/internal/server/httputils"
+)
+
+// patchableDropFields lists the members a merge patch may contain.
+var patchableDropFields = map[string]bool{
+	"topic": true, "url": true, "user_notes": true, "priority": true,
+	"status": true, "schedule": true, "permanent": true, "channel": true, "tags": true,
+	"metadata": true,
+}
+
+// PatchDropHandler applies an RFC 7386 JSON Merge Patch to a drop.
+// Members present in the patch are set, members set to null are cleared
+// (notes, priority, schedule and tags; permanent goes back to false, channel
+// to "default" and metadata to {}) and members left out are unchanged. topic, url
+// and status cannot be cleared. A metadata object is itself merged into the drop's
+// metadata. Only the members in the patch are written, and a new status only if the drop
+// still has the status the transition was checked against; otherwise it answers 409.
+// PATCH /api/v1/drops/{id}
+func (h *DropsHandler) PatchDropHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPatch {
+		httputils.RespondWithError(w, http.StatusMethodNotAllowed, "Only PATCH method is allowed")
+		return
+	}
+
+	userUUID, ok := r.Context().Value(middleware.UserIDKey).(uuid.UUID)
+	if !ok {
+		log.Printf("PatchDropHandler: UserID not found in context or not a UUID for path %s", r.URL.Path)
+		httputils.RespondWithError(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	existingDrop, ok := h.getOwnedDrop(w, r, userUUID)
+	if !ok {
+		return
+	}
+
+	var patch map[string]json.RawMessage
+	if !httputils.DecodeJSONBody(w, r, &patch) {
+		return
+	}
+	defer r.Body.Close()
+
+	if patch == nil {
+		httputils.RespondWithError(w, http.StatusBadRequest, "Merge patch must be a JSON object")
+		return
+	}
+	var unknown []string
+	for field := range patch {
+		if !patchableDropFields[field] {
+			unknown = append(unknown, field)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		httputils.RespondWithError(w, http.StatusBadRequest, "Fields cannot be patched: "+strings.Join(unknown, ", "))
+		return
+	}
+
+	// Only members present in the patch are written; the others keep their current values
+	// in the database, which may be newer than existingDrop.
+	params := db.PatchDropParams{
+		ID:             existingDrop.ID,
+		UserUuid:       uuid.NullUUID{UUID: userUUID, Valid: true},
+		ExpectedStatus: existingDrop.Status,
+	}
+
+	if raw, ok := patch["topic"]; ok {
+		var topic string
+		if isJSONNull(raw) || json.Unmarshal(raw, &topic) != nil || strings.TrimSpace(topic) == "" {
+			httputils.RespondWithError(w, http.StatusBadRequest, "Topic must be a non-empty string")
+			return
+		}
+		params.SetTopic, params.Topic = true, topic
+	}
+	if raw, ok := patch["url"]; ok {
+		var url string
+		if isJSONNull(raw) || json.Unmarshal(raw, &url) != nil || strings.TrimSpace(url) == "" {
+			httputils.RespondWithError(w, http.StatusBadRequest, "URL must be a non-empty string")
+			return
+		}
+		params.SetUrl, params.Url = true, url
+	}
+	if raw, ok := patch["user_notes"]; ok {
+		params.SetUserNotes = true
+		if !isJSONNull(raw) {
+			if err := json.Unmarshal(raw, &params.UserNotes.String); err != nil {
+				respondInvalidPatchField(w, "user_notes")
+				return
+			}
+			params.UserNotes.Valid = true
+		}
+	}
+	if raw, ok := patch["priority"]; ok {
+		params.SetPriority = true
+		if !isJSONNull(raw) {
+			if err := json.Unmarshal(raw, &params.Priority.Int32); err != nil {
+				httputils.RespondWithError(w, http.StatusBadRequest, httputils.InvalidFieldMessage("priority", err))
+				return
+			}
+			params.Priority.Valid = true
+		}
+	}
+	if raw, ok := patch["status"]; ok {
+		var status string
+		if isJSONNull(raw) || json.Unmarshal(raw, &status) != nil || !validDropStatuses[status] {
+			httputils.RespondWithError(w, http.StatusBadRequest, invalidDropStatusMessage)
+			return
+		}
+		if !canTransitionDropStatus(existingDrop.Status, status) {
+			respondIllegalStatusTransition(w, existingDrop.ID, existingDrop.Status, status)
+			return
+		}
+		params.SetStatus, params.Status = true, status
+	}
+	if raw, ok := patch["schedule"]; ok {
+		var expr string
+		if !isJSONNull(raw) {
+			if err := json.Unmarshal(raw, &expr); err != nil {
+				respondInvalidPatchField(w, "schedule")
+				return
+			}
+		}
+		expr = strings.TrimSpace(expr)
+		params.SetSchedule, params.Schedule = true, sql.NullString{String: expr, Valid: expr != ""}
+		if expr != "" {
+			nextSendDate, ok := parseDropSchedule(w, expr, middleware.GetUserTimezoneFromContext(r))
+			if !ok {
+				return
+			}
+			params.SetNextSendDate, params.NextSendDate = true, nextSendDate
+		}
+	}
+	if raw, ok := patch["permanent"]; ok {
+		params.SetPermanent = true
+		if !isJSONNull(raw) {
+			if err := json.Unmarshal(raw, &params.Permanent); err != nil {
+				respondInvalidPatchField(w, "permanent")
+				return
+			}
+		}
+		if params.Permanent && !existingDrop.Permanent && !params.SetNextSendDate && !existingDrop.NextSendDate.Valid {
+			intervals, ok := h.userIntervals(w, r, userUUID)
+			if !ok {
+				return
+			}
+			params.SetNextSendDate, params.NextSendDate = true, permanentRestartDate(existingDrop, intervals)
+		}
+	}
+	if raw, ok := patch["channel"]; ok {
+		params.SetChannel, params.Channel = true, delivery.Default
+		if !isJSONNull(raw) {
+			if json.Unmarshal(raw, &params.Channel) != nil || !delivery.IsValid(params.Channel, true) {
+				httputils.RespondWithError(w, http.StatusBadRequest, invalidDropChannelMessage)
+				return
+			}
+		}
+	}
+	if raw, ok := patch["metadata"]; ok {
+		merged, err := mergeDropMetadata(existingDrop.Metadata, raw)
+		if err != nil {
+			httputils.RespondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		params.SetMetadata, params.Metadata = true, merged
+	}
+	var tags []string
+	rawTags, patchTags := patch["tags"]
+	if patchTags && !isJSONNull(rawTags) {
+		if err := json.Unmarshal(rawTags, &tags); err != nil {
+			respondInvalidPatchField(w, "tags")
+			return
+		}
+	}
+
+	log.Printf("Attempting to patch drop with ID: %s for UserUUID: %s", existingDrop.ID, userUUID)
+
+	updatedDrop, err := h.APIConfig.DB.PatchDrop(r.Context(), params)
+	if err != nil {
+		if err == sql.ErrNoRows && params.SetStatus {
+			// The status changed since it was checked, or the drop was deleted
+			httputils.RespondWithError(w, http.StatusConflict, fmt.Sprintf("Drop %s changed while it was being updated, retry the patch", existingDrop.ID))
+		} else if err == sql.ErrNoRows {
+			httputils.RespondWithError(w, http.StatusNotFound, "Drop not found")
+		} else {
+			log.Printf("Error patching drop %s in database: %v", existingDrop.ID, err)
+			httputils.RespondWithServerError(w, err, "Failed to update drop: "+err.Error())
+		}
+		return
+	}
+
+	if patchTags {
+		if err := h.APIConfig.DB.RemoveAllTagsFromDrop(r.Context(), updatedDrop.ID); err != nil {
+			log.Printf("Error removing existing tags for drop %s: %v", updatedDrop.ID, err)
+		}
+		h.attachTags(r, updatedDrop.ID, tags)
+	}
+
+	log.Printf("Successfully patched drop with ID: %s", updatedDrop.ID)
+	if updatedDrop.Status != existingDrop.Status {
+		recordReview(r, h.APIConfig.DB, userUUID, updatedDrop.Status)
+	}
+	h.publishDropEvent(userUUID, events.DropUpdated, updatedDrop)
+	httputils.RespondWithJSON(w, http.StatusOK, toDropResponse(updatedDrop, h.dropTagNames(r, updatedDrop.ID), r, h.APIConfig.MaxSendCount))
+}
+
+// isJSONNull reports whether a raw JSON value is the literal null.
+func isJSONNull(raw json.RawMessage) bool {
+	return bytes.Equal(bytes.TrimSpace(raw), []byte("null"))
+}
+
+// respondInvalidPatchField writes a 400 response for a patch member of the wrong type.
+func respondInvalidPatchField(w http.ResponseWriter, field string) {
+	httputils.RespondWithError(w, http.StatusBadRequest, fmt.Sprintf("Invalid value for field '%s'", field))
+}
@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/google/uuid"
+	db "github.com/nouvadev/dropwise/internal/database/sqlc"
+	"github.com/nouvadev/dropwise/internal/middleware"
+	"github.com/nouvadev/dropwise/internal/server/httputils"
+)
+
+// maxRelatedDrops is the number of related drops returned for a drop.
+const maxRelatedDrops = 10
+
+// RelatedDropResponse is a drop along with the number of tags it shares with the requested drop.
+type RelatedDropResponse struct {
+	DropResponse
+	SharedTags int64 `json:"shared_tags"`
+}
+
+// RelatedDropsHandler lists the user's other drops that share the most tags with a drop.
+// GET /api/v1/drops/{id}/related
+func (h *DropsHandler) RelatedDropsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		httputils.RespondWithError(w, http.StatusMethodNotAllowed, "Only GET method is allowed")
+		return
+	}
+
+	userUUID, ok := r.Context().Value(middleware.UserIDKey).(uuid.UUID)
+	if !ok {
+		log.Printf("RelatedDropsHandler: UserID not found in context or not a UUID for path %s", r.URL.Path)
+		httputils.RespondWithError(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	drop, ok := h.getOwnedDrop(w, r, userUUID)
+	if !ok {
+		return
+	}
+
+	related, err := h.APIConfig.DBRead.ListRelatedDrops(r.Context(), db.ListRelatedDropsParams{
+		DropID:      drop.ID,
+		UserUuid:    uuid.NullUUID{UUID: userUUID, Valid: true},
+		WorkspaceID: drop.WorkspaceID,
+		Limit:       maxRelatedDrops,
+	})
+	if err != nil {
+		log.Printf("Error fetching related drops for drop %s: %v", drop.ID, err)
+		httputils.RespondWithServerError(w, err, "Failed to fetch related drops: "+err.Error())
+		return
+	}
+
+	response := make([]RelatedDropResponse, 0, len(related))
+	for _, rel := range related {
+		response = append(response, RelatedDropResponse{
+			DropResponse: toDropResponse(rel.Drop, h.dropTagNames(r, rel.Drop.ID), r, h.APIConfig.MaxSendCount),
+			SharedTags:   rel.SharedTags,
+		})
+	}
+
+	log.Printf("Found %d related drops for drop %s", len(response), drop.ID)
+	httputils.RespondWithJSON(w, http.StatusOK, response)
+}
@@ -0,0 +1,366 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"log"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	db "github.com/nouvadev/dropwise/internal/database/sqlc"
+	"github.com/nouvadev/dropwise/internal/events"
+	"github.com/nouvadev/dropwise/internal/middleware"
+	"github.com/nouvadev/dropwise/internal/schedule"
+	"github.com/nouvadev/dropwise/internal/server/httputils"
+)
+
+// RescheduleDropRequest defines the expected request body for rescheduling a drop.
+// Exactly one of Reset or NextSendDate must be provided.
+type RescheduleDropRequest struct {
+	Reset        bool       `json:"reset,omitempty"`
+	NextSendDate *time.Time `json:"next_send_date,omitempty"`
+}
+
+// RescheduleDropHandler resets or overrides a drop's repetition schedule.
+// POST /api/v1/drops/{id}/reschedule
+func (h *DropsHandler) RescheduleDropHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		httputils.RespondWithError(w, http.StatusMethodNotAllowed, "Only POST method is allowed")
+		return
+	}
+
+	userUUID, ok := r.Context().Value(middleware.UserIDKey).(uuid.UUID)
+	if !ok {
+		log.Printf("RescheduleDropHandler: UserID not found in context or not a UUID for path %s", r.URL.Path)
+		httputils.RespondWithError(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	drop, ok := h.getOwnedDrop(w, r, userUUID)
+	if !ok {
+		return
+	}
+
+	var req RescheduleDropRequest
+	if !httputils.DecodeJSONBody(w, r, &req) {
+		return
+	}
+	defer r.Body.Close()
+
+	if req.Reset == (req.NextSendDate != nil) {
+		httputils.RespondWithError(w, http.StatusBadRequest, "Provide either \"reset\": true or a \"next_send_date\"")
+		return
+	}
+
+	now := time.Now().UTC()
+	var updatedDrop db.Drop
+	var err error
+	if req.Reset {
+		log.Printf("Resetting schedule of drop %s for UserUUID: %s", drop.ID, userUUID)
+		next, _ := schedule.NextSendDate(0, now, false)
+		updatedDrop, err = h.APIConfig.DB.ResetDropSchedule(r.Context(), db.ResetDropScheduleParams{
+			ID:           drop.ID,
+			UserUuid:     uuid.NullUUID{UUID: userUUID, Valid: true},
+			NextSendDate: sql.NullTime{Time: next, Valid: true},
+		})
+	} else {
+		if !req.NextSendDate.After(now) {
+			httputils.RespondWithError(w, http.StatusBadRequest, "next_send_date must be in the future")
+			return
+		}
+		log.Printf("Setting next send date of drop %s to %s for UserUUID: %s", drop.ID, req.NextSendDate.UTC(), userUUID)
+		updatedDrop, err = h.APIConfig.DB.SetDropNextSendDate(r.Context(), db.SetDropNextSendDateParams{
+			ID:           drop.ID,
+			UserUuid:     uuid.NullUUID{UUID: userUUID, Valid: true},
+			NextSendDate: sql.NullTime{Time: req.NextSendDate.UTC(), Valid: true},
+		})
+	}
+	if err != nil {
+		if err == sql.ErrNoRows {
+			httputils.RespondWithError(w, http.StatusNotFound, "Drop not found")
+		} else {
+			log.Printf("Error rescheduling drop %s: %v", drop.ID, err)
+			httputils.RespondWithServerError(w, err, "Failed to reschedule drop: "+err.Error())
+		}
+		return
+	}
+
+	h.publishDropEvent(userUUID, events.DropUpdated, updatedDrop)
+
+	response := toDropResponse(updatedDrop, h.dropTagNames(r, updatedDrop.ID), r, h.APIConfig.MaxSendCount)
+	httputils.RespondWithJSON(w, http.StatusOK, response)
+}
+
+// DropDueDateRequest defines the expected request body for setting a drop's due date.
+// DueDate is a YYYY-MM-DD date, or null to remove the due date.
+type DropDueDateRequest struct {
+	DueDate json.RawMessage `json:"due_date"`
+}
+
+// DropDueDateHandler pins a drop's next send to a date, overriding the date its schedule set.
+// The drop is sent at the start of that day in the request's time zone; after that send the
+// schedule carries on as usual. A null due_date removes the pin and restores the scheduled date.
+// PUT /api/v1/drops/{id}/due-date
+func (h *DropsHandler) DropDueDateHandler(w http.ResponseWriter, r *http.Request) {
+	userUUID, ok := r.Context().Value(middleware.UserIDKey).(uuid.UUID)
+	if !ok {
+		httputils.RespondWithError(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	drop, ok := h.getOwnedDrop(w, r, userUUID)
+	if !ok {
+		return
+	}
+
+	var req DropDueDateRequest
+	if !httputils.DecodeJSONBody(w, r, &req) {
+		return
+	}
+	defer r.Body.Close()
+
+	if len(req.DueDate) == 0 {
+		httputils.RespondWithError(w, http.StatusBadRequest, "due_date is required, use null to remove it")
+		return
+	}
+
+	var updatedDrop db.Drop
+	var err error
+	if string(req.DueDate) == "null" {
+		log.Printf("Removing due date of drop %s for UserUUID: %s", drop.ID, userUUID)
+		updatedDrop, err = h.APIConfig.DB.ClearDropDueDate(r.Context(), db.ClearDropDueDateParams{
+			ID:       drop.ID,
+			UserUuid: uuid.NullUUID{UUID: userUUID, Valid: true},
+		})
+		if err == sql.ErrNoRows {
+			updatedDrop, err = drop, nil // No due date to remove
+		}
+	} else {
+		var raw string
+		if json.Unmarshal(req.DueDate, &raw) != nil {
+			httputils.RespondWithError(w, http.StatusBadRequest, "Invalid due_date, expected a YYYY-MM-DD date or null")
+			return
+		}
+		loc := middleware.GetTimezoneFromContext(r)
+		dueDate, parseErr := time.ParseInLocation(time.DateOnly, raw, loc)
+		if parseErr != nil {
+			httputils.RespondWithError(w, http.StatusBadRequest, "Invalid due_date, expected a YYYY-MM-DD date or null")
+			return
+		}
+		if dueDate.Before(schedule.StartOfNextDay(time.Now(), loc).AddDate(0, 0, -1)) {
+			httputils.RespondWithError(w, http.StatusBadRequest, "due_date must be today or later")
+			return
+		}
+		if drop.Status != "new" && drop.Status != "sent" {
+			httputils.RespondWithError(w, http.StatusConflict, "Only new and sent drops can be given a due date, this drop is "+drop.Status)
+			return
+		}
+		log.Printf("Setting due date of drop %s to %s for UserUUID: %s", drop.ID, raw, userUUID)
+		updatedDrop, err = h.APIConfig.DB.SetDropDueDate(r.Context(), db.SetDropDueDateParams{
+			DueDate:      sql.NullTime{Time: time.Date(dueDate.Year(), dueDate.Month(), dueDate.Day(), 0, 0, 0, 0, time.UTC), Valid: true},
+			NextSendDate: sql.NullTime{Time: dueDate.UTC(), Valid: true},
+			ID:           drop.ID,
+			UserUuid:     uuid.NullUUID{UUID: userUUID, Valid: true},
+		})
+	}
+	if err != nil {
+		if err == sql.ErrNoRows {
+			httputils.RespondWithError(w, http.StatusNotFound, "Drop not found")
+		} else {
+			log.Printf("Error setting due date of drop %s: %v", drop.ID, err)
+			httputils.RespondWithServerError(w, err, "Failed to set due date: "+err.Error())
+		}
+		return
+	}
+
+	h.publishDropEvent(userUUID, events.DropUpdated, updatedDrop)
+
+	response := toDropResponse(updatedDrop, h.dropTagNames(r, updatedDrop.ID), r, h.APIConfig.MaxSendCount)
+	httputils.RespondWithJSON(w, http.StatusOK, response)
+}
+
+// NextSendResponse describes a drop's upcoming send.
+// NextSendDate is null when the drop won't be sent again: it is archived or snoozed, or its
+// repetition sequence has finished. IntervalDays is the spaced-repetition gap leading up to
+// the send and is null for drops on a cron schedule.
+type NextSendResponse struct {
+	NextSendDate *time.Time `json:"next_send_date"`
+	IntervalDays *int       `json:"interval_days"`
+	Repetition   int32      `json:"repetition"`
+}
+
+// NextSendHandler returns when a drop will next be sent and where it is in its schedule.
+// GET /api/v1/drops/{id}/next
+func (h *DropsHandler) NextSendHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		httputils.RespondWithError(w, http.StatusMethodNotAllowed, "Only GET method is allowed")
+		return
+	}
+
+	userUUID, ok := r.Context().Value(middleware.UserIDKey).(uuid.UUID)
+	if !ok {
+		log.Printf("NextSendHandler: UserID not found in context or not a UUID for path %s", r.URL.Path)
+		httputils.RespondWithError(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	drop, ok := h.getOwnedDrop(w, r, userUUID)
+	if !ok {
+		return
+	}
+
+	intervals, ok := h.userIntervals(w, r, userUUID)
+	if !ok {
+		return
+	}
+
+	httputils.RespondWithJSON(w, http.StatusOK, computeNextSend(drop, time.Now(), intervals))
+}
+
+// computeNextSend works out a drop's upcoming send the way the worker will pick it: a new
+// drop without a next send date is due now, otherwise the stored date holds. The interval
+// comes from the user's spaced-repetition sequence, which the worker schedules with too.
+func computeNextSend(drop db.Drop, now time.Time, intervals schedule.Intervals) NextSendResponse {
+	response := NextSendResponse{Repetition: drop.SendCount + 1}
+
+	var next time.Time
+	switch {
+	case drop.Status != "new" && drop.Status != "sent":
+		return response
+	case drop.NextSendDate.Valid:
+		next = drop.NextSendDate.Time.UTC()
+	case drop.Status == "new":
+		next = now.UTC()
+	default:
+		return response // A sent drop without a next send date has finished its sequence
+	}
+	response.NextSendDate = &next
+
+	if !drop.Schedule.Valid {
+		if days, ok := intervals.Interval(drop.SendCount, drop.Permanent); ok {
+			response.IntervalDays = &days
+		}
+	}
+	return response
+}
+
+// DueCountResponse reports how many drops are waiting to be sent.
+type DueCountResponse struct {
+	DueCount int64 `json:"due_count"`
+}
+
+// DueCountHandler returns the number of the user's drops that are currently due.
+// GET /api/v1/drops/due-count
+func (h *DropsHandler) DueCountHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		httputils.RespondWithError(w, http.StatusMethodNotAllowed, "Only GET method is allowed")
+		return
+	}
+
+	userUUID, ok := r.Context().Value(middleware.UserIDKey).(uuid.UUID)
+	if !ok {
+		log.Printf("DueCountHandler: UserID not found in context or not a UUID for path %s", r.URL.Path)
+		httputils.RespondWithError(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	dueBefore, lastSentBefore := h.APIConfig.DueWindow.Cutoffs(time.Now())
+	count, err := h.APIConfig.DBRead.CountDueDropsByUserUUID(r.Context(), db.CountDueDropsByUserUUIDParams{
+		UserUuid:       uuid.NullUUID{UUID: userUUID, Valid: true},
+		WorkspaceID:    middleware.GetWorkspaceIDFromContext(r),
+		DueBefore:      dueBefore,
+		LastSentBefore: lastSentBefore,
+	})
+	if err != nil {
+		log.Printf("Error counting due drops for UserUUID %s: %v", userUUID, err)
+		httputils.RespondWithServerError(w, err, "Failed to count due drops: "+err.Error())
+		return
+	}
+
+	httputils.RespondWithJSON(w, http.StatusOK, DueCountResponse{DueCount: count})
+}
+
+// ClearDueResponse reports how many due drops were archived.
+type ClearDueResponse struct {
+	ArchivedCount int `json:"archived_count"`
+}
+
+// ClearDueHandler archives every drop in the active workspace that is currently due, using
+// the worker's due criteria, to give a user who fell behind an empty queue. Because it
+// can't be undone in one step, ?confirm=true is required. Archiving this way doesn't count
+// as a review for the streak.
+// POST /api/v1/drops/clear-due?confirm=true
+func (h *DropsHandler) ClearDueHandler(w http.ResponseWriter, r *http.Request) {
+	userUUID, ok := r.Context().Value(middleware.UserIDKey).(uuid.UUID)
+	if !ok {
+		httputils.RespondWithError(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	if r.URL.Query().Get("confirm") != "true" {
+		httputils.RespondWithError(w, http.StatusBadRequest, "This archives every due drop, add ?confirm=true to proceed")
+		return
+	}
+	workspaceID := middleware.GetWorkspaceIDFromContext(r)
+
+	tx, err := h.APIConfig.DBConn.BeginTx(r.Context(), nil)
+	if err != nil {
+		log.Printf("Error starting transaction for clearing due drops: %v", err)
+		httputils.RespondWithServerError(w, err, "Failed to clear due drops")
+		return
+	}
+	defer tx.Rollback()
+	qtx := h.APIConfig.DB.WithTx(tx)
+
+	dueBefore, lastSentBefore := h.APIConfig.DueWindow.Cutoffs(time.Now())
+	due, err := qtx.GetDueDropsByUserUUID(r.Context(), db.GetDueDropsByUserUUIDParams{
+		UserUuid:       uuid.NullUUID{UUID: userUUID, Valid: true},
+		DueBefore:      dueBefore,
+		LastSentBefore: lastSentBefore,
+		Limit:          math.MaxInt32, // The query always takes a limit; every due drop is wanted here
+	})
+	if err != nil {
+		log.Printf("Error fetching due drops to clear for UserUUID %s: %v", userUUID, err)
+		httputils.RespondWithServerError(w, err, "Failed to clear due drops: "+err.Error())
+		return
+	}
+
+	// The worker's query spans all workspaces, the queue being cleared is the active one's
+	var dueIDs []uuid.UUID
+	for _, drop := range due {
+		if dropInWorkspace(drop, workspaceID) {
+			dueIDs = append(dueIDs, drop.ID)
+		}
+	}
+
+	var archivedIDs []uuid.UUID
+	if len(dueIDs) > 0 {
+		archivedIDs, err = qtx.UpdateDropsStatus(r.Context(), db.UpdateDropsStatusParams{
+			Status:       "archived",
+			Ids:          dueIDs,
+			UserUuid:     uuid.NullUUID{UUID: userUUID, Valid: true},
+			WorkspaceID:  workspaceID,
+			FromStatuses: statusesTransitioningTo("archived"),
+		})
+		if err != nil {
+			log.Printf("Error archiving due drops for UserUUID %s: %v", userUUID, err)
+			httputils.RespondWithServerError(w, err, "Failed to clear due drops: "+err.Error())
+			return
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("Error committing cleared due drops for UserUUID %s: %v", userUUID, err)
+		httputils.RespondWithServerError(w, err, "Failed to clear due drops")
+		return
+	}
+
+	for _, id := range archivedIDs {
+		h.APIConfig.Events.Publish(userUUID, events.Event{Type: events.DropUpdated, DropID: id, WorkspaceID: workspaceID})
+	}
+
+	log.Printf("Archived %d due drops for UserUUID: %s", len(archivedIDs), userUUID)
+	httputils.RespondWithJSON(w, http.StatusOK, ClearDueResponse{ArchivedCount: len(archivedIDs)})
+}
@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	db "github.com/nouvadev/dropwise/internal/database/sqlc"
+	"github.com/nouvadev/dropwise/internal/middleware"
+	"github.com/nouvadev/dropwise/internal/server/httputils"
+)
+
+// Number of drops a search returns by default and at most.
+const (
+	defaultDropSearchLimit = 20
+	maxDropSearchLimit     = 100
+)
+
+// SearchDropResponse is a drop found by a search. Score is the similarity to the query,
+// set only for fuzzy searches.
+type SearchDropResponse struct {
+	DropResponse
+	Score *float64 `json:"score,omitempty"`
+}
+
+// SearchDropsHandler finds the user's drops whose topic or tags match q. By default the
+// match is a case-insensitive substring; with fuzzy=true drops are ranked by trigram
+// similarity instead, so misspelled queries still find them.
+// GET /api/v1/drops/search?q=&fuzzy=&limit=
+func (h *DropsHandler) SearchDropsHandler(w http.ResponseWriter, r *http.Request) {
+	userUUID, ok := r.Context().Value(middleware.UserIDKey).(uuid.UUID)
+	if !ok {
+		log.Printf("SearchDropsHandler: UserID not found in context or not a UUID for path %s", r.URL.Path)
+		httputils.RespondWithError(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	limit := defaultDropSearchLimit
+	if v := r.URL.Query().Get("limit"); v != "" {
+		var err error
+		limit, err = strconv.Atoi(v)
+		if err != nil || limit < 1 || limit > maxDropSearchLimit {
+			httputils.RespondWithError(w, http.StatusBadRequest, fmt.Sprintf("limit must be an integer between 1 and %d", maxDropSearchLimit))
+			return
+		}
+	}
+
+	fuzzy := false
+	if v := r.URL.Query().Get("fuzzy"); v != "" {
+		var err error
+		fuzzy, err = strconv.ParseBool(v)
+		if err != nil {
+			httputils.RespondWithError(w, http.StatusBadRequest, "fuzzy must be true or false")
+			return
+		}
+	}
+
+	// Ensure a non-nil slice for JSON marshaling as [] if no drops match.
+	response := []SearchDropResponse{}
+	q := strings.TrimSpace(r.URL.Query().Get("q"))
+	if q == "" {
+		httputils.RespondWithJSON(w, http.StatusOK, response)
+		return
+	}
+
+	userID := uuid.NullUUID{UUID: userUUID, Valid: true}
+	workspaceID := middleware.GetWorkspaceIDFromContext(r)
+	if fuzzy {
+		rows, err := h.APIConfig.DBRead.FuzzySearchDrops(r.Context(), db.FuzzySearchDropsParams{
+			Query:       q,
+			UserUuid:    userID,
+			WorkspaceID: workspaceID,
+			MinScore:    h.APIConfig.FuzzySearchThreshold,
+			Limit:       int32(limit),
+		})
+		if err != nil {
+			log.Printf("Error searching drops for UserUUID %s: %v", userUUID, err)
+			httputils.RespondWithServerError(w, err, "Failed to search drops: "+err.Error())
+			return
+		}
+		for _, row := range rows {
+			score := math.Round(row.Score*1000) / 1000 // Three decimals are plenty to compare matches
+			response = append(response, SearchDropResponse{
+				DropResponse: toDropResponse(row.Drop, h.dropTagNames(r, row.Drop.ID), r, h.APIConfig.MaxSendCount),
+				Score:        &score,
+			})
+		}
+	} else {
+		rows, err := h.APIConfig.DBRead.SearchDrops(r.Context(), db.SearchDropsParams{
+			UserUuid:    userID,
+			WorkspaceID: workspaceID,
+			Pattern:     "%" + likeEscaper.Replace(q) + "%",
+			Limit:       int32(limit),
+		})
+		if err != nil {
+			log.Printf("Error searching drops for UserUUID %s: %v", userUUID, err)
+			httputils.RespondWithServerError(w, err, "Failed to search drops: "+err.Error())
+			return
+		}
+		for _, row := range rows {
+			response = append(response, SearchDropResponse{
+				DropResponse: toDropResponse(row.Drop, h.dropTagNames(r, row.Drop.ID), r, h.APIConfig.MaxSendCount),
+			})
+		}
+	}
+
+	log.Printf("Found %d drops matching search for UserUUID %s (fuzzy: %t)", len(response), userUUID, fuzzy)
+	httputils.RespondWithJSON(w, http.StatusOK, response)
+}
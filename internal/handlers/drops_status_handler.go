@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+	db "github.com/nouvadev/dropwise/internal/database/sqlc"
+	"github.com/nouvadev/dropwise/internal/middleware"
+	"github.com/nouvadev/dropwise/internal/server/httputils"
+)
+
+// StatusCountResponse is one entry of the status summary.
+type StatusCountResponse struct {
+	Status string `json:"status"`
+	Count  int64  `json:"count"`
+}
+
+// StatusSummaryHandler returns how many of the user's drops have each status.
+// Only statuses in use are listed unless ?include_empty=true is given.
+// GET /api/v1/drops/status-summary
+func (h *DropsHandler) StatusSummaryHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		httputils.RespondWithError(w, http.StatusMethodNotAllowed, "Only GET method is allowed")
+		return
+	}
+
+	userUUID, ok := r.Context().Value(middleware.UserIDKey).(uuid.UUID)
+	if !ok {
+		log.Printf("StatusSummaryHandler: UserID not found in context or not a UUID for path %s", r.URL.Path)
+		httputils.RespondWithError(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	includeEmpty := false
+	if v := r.URL.Query().Get("include_empty"); v != "" {
+		parsed, err := strconv.ParseBool(v)
+		if err != nil {
+			httputils.RespondWithError(w, http.StatusBadRequest, "Invalid include_empty value, expected true or false")
+			return
+		}
+		includeEmpty = parsed
+	}
+
+	rows, err := h.APIConfig.DBRead.CountDropsByStatus(r.Context(), db.CountDropsByStatusParams{
+		UserUuid:    uuid.NullUUID{UUID: userUUID, Valid: true},
+		WorkspaceID: middleware.GetWorkspaceIDFromContext(r),
+	})
+	if err != nil {
+		log.Printf("Error counting drops by status for UserUUID %s: %v", userUUID, err)
+		httputils.RespondWithServerError(w, err, "Failed to fetch status summary: "+err.Error())
+		return
+	}
+
+	counts := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		counts[row.Status] = row.Count
+	}
+
+	// Known statuses come first in display order, followed by any others found in the data.
+	response := make([]StatusCountResponse, 0, len(knownDropStatuses))
+	for _, status := range knownDropStatuses {
+		if count := counts[status]; count > 0 || includeEmpty {
+			response = append(response, StatusCountResponse{Status: status, Count: count})
+		}
+	}
+	for _, row := range rows {
+		if !validDropStatuses[row.Status] {
+			response = append(response, StatusCountResponse{Status: row.Status, Count: row.Count})
+		}
+	}
+
+	httputils.RespondWithJSON(w, http.StatusOK, response)
+}
@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/google/uuid"
+	db "github.com/nouvadev/dropwise/internal/database/sqlc"
+	"github.com/nouvadev/dropwise/internal/middleware"
+	"github.com/nouvadev/dropwise/internal/server/httputils"
+)
+
+// maxSuggestedTags is the number of tags suggested for a drop.
+const maxSuggestedTags = 10
+
+// suggestionFields are the parts of a drop searched for tag names, with how much a match in
+// each counts towards a suggestion's score. The topic says the most about a drop.
+var suggestionFields = []struct {
+	name   string
+	weight int
+	text   func(db.Drop) string
+}{
+	{"topic", 3, func(d db.Drop) string { return d.Topic }},
+	{"notes", 2, func(d db.Drop) string { return d.UserNotes.String }},
+	{"url", 1, func(d db.Drop) string { return d.Url }},
+}
+
+// SuggestedTagResponse is one of the user's tags whose name appears in a drop.
+type SuggestedTagResponse struct {
+	ID        int32    `json:"id"`
+	Name      string   `json:"name"`
+	DropCount int64    `json:"drop_count"` // Drops already carrying the tag
+	Score     int      `json:"score"`      // 3 for a match in the topic, 2 in the notes, 1 in the URL, summed
+	MatchedIn []string `json:"matched_in"` // topic, notes and/or url
+}
+
+// SuggestedTagsHandler suggests tags for a drop from the tags the user already uses: those
+// whose name appears as whole words in the drop's topic, notes or URL. Tags the drop carries
+// are left out.
+// GET /api/v1/drops/{id}/suggested-tags
+func (h *DropsHandler) SuggestedTagsHandler(w http.ResponseWriter, r *http.Request) {
+	userUUID, ok := r.Context().Value(middleware.UserIDKey).(uuid.UUID)
+	if !ok {
+		log.Printf("SuggestedTagsHandler: UserID not found in context or not a UUID for path %s", r.URL.Path)
+		httputils.RespondWithError(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	drop, ok := h.getOwnedDrop(w, r, userUUID)
+	if !ok {
+		return
+	}
+
+	candidates, err := h.APIConfig.DBRead.ListTagCandidatesForDrop(r.Context(), db.ListTagCandidatesForDropParams{
+		UserUuid:    uuid.NullUUID{UUID: userUUID, Valid: true},
+		WorkspaceID: drop.WorkspaceID,
+		DropID:      drop.ID,
+	})
+	if err != nil {
+		log.Printf("Error fetching tag candidates for drop %s: %v", drop.ID, err)
+		httputils.RespondWithServerError(w, err, "Failed to suggest tags: "+err.Error())
+		return
+	}
+
+	suggestions := suggestTags(drop, candidates)
+	if len(suggestions) > maxSuggestedTags {
+		suggestions = suggestions[:maxSuggestedTags]
+	}
+	httputils.RespondWithJSON(w, http.StatusOK, suggestions)
+}
+
+// suggestTags scores each candidate tag by where its name appears in the drop and returns
+// the matching ones, best first. Ties go to the more used tag, then to the name.
+func suggestTags(drop db.Drop, candidates []db.ListTagCandidatesForDropRow) []SuggestedTagResponse {
+	fieldWords := make([][]string, len(suggestionFields))
+	for i, field := range suggestionFields {
+		fieldWords[i] = splitWords(field.text(drop))
+	}
+
+	suggestions := []SuggestedTagResponse{}
+	for _, tag := range candidates {
+		tagWords := splitWords(tag.Name)
+		if len(tagWords) == 0 {
+			continue
+		}
+		suggestion := SuggestedTagResponse{ID: tag.ID, Name: tag.Name, DropCount: tag.DropCount}
+		for i, field := range suggestionFields {
+			if containsWords(fieldWords[i], tagWords) {
+				suggestion.Score += field.weight
+				suggestion.MatchedIn = append(suggestion.MatchedIn, field.name)
+			}
+		}
+		if suggestion.Score > 0 {
+			suggestions = append(suggestions, suggestion)
+		}
+	}
+
+	sort.Slice(suggestions, func(i, j int) bool {
+		a, b := suggestions[i], suggestions[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		if a.DropCount != b.DropCount {
+			return a.DropCount > b.DropCount
+		}
+		return a.Name < b.Name
+	})
+	return suggestions
+}
+
+// splitWords lower-cases s and splits it into runs of letters and digits, so punctuation,
+// URL separators and hyphens all separate words.
+func splitWords(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
+
+// containsWords reports whether words holds phrase as consecutive words. A word followed by
+// "s" also matches, so the tag "goroutine" is found in "goroutines".
+func containsWords(words, phrase []string) bool {
+	for start := 0; start+len(phrase) <= len(words); start++ {
+		matched := true
+		for i, want := range phrase {
+			if got := words[start+i]; got != want && got != want+"s" {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	db "github.com/nouvadev/dropwise/internal/database/sqlc"
+	"github.com/nouvadev/dropwise/internal/middleware"
+	"github.com/nouvadev/dropwise/internal/server/httputils"
+)
+
+// DropChangesResponse lists what changed in the active workspace since a point in time.
+type DropChangesResponse struct {
+	Drops   []DropResponse `json:"drops"`   // Created or updated drops, oldest change first
+	Deleted []DeletedDrop  `json:"deleted"` // Drops deleted since then
+	// NextUpdatedSince is the updated_since value to send on the next sync.
+	NextUpdatedSince time.Time `json:"next_updated_since"`
+}
+
+// DeletedDrop identifies a drop that no longer exists.
+type DeletedDrop struct {
+	ID        uuid.UUID `json:"id"`
+	DeletedAt time.Time `json:"deleted_at"`
+}
+
+// listDropChanges responds with the drops created, updated or deleted after since, for
+// clients that keep a local copy and only fetch the difference. Status changes, including
+// the worker sending a drop, update updated_at and so are included.
+// GET /api/v1/drops?updated_since=2025-06-01T00:00:00Z
+func (h *DropsHandler) listDropChanges(w http.ResponseWriter, r *http.Request, userUUID uuid.UUID, since time.Time) {
+	workspaceID := middleware.GetWorkspaceIDFromContext(r)
+
+	drops, err := h.APIConfig.DB.GetDropsUpdatedSince(r.Context(), db.GetDropsUpdatedSinceParams{
+		UserUuid:    uuid.NullUUID{UUID: userUUID, Valid: true},
+		WorkspaceID: workspaceID,
+		Since:       since,
+	})
+	if err != nil {
+		log.Printf("Error fetching drops updated since %s for UserUUID %s: %v", since, userUUID, err)
+		httputils.RespondWithServerError(w, err, "Failed to fetch drops: "+err.Error())
+		return
+	}
+
+	tombstones, err := h.APIConfig.DB.ListDropTombstonesSince(r.Context(), db.ListDropTombstonesSinceParams{
+		UserUuid:    userUUID,
+		WorkspaceID: workspaceID,
+		Since:       since,
+	})
+	if err != nil {
+		log.Printf("Error fetching drops deleted since %s for UserUUID %s: %v", since, userUUID, err)
+		httputils.RespondWithServerError(w, err, "Failed to fetch drops: "+err.Error())
+		return
+	}
+
+	resp := DropChangesResponse{
+		Drops:            make([]DropResponse, 0, len(drops)),
+		Deleted:          make([]DeletedDrop, 0, len(tombstones)),
+		NextUpdatedSince: since.UTC(),
+	}
+
+	dropIDs := make([]uuid.UUID, len(drops))
+	for i, drop := range drops {
+		dropIDs[i] = drop.ID
+	}
+	tagsByDrop := h.tagNamesForDrops(r, dropIDs)
+	for _, drop := range drops {
+		resp.Drops = append(resp.Drops, toDropResponse(drop, tagsByDrop[drop.ID], r, h.APIConfig.MaxSendCount))
+		if drop.UpdatedAt.After(resp.NextUpdatedSince) {
+			resp.NextUpdatedSince = drop.UpdatedAt.UTC()
+		}
+	}
+	for _, tombstone := range tombstones {
+		resp.Deleted = append(resp.Deleted, DeletedDrop{ID: tombstone.DropID, DeletedAt: tombstone.DeletedAt.UTC()})
+		if tombstone.DeletedAt.After(resp.NextUpdatedSince) {
+			resp.NextUpdatedSince = tombstone.DeletedAt.UTC()
+		}
+	}
+
+	log.Printf("Found %d changed and %d deleted drops since %s for UserUUID: %s", len(resp.Drops), len(resp.Deleted), since, userUUID)
+	httputils.RespondWithJSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nouvadev/dropwise/internal/middleware"
+)
+
+// dropResponseMappers maps each API version newer than v1 to the function reshaping a
+// DropResponse for it. v1 is the DropResponse struct itself.
+var dropResponseMappers = map[int]func(DropResponse) any{
+	middleware.APIVersion2: toDropResponseV2,
+}
+
+// MarshalJSON writes the drop in the shape of the API version it was built for.
+func (d DropResponse) MarshalJSON() ([]byte, error) {
+	if mapper, ok := dropResponseMappers[d.apiVersion]; ok {
+		return json.Marshal(mapper(d))
+	}
+	type dropResponseV1 DropResponse // Has no methods, so marshalling it doesn't recurse
+	return json.Marshal(dropResponseV1(d))
+}
+
+// DropResponseV2 is a drop in API version 2: the fields deciding when the drop is sent
+// next are grouped under schedule.
+type DropResponseV2 struct {
+	ID            uuid.UUID                `json:"id"`
+	WorkspaceID   *uuid.UUID               `json:"workspace_id"`
+	Topic         string                   `json:"topic"`
+	URL           string                   `json:"url"`
+	UserNotes     *string                  `json:"user_notes"`
+	UserNotesHTML *string                  `json:"user_notes_html,omitempty"`
+	Excerpt       *string                  `json:"excerpt"`
+	AddedDate     time.Time                `json:"added_date"`
+	UpdatedAt     time.Time                `json:"updated_at"`
+	Status        string                   `json:"status"`
+	Priority      *int32                   `json:"priority"`
+	Channel       string                   `json:"channel"`
+	Tags          []string                 `json:"tags"`
+	Collections   []DropCollectionResponse `json:"collections,omitzero"`
+	Metadata      json.RawMessage          `json:"metadata"`
+	Schedule      DropScheduleResponse     `json:"schedule"`
+}
+
+// DropScheduleResponse holds a drop's scheduling state in API version 2.
+type DropScheduleResponse struct {
+	Cron              *string    `json:"cron"` // The drop's cron schedule, null when it follows the review intervals
+	Permanent         bool       `json:"permanent"`
+	SendCount         int32      `json:"send_count"`
+	MaxSendCount      *int       `json:"max_send_count"`
+	LastSentDate      *time.Time `json:"last_sent_date"`
+	NextSendDate      *time.Time `json:"next_send_date"`
+	NextSendLocalDate *string    `json:"next_send_local_date"`
+	DueDate           *string    `json:"due_date"`
+	DueToday          bool       `json:"due_today"`
+	EaseFactor        float64    `json:"ease_factor"`
+	IntervalDays      *int32     `json:"interval_days"`
+}
+
+// toDropResponseV2 reshapes a drop for API version 2.
+func toDropResponseV2(d DropResponse) any {
+	return DropResponseV2{
+		ID:            d.ID,
+		WorkspaceID:   d.WorkspaceID,
+		Topic:         d.Topic,
+		URL:           d.URL,
+		UserNotes:     d.UserNotes,
+		UserNotesHTML: d.UserNotesHTML,
+		Excerpt:       d.Excerpt,
+		AddedDate:     d.AddedDate,
+		UpdatedAt:     d.UpdatedAt,
+		Status:        d.Status,
+		Priority:      d.Priority,
+		Channel:       d.Channel,
+		Tags:          d.Tags,
+		Collections:   d.Collections,
+		Metadata:      d.Metadata,
+		Schedule: DropScheduleResponse{
+			Cron:              d.Schedule,
+			Permanent:         d.Permanent,
+			SendCount:         d.SendCount,
+			MaxSendCount:      d.MaxSendCount,
+			LastSentDate:      d.LastSentDate,
+			NextSendDate:      d.NextSendDate,
+			NextSendLocalDate: d.NextSendLocalDate,
+			DueDate:           d.DueDate,
+			DueToday:          d.DueToday,
+			EaseFactor:        d.EaseFactor,
+			IntervalDays:      d.IntervalDays,
+		},
+	}
+}
+
+// MarshalJSON adds shared_tags to the drop, which would otherwise be lost to the
+// MarshalJSON promoted from the embedded DropResponse.
+func (rd RelatedDropResponse) MarshalJSON() ([]byte, error) {
+	encoded, err := rd.DropResponse.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	return appendJSONField(encoded, "shared_tags", strconv.AppendInt(nil, rd.SharedTags, 10)), nil
+}
+
+// MarshalJSON adds score to the drop when the search ranked it, like RelatedDropResponse does
+// with shared_tags.
+func (sd SearchDropResponse) MarshalJSON() ([]byte, error) {
+	encoded, err := sd.DropResponse.MarshalJSON()
+	if err != nil || sd.Score == nil {
+		return encoded, err
+	}
+	return appendJSONField(encoded, "score", strconv.AppendFloat(nil, *sd.Score, 'f', -1, 64)), nil
+}
+
+// appendJSONField adds a field with an already encoded value to the end of a JSON object.
+func appendJSONField(object []byte, name string, value []byte) []byte {
+	object = bytes.TrimSuffix(object, []byte("}"))
+	object = append(object, `,"`+name+`":`...)
+	object = append(object, value...)
+	return append(object, '}')
+}
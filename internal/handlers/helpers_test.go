@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/nouvadev/dropwise/internal/config"
+	db "github.com/nouvadev/dropwise/internal/database/sqlc"
+	"github.com/nouvadev/dropwise/internal/middleware"
+	"github.com/nouvadev/dropwise/internal/testdb"
+)
+
+// newTestConfig returns an APIConfig backed by a fresh test database, skipping the test
+// when none is configured.
+func newTestConfig(t *testing.T) (*config.APIConfig, *testdb.Database) {
+	t.Helper()
+	database := testdb.New(t)
+	return &config.APIConfig{DB: database.Queries, DBConn: database.Conn, DBRead: database.Queries}, database
+}
+
+// authedRequest builds a request as the auth middleware would hand it to a handler for
+// the user, in their personal space.
+func authedRequest(method, target string, body io.Reader, userID uuid.UUID) *http.Request {
+	r := httptest.NewRequest(method, target, body)
+	return r.WithContext(context.WithValue(r.Context(), middleware.UserIDKey, userID))
+}
+
+// tagDrop attaches the named tag to a drop, creating the tag if needed.
+func tagDrop(t *testing.T, q *db.Queries, dropID uuid.UUID, name string) db.Tag {
+	t.Helper()
+	tag, err := q.CreateTag(context.Background(), name)
+	if err != nil {
+		t.Fatalf("creating tag: %v", err)
+	}
+	if err := q.AddTagToDrop(context.Background(), db.AddTagToDropParams{DropsID: dropID, TagID: tag.ID}); err != nil {
+		t.Fatalf("tagging drop: %v", err)
+	}
+	return tag
+}
@@ -0,0 +1,197 @@
+package handlers
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"net/mail"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	db "github.com/nouvadev/dropwise/internal/database/sqlc"
+	"github.com/nouvadev/dropwise/internal/delivery"
+	"github.com/nouvadev/dropwise/internal/events"
+	"github.com/nouvadev/dropwise/internal/metadata"
+	"github.com/nouvadev/dropwise/internal/server/httputils"
+)
+
+const (
+	// maxInboundEmailBytes caps the size of a forwarded email, attachments included.
+	maxInboundEmailBytes = 10 << 20
+	// inboundSignatureMaxAge is how old a webhook's signed timestamp may be, against replays.
+	inboundSignatureMaxAge = 5 * time.Minute
+)
+
+// inboundURLPattern finds http(s) links in the text of a forwarded email.
+var inboundURLPattern = regexp.MustCompile(`https?://[^\s<>"'()\[\]]+`)
+
+// inboundDropMetadata marks drops created from a forwarded email.
+var inboundDropMetadata = json.RawMessage(`{"source":"email"}`)
+
+// InboundEmailHandler creates a drop from an email forwarded to the Mailgun inbound route.
+// The webhook is authenticated by its Mailgun signature. The From address of an email can be
+// forged, so the user is found by the secret token in the recipient address instead, see
+// MeHandler.InboundEmailAddressHandler. The first link in the body (or the subject) becomes
+// the drop and the subject its topic. It is created in the user's personal space.
+// Mailgun retries on errors other than 406, so emails that can never become a drop get 406.
+// POST /api/v1/inbound/email
+func (h *DropsHandler) InboundEmailHandler(w http.ResponseWriter, r *http.Request) {
+	if h.APIConfig.InboundEmailSigningKey == "" {
+		httputils.RespondWithError(w, http.StatusNotFound, "Inbound email is not enabled")
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxInboundEmailBytes)
+	defer r.Body.Close()
+	if err := r.ParseMultipartForm(maxInboundEmailBytes); err != nil && !errors.Is(err, http.ErrNotMultipart) {
+		httputils.RespondWithError(w, http.StatusBadRequest, "Invalid inbound email payload: "+err.Error())
+		return
+	}
+
+	if !validMailgunSignature(h.APIConfig.InboundEmailSigningKey, r.FormValue("timestamp"), r.FormValue("token"), r.FormValue("signature"), time.Now()) {
+		log.Printf("Rejected inbound email with an invalid or expired signature")
+		httputils.RespondWithError(w, http.StatusUnauthorized, "Invalid webhook signature")
+		return
+	}
+
+	token := inboundToken(h.APIConfig.InboundEmailAddress, r.FormValue("recipient"))
+	if token == "" {
+		log.Printf("Rejected inbound email to %q, not a user's inbound address", r.FormValue("recipient"))
+		httputils.RespondWithError(w, http.StatusNotAcceptable, "Recipient is not a Dropwise inbound address")
+		return
+	}
+	userUUID, err := h.APIConfig.DB.GetUserUUIDByInboundEmailToken(r.Context(), token)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			log.Printf("Rejected inbound email to %q, no user has that address", r.FormValue("recipient"))
+			httputils.RespondWithError(w, http.StatusNotAcceptable, "Recipient is not a Dropwise inbound address")
+			return
+		}
+		log.Printf("Error looking up inbound email recipient: %v", err)
+		httputils.RespondWithServerError(w, err, "Failed to look up recipient: "+err.Error())
+		return
+	}
+
+	subject := strings.TrimSpace(r.FormValue("subject"))
+	dropURL := firstURL(r.FormValue("body-plain"))
+	if dropURL == "" {
+		dropURL = firstURL(subject)
+	}
+	if dropURL == "" {
+		httputils.RespondWithError(w, http.StatusNotAcceptable, "No link found in the email")
+		return
+	}
+
+	var pageMeta metadata.PageMetadata
+	if fetched, err := h.Fetcher.Fetch(r.Context(), dropURL); err != nil {
+		log.Printf("Could not fetch metadata for inbound URL %s: %v", dropURL, err)
+	} else {
+		pageMeta = fetched
+	}
+
+	topic := forwardedSubject(subject)
+	if topic == "" || topic == dropURL {
+		topic = pageMeta.Title
+	}
+	if topic == "" {
+		topic = dropURL
+	}
+
+	params := db.CreateDropParams{
+		UserUuid: uuid.NullUUID{UUID: userUUID, Valid: true},
+		Topic:    topic,
+		Url:      dropURL,
+		Channel:  delivery.Default,
+		Metadata: inboundDropMetadata,
+	}
+	if pageMeta.Description != "" {
+		params.Excerpt = sql.NullString{String: pageMeta.Description, Valid: true}
+	}
+
+	createdDrop, err := h.APIConfig.DB.CreateDrop(r.Context(), params)
+	if err != nil {
+		log.Printf("Error creating drop from inbound email for UserUUID %s: %v", userUUID, err)
+		httputils.RespondWithServerError(w, err, "Failed to create drop: "+err.Error())
+		return
+	}
+	h.publishDropEvent(userUUID, events.DropCreated, createdDrop)
+	h.APIConfig.DropCreationMonitor.Observe(r.Context(), userUUID, 1)
+
+	log.Printf("Created drop %s from an inbound email for UserUUID: %s", createdDrop.ID, userUUID)
+	httputils.RespondWithJSON(w, http.StatusCreated, map[string]uuid.UUID{"id": createdDrop.ID})
+}
+
+// validMailgunSignature checks a Mailgun webhook signature: the hex HMAC-SHA256 of the
+// timestamp and token under the signing key. Timestamps older than inboundSignatureMaxAge
+// are refused so a captured request can't be replayed later.
+func validMailgunSignature(signingKey, timestamp, token, signature string, now time.Time) bool {
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil || token == "" {
+		return false
+	}
+	if age := now.Sub(time.Unix(seconds, 0)); age > inboundSignatureMaxAge || age < -inboundSignatureMaxAge {
+		return false
+	}
+	got, err := hex.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(signingKey))
+	mac.Write([]byte(timestamp + token))
+	return hmac.Equal(got, mac.Sum(nil))
+}
+
+// inboundAddress returns the user's inbound address: base with the token after a "+".
+func inboundAddress(base, token string) string {
+	local, domain, _ := strings.Cut(base, "@")
+	return local + "+" + token + "@" + domain
+}
+
+// inboundToken returns the user token of the first recipient that is an inbound address
+// built on base by inboundAddress, or "". Addresses are compared case-insensitively.
+func inboundToken(base, recipients string) string {
+	baseLocal, baseDomain, ok := strings.Cut(base, "@")
+	if !ok {
+		return ""
+	}
+	addrs, err := mail.ParseAddressList(recipients)
+	if err != nil {
+		return ""
+	}
+	for _, addr := range addrs {
+		local, domain, _ := strings.Cut(strings.ToLower(addr.Address), "@")
+		prefix, token, ok := strings.Cut(local, "+")
+		if ok && token != "" && prefix == baseLocal && domain == baseDomain {
+			return token
+		}
+	}
+	return ""
+}
+
+// firstURL returns the first http(s) link in text, without trailing punctuation, or "".
+func firstURL(text string) string {
+	return strings.TrimRight(inboundURLPattern.FindString(text), ".,;:!?")
+}
+
+// forwardedSubject strips the "Fwd:" or "Fw:" prefixes mail clients add to a forwarded subject.
+func forwardedSubject(subject string) string {
+	for {
+		lower := strings.ToLower(subject)
+		switch {
+		case strings.HasPrefix(lower, "fwd:"):
+			subject = strings.TrimSpace(subject[len("fwd:"):])
+		case strings.HasPrefix(lower, "fw:"):
+			subject = strings.TrimSpace(subject[len("fw:"):])
+		default:
+			return subject
+		}
+	}
+}
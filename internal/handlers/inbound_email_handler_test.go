@@ -0,0 +1,195 @@
+package handlers
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	db "github.com/nouvadev/dropwise/internal/database/sqlc"
+	"github.com/nouvadev/dropwise/internal/testdb"
+)
+
+// mailgunSignature signs timestamp and token the way Mailgun does.
+func mailgunSignature(key, timestamp, token string) string {
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(timestamp + token))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestValidMailgunSignature(t *testing.T) {
+	const key = "signing-key"
+	now := time.Unix(1_700_000_000, 0)
+	ts := func(d time.Duration) string { return strconv.FormatInt(now.Add(d).Unix(), 10) }
+
+	tests := []struct {
+		name      string
+		key       string
+		timestamp string
+		token     string
+		signature string
+		want      bool
+	}{
+		{"valid", key, ts(0), "abc", mailgunSignature(key, ts(0), "abc"), true},
+		{"slightly old", key, ts(-4 * time.Minute), "abc", mailgunSignature(key, ts(-4*time.Minute), "abc"), true},
+		{"wrong key", "other-key", ts(0), "abc", mailgunSignature(key, ts(0), "abc"), false},
+		{"other token", key, ts(0), "abd", mailgunSignature(key, ts(0), "abc"), false},
+		{"stale", key, ts(-6 * time.Minute), "abc", mailgunSignature(key, ts(-6*time.Minute), "abc"), false},
+		{"future", key, ts(6 * time.Minute), "abc", mailgunSignature(key, ts(6*time.Minute), "abc"), false},
+		{"bad hex", key, ts(0), "abc", "not-hex", false},
+		{"truncated", key, ts(0), "abc", mailgunSignature(key, ts(0), "abc")[:32], false},
+		{"empty token", key, ts(0), "", mailgunSignature(key, ts(0), ""), false},
+		{"timestamp not a number", key, "soon", "abc", mailgunSignature(key, "soon", "abc"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := validMailgunSignature(tt.key, tt.timestamp, tt.token, tt.signature, now); got != tt.want {
+				t.Errorf("validMailgunSignature() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFirstURL(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"Read this: https://example.com/article.", "https://example.com/article"},
+		{"see <https://example.com/a?b=c> and http://example.org", "https://example.com/a?b=c"},
+		{"(http://example.com/x), later", "http://example.com/x"},
+		{"ftp://example.com/file and no web link", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := firstURL(tt.text); got != tt.want {
+			t.Errorf("firstURL(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestForwardedSubject(t *testing.T) {
+	tests := []struct {
+		subject string
+		want    string
+	}{
+		{"Fwd: Great article", "Great article"},
+		{"FW: fwd:  Nested", "Nested"},
+		{"Fwd:", ""},
+		{"Forward thinking", "Forward thinking"},
+		{"Re: Fwd: kept", "Re: Fwd: kept"},
+	}
+	for _, tt := range tests {
+		if got := forwardedSubject(tt.subject); got != tt.want {
+			t.Errorf("forwardedSubject(%q) = %q, want %q", tt.subject, got, tt.want)
+		}
+	}
+}
+
+func TestInboundToken(t *testing.T) {
+	const base = "drops@in.example.com"
+	tests := []struct {
+		name       string
+		recipients string
+		want       string
+	}{
+		{"user address", "drops+abc123@in.example.com", "abc123"},
+		{"case-insensitive", "Drops+ABC123@In.Example.com", "abc123"},
+		{"with a name", "Dropwise <drops+abc123@in.example.com>", "abc123"},
+		{"second recipient", "someone@example.com, drops+abc123@in.example.com", "abc123"},
+		{"base address", "drops@in.example.com", ""},
+		{"empty token", "drops+@in.example.com", ""},
+		{"other local part", "alerts+abc123@in.example.com", ""},
+		{"other domain", "drops+abc123@example.com", ""},
+		{"not an address", "drops+abc123", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := inboundToken(base, tt.recipients); got != tt.want {
+				t.Errorf("inboundToken(%q) = %q, want %q", tt.recipients, got, tt.want)
+			}
+		})
+	}
+	if got := inboundToken(base, inboundAddress(base, "xyz")); got != "xyz" {
+		t.Errorf("inboundToken(inboundAddress(xyz)) = %q, want xyz", got)
+	}
+}
+
+func TestInboundEmailIsMatchedByRecipientNotSender(t *testing.T) {
+	apiCfg, database := newTestConfig(t)
+	apiCfg.InboundEmailSigningKey = "signing-key"
+	apiCfg.InboundEmailAddress = "drops@in.example.com"
+	h := NewDropsHandler(apiCfg)
+	q := database.Queries
+	ctx := context.Background()
+
+	victim := testdb.CreateUser(t, q)
+	victimUser, err := q.GetUserByID(ctx, victim)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := q.EnsureInboundEmailToken(ctx, db.EnsureInboundEmailTokenParams{UserUuid: victim, Token: newInboundEmailToken()})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	deliver := func(sender, recipient string) int {
+		t.Helper()
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		form := url.Values{
+			"timestamp":  {timestamp},
+			"token":      {"webhook-token"},
+			"signature":  {mailgunSignature(apiCfg.InboundEmailSigningKey, timestamp, "webhook-token")},
+			"sender":     {sender},
+			"recipient":  {recipient},
+			"subject":    {"Fwd: An article"},
+			"body-plain": {"http://127.0.0.1/article"}, // A blocked address, so nothing is fetched
+		}
+		r := httptest.NewRequest(http.MethodPost, "/api/v1/inbound/email", strings.NewReader(form.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rec := httptest.NewRecorder()
+		h.InboundEmailHandler(rec, r)
+		return rec.Code
+	}
+
+	tests := []struct {
+		name      string
+		sender    string
+		recipient string
+		want      int
+	}{
+		{"forged sender to the base address", victimUser.Email, apiCfg.InboundEmailAddress, http.StatusNotAcceptable},
+		{"forged sender with a guessed token", victimUser.Email, inboundAddress(apiCfg.InboundEmailAddress, "guessed"), http.StatusNotAcceptable},
+		{"any sender to the user's address", "someone@example.com", inboundAddress(apiCfg.InboundEmailAddress, token), http.StatusCreated},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := deliver(tt.sender, tt.recipient); got != tt.want {
+				t.Errorf("status = %d, want %d", got, tt.want)
+			}
+		})
+	}
+
+	drops, err := q.ListDropsByUserUUID(ctx, db.ListDropsByUserUUIDParams{UserUuid: uuid.NullUUID{UUID: victim, Valid: true}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(drops) != 1 || drops[0].Topic != "An article" {
+		t.Fatalf("user has drops %+v, want only the one sent to their address", drops)
+	}
+
+	if _, err := q.RotateInboundEmailToken(ctx, db.RotateInboundEmailTokenParams{UserUuid: victim, Token: newInboundEmailToken()}); err != nil {
+		t.Fatal(err)
+	}
+	if got := deliver("someone@example.com", inboundAddress(apiCfg.InboundEmailAddress, token)); got != http.StatusNotAcceptable {
+		t.Errorf("status after rotating = %d, want %d", got, http.StatusNotAcceptable)
+	}
+}
@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"database/sql"
+	"log"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nouvadev/dropwise/internal/audit"
+	"github.com/nouvadev/dropwise/internal/auth"
+	"github.com/nouvadev/dropwise/internal/middleware"
+	"github.com/nouvadev/dropwise/internal/schedule"
+	"github.com/nouvadev/dropwise/internal/server/httputils"
+)
+
+// AccountExportResponse is everything stored about a user, as returned by GET /api/v1/me/export.
+type AccountExportResponse struct {
+	ExportedAt  time.Time           `json:"exported_at"`
+	User        UserResponse        `json:"user"`
+	Preferences PreferencesResponse `json:"preferences"`
+	Streak      StreakResponse      `json:"streak"`
+	Drops       []DropResponse      `json:"drops"` // In every workspace, oldest first
+	Tags        []string            `json:"tags"`  // Every tag used on the drops
+}
+
+// DeleteAccountRequest defines the structure for account deletion requests.
+type DeleteAccountRequest struct {
+	Password string `json:"password"` // The current password, to confirm the deletion
+}
+
+// ExportAccountHandler returns a complete copy of the authenticated user's data: profile,
+// preferences, review streak, and all their drops with tags, across every workspace.
+// GET /api/v1/me/export
+func (h *MeHandler) ExportAccountHandler(w http.ResponseWriter, r *http.Request) {
+	userUUID, ok := r.Context().Value(middleware.UserIDKey).(uuid.UUID)
+	if !ok {
+		httputils.RespondWithError(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	user, err := h.APIConfig.DB.GetUserByID(r.Context(), userUUID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			httputils.RespondWithError(w, http.StatusNotFound, "User not found")
+			return
+		}
+		log.Printf("Error fetching user %s for export: %v", userUUID, err)
+		httputils.RespondWithServerError(w, err, "Failed to export account: "+err.Error())
+		return
+	}
+
+	prefs, err := getPreferences(r, h.APIConfig.DB, userUUID)
+	if err != nil {
+		log.Printf("Error fetching preferences of user %s for export: %v", userUUID, err)
+		httputils.RespondWithServerError(w, err, "Failed to export account: "+err.Error())
+		return
+	}
+
+	streak, err := h.APIConfig.DB.GetReviewStreak(r.Context(), userUUID)
+	if err != nil && err != sql.ErrNoRows {
+		log.Printf("Error fetching review streak of user %s for export: %v", userUUID, err)
+		httputils.RespondWithServerError(w, err, "Failed to export account: "+err.Error())
+		return
+	}
+
+	drops, err := h.APIConfig.DB.ListAllDropsByUserUUID(r.Context(), uuid.NullUUID{UUID: userUUID, Valid: true})
+	if err != nil {
+		log.Printf("Error fetching drops of user %s for export: %v", userUUID, err)
+		httputils.RespondWithServerError(w, err, "Failed to export account: "+err.Error())
+		return
+	}
+
+	dropIDs := make([]uuid.UUID, len(drops))
+	for i, drop := range drops {
+		dropIDs[i] = drop.ID
+	}
+	tagRows, err := h.APIConfig.DB.GetTagsForDrops(r.Context(), dropIDs)
+	if err != nil {
+		log.Printf("Error fetching tags of user %s for export: %v", userUUID, err)
+		httputils.RespondWithServerError(w, err, "Failed to export account: "+err.Error())
+		return
+	}
+	tagsByDrop := make(map[uuid.UUID][]string)
+	tagSet := make(map[string]bool)
+	for _, row := range tagRows {
+		tagsByDrop[row.DropsID] = append(tagsByDrop[row.DropsID], row.Name)
+		tagSet[row.Name] = true
+	}
+
+	resp := AccountExportResponse{
+		ExportedAt: time.Now().UTC(),
+		User: UserResponse{
+			ID:        user.ID,
+			Email:     user.Email,
+			CreatedAt: user.CreatedAt.UTC(),
+			UpdatedAt: user.UpdatedAt.UTC(),
+		},
+		Preferences: toPreferencesResponse(prefs),
+		Streak:      toStreakResponse(streak, schedule.LocalDate(time.Now(), middleware.GetUserTimezoneFromContext(r))),
+		Drops:       make([]DropResponse, 0, len(drops)),
+		Tags:        make([]string, 0, len(tagSet)),
+	}
+	for _, drop := range drops {
+		resp.Drops = append(resp.Drops, toDropResponse(drop, tagsByDrop[drop.ID], r, h.APIConfig.MaxSendCount))
+	}
+	for name := range tagSet {
+		resp.Tags = append(resp.Tags, name)
+	}
+	sort.Strings(resp.Tags)
+
+	log.Printf("Exported account data of UserUUID %s (%d drops)", userUUID, len(drops))
+	h.APIConfig.Audit.Record(r.Context(), audit.ActionAccountExported, userUUID, audit.Metadata{
+		Details: map[string]any{"drop_count": len(drops)},
+	})
+	w.Header().Set("Content-Disposition", `attachment; filename="dropwise-export.json"`)
+	httputils.RespondWithJSON(w, http.StatusOK, resp)
+}
+
+// DeleteAccountHandler permanently deletes the authenticated user and everything they own:
+// drops (with their tag links), preferences, streaks, collections, workspace memberships and
+// the workspaces they own. The current password must be given to confirm.
+// DELETE /api/v1/me
+func (h *MeHandler) DeleteAccountHandler(w http.ResponseWriter, r *http.Request) {
+	userUUID, ok := r.Context().Value(middleware.UserIDKey).(uuid.UUID)
+	if !ok {
+		httputils.RespondWithError(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	var req DeleteAccountRequest
+	if !httputils.DecodeJSONBody(w, r, &req) {
+		return
+	}
+	defer r.Body.Close()
+
+	if req.Password == "" {
+		httputils.RespondWithError(w, http.StatusBadRequest, "Password is required to delete the account")
+		return
+	}
+
+	hashedPassword, err := h.APIConfig.DB.GetUserPasswordHash(r.Context(), userUUID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			httputils.RespondWithError(w, http.StatusNotFound, "User not found")
+			return
+		}
+		log.Printf("Error fetching password hash of user %s: %v", userUUID, err)
+		httputils.RespondWithServerError(w, err, "Failed to delete account: "+err.Error())
+		return
+	}
+	if !auth.CheckPasswordHash(req.Password, hashedPassword) {
+		log.Printf("Account deletion refused for user %s: invalid password", userUUID)
+		httputils.RespondWithError(w, http.StatusForbidden, "Incorrect password")
+		return
+	}
+
+	log.Printf("Attempting to delete account of UserUUID: %s", userUUID)
+
+	// Everything the user owns is deleted by the foreign keys' ON DELETE CASCADE.
+	if _, err := h.APIConfig.DB.DeleteUser(r.Context(), userUUID); err != nil {
+		log.Printf("Error deleting user %s: %v", userUUID, err)
+		httputils.RespondWithServerError(w, err, "Failed to delete account: "+err.Error())
+		return
+	}
+
+	log.Printf("Deleted account of UserUUID %s", userUUID)
+	h.APIConfig.Audit.Record(r.Context(), audit.ActionAccountDeleted, userUUID, audit.Metadata{})
+	httputils.RespondWithJSON(w, http.StatusNoContent, nil)
+}
@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	db "github.com/nouvadev/dropwise/internal/database/sqlc"
+	"github.com/nouvadev/dropwise/internal/middleware"
+	"github.com/nouvadev/dropwise/internal/server/httputils"
+)
+
+// Activity timeline pagination defaults.
+const (
+	defaultActivityPageSize = 50
+	maxActivityPageSize     = 200
+)
+
+// ActivityEntry is one event of the user's activity timeline.
+type ActivityEntry struct {
+	Type        string     `json:"type"` // added, sent or archived
+	DropID      uuid.UUID  `json:"drop_id"`
+	Topic       string     `json:"topic"`
+	WorkspaceID *uuid.UUID `json:"workspace_id"` // null for the personal space
+	OccurredAt  time.Time  `json:"occurred_at"`
+}
+
+// toActivityEntry converts a timeline row to an ActivityEntry.
+func toActivityEntry(row db.ListUserActivityRow) ActivityEntry {
+	var workspaceID *uuid.UUID
+	if row.WorkspaceID.Valid {
+		workspaceID = &row.WorkspaceID.UUID
+	}
+	return ActivityEntry{
+		Type:        row.Type,
+		DropID:      row.DropID,
+		Topic:       row.Topic,
+		WorkspaceID: workspaceID,
+		OccurredAt:  row.OccurredAt.UTC(),
+	}
+}
+
+// ActivityHandler returns the authenticated user's activity timeline across all workspaces,
+// newest first: the drops they added, were sent and archived.
+// GET /api/v1/me/activity?limit=&offset=
+func (h *MeHandler) ActivityHandler(w http.ResponseWriter, r *http.Request) {
+	userUUID, ok := r.Context().Value(middleware.UserIDKey).(uuid.UUID)
+	if !ok {
+		httputils.RespondWithError(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	limit, offset, err := httputils.ParsePagination(r, defaultActivityPageSize, maxActivityPageSize)
+	if err != nil {
+		httputils.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	rows, err := h.APIConfig.DB.ListUserActivity(r.Context(), db.ListUserActivityParams{
+		UserUuid: uuid.NullUUID{UUID: userUUID, Valid: true},
+		Limit:    int32(limit),
+		Offset:   int32(offset),
+	})
+	if err != nil {
+		log.Printf("Error fetching activity for UserUUID %s: %v", userUUID, err)
+		httputils.RespondWithServerError(w, err, "Failed to fetch activity: "+err.Error())
+		return
+	}
+	total, err := h.APIConfig.DB.CountUserActivity(r.Context(), uuid.NullUUID{UUID: userUUID, Valid: true})
+	if err != nil {
+		log.Printf("Error counting activity for UserUUID %s: %v", userUUID, err)
+		httputils.RespondWithServerError(w, err, "Failed to fetch activity: "+err.Error())
+		return
+	}
+
+	entries := make([]ActivityEntry, 0, len(rows))
+	for _, row := range rows {
+		entries = append(entries, toActivityEntry(row))
+	}
+	httputils.RespondWithJSON(w, http.StatusOK, httputils.PaginatedResponse{
+		Data:       entries,
+		Pagination: httputils.Pagination{Limit: limit, Offset: offset, Total: total},
+	})
+}
@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	db "github.com/nouvadev/dropwise/internal/database/sqlc"
+	"github.com/nouvadev/dropwise/internal/middleware"
+	"github.com/nouvadev/dropwise/internal/schedule"
+	"github.com/nouvadev/dropwise/internal/server/httputils"
+)
+
+const (
+	defaultForecastDays = 14
+	maxForecastDays     = 90
+)
+
+// ForecastDay is the number of drops falling due on one day.
+type ForecastDay struct {
+	Date  string `json:"date"` // YYYY-MM-DD in the request's time zone
+	Count int64  `json:"count"`
+}
+
+// ForecastHandler returns how many of the user's drops fall due on each of the next days,
+// across all workspaces, starting today. Drops already due count toward today.
+// GET /api/v1/me/forecast?days=
+func (h *MeHandler) ForecastHandler(w http.ResponseWriter, r *http.Request) {
+	userUUID, ok := r.Context().Value(middleware.UserIDKey).(uuid.UUID)
+	if !ok {
+		httputils.RespondWithError(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	days := defaultForecastDays
+	if raw := r.URL.Query().Get("days"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 || n > maxForecastDays {
+			httputils.RespondWithError(w, http.StatusBadRequest, "days must be between 1 and 90")
+			return
+		}
+		days = n
+	}
+
+	loc := middleware.GetTimezoneFromContext(r)
+	now := time.Now()
+	today := schedule.LocalDate(now, loc)
+	local := now.In(loc)
+	until := time.Date(local.Year(), local.Month(), local.Day()+days, 0, 0, 0, 0, loc)
+
+	rows, err := h.APIConfig.DB.ForecastDueDropsByDay(r.Context(), db.ForecastDueDropsByDayParams{
+		Tz:       loc.String(),
+		FromDate: today,
+		UserUuid: uuid.NullUUID{UUID: userUUID, Valid: true},
+		Until:    until,
+	})
+	if err != nil {
+		log.Printf("Error forecasting due drops for UserUUID %s: %v", userUUID, err)
+		httputils.RespondWithServerError(w, err, "Failed to forecast drops: "+err.Error())
+		return
+	}
+
+	counts := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		counts[row.Day.Format(time.DateOnly)] = row.Count
+	}
+	forecast := make([]ForecastDay, days)
+	for i := range forecast {
+		date := today.AddDate(0, 0, i).Format(time.DateOnly)
+		forecast[i] = ForecastDay{Date: date, Count: counts[date]}
+	}
+	httputils.RespondWithJSON(w, http.StatusOK, forecast)
+}
@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"database/sql"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nouvadev/dropwise/internal/config"
+	db "github.com/nouvadev/dropwise/internal/database/sqlc"
+	"github.com/nouvadev/dropwise/internal/middleware"
+	"github.com/nouvadev/dropwise/internal/schedule"
+	"github.com/nouvadev/dropwise/internal/server/httputils"
+)
+
+// reviewStatuses are the statuses that count as completing a drop for the review streak.
+var reviewStatuses = map[string]bool{"sent": true, "archived": true}
+
+// MeHandler handles HTTP requests about the authenticated user.
+type MeHandler struct {
+	APIConfig *config.APIConfig
+}
+
+// NewMeHandler creates a new MeHandler.
+func NewMeHandler(apiCfg *config.APIConfig) *MeHandler {
+	return &MeHandler{APIConfig: apiCfg}
+}
+
+// StreakResponse defines the structure for review streak responses.
+type StreakResponse struct {
+	CurrentStreak  int32   `json:"current_streak"`
+	LongestStreak  int32   `json:"longest_streak"`
+	LastReviewDate *string `json:"last_review_date"` // YYYY-MM-DD in the user's time zone
+	ReviewedToday  bool    `json:"reviewed_today"`
+}
+
+// toStreakResponse converts a db.ReviewStreak to a StreakResponse as of today (see schedule.LocalDate).
+// The stored streak is only updated on reviews, so one whose last review is before yesterday
+// is reported as broken.
+func toStreakResponse(streak db.ReviewStreak, today time.Time) StreakResponse {
+	resp := StreakResponse{LongestStreak: streak.LongestStreak}
+	if !streak.LastReviewDate.Valid {
+		return resp
+	}
+	last := streak.LastReviewDate.Time.UTC()
+	lastDate := last.Format(time.DateOnly)
+	resp.LastReviewDate = &lastDate
+	resp.ReviewedToday = !last.Before(today)
+	if !last.Before(today.AddDate(0, 0, -1)) {
+		resp.CurrentStreak = streak.CurrentStreak
+	}
+	return resp
+}
+
+// StreakHandler returns the authenticated user's review streak: the number of consecutive
+// days, in the user's time zone, on which they marked at least one drop sent or archived.
+// GET /api/v1/me/streak
+func (h *MeHandler) StreakHandler(w http.ResponseWriter, r *http.Request) {
+	userUUID, ok := r.Context().Value(middleware.UserIDKey).(uuid.UUID)
+	if !ok {
+		httputils.RespondWithError(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	streak, err := h.APIConfig.DB.GetReviewStreak(r.Context(), userUUID)
+	if err != nil && err != sql.ErrNoRows {
+		log.Printf("Error fetching review streak for UserUUID %s: %v", userUUID, err)
+		httputils.RespondWithServerError(w, err, "Failed to fetch streak: "+err.Error())
+		return
+	}
+
+	today := schedule.LocalDate(time.Now(), middleware.GetUserTimezoneFromContext(r))
+	httputils.RespondWithJSON(w, http.StatusOK, toStreakResponse(streak, today))
+}
+
+// recordReview extends the user's review streak when a drop was given a completing status.
+// The streak is secondary to the status change, so errors are only logged.
+func recordReview(r *http.Request, queries *db.Queries, userUUID uuid.UUID, status string) {
+	if !reviewStatuses[status] {
+		return
+	}
+	_, err := queries.RecordReview(r.Context(), db.RecordReviewParams{
+		UserUuid:   userUUID,
+		ReviewDate: schedule.LocalDate(time.Now(), middleware.GetUserTimezoneFromContext(r)),
+	})
+	if err != nil {
+		log.Printf("Error recording review for UserUUID %s: %v", userUUID, err)
+	}
+}
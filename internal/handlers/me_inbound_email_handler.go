@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"context"
+	"crypto/rand"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+	db "github.com/nouvadev/dropwise/internal/database/sqlc"
+	"github.com/nouvadev/dropwise/internal/middleware"
+	"github.com/nouvadev/dropwise/internal/server/httputils"
+)
+
+// InboundEmailAddressResponse holds the address the user forwards emails to.
+type InboundEmailAddressResponse struct {
+	Address string `json:"address"`
+}
+
+// newInboundEmailToken returns a random user token for an inbound address. It has over
+// 128 random bits, so addresses can't be guessed, and is lower case like email addresses.
+func newInboundEmailToken() string {
+	return strings.ToLower(rand.Text())
+}
+
+// InboundEmailAddressHandler returns the authenticated user's secret inbound email address,
+// creating it on first use. Emails forwarded to it become drops, see InboundEmailHandler.
+// GET /api/v1/me/inbound-email
+func (h *MeHandler) InboundEmailAddressHandler(w http.ResponseWriter, r *http.Request) {
+	h.respondWithInboundEmailAddress(w, r, h.APIConfig.DB.EnsureInboundEmailToken)
+}
+
+// RotateInboundEmailAddressHandler gives the authenticated user a new inbound email address.
+// Emails to the previous one are refused from then on.
+// POST /api/v1/me/inbound-email/rotate
+func (h *MeHandler) RotateInboundEmailAddressHandler(w http.ResponseWriter, r *http.Request) {
+	h.respondWithInboundEmailAddress(w, r, func(ctx context.Context, arg db.EnsureInboundEmailTokenParams) (string, error) {
+		return h.APIConfig.DB.RotateInboundEmailToken(ctx, db.RotateInboundEmailTokenParams(arg))
+	})
+}
+
+// respondWithInboundEmailAddress stores a new token for the user with store, which returns
+// the token in effect, and responds with the address it makes.
+func (h *MeHandler) respondWithInboundEmailAddress(w http.ResponseWriter, r *http.Request, store func(context.Context, db.EnsureInboundEmailTokenParams) (string, error)) {
+	if h.APIConfig.InboundEmailSigningKey == "" {
+		httputils.RespondWithError(w, http.StatusNotFound, "Inbound email is not enabled")
+		return
+	}
+	userUUID, ok := r.Context().Value(middleware.UserIDKey).(uuid.UUID)
+	if !ok {
+		httputils.RespondWithError(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	token, err := store(r.Context(), db.EnsureInboundEmailTokenParams{UserUuid: userUUID, Token: newInboundEmailToken()})
+	if err != nil {
+		log.Printf("Error storing inbound email token for UserUUID %s: %v", userUUID, err)
+		httputils.RespondWithServerError(w, err, "Failed to get inbound email address: "+err.Error())
+		return
+	}
+	httputils.RespondWithJSON(w, http.StatusOK, InboundEmailAddressResponse{Address: inboundAddress(h.APIConfig.InboundEmailAddress, token)})
+}
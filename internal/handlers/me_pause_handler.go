@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"database/sql"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	db "github.com/nouvadev/dropwise/internal/database/sqlc"
+	"github.com/nouvadev/dropwise/internal/events"
+	"github.com/nouvadev/dropwise/internal/middleware"
+	"github.com/nouvadev/dropwise/internal/server/httputils"
+)
+
+// PauseRemindersRequest is the optional body of POST /api/v1/me/pause.
+type PauseRemindersRequest struct {
+	PausedUntil *time.Time `json:"paused_until,omitempty"` // Omit to pause until unpaused
+}
+
+// UnpauseRemindersRequest is the optional body of POST /api/v1/me/unpause.
+type UnpauseRemindersRequest struct {
+	ShiftSchedules bool `json:"shift_schedules"`
+}
+
+// ReminderPauseResponse describes the user's reminder pause.
+type ReminderPauseResponse struct {
+	RemindersPaused bool       `json:"reminders_paused"`
+	PausedAt        *time.Time `json:"paused_at"`
+	PausedUntil     *time.Time `json:"paused_until"`            // null while paused means until unpaused
+	ShiftedCount    *int       `json:"shifted_count,omitempty"` // Set by unpause
+}
+
+// remindersPaused reports whether the user's reminders are paused at now. A pause with
+// paused_until ends by itself once that time has passed.
+func remindersPaused(prefs db.UserPreference, now time.Time) bool {
+	return prefs.RemindersPaused && (!prefs.PausedUntil.Valid || prefs.PausedUntil.Time.After(now))
+}
+
+// PauseRemindersHandler pauses every send to the authenticated user, in all workspaces, until
+// they unpause or until paused_until. Drops keep their schedules while paused.
+// POST /api/v1/me/pause
+func (h *MeHandler) PauseRemindersHandler(w http.ResponseWriter, r *http.Request) {
+	userUUID, ok := r.Context().Value(middleware.UserIDKey).(uuid.UUID)
+	if !ok {
+		httputils.RespondWithError(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	var req PauseRemindersRequest
+	if r.ContentLength != 0 { // The body is optional
+		if !httputils.DecodeJSONBody(w, r, &req) {
+			return
+		}
+		defer r.Body.Close()
+	}
+	var pausedUntil sql.NullTime
+	if req.PausedUntil != nil {
+		if !req.PausedUntil.After(time.Now()) {
+			httputils.RespondWithError(w, http.StatusBadRequest, "paused_until must be in the future")
+			return
+		}
+		pausedUntil = sql.NullTime{Time: *req.PausedUntil, Valid: true}
+	}
+
+	prefs, err := h.APIConfig.DB.PauseUserReminders(r.Context(), db.PauseUserRemindersParams{
+		UserUuid:    userUUID,
+		PausedUntil: pausedUntil,
+	})
+	if err != nil {
+		log.Printf("Error pausing reminders for UserUUID %s: %v", userUUID, err)
+		httputils.RespondWithServerError(w, err, "Failed to pause reminders: "+err.Error())
+		return
+	}
+
+	log.Printf("Paused reminders for UserUUID %s (until %v)", userUUID, req.PausedUntil)
+	response := ReminderPauseResponse{RemindersPaused: true}
+	if prefs.PausedAt.Valid {
+		t := prefs.PausedAt.Time.UTC()
+		response.PausedAt = &t
+	}
+	if prefs.PausedUntil.Valid {
+		t := prefs.PausedUntil.Time.UTC()
+		response.PausedUntil = &t
+	}
+	httputils.RespondWithJSON(w, http.StatusOK, response)
+}
+
+// UnpauseRemindersHandler resumes sends to the authenticated user. With shift_schedules,
+// every pending send is moved later by how long the pause lasted, so the drops that fell
+// due during the pause don't all arrive at once. Unpausing when not paused does nothing.
+// POST /api/v1/me/unpause
+func (h *MeHandler) UnpauseRemindersHandler(w http.ResponseWriter, r *http.Request) {
+	userUUID, ok := r.Context().Value(middleware.UserIDKey).(uuid.UUID)
+	if !ok {
+		httputils.RespondWithError(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	var req UnpauseRemindersRequest
+	if r.ContentLength != 0 { // The body is optional
+		if !httputils.DecodeJSONBody(w, r, &req) {
+			return
+		}
+		defer r.Body.Close()
+	}
+
+	tx, err := h.APIConfig.DBConn.BeginTx(r.Context(), nil)
+	if err != nil {
+		log.Printf("Error starting transaction for unpausing reminders: %v", err)
+		httputils.RespondWithServerError(w, err, "Failed to unpause reminders")
+		return
+	}
+	defer tx.Rollback()
+	qtx := h.APIConfig.DB.WithTx(tx)
+
+	shifted := []db.Drop{}
+	pause, err := qtx.UnpauseUserReminders(r.Context(), userUUID)
+	if err != nil && err != sql.ErrNoRows {
+		log.Printf("Error unpausing reminders for UserUUID %s: %v", userUUID, err)
+		httputils.RespondWithServerError(w, err, "Failed to unpause reminders: "+err.Error())
+		return
+	}
+	if err == nil && req.ShiftSchedules && pause.PausedAt.Valid {
+		// A pause that already ended by itself lasted until paused_until
+		end := time.Now()
+		if pause.PausedUntil.Valid && pause.PausedUntil.Time.Before(end) {
+			end = pause.PausedUntil.Time
+		}
+		if duration := end.Sub(pause.PausedAt.Time); duration > 0 {
+			shifted, err = qtx.ShiftDropSchedules(r.Context(), db.ShiftDropSchedulesParams{
+				Seconds:  duration.Seconds(),
+				UserUuid: uuid.NullUUID{UUID: userUUID, Valid: true},
+			})
+			if err != nil {
+				log.Printf("Error shifting drop schedules for UserUUID %s: %v", userUUID, err)
+				httputils.RespondWithServerError(w, err, "Failed to unpause reminders: "+err.Error())
+				return
+			}
+			log.Printf("Shifting %d drops of UserUUID %s by %v", len(shifted), userUUID, duration.Round(time.Second))
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("Error committing unpause for UserUUID %s: %v", userUUID, err)
+		httputils.RespondWithServerError(w, err, "Failed to unpause reminders")
+		return
+	}
+
+	for _, drop := range shifted {
+		h.APIConfig.Events.Publish(userUUID, events.Event{Type: events.DropUpdated, DropID: drop.ID, WorkspaceID: drop.WorkspaceID})
+	}
+
+	log.Printf("Unpaused reminders for UserUUID %s", userUUID)
+	shiftedCount := len(shifted)
+	httputils.RespondWithJSON(w, http.StatusOK, ReminderPauseResponse{RemindersPaused: false, ShiftedCount: &shiftedCount})
+}
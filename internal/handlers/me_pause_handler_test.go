@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	db "github.com/nouvadev/dropwise/internal/database/sqlc"
+	"github.com/nouvadev/dropwise/internal/testdb"
+)
+
+func TestUnpauseShiftsPendingDropsOnly(t *testing.T) {
+	apiCfg, database := newTestConfig(t)
+	h := NewMeHandler(apiCfg)
+	ctx := context.Background()
+	userID := testdb.CreateUser(t, database.Queries)
+
+	// Every drop has a send scheduled in a week, then some are moved out of rotation
+	scheduled := time.Now().UTC().Add(7 * 24 * time.Hour).Truncate(time.Second)
+	newDrop := func(status string, dueDate bool) uuid.UUID {
+		drop := testdb.CreateDrop(t, database.Queries, userID, func(p *db.CreateDropParams) {
+			p.NextSendDate = sql.NullTime{Time: scheduled, Valid: true}
+		})
+		if _, err := database.Conn.Exec("UPDATE drops SET status = $1 WHERE id = $2", status, drop.ID); err != nil {
+			t.Fatal(err)
+		}
+		if dueDate {
+			if _, err := database.Conn.Exec("UPDATE drops SET due_date = $1 WHERE id = $2", scheduled, drop.ID); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return drop.ID
+	}
+	tests := []struct {
+		name   string
+		id     uuid.UUID
+		shifts bool
+	}{
+		{"new drop", newDrop("new", false), true},
+		{"sent drop awaiting its next repetition", newDrop("sent", false), true},
+		{"archived drop", newDrop("archived", false), false},
+		{"graduated drop", newDrop("graduated", false), false},
+		{"snoozed drop", newDrop("snoozed", false), false},
+		{"drop pinned to a due date", newDrop("new", true), false},
+	}
+
+	// Pause two days ago, then unpause with shift_schedules
+	if _, err := database.Queries.PauseUserReminders(ctx, db.PauseUserRemindersParams{UserUuid: userID}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := database.Conn.Exec("UPDATE user_preferences SET paused_at = NOW() - INTERVAL '2 days' WHERE user_uuid = $1", userID); err != nil {
+		t.Fatal(err)
+	}
+	rec := httptest.NewRecorder()
+	h.UnpauseRemindersHandler(rec, authedRequest(http.MethodPost, "/api/v1/me/unpause", strings.NewReader(`{"shift_schedules": true}`), userID))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	var resp ReminderPauseResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.ShiftedCount == nil || *resp.ShiftedCount != 2 {
+		t.Errorf("shifted_count = %v, want 2", resp.ShiftedCount)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			drop, err := database.Queries.GetDrop(ctx, tt.id)
+			if err != nil {
+				t.Fatal(err)
+			}
+			shift := drop.NextSendDate.Time.Sub(scheduled)
+			if tt.shifts && (shift < 48*time.Hour-time.Minute || shift > 48*time.Hour+time.Minute) {
+				t.Errorf("next send moved by %v, want about the 48h pause", shift)
+			}
+			if !tt.shifts && shift != 0 {
+				t.Errorf("next send moved by %v, want it left alone", shift)
+			}
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"database/sql"
+	"log"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	db "github.com/nouvadev/dropwise/internal/database/sqlc"
+	"github.com/nouvadev/dropwise/internal/events"
+	"github.com/nouvadev/dropwise/internal/middleware"
+	"github.com/nouvadev/dropwise/internal/server/httputils"
+)
+
+const (
+	defaultRescheduleSpreadDays = 7
+	maxRescheduleSpreadDays     = 365
+)
+
+// RescheduleAllRequest is the optional body of POST /api/v1/me/reschedule-all.
+type RescheduleAllRequest struct {
+	SpreadDays *int `json:"spread_days,omitempty"`
+}
+
+// RescheduleAllResponse reports how many due drops were spread out.
+type RescheduleAllResponse struct {
+	RescheduledCount int `json:"rescheduled_count"`
+	SpreadDays       int `json:"spread_days"`
+}
+
+// RescheduleAllHandler spreads the user's due drops evenly over the coming spread_days days,
+// so coming back after a long break doesn't bring every overdue drop at once. Drops keep
+// the worker's order: the highest priority and most overdue ones stay due today.
+// POST /api/v1/me/reschedule-all
+func (h *MeHandler) RescheduleAllHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		httputils.RespondWithError(w, http.StatusMethodNotAllowed, "Only POST method is allowed")
+		return
+	}
+
+	userUUID, ok := r.Context().Value(middleware.UserIDKey).(uuid.UUID)
+	if !ok {
+		log.Printf("RescheduleAllHandler: UserID not found in context or not a UUID for path %s", r.URL.Path)
+		httputils.RespondWithError(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	var req RescheduleAllRequest
+	if r.ContentLength != 0 { // The body is optional
+		if !httputils.DecodeJSONBody(w, r, &req) {
+			return
+		}
+		defer r.Body.Close()
+	}
+	spreadDays := defaultRescheduleSpreadDays
+	if req.SpreadDays != nil {
+		spreadDays = *req.SpreadDays
+	}
+	if spreadDays < 1 || spreadDays > maxRescheduleSpreadDays {
+		httputils.RespondWithError(w, http.StatusBadRequest, "spread_days must be between 1 and 365")
+		return
+	}
+
+	tx, err := h.APIConfig.DBConn.BeginTx(r.Context(), nil)
+	if err != nil {
+		log.Printf("Error starting transaction for rescheduling due drops: %v", err)
+		httputils.RespondWithServerError(w, err, "Failed to reschedule drops")
+		return
+	}
+	defer tx.Rollback()
+	qtx := h.APIConfig.DB.WithTx(tx)
+
+	now := time.Now().UTC()
+	dueBefore, lastSentBefore := h.APIConfig.DueWindow.Cutoffs(now)
+	due, err := qtx.GetDueDropsByUserUUID(r.Context(), db.GetDueDropsByUserUUIDParams{
+		UserUuid:       uuid.NullUUID{UUID: userUUID, Valid: true},
+		DueBefore:      dueBefore,
+		LastSentBefore: lastSentBefore,
+		Limit:          math.MaxInt32, // The query always takes a limit; every due drop is wanted here
+	})
+	if err != nil {
+		log.Printf("Error fetching due drops to reschedule for UserUUID %s: %v", userUUID, err)
+		httputils.RespondWithServerError(w, err, "Failed to reschedule drops: "+err.Error())
+		return
+	}
+
+	rescheduled := make([]db.Drop, 0, len(due))
+	for i, drop := range due {
+		day := i * spreadDays / len(due)
+		updated, err := qtx.SetDropNextSendDate(r.Context(), db.SetDropNextSendDateParams{
+			ID:           drop.ID,
+			UserUuid:     uuid.NullUUID{UUID: userUUID, Valid: true},
+			NextSendDate: sql.NullTime{Time: now.AddDate(0, 0, day), Valid: true},
+		})
+		if err != nil {
+			log.Printf("Error rescheduling drop %s for UserUUID %s: %v", drop.ID, userUUID, err)
+			httputils.RespondWithServerError(w, err, "Failed to reschedule drops: "+err.Error())
+			return
+		}
+		rescheduled = append(rescheduled, updated)
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("Error committing rescheduled drops for UserUUID %s: %v", userUUID, err)
+		httputils.RespondWithServerError(w, err, "Failed to reschedule drops")
+		return
+	}
+
+	for _, drop := range rescheduled {
+		h.APIConfig.Events.Publish(userUUID, events.Event{Type: events.DropUpdated, DropID: drop.ID, WorkspaceID: drop.WorkspaceID})
+	}
+
+	log.Printf("Spread %d due drops over %d days for UserUUID: %s", len(rescheduled), spreadDays, userUUID)
+	httputils.RespondWithJSON(w, http.StatusOK, RescheduleAllResponse{RescheduledCount: len(rescheduled), SpreadDays: spreadDays})
+}
@@ -0,0 +1,283 @@
+package handlers
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nouvadev/dropwise/internal/config"
+	db "github.com/nouvadev/dropwise/internal/database/sqlc"
+	"github.com/nouvadev/dropwise/internal/delivery"
+	"github.com/nouvadev/dropwise/internal/middleware"
+	"github.com/nouvadev/dropwise/internal/schedule"
+	"github.com/nouvadev/dropwise/internal/server/httputils"
+)
+
+// defaultDailyDropHour is the local hour from which the daily drop is sent, matching the column default.
+const defaultDailyDropHour = 9
+
+// PreferencesHandler handles HTTP requests for the user's preferences.
+type PreferencesHandler struct {
+	APIConfig *config.APIConfig
+}
+
+// NewPreferencesHandler creates a new PreferencesHandler.
+func NewPreferencesHandler(apiCfg *config.APIConfig) *PreferencesHandler {
+	return &PreferencesHandler{APIConfig: apiCfg}
+}
+
+// UpdatePreferencesRequest defines the expected request body for updating preferences.
+// Omitted fields keep their current value; an empty webhook URL removes it.
+type UpdatePreferencesRequest struct {
+	NotifyWhenCaughtUp   *bool   `json:"notify_when_caught_up,omitempty"`
+	CaughtUpWebhookURL   *string `json:"caught_up_webhook_url,omitempty"`
+	DefaultChannel       *string `json:"default_channel,omitempty"` // email, slack or both
+	SlackWebhookURL      *string `json:"slack_webhook_url,omitempty"`
+	Timezone             *string `json:"timezone,omitempty"` // IANA name, empty for the server default
+	DailyDrop            *bool   `json:"daily_drop,omitempty"`
+	DailyDropHour        *int32  `json:"daily_drop_hour,omitempty"`      // 0-23, in the user's time zone
+	RepetitionIntervals  *[]int  `json:"repetition_intervals,omitempty"` // Days between sends; empty for the default sequence
+	AutoArchiveAfterSend *bool   `json:"auto_archive_after_send,omitempty"`
+	DailyDropLimit       *int32  `json:"daily_drop_limit,omitempty"` // Most drops sent per local day; 0 removes the limit
+}
+
+// PreferencesResponse defines the structure for preferences responses.
+type PreferencesResponse struct {
+	NotifyWhenCaughtUp   bool       `json:"notify_when_caught_up"`
+	CaughtUpWebhookURL   *string    `json:"caught_up_webhook_url"`
+	DefaultChannel       string     `json:"default_channel"`
+	SlackWebhookURL      *string    `json:"slack_webhook_url"`
+	Timezone             *string    `json:"timezone"` // null means the server's DEFAULT_TIMEZONE
+	DailyDrop            bool       `json:"daily_drop"`
+	DailyDropHour        int32      `json:"daily_drop_hour"`
+	RepetitionIntervals  []int      `json:"repetition_intervals"`    // null means the default sequence
+	AutoArchiveAfterSend bool       `json:"auto_archive_after_send"` // Sent drops are archived instead of rescheduled
+	DailyDropLimit       *int32     `json:"daily_drop_limit"`        // null means no limit
+	SendsToday           int32      `json:"sends_today"`             // Sends counted towards daily_drop_limit since local midnight
+	RemindersPaused      bool       `json:"reminders_paused"`
+	PausedUntil          *time.Time `json:"paused_until"` // null while paused means until unpaused
+	UpdatedAt            *time.Time `json:"updated_at"`
+}
+
+// toPreferencesResponse converts a db.UserPreference to a PreferencesResponse.
+func toPreferencesResponse(prefs db.UserPreference) PreferencesResponse {
+	var webhookURL *string
+	if prefs.CaughtUpWebhookUrl.Valid {
+		webhookURL = &prefs.CaughtUpWebhookUrl.String
+	}
+	var slackWebhookURL *string
+	if prefs.SlackWebhookUrl.Valid {
+		slackWebhookURL = &prefs.SlackWebhookUrl.String
+	}
+	var timezone *string
+	if prefs.Timezone.Valid {
+		timezone = &prefs.Timezone.String
+	}
+	var repetitionIntervals []int
+	if len(prefs.RepetitionIntervals) > 0 {
+		repetitionIntervals = schedule.NewIntervals(prefs.RepetitionIntervals)
+	}
+	paused := remindersPaused(prefs, time.Now())
+	var pausedUntil *time.Time
+	if paused && prefs.PausedUntil.Valid {
+		t := prefs.PausedUntil.Time.UTC()
+		pausedUntil = &t
+	}
+	var dailyDropLimit *int32
+	if prefs.DailyDropLimit.Valid {
+		dailyDropLimit = &prefs.DailyDropLimit.Int32
+	}
+	var sendsToday int32
+	if prefs.SendsResetAt.Valid && prefs.SendsResetAt.Time.After(time.Now()) {
+		sendsToday = prefs.SendsToday
+	}
+	var updatedAt *time.Time
+	if !prefs.UpdatedAt.IsZero() {
+		t := prefs.UpdatedAt.UTC()
+		updatedAt = &t
+	}
+	return PreferencesResponse{
+		NotifyWhenCaughtUp:   prefs.NotifyWhenCaughtUp,
+		CaughtUpWebhookURL:   webhookURL,
+		DefaultChannel:       prefs.DefaultChannel,
+		SlackWebhookURL:      slackWebhookURL,
+		Timezone:             timezone,
+		DailyDrop:            prefs.DailyDrop,
+		DailyDropHour:        prefs.DailyDropHour,
+		RepetitionIntervals:  repetitionIntervals,
+		AutoArchiveAfterSend: prefs.AutoArchiveAfterSend,
+		DailyDropLimit:       dailyDropLimit,
+		SendsToday:           sendsToday,
+		RemindersPaused:      paused,
+		PausedUntil:          pausedUntil,
+		UpdatedAt:            updatedAt,
+	}
+}
+
+// getPreferences loads the user's preferences, returning the defaults when none are stored.
+func getPreferences(r *http.Request, queries *db.Queries, userUUID uuid.UUID) (db.UserPreference, error) {
+	prefs, err := queries.GetUserPreferences(r.Context(), userUUID)
+	if err == sql.ErrNoRows {
+		return db.UserPreference{UserUuid: userUUID, DefaultChannel: delivery.Email, DailyDropHour: defaultDailyDropHour}, nil
+	}
+	return prefs, err
+}
+
+// GetPreferencesHandler returns the authenticated user's preferences.
+// GET /api/v1/preferences
+func (h *PreferencesHandler) GetPreferencesHandler(w http.ResponseWriter, r *http.Request) {
+	userUUID, ok := r.Context().Value(middleware.UserIDKey).(uuid.UUID)
+	if !ok {
+		httputils.RespondWithError(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	prefs, err := getPreferences(r, h.APIConfig.DB, userUUID)
+	if err != nil {
+		log.Printf("Error fetching preferences for UserUUID %s: %v", userUUID, err)
+		httputils.RespondWithServerError(w, err, "Failed to fetch preferences: "+err.Error())
+		return
+	}
+
+	httputils.RespondWithJSON(w, http.StatusOK, toPreferencesResponse(prefs))
+}
+
+// UpdatePreferencesHandler updates the authenticated user's preferences.
+// PUT /api/v1/preferences
+func (h *PreferencesHandler) UpdatePreferencesHandler(w http.ResponseWriter, r *http.Request) {
+	userUUID, ok := r.Context().Value(middleware.UserIDKey).(uuid.UUID)
+	if !ok {
+		httputils.RespondWithError(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	var req UpdatePreferencesRequest
+	if !httputils.DecodeJSONBody(w, r, &req) {
+		return
+	}
+	defer r.Body.Close()
+
+	prefs, err := getPreferences(r, h.APIConfig.DB, userUUID)
+	if err != nil {
+		log.Printf("Error fetching preferences for UserUUID %s: %v", userUUID, err)
+		httputils.RespondWithServerError(w, err, "Failed to update preferences: "+err.Error())
+		return
+	}
+
+	params := db.UpsertUserPreferencesParams{
+		UserUuid:             userUUID,
+		NotifyWhenCaughtUp:   prefs.NotifyWhenCaughtUp,
+		CaughtUpWebhookUrl:   prefs.CaughtUpWebhookUrl,
+		DefaultChannel:       prefs.DefaultChannel,
+		SlackWebhookUrl:      prefs.SlackWebhookUrl,
+		Timezone:             prefs.Timezone,
+		DailyDrop:            prefs.DailyDrop,
+		DailyDropHour:        prefs.DailyDropHour,
+		RepetitionIntervals:  prefs.RepetitionIntervals,
+		AutoArchiveAfterSend: prefs.AutoArchiveAfterSend,
+		DailyDropLimit:       prefs.DailyDropLimit,
+	}
+	if req.NotifyWhenCaughtUp != nil {
+		params.NotifyWhenCaughtUp = *req.NotifyWhenCaughtUp
+	}
+	if req.CaughtUpWebhookURL != nil {
+		webhookURL, ok := parseWebhookURL(w, "caught_up_webhook_url", *req.CaughtUpWebhookURL)
+		if !ok {
+			return
+		}
+		params.CaughtUpWebhookUrl = webhookURL
+	}
+	if req.DefaultChannel != nil {
+		if !delivery.IsValid(*req.DefaultChannel, false) {
+			httputils.RespondWithError(w, http.StatusBadRequest, "Invalid default_channel value. Allowed: email, slack, both.")
+			return
+		}
+		params.DefaultChannel = *req.DefaultChannel
+	}
+	if req.SlackWebhookURL != nil {
+		webhookURL, ok := parseWebhookURL(w, "slack_webhook_url", *req.SlackWebhookURL)
+		if !ok {
+			return
+		}
+		params.SlackWebhookUrl = webhookURL
+	}
+
+	if req.Timezone != nil {
+		params.Timezone = sql.NullString{}
+		if name := strings.TrimSpace(*req.Timezone); name != "" {
+			if _, err := schedule.LoadTimezone(name); err != nil {
+				httputils.RespondWithError(w, http.StatusBadRequest, "Invalid timezone: "+err.Error())
+				return
+			}
+			params.Timezone = sql.NullString{String: name, Valid: true}
+		}
+	}
+
+	if req.DailyDrop != nil {
+		params.DailyDrop = *req.DailyDrop
+	}
+	if req.DailyDropHour != nil {
+		if *req.DailyDropHour < 0 || *req.DailyDropHour > 23 {
+			httputils.RespondWithError(w, http.StatusBadRequest, fmt.Sprintf("daily_drop_hour must be between 0 and 23, got %d", *req.DailyDropHour))
+			return
+		}
+		params.DailyDropHour = *req.DailyDropHour
+	}
+	if req.RepetitionIntervals != nil {
+		params.RepetitionIntervals = nil
+		if days := *req.RepetitionIntervals; len(days) > 0 {
+			if err := schedule.ValidateIntervals(days); err != nil {
+				httputils.RespondWithError(w, http.StatusBadRequest, "Invalid repetition_intervals: "+err.Error())
+				return
+			}
+			params.RepetitionIntervals = make([]int32, len(days))
+			for i, d := range days {
+				params.RepetitionIntervals[i] = int32(d)
+			}
+		}
+	}
+
+	if req.AutoArchiveAfterSend != nil {
+		params.AutoArchiveAfterSend = *req.AutoArchiveAfterSend
+	}
+	if req.DailyDropLimit != nil {
+		if *req.DailyDropLimit < 0 {
+			httputils.RespondWithError(w, http.StatusBadRequest, fmt.Sprintf("daily_drop_limit must not be negative, got %d", *req.DailyDropLimit))
+			return
+		}
+		params.DailyDropLimit = sql.NullInt32{}
+		if *req.DailyDropLimit > 0 {
+			params.DailyDropLimit = sql.NullInt32{Int32: *req.DailyDropLimit, Valid: true}
+		}
+	}
+
+	updated, err := h.APIConfig.DB.UpsertUserPreferences(r.Context(), params)
+	if err != nil {
+		log.Printf("Error saving preferences for UserUUID %s: %v", userUUID, err)
+		httputils.RespondWithServerError(w, err, "Failed to update preferences: "+err.Error())
+		return
+	}
+
+	log.Printf("Successfully updated preferences for UserUUID: %s", userUUID)
+	httputils.RespondWithJSON(w, http.StatusOK, toPreferencesResponse(updated))
+}
+
+// parseWebhookURL validates a user-supplied webhook URL. An empty value clears it.
+// On failure it writes a 400 response naming the field and returns false.
+func parseWebhookURL(w http.ResponseWriter, field, raw string) (sql.NullString, bool) {
+	webhookURL := strings.TrimSpace(raw)
+	if webhookURL == "" {
+		return sql.NullString{}, true
+	}
+	u, err := url.Parse(webhookURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		httputils.RespondWithError(w, http.StatusBadRequest, field+" must be an http or https URL")
+		return sql.NullString{}, false
+	}
+	return sql.NullString{String: webhookURL, Valid: true}, true
+}
@@ -0,0 +1,334 @@
+package handlers
+
+import (
+	"database/sql"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	db "github.com/nouvadev/dropwise/internal/database/sqlc"
+	"github.com/nouvadev/dropwise/internal/events"
+	"github.com/nouvadev/dropwise/internal/middleware"
+	"github.com/nouvadev/dropwise/internal/schedule"
+	"github.com/nouvadev/dropwise/internal/server/httputils"
+)
+
+// defaultReviewCount is how many drops a review session hands out without ?count=.
+// A session takes at most maxBatchSize, so its drops can be completed in one request.
+const defaultReviewCount = 10
+
+// Outcomes of reviewing a drop in a review session.
+const (
+	reviewRemembered = "remembered"
+	reviewForgot     = "forgot"
+)
+
+// ReviewSessionResponse lists the drops handed out by a review session.
+type ReviewSessionResponse struct {
+	Drops          []DropResponse `json:"drops"`
+	ReviewingUntil time.Time      `json:"reviewing_until"` // Complete the drops before this, or they are released
+}
+
+// ReviewResult is the outcome of reviewing one drop, "remembered" or "forgot".
+type ReviewResult struct {
+	ID      uuid.UUID `json:"id"`
+	Outcome string    `json:"outcome"`
+}
+
+// ReviewCompleteRequest defines the expected request body for completing a review session.
+type ReviewCompleteRequest struct {
+	Results []ReviewResult `json:"results"`
+}
+
+// ReviewCompleteResponse lists the drops whose review was recorded, with their new schedules.
+// SkippedIDs lists drops that don't exist, aren't the user's or aren't in a review session,
+// e.g. because the lock expired and the worker sent them meanwhile.
+type ReviewCompleteResponse struct {
+	Drops      []DropResponse `json:"drops"`
+	SkippedIDs []uuid.UUID    `json:"skipped_ids"`
+}
+
+// StartReviewHandler starts a review session: it locks up to ?count= of the user's due drops
+// in the active workspace for ReviewLockDuration and returns them, most urgent first. Locked
+// drops are neither sent by the worker nor handed out by another session until the lock
+// expires or the session completes them.
+// POST /api/v1/review/start?count=10
+func (h *DropsHandler) StartReviewHandler(w http.ResponseWriter, r *http.Request) {
+	userUUID, ok := r.Context().Value(middleware.UserIDKey).(uuid.UUID)
+	if !ok {
+		httputils.RespondWithError(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	count := defaultReviewCount
+	if raw := r.URL.Query().Get("count"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 || n > maxBatchSize {
+			httputils.RespondWithError(w, http.StatusBadRequest, "count must be between 1 and 100")
+			return
+		}
+		count = n
+	}
+
+	now := time.Now()
+	reviewingUntil := now.Add(h.APIConfig.ReviewLockDuration).UTC()
+	dueBefore, lastSentBefore := h.APIConfig.DueWindow.Cutoffs(now)
+	drops, err := h.APIConfig.DB.StartDropReview(r.Context(), db.StartDropReviewParams{
+		ReviewingUntil: sql.NullTime{Time: reviewingUntil, Valid: true},
+		UserUuid:       uuid.NullUUID{UUID: userUUID, Valid: true},
+		WorkspaceID:    middleware.GetWorkspaceIDFromContext(r),
+		DueBefore:      dueBefore,
+		LastSentBefore: lastSentBefore,
+		Limit:          int32(count),
+	})
+	if err != nil {
+		log.Printf("Error starting review session for UserUUID %s: %v", userUUID, err)
+		httputils.RespondWithServerError(w, err, "Failed to start review session: "+err.Error())
+		return
+	}
+	// UPDATE ... RETURNING doesn't keep the order the drops were picked in
+	sort.SliceStable(drops, func(i, j int) bool { return reviewsBefore(drops[i], drops[j]) })
+
+	dropIDs := make([]uuid.UUID, len(drops))
+	for i, drop := range drops {
+		dropIDs[i] = drop.ID
+	}
+	tagsByDrop := h.tagNamesForDrops(r, dropIDs)
+	response := ReviewSessionResponse{Drops: make([]DropResponse, 0, len(drops)), ReviewingUntil: reviewingUntil}
+	for _, drop := range drops {
+		response.Drops = append(response.Drops, toDropResponse(drop, tagsByDrop[drop.ID], r, h.APIConfig.MaxSendCount))
+	}
+
+	log.Printf("Started review session of %d drops for UserUUID %s until %s", len(drops), userUUID, reviewingUntil)
+	httputils.RespondWithJSON(w, http.StatusOK, response)
+}
+
+// CompleteReviewHandler records the outcomes of a review session. A remembered drop moves on
+// to its next repetition as if it had been sent; a forgotten drop starts its repetition
+// sequence over, coming back after the first interval. Either way its lock is released,
+// and completing any drop counts as a review for the streak.
+// POST /api/v1/review/complete
+func (h *DropsHandler) CompleteReviewHandler(w http.ResponseWriter, r *http.Request) {
+	userUUID, ok := r.Context().Value(middleware.UserIDKey).(uuid.UUID)
+	if !ok {
+		httputils.RespondWithError(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	var req ReviewCompleteRequest
+	if !httputils.DecodeJSONBody(w, r, &req) {
+		return
+	}
+	defer r.Body.Close()
+
+	if len(req.Results) == 0 {
+		httputils.RespondWithError(w, http.StatusBadRequest, "At least one result is required")
+		return
+	}
+	if len(req.Results) > maxBatchSize {
+		httputils.RespondWithError(w, http.StatusBadRequest, "Too many results, the maximum is 100")
+		return
+	}
+	ids := make([]uuid.UUID, len(req.Results))
+	for i, result := range req.Results {
+		if result.Outcome != reviewRemembered && result.Outcome != reviewForgot {
+			httputils.RespondWithError(w, http.StatusBadRequest, "outcome must be remembered or forgot")
+			return
+		}
+		ids[i] = result.ID
+	}
+
+	intervals, ok := h.userIntervals(w, r, userUUID)
+	if !ok {
+		return
+	}
+	current, err := h.APIConfig.DB.GetDropsByIDs(r.Context(), db.GetDropsByIDsParams{
+		Ids:         ids,
+		UserUuid:    uuid.NullUUID{UUID: userUUID, Valid: true},
+		WorkspaceID: middleware.GetWorkspaceIDFromContext(r),
+	})
+	if err != nil {
+		log.Printf("Error fetching reviewed drops for UserUUID %s: %v", userUUID, err)
+		httputils.RespondWithServerError(w, err, "Failed to complete review session: "+err.Error())
+		return
+	}
+	dropsByID := make(map[uuid.UUID]db.Drop, len(current))
+	for _, drop := range current {
+		dropsByID[drop.ID] = drop
+	}
+
+	tx, err := h.APIConfig.DBConn.BeginTx(r.Context(), nil)
+	if err != nil {
+		log.Printf("Error starting transaction for completing a review session: %v", err)
+		httputils.RespondWithServerError(w, err, "Failed to complete review session")
+		return
+	}
+	defer tx.Rollback()
+	qtx := h.APIConfig.DB.WithTx(tx)
+
+	reviewedAt := time.Now().UTC()
+	loc := middleware.GetUserTimezoneFromContext(r)
+	var completed []db.Drop
+	skipped := []uuid.UUID{}
+	for _, result := range req.Results {
+		drop, found := dropsByID[result.ID]
+		if !found {
+			skipped = append(skipped, result.ID)
+			continue
+		}
+		updated, err := qtx.CompleteDropReview(r.Context(), reviewOutcomeParams(drop, result.Outcome, reviewedAt, loc, intervals, h.APIConfig.MaxSendCount))
+		if err == sql.ErrNoRows {
+			skipped = append(skipped, result.ID)
+			continue
+		}
+		if err != nil {
+			log.Printf("Error completing review of drop %s for UserUUID %s: %v", drop.ID, userUUID, err)
+			httputils.RespondWithServerError(w, err, "Failed to complete review session: "+err.Error())
+			return
+		}
+		completed = append(completed, updated)
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("Error committing review session for UserUUID %s: %v", userUUID, err)
+		httputils.RespondWithServerError(w, err, "Failed to complete review session")
+		return
+	}
+
+	completedIDs := make([]uuid.UUID, len(completed))
+	for i, drop := range completed {
+		completedIDs[i] = drop.ID
+		h.APIConfig.Events.Publish(userUUID, events.Event{Type: events.DropUpdated, DropID: drop.ID, WorkspaceID: drop.WorkspaceID})
+	}
+	if len(completed) > 0 {
+		recordReview(r, h.APIConfig.DB, userUUID, "sent")
+	}
+
+	tagsByDrop := h.tagNamesForDrops(r, completedIDs)
+	response := ReviewCompleteResponse{Drops: make([]DropResponse, 0, len(completed)), SkippedIDs: skipped}
+	for _, drop := range completed {
+		response.Drops = append(response.Drops, toDropResponse(drop, tagsByDrop[drop.ID], r, h.APIConfig.MaxSendCount))
+	}
+
+	log.Printf("Completed review of %d drops for UserUUID %s, skipped %d", len(completed), userUUID, len(skipped))
+	httputils.RespondWithJSON(w, http.StatusOK, response)
+}
+
+// ReviewDropRequest defines the expected request body for reviewing a single drop.
+type ReviewDropRequest struct {
+	Outcome string `json:"outcome"` // "remembered" or "forgot"
+}
+
+// ReviewDropHandler records the outcome of reviewing one drop outside a review session, with
+// the same effect as completing it in one. Only new and sent drops can be reviewed.
+// POST /api/v1/drops/{id}/review
+func (h *DropsHandler) ReviewDropHandler(w http.ResponseWriter, r *http.Request) {
+	userUUID, ok := r.Context().Value(middleware.UserIDKey).(uuid.UUID)
+	if !ok {
+		httputils.RespondWithError(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	drop, ok := h.getOwnedDrop(w, r, userUUID)
+	if !ok {
+		return
+	}
+
+	var req ReviewDropRequest
+	if !httputils.DecodeJSONBody(w, r, &req) {
+		return
+	}
+	defer r.Body.Close()
+
+	if req.Outcome != reviewRemembered && req.Outcome != reviewForgot {
+		httputils.RespondWithError(w, http.StatusBadRequest, "outcome must be remembered or forgot")
+		return
+	}
+	intervals, ok := h.userIntervals(w, r, userUUID)
+	if !ok {
+		return
+	}
+
+	params := reviewOutcomeParams(drop, req.Outcome, time.Now().UTC(), middleware.GetUserTimezoneFromContext(r), intervals, h.APIConfig.MaxSendCount)
+	updatedDrop, err := h.APIConfig.DB.ReviewDrop(r.Context(), db.ReviewDropParams(params))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			httputils.RespondWithError(w, http.StatusConflict, "Only new and sent drops can be reviewed, this drop is "+drop.Status)
+		} else {
+			log.Printf("Error reviewing drop %s: %v", drop.ID, err)
+			httputils.RespondWithServerError(w, err, "Failed to review drop: "+err.Error())
+		}
+		return
+	}
+
+	h.publishDropEvent(userUUID, events.DropUpdated, updatedDrop)
+	recordReview(r, h.APIConfig.DB, userUUID, "sent")
+
+	log.Printf("Reviewed drop %s for UserUUID %s: %s, next interval %d days", updatedDrop.ID, userUUID, req.Outcome, updatedDrop.IntervalDays.Int32)
+	response := toDropResponse(updatedDrop, h.dropTagNames(r, updatedDrop.ID), r, h.APIConfig.MaxSendCount)
+	httputils.RespondWithJSON(w, http.StatusOK, response)
+}
+
+// reviewOutcomeParams returns the update recording outcome for drop, reviewed at reviewedAt.
+// The next interval comes from SM-2 (see schedule.Review): a remembered drop's interval grows
+// with its ease factor, a forgotten drop starts over at 1 day and becomes harder. A drop that
+// was never reviewed with an outcome starts from the interval its fixed sequence gave it.
+// A remembered drop graduates at the send cap like a worker send, and a drop on a cron
+// schedule keeps following it.
+func reviewOutcomeParams(drop db.Drop, outcome string, reviewedAt time.Time, loc *time.Location, intervals schedule.Intervals, maxSendCount int) db.CompleteDropReviewParams {
+	state := schedule.Review{EaseFactor: drop.EaseFactor, IntervalDays: int(drop.IntervalDays.Int32)}
+	if !drop.IntervalDays.Valid {
+		state.IntervalDays, _ = intervals.Interval(drop.SendCount, drop.Permanent)
+	}
+	state = state.Next(outcome == reviewRemembered)
+
+	params := db.CompleteDropReviewParams{
+		Status:       "sent",
+		LastSentDate: sql.NullTime{Time: reviewedAt, Valid: true},
+		SendCount:    drop.SendCount + 1,
+		EaseFactor:   state.EaseFactor,
+		IntervalDays: sql.NullInt32{Int32: int32(state.IntervalDays), Valid: true},
+		ID:           drop.ID,
+		UserUuid:     drop.UserUuid,
+	}
+	if outcome == reviewForgot {
+		params.SendCount = 1
+	} else if schedule.Graduated(params.SendCount, drop.Permanent, maxSendCount) {
+		params.Status = "graduated"
+		return params
+	}
+
+	if drop.Schedule.Valid {
+		// The schedule was valid when stored, so a parse error here falls back to the interval
+		if cron, err := schedule.ParseCron(drop.Schedule.String); err == nil {
+			if next, ok := cron.NextIn(reviewedAt, loc); ok {
+				params.NextSendDate = sql.NullTime{Time: next, Valid: true}
+			}
+			return params
+		}
+	}
+	params.NextSendDate = sql.NullTime{Time: reviewedAt.AddDate(0, 0, state.IntervalDays), Valid: true}
+	return params
+}
+
+// reviewsBefore reports whether a comes before b in a review session, in the order of the
+// due-drop queries: highest priority first, then most overdue, then oldest.
+func reviewsBefore(a, b db.Drop) bool {
+	if a.Priority.Int32 != b.Priority.Int32 {
+		return a.Priority.Int32 > b.Priority.Int32
+	}
+	aDue, bDue := a.AddedDate, b.AddedDate
+	if a.NextSendDate.Valid {
+		aDue = a.NextSendDate.Time
+	}
+	if b.NextSendDate.Valid {
+		bDue = b.NextSendDate.Time
+	}
+	if !aDue.Equal(bDue) {
+		return aDue.Before(bDue)
+	}
+	return a.AddedDate.Before(b.AddedDate)
+}
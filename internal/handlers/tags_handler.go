@@ -1,11 +1,18 @@
 package handlers
 
 import (
+	"fmt"
 	"log"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/nouvadev/dropwise/internal/config"
 	db "github.com/nouvadev/dropwise/internal/database/sqlc"
+	"github.com/nouvadev/dropwise/internal/events"
+	"github.com/nouvadev/dropwise/internal/middleware"
 	"github.com/nouvadev/dropwise/internal/server/httputils"
 )
 
@@ -19,29 +26,465 @@ func NewTagsHandler(apiCfg *config.APIConfig) *TagsHandler {
 	return &TagsHandler{APIConfig: apiCfg}
 }
 
-// ListTagsHandler handles fetching all unique tags.
-// GET /api/v1/tags
+// TagStatsResponse defines the per-tag statistics returned to the client.
+type TagStatsResponse struct {
+	DropCount  int64            `json:"drop_count"`
+	ByStatus   map[string]int64 `json:"by_status"`
+	TotalSends int64            `json:"total_sends"`
+}
+
+// Default number of tags per list page, search and recent tags. All are capped by
+// APIConfig.TagsMaxResults.
+const (
+	defaultTagsPageSize   = 50
+	defaultTagSearchLimit = 20
+	defaultRecentTagLimit = 10
+)
+
+// tagsTruncatedHeader is set on a tag search response when more tags matched than were returned.
+const tagsTruncatedHeader = "X-Results-Truncated"
+
+// likeEscaper escapes the ILIKE wildcards in user input, so it is matched literally.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// validTagSortFields is the allowlist for the ?sort= parameter of ListTagsHandler.
+var validTagSortFields = map[string]bool{"name": true, "count": true}
+
+// TagResponse defines a tag as listed for a user, with the number of their drops carrying it.
+type TagResponse struct {
+	ID        int32  `json:"id"`
+	Name      string `json:"name"`
+	DropCount int64  `json:"drop_count"`
+}
+
+// ListTagsHandler handles fetching the tags used on the authenticated user's drops.
+// GET /api/v1/tags?sort=name|count&order=asc|desc&limit=&offset=
 func (h *TagsHandler) ListTagsHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		httputils.RespondWithError(w, http.StatusMethodNotAllowed, "Only GET method is allowed")
 		return
 	}
 
-	log.Println("Attempting to list all tags")
+	userUUID, ok := r.Context().Value(middleware.UserIDKey).(uuid.UUID)
+	if !ok {
+		log.Printf("ListTagsHandler: UserID not found in context or not a UUID for path %s", r.URL.Path)
+		httputils.RespondWithError(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	query := r.URL.Query()
+	sortBy := query.Get("sort")
+	if sortBy == "" {
+		sortBy = "name"
+	}
+	if !validTagSortFields[sortBy] {
+		httputils.RespondWithError(w, http.StatusBadRequest, "Invalid sort value. Allowed: name, count.")
+		return
+	}
+
+	sortOrder := query.Get("order")
+	if sortOrder == "" {
+		sortOrder = "asc"
+		if sortBy == "count" {
+			sortOrder = "desc" // Most used tags first
+		}
+	}
+	if sortOrder != "asc" && sortOrder != "desc" {
+		httputils.RespondWithError(w, http.StatusBadRequest, "Invalid order value. Allowed: asc, desc.")
+		return
+	}
+
+	maxResults := h.APIConfig.TagsMaxResults
+	limit, offset, err := httputils.ParsePagination(r, min(defaultTagsPageSize, maxResults), maxResults)
+	if err != nil {
+		httputils.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
 
-	tags, err := h.APIConfig.DB.ListTags(r.Context())
+	log.Printf("Attempting to list tags for UserUUID: %s (sort=%s %s, limit=%d, offset=%d)", userUUID, sortBy, sortOrder, limit, offset)
+
+	workspaceID := middleware.GetWorkspaceIDFromContext(r)
+	listing, err := h.listTags(r, userUUID, workspaceID, sortBy, sortOrder, limit, offset)
 	if err != nil {
 		log.Printf("Error fetching tags from database: %v", err)
-		httputils.RespondWithError(w, http.StatusInternalServerError, "Failed to fetch tags: "+err.Error())
+		httputils.RespondWithServerError(w, err, "Failed to fetch tags: "+err.Error())
 		return
 	}
+	tags, total := listing.Tags, listing.Total
 
 	// Ensure a non-nil slice for JSON marshaling as [] if no tags are found.
-	// sqlc typically returns an empty slice, but this is a good safeguard.
-	if tags == nil {
-		tags = []db.Tag{}
+	tagResponses := make([]TagResponse, 0, len(tags))
+	for _, tag := range tags {
+		tagResponses = append(tagResponses, TagResponse{ID: tag.ID, Name: tag.Name, DropCount: tag.DropCount})
+	}
+
+	log.Printf("Successfully fetched %d of %d tags", len(tagResponses), total)
+	w.Header().Set("Cache-Control", "private, max-age="+strconv.Itoa(int(h.APIConfig.TagsMaxAge.Seconds())))
+	httputils.RespondWithJSON(w, http.StatusOK, httputils.PaginatedResponse{
+		Data:       tagResponses,
+		Pagination: httputils.Pagination{Limit: limit, Offset: offset, Total: total},
+	})
+}
+
+// listTags loads a page of the user's tags in a workspace and their total count, going
+// through APIConfig.TagsCache.
+func (h *TagsHandler) listTags(r *http.Request, userUUID uuid.UUID, workspaceID uuid.NullUUID, sortBy, sortOrder string, limit, offset int) (config.TagsListing, error) {
+	cacheKey := fmt.Sprintf("%s|%s|%s|%d|%d", workspaceID.UUID, sortBy, sortOrder, limit, offset) // uuid.Nil for the personal space
+	if listing, ok := h.APIConfig.TagsCache.Get(userUUID, cacheKey); ok {
+		return listing, nil
+	}
+	generation := h.APIConfig.TagsCache.Generation(userUUID)
+
+	tags, err := h.APIConfig.DB.ListTagsForUser(r.Context(), db.ListTagsForUserParams{
+		UserUuid:    uuid.NullUUID{UUID: userUUID, Valid: true},
+		WorkspaceID: workspaceID,
+		SortBy:      sortBy,
+		SortOrder:   sortOrder,
+		Limit:       int32(limit),
+		Offset:      int32(offset),
+	})
+	if err != nil {
+		return config.TagsListing{}, err
+	}
+	total, err := h.APIConfig.DB.CountTagsForUser(r.Context(), db.CountTagsForUserParams{
+		UserUuid:    uuid.NullUUID{UUID: userUUID, Valid: true},
+		WorkspaceID: workspaceID,
+	})
+	if err != nil {
+		return config.TagsListing{}, err
+	}
+
+	listing := config.TagsListing{Tags: tags, Total: total}
+	h.APIConfig.TagsCache.Set(userUUID, cacheKey, listing, generation)
+	return listing, nil
+}
+
+// SearchTagsHandler handles finding the authenticated user's tags whose name contains q.
+// GET /api/v1/tags/search?q=&limit=
+func (h *TagsHandler) SearchTagsHandler(w http.ResponseWriter, r *http.Request) {
+	userUUID, ok := r.Context().Value(middleware.UserIDKey).(uuid.UUID)
+	if !ok {
+		log.Printf("SearchTagsHandler: UserID not found in context or not a UUID for path %s", r.URL.Path)
+		httputils.RespondWithError(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	maxResults := h.APIConfig.TagsMaxResults
+	limit := min(defaultTagSearchLimit, maxResults)
+	if v := r.URL.Query().Get("limit"); v != "" {
+		var err error
+		limit, err = strconv.Atoi(v)
+		if err != nil || limit < 1 || limit > maxResults {
+			httputils.RespondWithError(w, http.StatusBadRequest, fmt.Sprintf("limit must be an integer between 1 and %d", maxResults))
+			return
+		}
+	}
+
+	// Ensure a non-nil slice for JSON marshaling as [] if no tags match.
+	tagResponses := []TagResponse{}
+	q := strings.TrimSpace(r.URL.Query().Get("q"))
+	if q == "" {
+		httputils.RespondWithJSON(w, http.StatusOK, tagResponses)
+		return
+	}
+
+	tags, err := h.APIConfig.DBRead.SearchTagsForUser(r.Context(), db.SearchTagsForUserParams{
+		UserUuid:    uuid.NullUUID{UUID: userUUID, Valid: true},
+		WorkspaceID: middleware.GetWorkspaceIDFromContext(r),
+		Pattern:     "%" + likeEscaper.Replace(q) + "%",
+		Query:       q,
+		Limit:       int32(limit) + 1, // One extra tells whether the result was truncated
+	})
+	if err != nil {
+		log.Printf("Error searching tags for UserUUID %s: %v", userUUID, err)
+		httputils.RespondWithServerError(w, err, "Failed to search tags: "+err.Error())
+		return
+	}
+
+	if len(tags) > limit {
+		tags = tags[:limit]
+		w.Header().Set(tagsTruncatedHeader, "true")
+	}
+	for _, tag := range tags {
+		tagResponses = append(tagResponses, TagResponse{ID: tag.ID, Name: tag.Name, DropCount: tag.DropCount})
+	}
+	httputils.RespondWithJSON(w, http.StatusOK, tagResponses)
+}
+
+// RecentTagResponse is a tag with when it was last used on one of the user's drops.
+type RecentTagResponse struct {
+	ID         int32     `json:"id"`
+	Name       string    `json:"name"`
+	LastUsedAt time.Time `json:"last_used_at"`
+}
+
+// RecentTagsHandler returns the authenticated user's tags in the active workspace, most
+// recently used first, for offering quick re-tagging.
+// GET /api/v1/tags/recent?limit=
+func (h *TagsHandler) RecentTagsHandler(w http.ResponseWriter, r *http.Request) {
+	userUUID, ok := r.Context().Value(middleware.UserIDKey).(uuid.UUID)
+	if !ok {
+		httputils.RespondWithError(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	maxResults := h.APIConfig.TagsMaxResults
+	limit := min(defaultRecentTagLimit, maxResults)
+	if v := r.URL.Query().Get("limit"); v != "" {
+		var err error
+		limit, err = strconv.Atoi(v)
+		if err != nil || limit < 1 || limit > maxResults {
+			httputils.RespondWithError(w, http.StatusBadRequest, fmt.Sprintf("limit must be an integer between 1 and %d", maxResults))
+			return
+		}
+	}
+
+	tags, err := h.APIConfig.DBRead.ListRecentTagsForUser(r.Context(), db.ListRecentTagsForUserParams{
+		UserUuid:    uuid.NullUUID{UUID: userUUID, Valid: true},
+		WorkspaceID: middleware.GetWorkspaceIDFromContext(r),
+		Limit:       int32(limit),
+	})
+	if err != nil {
+		log.Printf("Error fetching recent tags for UserUUID %s: %v", userUUID, err)
+		httputils.RespondWithServerError(w, err, "Failed to fetch recent tags: "+err.Error())
+		return
+	}
+
+	// Ensure a non-nil slice for JSON marshaling as [] if the user has no tags.
+	tagResponses := make([]RecentTagResponse, 0, len(tags))
+	for _, tag := range tags {
+		tagResponses = append(tagResponses, RecentTagResponse{ID: tag.ID, Name: tag.Name, LastUsedAt: tag.LastUsedAt.UTC()})
+	}
+	httputils.RespondWithJSON(w, http.StatusOK, tagResponses)
+}
+
+// AssignTagRequest defines the expected request body for assigning a tag to several drops.
+type AssignTagRequest struct {
+	DropIDs []uuid.UUID `json:"drop_ids"`
+}
+
+// AssignTagResponse reports how many drops newly got the tag.
+type AssignTagResponse struct {
+	AssignedCount int `json:"assigned_count"`
+}
+
+// AssignTagHandler adds a tag to several of the user's drops at once. Drops that already
+// carry the tag, don't exist or belong to someone else or another workspace are skipped.
+// POST /api/v1/tags/{id}/assign
+func (h *TagsHandler) AssignTagHandler(w http.ResponseWriter, r *http.Request) {
+	userUUID, ok := r.Context().Value(middleware.UserIDKey).(uuid.UUID)
+	if !ok {
+		log.Printf("AssignTagHandler: UserID not found in context or not a UUID for path %s", r.URL.Path)
+		httputils.RespondWithError(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	tagID, err := strconv.ParseInt(r.PathValue("id"), 10, 32)
+	if err != nil {
+		httputils.RespondWithError(w, http.StatusBadRequest, "Invalid Tag ID format")
+		return
+	}
+
+	var req AssignTagRequest
+	if !httputils.DecodeJSONBody(w, r, &req) {
+		return
+	}
+	defer r.Body.Close()
+
+	if len(req.DropIDs) == 0 {
+		httputils.RespondWithError(w, http.StatusBadRequest, "At least one drop ID is required")
+		return
+	}
+	if len(req.DropIDs) > maxBatchSize {
+		httputils.RespondWithError(w, http.StatusBadRequest, "Too many drop IDs, the maximum is 100")
+		return
+	}
+
+	if !h.requireUserTag(w, r, int32(tagID), userUUID, "assign") {
+		return
+	}
+
+	log.Printf("Attempting to assign tag %d to %d drops for UserUUID: %s", tagID, len(req.DropIDs), userUUID)
+
+	// A single statement runs in its own transaction, so either all owned drops get the tag or none do.
+	workspaceID := middleware.GetWorkspaceIDFromContext(r)
+	assignedIDs, err := h.APIConfig.DB.AssignTagToDrops(r.Context(), db.AssignTagToDropsParams{
+		TagID:       int32(tagID),
+		DropIds:     req.DropIDs,
+		UserUuid:    uuid.NullUUID{UUID: userUUID, Valid: true},
+		WorkspaceID: workspaceID,
+	})
+	if err != nil {
+		log.Printf("Error assigning tag %d to drops: %v", tagID, err)
+		httputils.RespondWithServerError(w, err, "Failed to assign tag: "+err.Error())
+		return
+	}
+
+	if len(assignedIDs) > 0 {
+		h.APIConfig.TagsCache.Invalidate(userUUID)
+	}
+	for _, id := range assignedIDs {
+		h.APIConfig.Events.Publish(userUUID, events.Event{Type: events.DropUpdated, DropID: id, WorkspaceID: workspaceID})
+	}
+
+	log.Printf("Assigned tag %d to %d drops for UserUUID: %s", tagID, len(assignedIDs), userUUID)
+	httputils.RespondWithJSON(w, http.StatusOK, AssignTagResponse{AssignedCount: len(assignedIDs)})
+}
+
+// UnassignTagRequest defines the expected request body for removing a tag from several drops.
+// Exactly one of DropIDs or All must be provided.
+type UnassignTagRequest struct {
+	DropIDs []uuid.UUID `json:"drop_ids,omitempty"`
+	All     bool        `json:"all,omitempty"` // Remove the tag from every drop in the active workspace
+}
+
+// UnassignTagResponse reports how many drops lost the tag.
+type UnassignTagResponse struct {
+	RemovedCount int `json:"removed_count"`
+}
+
+// UnassignTagHandler removes a tag from several of the user's drops at once, or with
+// "all": true from every one of them in the active workspace. The tag itself is kept.
+// Drops that don't carry the tag, don't exist or belong to someone else or another
+// workspace are skipped.
+// POST /api/v1/tags/{id}/unassign
+func (h *TagsHandler) UnassignTagHandler(w http.ResponseWriter, r *http.Request) {
+	userUUID, ok := r.Context().Value(middleware.UserIDKey).(uuid.UUID)
+	if !ok {
+		httputils.RespondWithError(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	tagID, err := strconv.ParseInt(r.PathValue("id"), 10, 32)
+	if err != nil {
+		httputils.RespondWithError(w, http.StatusBadRequest, "Invalid Tag ID format")
+		return
+	}
+
+	var req UnassignTagRequest
+	if !httputils.DecodeJSONBody(w, r, &req) {
+		return
+	}
+	defer r.Body.Close()
+
+	if req.All == (len(req.DropIDs) > 0) {
+		httputils.RespondWithError(w, http.StatusBadRequest, "Provide either \"drop_ids\" or \"all\": true")
+		return
+	}
+	if len(req.DropIDs) > maxBatchSize {
+		httputils.RespondWithError(w, http.StatusBadRequest, "Too many drop IDs, the maximum is 100")
+		return
+	}
+
+	if !h.requireUserTag(w, r, int32(tagID), userUUID, "unassign") {
+		return
+	}
+
+	// A single statement runs in its own transaction, so either all listed drops lose the tag or none do.
+	workspaceID := middleware.GetWorkspaceIDFromContext(r)
+	removedIDs, err := h.APIConfig.DB.UnassignTagFromDrops(r.Context(), db.UnassignTagFromDropsParams{
+		TagID:       int32(tagID),
+		AllDrops:    req.All,
+		DropIds:     req.DropIDs,
+		UserUuid:    uuid.NullUUID{UUID: userUUID, Valid: true},
+		WorkspaceID: workspaceID,
+	})
+	if err != nil {
+		log.Printf("Error unassigning tag %d from drops: %v", tagID, err)
+		httputils.RespondWithServerError(w, err, "Failed to unassign tag: "+err.Error())
+		return
+	}
+
+	if len(removedIDs) > 0 {
+		h.APIConfig.TagsCache.Invalidate(userUUID)
+	}
+	for _, id := range removedIDs {
+		h.APIConfig.Events.Publish(userUUID, events.Event{Type: events.DropUpdated, DropID: id, WorkspaceID: workspaceID})
+	}
+
+	log.Printf("Removed tag %d from %d drops for UserUUID: %s", tagID, len(removedIDs), userUUID)
+	httputils.RespondWithJSON(w, http.StatusOK, UnassignTagResponse{RemovedCount: len(removedIDs)})
+}
+
+// TagStatsHandler handles fetching statistics for a tag, limited to the authenticated user's drops.
+// GET /api/v1/tags/{id}/stats
+func (h *TagsHandler) TagStatsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		httputils.RespondWithError(w, http.StatusMethodNotAllowed, "Only GET method is allowed")
+		return
+	}
+
+	userUUID, ok := r.Context().Value(middleware.UserIDKey).(uuid.UUID)
+	if !ok {
+		log.Printf("TagStatsHandler: UserID not found in context or not a UUID for path %s", r.URL.Path)
+		httputils.RespondWithError(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	tagID, err := strconv.ParseInt(r.PathValue("id"), 10, 32)
+	if err != nil {
+		httputils.RespondWithError(w, http.StatusBadRequest, "Invalid Tag ID format")
+		return
 	}
 
-	log.Printf("Successfully fetched %d tags", len(tags))
-	httputils.RespondWithJSON(w, http.StatusOK, tags)
+	log.Printf("Attempting to fetch stats for tag ID: %d for UserUUID: %s", tagID, userUUID.String())
+
+	stats, err := h.APIConfig.DBRead.GetTagStatsForUser(r.Context(), db.GetTagStatsForUserParams{
+		TagID:       int32(tagID),
+		UserUuid:    uuid.NullUUID{UUID: userUUID, Valid: true},
+		WorkspaceID: middleware.GetWorkspaceIDFromContext(r),
+	})
+	if err != nil {
+		log.Printf("Error fetching stats for tag %d: %v", tagID, err)
+		httputils.RespondWithServerError(w, err, "Failed to fetch tag stats: "+err.Error())
+		return
+	}
+
+	if stats.DropCount == 0 { // Not one of the user's tags, see requireUserTag
+		httputils.RespondWithError(w, http.StatusNotFound, "Tag not found")
+		return
+	}
+
+	statusCounts, err := h.APIConfig.DBRead.CountTagDropsByStatusForUser(r.Context(), db.CountTagDropsByStatusForUserParams{
+		TagID:       int32(tagID),
+		UserUuid:    uuid.NullUUID{UUID: userUUID, Valid: true},
+		WorkspaceID: middleware.GetWorkspaceIDFromContext(r),
+	})
+	if err != nil {
+		log.Printf("Error fetching status counts for tag %d: %v", tagID, err)
+		httputils.RespondWithServerError(w, err, "Failed to fetch tag stats: "+err.Error())
+		return
+	}
+
+	byStatus := make(map[string]int64, len(statusCounts))
+	for _, sc := range statusCounts {
+		byStatus[sc.Status] = sc.Count
+	}
+
+	response := TagStatsResponse{
+		DropCount:  stats.DropCount,
+		ByStatus:   byStatus,
+		TotalSends: stats.TotalSends,
+	}
+	httputils.RespondWithJSON(w, http.StatusOK, response)
+}
+
+// requireUserTag reports whether the user has the tag on any of their drops, answering 404
+// otherwise. Tags are shared across users, so a tag the user has never used is reported as
+// not found. action names the operation in the error message of a failed check.
+func (h *TagsHandler) requireUserTag(w http.ResponseWriter, r *http.Request, tagID int32, userUUID uuid.UUID, action string) bool {
+	hasTag, err := h.APIConfig.DB.UserHasTag(r.Context(), db.UserHasTagParams{
+		TagID:    tagID,
+		UserUuid: uuid.NullUUID{UUID: userUUID, Valid: true},
+	})
+	if err != nil {
+		log.Printf("Error checking tag %d for UserUUID %s: %v", tagID, userUUID, err)
+		httputils.RespondWithServerError(w, err, "Failed to "+action+" tag: "+err.Error())
+		return false
+	}
+	if !hasTag {
+		httputils.RespondWithError(w, http.StatusNotFound, "Tag not found")
+		return false
+	}
+	return true
 }
@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/nouvadev/dropwise/internal/testdb"
+)
+
+func TestTagsOfOtherUsersAreHidden(t *testing.T) {
+	apiCfg, database := newTestConfig(t)
+	apiCfg.TagsMaxResults = 200
+	h := NewTagsHandler(apiCfg)
+
+	alice := testdb.CreateUser(t, database.Queries)
+	bob := testdb.CreateUser(t, database.Queries)
+	tagDrop(t, database.Queries, testdb.CreateDrop(t, database.Queries, alice, nil).ID, "shared-alice")
+	bobTag := tagDrop(t, database.Queries, testdb.CreateDrop(t, database.Queries, bob, nil).ID, "shared-bob")
+
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		method  string
+		target  string
+		body    string
+		status  int
+	}{
+		{"list", h.ListTagsHandler, http.MethodGet, "/api/v1/tags", "", http.StatusOK},
+		{"search", h.SearchTagsHandler, http.MethodGet, "/api/v1/tags/search?q=shared", "", http.StatusOK},
+		{"recent", h.RecentTagsHandler, http.MethodGet, "/api/v1/tags/recent", "", http.StatusOK},
+		{"stats", h.TagStatsHandler, http.MethodGet, "/api/v1/tags/{id}/stats", "", http.StatusNotFound},
+		{"assign", h.AssignTagHandler, http.MethodPost, "/api/v1/tags/{id}/assign", `{"drop_ids": ["00000000-0000-0000-0000-000000000001"]}`, http.StatusNotFound},
+		{"unassign", h.UnassignTagHandler, http.MethodPost, "/api/v1/tags/{id}/unassign", `{"all": true}`, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := authedRequest(tt.method, tt.target, strings.NewReader(tt.body), alice)
+			r.SetPathValue("id", strconv.Itoa(int(bobTag.ID)))
+			rec := httptest.NewRecorder()
+			tt.handler(rec, r)
+
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.status, rec.Body.String())
+			}
+			if strings.Contains(rec.Body.String(), "shared-bob") {
+				t.Errorf("response shows another user's tag: %s", rec.Body.String())
+			}
+			if tt.status == http.StatusOK && !strings.Contains(rec.Body.String(), "shared-alice") {
+				t.Errorf("response is missing the user's own tag: %s", rec.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,195 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nouvadev/dropwise/internal/auth"
+	"github.com/nouvadev/dropwise/internal/config"
+	db "github.com/nouvadev/dropwise/internal/database/sqlc"
+	"github.com/nouvadev/dropwise/internal/middleware"
+	"github.com/nouvadev/dropwise/internal/server/httputils"
+)
+
+// WorkspacesHandler handles HTTP requests for workspaces.
+type WorkspacesHandler struct {
+	APIConfig *config.APIConfig
+}
+
+// NewWorkspacesHandler creates a new WorkspacesHandler.
+func NewWorkspacesHandler(apiCfg *config.APIConfig) *WorkspacesHandler {
+	return &WorkspacesHandler{APIConfig: apiCfg}
+}
+
+// CreateWorkspaceRequest defines the expected request body for creating a workspace.
+type CreateWorkspaceRequest struct {
+	Name string `json:"name"`
+}
+
+// SwitchWorkspaceRequest defines the expected request body for switching the active workspace.
+// A null or missing WorkspaceID switches back to the personal space.
+type SwitchWorkspaceRequest struct {
+	WorkspaceID *uuid.UUID `json:"workspace_id"`
+}
+
+// WorkspaceResponse defines the structure for workspace responses.
+type WorkspaceResponse struct {
+	ID        uuid.UUID `json:"id"`
+	Name      string    `json:"name"`
+	OwnerID   uuid.UUID `json:"owner_id"`
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// SwitchWorkspaceResponse carries a new token scoped to the selected workspace.
+type SwitchWorkspaceResponse struct {
+	Token       string     `json:"token"`
+	WorkspaceID *uuid.UUID `json:"workspace_id"`
+}
+
+// CreateWorkspaceHandler creates a workspace owned by the authenticated user.
+// POST /api/v1/workspaces
+func (h *WorkspacesHandler) CreateWorkspaceHandler(w http.ResponseWriter, r *http.Request) {
+	userUUID, ok := r.Context().Value(middleware.UserIDKey).(uuid.UUID)
+	if !ok {
+		httputils.RespondWithError(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	var req CreateWorkspaceRequest
+	if !httputils.DecodeJSONBody(w, r, &req) {
+		return
+	}
+	defer r.Body.Close()
+
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		httputils.RespondWithError(w, http.StatusBadRequest, "Workspace name cannot be empty")
+		return
+	}
+
+	log.Printf("Attempting to create workspace '%s' for UserUUID: %s", req.Name, userUUID)
+
+	// The workspace and its owner membership are created together or not at all.
+	tx, err := h.APIConfig.DBConn.BeginTx(r.Context(), nil)
+	if err != nil {
+		log.Printf("Error starting transaction for workspace creation: %v", err)
+		httputils.RespondWithServerError(w, err, "Failed to create workspace")
+		return
+	}
+	defer tx.Rollback()
+	qtx := h.APIConfig.DB.WithTx(tx)
+
+	workspace, err := qtx.CreateWorkspace(r.Context(), db.CreateWorkspaceParams{
+		Name:      req.Name,
+		OwnerUuid: userUUID,
+	})
+	if err != nil {
+		log.Printf("Error creating workspace: %v", err)
+		httputils.RespondWithServerError(w, err, "Failed to create workspace")
+		return
+	}
+
+	err = qtx.AddWorkspaceMember(r.Context(), db.AddWorkspaceMemberParams{
+		WorkspaceID: workspace.ID,
+		UserUuid:    userUUID,
+		Role:        "owner",
+	})
+	if err != nil {
+		log.Printf("Error adding owner to workspace %s: %v", workspace.ID, err)
+		httputils.RespondWithServerError(w, err, "Failed to create workspace")
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("Error committing workspace creation: %v", err)
+		httputils.RespondWithServerError(w, err, "Failed to create workspace")
+		return
+	}
+
+	log.Printf("Successfully created workspace %s for UserUUID: %s", workspace.ID, userUUID)
+	httputils.RespondWithJSON(w, http.StatusCreated, WorkspaceResponse{
+		ID:        workspace.ID,
+		Name:      workspace.Name,
+		OwnerID:   workspace.OwnerUuid,
+		Role:      "owner",
+		CreatedAt: workspace.CreatedAt.UTC(),
+	})
+}
+
+// ListWorkspacesHandler lists the workspaces the authenticated user belongs to.
+// GET /api/v1/workspaces
+func (h *WorkspacesHandler) ListWorkspacesHandler(w http.ResponseWriter, r *http.Request) {
+	userUUID, ok := r.Context().Value(middleware.UserIDKey).(uuid.UUID)
+	if !ok {
+		httputils.RespondWithError(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	workspaces, err := h.APIConfig.DB.ListWorkspacesForUser(r.Context(), userUUID)
+	if err != nil {
+		log.Printf("Error fetching workspaces for UserUUID %s: %v", userUUID, err)
+		httputils.RespondWithServerError(w, err, "Failed to fetch workspaces: "+err.Error())
+		return
+	}
+
+	response := make([]WorkspaceResponse, 0, len(workspaces))
+	for _, ws := range workspaces {
+		response = append(response, WorkspaceResponse{
+			ID:        ws.ID,
+			Name:      ws.Name,
+			OwnerID:   ws.OwnerUuid,
+			Role:      ws.Role,
+			CreatedAt: ws.CreatedAt.UTC(),
+		})
+	}
+
+	httputils.RespondWithJSON(w, http.StatusOK, response)
+}
+
+// SwitchWorkspaceHandler issues a new token whose active workspace is the requested one.
+// POST /api/v1/workspaces/switch
+func (h *WorkspacesHandler) SwitchWorkspaceHandler(w http.ResponseWriter, r *http.Request) {
+	userUUID, ok := r.Context().Value(middleware.UserIDKey).(uuid.UUID)
+	if !ok {
+		httputils.RespondWithError(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	var req SwitchWorkspaceRequest
+	if !httputils.DecodeJSONBody(w, r, &req) {
+		return
+	}
+	defer r.Body.Close()
+
+	if req.WorkspaceID != nil {
+		isMember, err := h.APIConfig.DB.IsWorkspaceMember(r.Context(), db.IsWorkspaceMemberParams{
+			WorkspaceID: *req.WorkspaceID,
+			UserUuid:    userUUID,
+		})
+		if err != nil {
+			log.Printf("Error checking membership of user %s in workspace %s: %v", userUUID, *req.WorkspaceID, err)
+			httputils.RespondWithServerError(w, err, "Failed to verify workspace membership")
+			return
+		}
+		if !isMember {
+			httputils.RespondWithError(w, http.StatusForbidden, "Not a member of this workspace")
+			return
+		}
+	}
+
+	tokenString, err := auth.GenerateWorkspaceJWT(userUUID, req.WorkspaceID, h.APIConfig.JWTKeys, h.APIConfig.JWTExpiration)
+	if err != nil {
+		log.Printf("Error generating workspace JWT for user %s: %v", userUUID, err)
+		httputils.RespondWithError(w, http.StatusInternalServerError, "Failed to generate authentication token")
+		return
+	}
+
+	httputils.RespondWithJSON(w, http.StatusOK, SwitchWorkspaceResponse{
+		Token:       tokenString,
+		WorkspaceID: req.WorkspaceID,
+	})
+}
@@ -0,0 +1,35 @@
+// Package markdown renders user-written markdown, such as drop notes, to HTML that is safe
+// to embed in emails and web pages.
+package markdown
+
+import (
+	"bytes"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+)
+
+var (
+	// renderer turns CommonMark plus GitHub-style tables, strikethrough and autolinks into HTML.
+	// Raw HTML in the source is dropped (goldmark's default); the sanitizer is the real guard.
+	renderer = goldmark.New(goldmark.WithExtensions(extension.GFM))
+
+	// policy allows the formatting markdown can produce and strips scripts, event handlers,
+	// styles and javascript: URLs. Links open in a new tab and are marked nofollow.
+	policy = func() *bluemonday.Policy {
+		p := bluemonday.UGCPolicy()
+		p.AddTargetBlankToFullyQualifiedLinks(true)
+		return p
+	}()
+)
+
+// ToHTML renders src as markdown and returns sanitized HTML. Notes are user-controlled,
+// so the output is always passed through the sanitizer before it is returned.
+func ToHTML(src string) (string, error) {
+	var buf bytes.Buffer
+	if err := renderer.Convert([]byte(src), &buf); err != nil {
+		return "", err
+	}
+	return policy.Sanitize(buf.String()), nil
+}
@@ -0,0 +1,87 @@
+package markdown
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestToHTML(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{
+			name: "formatting and links are kept",
+			src:  "**b** [ok](https://example.com)",
+			want: "<p><strong>b</strong> <a href=\"https://example.com\" rel=\"nofollow noopener\" target=\"_blank\">ok</a></p>\n",
+		},
+		{
+			name: "script block is dropped",
+			src:  "<script>alert(1)</script>hi",
+			want: "\n",
+		},
+		{
+			name: "javascript link loses its href",
+			src:  "[x](javascript:alert(1))",
+			want: "<p>x</p>\n",
+		},
+		{
+			name: "mixed-case javascript link loses its href",
+			src:  "[x](JaVaScRiPt:alert(1))",
+			want: "<p>x</p>\n",
+		},
+		{
+			name: "raw anchor with javascript href is dropped",
+			src:  `<a href="javascript:alert(1)">x</a>`,
+			want: "<p>x</p>\n",
+		},
+		{
+			name: "raw image with onerror is dropped",
+			src:  "<img src=x onerror=alert(1)>",
+			want: "\n",
+		},
+		{
+			name: "inline raw HTML does not pass through",
+			src:  "<b>bold</b> and <div>d</div>",
+			want: "<p>bold and d</p>\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ToHTML(tt.src)
+			if err != nil {
+				t.Fatalf("ToHTML() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("ToHTML(%q) = %q, want %q", tt.src, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestPolicy checks the sanitizer on its own, so the output stays safe even if the renderer
+// were changed to let raw HTML through.
+func TestPolicy(t *testing.T) {
+	tests := []struct {
+		name      string
+		html      string
+		forbidden []string
+	}{
+		{"script", `<p>hi</p><script>alert(1)</script>`, []string{"<script", "alert"}},
+		{"javascript link", `<a href="javascript:alert(1)">x</a>`, []string{"javascript:"}},
+		{"event handler", `<img src="x.png" onerror="alert(1)">`, []string{"onerror", "alert"}},
+		{"style", `<p style="background:url(x)">x</p><style>p{}</style>`, []string{"style"}},
+		{"iframe", `<iframe src="https://example.com"></iframe>`, []string{"<iframe"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := policy.Sanitize(tt.html)
+			for _, s := range tt.forbidden {
+				if strings.Contains(strings.ToLower(got), s) {
+					t.Errorf("Sanitize(%q) = %q, still contains %q", tt.html, got, s)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,185 @@
+package metadata
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/nouvadev/dropwise/internal/safehttp"
+)
+
+// MaxExcerptLength is the maximum number of characters kept from a page description.
+const MaxExcerptLength = 300
+
+// ErrBlockedAddress is returned when a URL resolves to an address the fetcher may not connect to.
+var ErrBlockedAddress = safehttp.ErrBlockedAddress
+
+// FetcherConfig holds the guardrails applied to metadata fetches of user-supplied URLs.
+type FetcherConfig struct {
+	Timeout      time.Duration  // Overall time budget for a single attempt, including redirects
+	MaxBodyBytes int64          // Maximum number of response bytes read
+	MaxRedirects int            // Maximum number of redirects followed
+	Retries      int            // Additional attempts after a network error or 5xx response
+	BlockedCIDRs []netip.Prefix // Extra ranges to refuse on top of loopback/private/link-local
+}
+
+// DefaultFetcherConfig returns the configuration used when nothing is configured.
+func DefaultFetcherConfig() FetcherConfig {
+	return FetcherConfig{
+		Timeout:      5 * time.Second,
+		MaxBodyBytes: 1 << 20, // Only the document head is needed, 1 MiB is plenty.
+		MaxRedirects: 3,
+		Retries:      0,
+	}
+}
+
+var (
+	titleRe   = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title>`)
+	metaTagRe = regexp.MustCompile(`(?is)<meta\s[^>]*>`)
+	attrRe    = regexp.MustCompile(`(?is)([a-z:-]+)\s*=\s*("([^"]*)"|'([^']*)')`)
+)
+
+// PageMetadata holds the information extracted from a fetched page.
+type PageMetadata struct {
+	Title       string
+	Description string
+}
+
+// Fetcher retrieves a page over HTTP and extracts its title and description.
+// It refuses to connect to loopback, private, link-local and other internal addresses
+// so user-supplied URLs cannot be used to reach internal services (SSRF).
+type Fetcher struct {
+	client *http.Client
+	cfg    FetcherConfig
+}
+
+// NewFetcher creates a Fetcher applying the given guardrails.
+func NewFetcher(cfg FetcherConfig) *Fetcher {
+	client := safehttp.NewClient(safehttp.Config{
+		Timeout:      cfg.Timeout,
+		MaxRedirects: cfg.MaxRedirects,
+		BlockedCIDRs: cfg.BlockedCIDRs,
+	})
+	return &Fetcher{client: client, cfg: cfg}
+}
+
+// Fetch downloads the page at rawURL and extracts its <title> and its
+// <meta name="description"> (falling back to og:description).
+// The description is truncated to MaxExcerptLength characters.
+// Network errors and 5xx responses are retried up to the configured number of times;
+// blocked addresses are never retried.
+func (f *Fetcher) Fetch(ctx context.Context, rawURL string) (PageMetadata, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return PageMetadata{}, fmt.Errorf("invalid url: %w", err)
+	}
+	if err := safehttp.CheckScheme(u); err != nil {
+		return PageMetadata{}, err
+	}
+
+	var lastErr error
+	for attempt := 0; attempt <= f.cfg.Retries; attempt++ {
+		meta, retryable, err := f.fetchOnce(ctx, u.String())
+		if err == nil {
+			return meta, nil
+		}
+		lastErr = err
+		if !retryable || ctx.Err() != nil {
+			break
+		}
+	}
+	return PageMetadata{}, lastErr
+}
+
+// fetchOnce performs a single fetch attempt and reports whether a failure is worth retrying.
+func (f *Fetcher) fetchOnce(ctx context.Context, rawURL string) (PageMetadata, bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return PageMetadata{}, false, fmt.Errorf("failed to build request: %w", err)
+	}
+	req.Header.Set("User-Agent", "dropwise-api metadata fetcher")
+	req.Header.Set("Accept", "text/html,application/xhtml+xml")
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return PageMetadata{}, !errors.Is(err, ErrBlockedAddress), fmt.Errorf("failed to fetch url: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return PageMetadata{}, resp.StatusCode >= 500, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, f.cfg.MaxBodyBytes))
+	if err != nil {
+		return PageMetadata{}, true, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	return parse(string(body)), false, nil
+}
+
+// parse extracts the title and description from an HTML document.
+func parse(doc string) PageMetadata {
+	var meta PageMetadata
+
+	if m := titleRe.FindStringSubmatch(doc); m != nil {
+		meta.Title = cleanText(m[1])
+	}
+
+	var ogDescription string
+	for _, tag := range metaTagRe.FindAllString(doc, -1) {
+		attrs := parseAttributes(tag)
+		content := cleanText(attrs["content"])
+		if content == "" {
+			continue
+		}
+		if strings.EqualFold(attrs["name"], "description") && meta.Description == "" {
+			meta.Description = content
+		}
+		if strings.EqualFold(attrs["property"], "og:description") && ogDescription == "" {
+			ogDescription = content
+		}
+	}
+	if meta.Description == "" {
+		meta.Description = ogDescription
+	}
+	meta.Description = Truncate(meta.Description, MaxExcerptLength)
+
+	return meta
+}
+
+// parseAttributes returns the lower-cased attribute names of a tag mapped to their values.
+func parseAttributes(tag string) map[string]string {
+	attrs := make(map[string]string)
+	for _, m := range attrRe.FindAllStringSubmatch(tag, -1) {
+		value := m[3]
+		if value == "" {
+			value = m[4]
+		}
+		attrs[strings.ToLower(m[1])] = value
+	}
+	return attrs
+}
+
+// cleanText unescapes HTML entities and collapses whitespace.
+func cleanText(s string) string {
+	return strings.Join(strings.Fields(html.UnescapeString(s)), " ")
+}
+
+// Truncate shortens s to at most max characters, appending an ellipsis when cut.
+func Truncate(s string, max int) string {
+	if utf8.RuneCountInString(s) <= max {
+		return s
+	}
+	runes := []rune(s)
+	return strings.TrimSpace(string(runes[:max-1])) + "…"
+}
@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/nouvadev/dropwise/internal/server/httputils"
+)
+
+// AdminMiddleware restricts a route to the users listed in ADMIN_USER_IDS.
+// It must run after AuthMiddleware.
+func AdminMiddleware(adminIDs map[uuid.UUID]bool) Middleware {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			userID, ok := GetUserIDFromContext(r)
+			if !ok {
+				httputils.RespondWithError(w, http.StatusUnauthorized, "User not authenticated")
+				return
+			}
+			if !adminIDs[userID] {
+				log.Printf("AdminMiddleware: User %s denied access to %s %s", userID, r.Method, r.URL.Path)
+				httputils.RespondWithError(w, http.StatusForbidden, "Admin access required")
+				return
+			}
+			next(w, r)
+		}
+	}
+}
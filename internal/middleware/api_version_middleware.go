@@ -0,0 +1,94 @@
+package middleware
+
+import (
+	"context"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/nouvadev/dropwise/internal/server/httputils"
+)
+
+// Versions of the response bodies a client can ask for with an Accept header such as
+// application/vnd.dropwise.v2+json. The path version (/api/v1/) stays the same across them.
+const (
+	APIVersion1 = 1
+	APIVersion2 = 2
+
+	// DefaultAPIVersion is used for requests that don't ask for a version
+	DefaultAPIVersion = APIVersion1
+)
+
+// supportedAPIVersions lists every version NegotiateAPIVersion may pick.
+var supportedAPIVersions = []int{APIVersion1, APIVersion2}
+
+// APIVersionKey is the key used to store the request's API version (an int) in the request context
+const APIVersionKey contextKey = "apiVersion"
+
+// APIVersionMiddleware negotiates the version of the response bodies from the Accept header
+// and stores it in the request context for handlers to shape their responses with.
+// Requests without a vendor media type get DefaultAPIVersion; an Accept header naming only
+// unsupported versions is answered with 406. When a version was asked for, JSON responses
+// are labelled with its vendor media type. Vary: Accept is added to every response.
+func APIVersionMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		httputils.AddVary(w.Header(), "Accept")
+
+		version, explicit, ok := httputils.NegotiateAPIVersion(r, DefaultAPIVersion, supportedAPIVersions...)
+		if !ok {
+			supported := make([]string, len(supportedAPIVersions))
+			for i, v := range supportedAPIVersions {
+				supported[i] = httputils.APIVersionMediaType(v)
+			}
+			httputils.RespondWithError(w, http.StatusNotAcceptable, "Unsupported API version in Accept header, supported: "+strings.Join(supported, ", "))
+			return
+		}
+
+		if explicit {
+			w = &versionedResponseWriter{ResponseWriter: w, mediaType: httputils.APIVersionMediaType(version)}
+		}
+		ctx := context.WithValue(r.Context(), APIVersionKey, version)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// GetAPIVersionFromContext retrieves the request's API version from the request context.
+// It returns DefaultAPIVersion when APIVersionMiddleware did not run.
+func GetAPIVersionFromContext(r *http.Request) int {
+	if version, ok := r.Context().Value(APIVersionKey).(int); ok {
+		return version
+	}
+	return DefaultAPIVersion
+}
+
+// versionedResponseWriter relabels application/json responses with the negotiated vendor
+// media type when the headers are written. Other content types (CSV, event streams) are left alone.
+type versionedResponseWriter struct {
+	http.ResponseWriter
+	mediaType   string
+	wroteHeader bool
+}
+
+// WriteHeader swaps the content type before calling the underlying ResponseWriter
+func (vw *versionedResponseWriter) WriteHeader(code int) {
+	if !vw.wroteHeader {
+		vw.wroteHeader = true
+		if mediaType, params, err := mime.ParseMediaType(vw.Header().Get("Content-Type")); err == nil && mediaType == "application/json" {
+			vw.Header().Set("Content-Type", mime.FormatMediaType(vw.mediaType, params))
+		}
+	}
+	vw.ResponseWriter.WriteHeader(code)
+}
+
+// Write sends the headers first when the handler didn't, so the content type is still swapped
+func (vw *versionedResponseWriter) Write(p []byte) (int, error) {
+	if !vw.wroteHeader {
+		vw.WriteHeader(http.StatusOK)
+	}
+	return vw.ResponseWriter.Write(p)
+}
+
+// Unwrap returns the underlying ResponseWriter, so streaming handlers can still flush it
+func (vw *versionedResponseWriter) Unwrap() http.ResponseWriter {
+	return vw.ResponseWriter
+}
@@ -17,9 +17,13 @@ type contextKey string
 // UserIDKey is the key used to store the user ID in the request context
 const UserIDKey contextKey = "userID"
 
+// WorkspaceIDKey is the key used to store the active workspace ID (a uuid.NullUUID) in the request context
+const WorkspaceIDKey contextKey = "workspaceID"
+
 // AuthMiddleware validates JWT tokens from the Authorization header
-// and adds the user ID to the request context
-func AuthMiddleware(jwtSecret string) Middleware {
+// and adds the user ID to the request context. With a non-nil tokenCache, tokens validated
+// recently are not verified again.
+func AuthMiddleware(jwtKeys auth.JWTKeys, tokenCache *auth.TokenCache) Middleware {
 	return func(next http.HandlerFunc) http.HandlerFunc {
 		return func(w http.ResponseWriter, r *http.Request) {
 			// Get the Authorization header
@@ -40,14 +44,19 @@ func AuthMiddleware(jwtSecret string) Middleware {
 			tokenString := parts[1]
 
 			// Validate the token
-			claims, err := auth.ValidateJWT(tokenString, jwtSecret)
+			claims, err := tokenCache.Validate(tokenString, jwtKeys)
 			if err != nil {
 				httputils.RespondWithError(w, http.StatusUnauthorized, fmt.Sprintf("Invalid or expired token: %v", err))
 				return
 			}
 
-			// Store user ID in context
+			// Store user ID and the token's workspace in context
 			ctx := context.WithValue(r.Context(), UserIDKey, claims.UserID)
+			workspaceID := uuid.NullUUID{}
+			if claims.WorkspaceID != nil {
+				workspaceID = uuid.NullUUID{UUID: *claims.WorkspaceID, Valid: true}
+			}
+			ctx = context.WithValue(ctx, WorkspaceIDKey, workspaceID)
 
 			// Call the next handler with the enhanced context
 			next(w, r.WithContext(ctx))
@@ -61,3 +70,10 @@ func GetUserIDFromContext(r *http.Request) (uuid.UUID, bool) {
 	userID, ok := r.Context().Value(UserIDKey).(uuid.UUID)
 	return userID, ok
 }
+
+// GetWorkspaceIDFromContext retrieves the active workspace ID from the request context.
+// An invalid (null) result means the request is scoped to the user's personal space.
+func GetWorkspaceIDFromContext(r *http.Request) uuid.NullUUID {
+	workspaceID, _ := r.Context().Value(WorkspaceIDKey).(uuid.NullUUID)
+	return workspaceID
+}
@@ -0,0 +1,149 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// BodyLoggingConfig configures BodyLoggingMiddleware.
+type BodyLoggingConfig struct {
+	Enabled bool
+	// MaxBytes caps how much of each body is logged; longer bodies are cut off.
+	MaxBytes int
+	// ExcludedPrefixes lists path prefixes whose bodies are never logged, e.g. the auth routes.
+	ExcludedPrefixes []string
+}
+
+// redactedValue replaces the values of sensitive fields in logged bodies.
+const redactedValue = "[REDACTED]"
+
+// sensitiveKeyParts are the substrings marking a JSON field as sensitive, compared lower-cased.
+var sensitiveKeyParts = []string{"password", "token", "secret"}
+
+// sensitiveFieldPattern finds sensitive string fields in text that isn't valid JSON, such as a
+// body cut off at MaxBytes. A value cut off before its closing quote is matched to the end.
+var sensitiveFieldPattern = regexp.MustCompile(`(?i)("[^"]*(?:password|token|secret)[^"]*"\s*:\s*)"(?:[^"\\]|\\.)*(?:"|\\?$)`)
+
+// BodyLoggingMiddleware logs the request and response bodies of every request outside
+// cfg.ExcludedPrefixes, with password, token and secret fields redacted. It is a debugging
+// aid and does nothing unless enabled (DEBUG_LOG_BODIES, which requires DEBUG=true).
+// The request body is still passed on in full to the handler.
+func BodyLoggingMiddleware(cfg BodyLoggingConfig, next http.Handler) http.Handler {
+	if !cfg.Enabled {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for _, prefix := range cfg.ExcludedPrefixes {
+			if strings.HasPrefix(r.URL.Path, prefix) {
+				next.ServeHTTP(w, r)
+				return
+			}
+		}
+
+		if r.Body != nil && r.Body != http.NoBody {
+			// Only the logged part is buffered; the rest is read from the original body
+			head, err := io.ReadAll(io.LimitReader(r.Body, int64(cfg.MaxBytes)+1))
+			if err != nil {
+				log.Printf("[%s] %s - Failed to read request body for logging: %v", r.Method, r.URL.Path, err)
+			}
+			r.Body = readCloser{Reader: io.MultiReader(bytes.NewReader(head), r.Body), Closer: r.Body}
+			if len(head) > 0 {
+				log.Printf("[%s] %s - Request body: %s", r.Method, r.URL.Path, loggedBody(head, cfg.MaxBytes))
+			}
+		}
+
+		bw := &bodyCapturingResponseWriter{ResponseWriter: w, statusCode: http.StatusOK, maxBytes: cfg.MaxBytes}
+		next.ServeHTTP(bw, r)
+		log.Printf("[%s] %s - Status: %d - Response body: %s", r.Method, r.URL.Path, bw.statusCode, loggedBody(bw.body.Bytes(), cfg.MaxBytes))
+	})
+}
+
+// loggedBody returns body, or its first maxBytes when longer, with sensitive fields redacted.
+func loggedBody(body []byte, maxBytes int) string {
+	if len(body) > maxBytes {
+		return redactText(body[:maxBytes]) + "... (truncated)"
+	}
+	var value any
+	if err := json.Unmarshal(body, &value); err != nil {
+		return redactText(body)
+	}
+	redacted, err := json.Marshal(redactJSON(value))
+	if err != nil {
+		return redactText(body)
+	}
+	return string(redacted)
+}
+
+// redactJSON replaces the values of sensitive fields anywhere in a decoded JSON value.
+func redactJSON(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, field := range v {
+			if isSensitiveKey(key) {
+				v[key] = redactedValue
+			} else {
+				v[key] = redactJSON(field)
+			}
+		}
+	case []any:
+		for i, item := range v {
+			v[i] = redactJSON(item)
+		}
+	}
+	return value
+}
+
+// redactText redacts sensitive string fields in body text that couldn't be decoded as JSON.
+func redactText(body []byte) string {
+	return sensitiveFieldPattern.ReplaceAllString(string(body), `${1}"`+redactedValue+`"`)
+}
+
+// isSensitiveKey reports whether a JSON field name marks a secret.
+func isSensitiveKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, part := range sensitiveKeyParts {
+		if strings.Contains(key, part) {
+			return true
+		}
+	}
+	return false
+}
+
+// readCloser combines the reader handed to the handler with the original body's Close.
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// bodyCapturingResponseWriter keeps the status code and the first maxBytes+1 bytes of the
+// response, enough to tell whether it was cut off.
+type bodyCapturingResponseWriter struct {
+	http.ResponseWriter
+	statusCode int
+	maxBytes   int
+	body       bytes.Buffer
+}
+
+// WriteHeader captures the status code before calling the underlying ResponseWriter
+func (bw *bodyCapturingResponseWriter) WriteHeader(code int) {
+	bw.statusCode = code
+	bw.ResponseWriter.WriteHeader(code)
+}
+
+// Write captures what fits under the size cap and writes everything through.
+func (bw *bodyCapturingResponseWriter) Write(p []byte) (int, error) {
+	if room := bw.maxBytes + 1 - bw.body.Len(); room > 0 {
+		bw.body.Write(p[:min(room, len(p))])
+	}
+	return bw.ResponseWriter.Write(p)
+}
+
+// Unwrap returns the underlying ResponseWriter, so streaming handlers can still flush it
+func (bw *bodyCapturingResponseWriter) Unwrap() http.ResponseWriter {
+	return bw.ResponseWriter
+}
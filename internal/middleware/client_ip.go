@@ -0,0 +1,79 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+
+	"github.com/nouvadev/dropwise/internal/audit"
+)
+
+// ClientIPResolver determines the address of the client that made a request.
+// X-Forwarded-For is only honoured when the request arrives from a trusted proxy,
+// otherwise any client could pick its own address by sending the header.
+type ClientIPResolver struct {
+	trustedProxies []netip.Prefix
+}
+
+// NewClientIPResolver creates a resolver trusting forwarding headers from the given ranges
+// (e.g. the load balancer in front of Cloud Run). With no ranges the peer address is always used.
+func NewClientIPResolver(trustedProxies []netip.Prefix) *ClientIPResolver {
+	return &ClientIPResolver{trustedProxies: trustedProxies}
+}
+
+// ClientIP returns the client address for r.
+// X-Forwarded-For is walked from the right, skipping trusted proxies, so the first
+// untrusted hop is returned; entries further left were supplied by the client itself.
+func (c *ClientIPResolver) ClientIP(r *http.Request) string {
+	peer := remoteIP(r.RemoteAddr)
+	if !c.isTrusted(peer) {
+		return peer
+	}
+
+	hops := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(hops[i])
+		if hop == "" {
+			continue
+		}
+		if !c.isTrusted(hop) {
+			return hop
+		}
+		peer = hop
+	}
+	return peer // Every hop is a trusted proxy, use the outermost one
+}
+
+// isTrusted reports whether ip belongs to a trusted proxy range.
+func (c *ClientIPResolver) isTrusted(ip string) bool {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, prefix := range c.trustedProxies {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// remoteIP strips the port from a RemoteAddr value.
+func remoteIP(remoteAddr string) string {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		return remoteAddr
+	}
+	return host
+}
+
+// ClientIPMiddleware stores the client IP of every request in its context, where the
+// audit log picks it up (see audit.WithSourceIP).
+func ClientIPMiddleware(resolver *ClientIPResolver, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := audit.WithSourceIP(r.Context(), resolver.ClientIP(r))
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"mime"
+	"net/http"
+
+	"github.com/nouvadev/dropwise/internal/server/httputils"
+)
+
+// RequireJSONContentType rejects write requests carrying a body that is not declared as
+// application/json (or application/merge-patch+json for PATCH) with 415 Unsupported Media Type.
+// Parameters such as charset are allowed.
+// Requests without a body are passed through so the handler can report the missing body.
+// When enabled is false the middleware does nothing.
+func RequireJSONContentType(enabled bool) Middleware {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		if !enabled {
+			return next
+		}
+		return func(w http.ResponseWriter, r *http.Request) {
+			isWrite := r.Method == http.MethodPost || r.Method == http.MethodPut || r.Method == http.MethodPatch
+			if isWrite && r.ContentLength != 0 {
+				mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+				isMergePatch := r.Method == http.MethodPatch && mediaType == "application/merge-patch+json"
+				if err != nil || (mediaType != "application/json" && !isMergePatch) {
+					httputils.RespondWithError(w, http.StatusUnsupportedMediaType, "Content-Type must be application/json")
+					return
+				}
+			}
+			next(w, r)
+		}
+	}
+}
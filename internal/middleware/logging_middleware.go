@@ -3,37 +3,63 @@ package middleware
 import (
 	"log"
 	"net/http"
+	"sync/atomic"
 	"time"
 )
 
 // LoggingMiddleware logs details about HTTP requests including method, path,
-// status code, and request duration
-func LoggingMiddleware(next http.HandlerFunc) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		// Start timer
-		start := time.Now()
-
-		// Create a custom response writer to capture the status code
-		crw := &customResponseWriter{ResponseWriter: w, statusCode: http.StatusOK}
-
-		// Call the next handler
-		next(crw, r)
-
-		// Calculate duration
-		duration := time.Since(start)
-
-		// Log request details
-		log.Printf(
-			"[%s] %s %s - Status: %d - Duration: %v",
-			r.Method,
-			r.URL.Path,
-			r.RemoteAddr,
-			crw.statusCode,
-			duration,
-		)
+// status code, and request duration.
+// Non-2xx responses and requests slower than slowThreshold are always logged;
+// successful requests are sampled, logging one in every sampleRate.
+func LoggingMiddleware(sampleRate int, slowThreshold time.Duration) Middleware {
+	if sampleRate < 1 {
+		sampleRate = 1
+	}
+	var successCount atomic.Uint64
+
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			// Start timer
+			start := time.Now()
+
+			// Create a custom response writer to capture the status code
+			crw := &customResponseWriter{ResponseWriter: w, statusCode: http.StatusOK}
+
+			// Call the next handler
+			next(crw, r)
+
+			// Calculate duration
+			duration := time.Since(start)
+
+			if !shouldLogRequest(crw.statusCode, duration, slowThreshold, sampleRate, &successCount) {
+				return
+			}
+
+			// Log request details
+			log.Printf(
+				"[%s] %s %s - Status: %d - Duration: %v",
+				r.Method,
+				r.URL.Path,
+				r.RemoteAddr,
+				crw.statusCode,
+				duration,
+			)
+		}
 	}
 }
 
+// shouldLogRequest decides whether a finished request is logged.
+func shouldLogRequest(statusCode int, duration, slowThreshold time.Duration, sampleRate int, successCount *atomic.Uint64) bool {
+	if statusCode < 200 || statusCode > 299 {
+		return true // Always log errors and other non-2xx responses
+	}
+	if slowThreshold > 0 && duration >= slowThreshold {
+		return true // Always log slow requests
+	}
+	// Sample successful requests: the 1st, (n+1)th, (2n+1)th... are logged
+	return (successCount.Add(1)-1)%uint64(sampleRate) == 0
+}
+
 // customResponseWriter is a wrapper around http.ResponseWriter that captures the status code
 type customResponseWriter struct {
 	http.ResponseWriter
@@ -45,3 +71,8 @@ func (crw *customResponseWriter) WriteHeader(code int) {
 	crw.statusCode = code
 	crw.ResponseWriter.WriteHeader(code)
 }
+
+// Unwrap returns the underlying ResponseWriter
+func (crw *customResponseWriter) Unwrap() http.ResponseWriter {
+	return crw.ResponseWriter
+}
@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/nouvadev/dropwise/internal/server/httputils"
+)
+
+func TestLoggingMiddlewareLogsCancelledRequests(t *testing.T) {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	handler := LoggingMiddleware(1000, 0)(func(w http.ResponseWriter, r *http.Request) {
+		httputils.RespondWithServerError(w, r.Context().Err(), "Failed to list drops")
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodGet, "/api/v1/drops", nil).WithContext(ctx))
+
+	if rec.Code != httputils.StatusClientClosedRequest {
+		t.Errorf("status = %d, want %d", rec.Code, httputils.StatusClientClosedRequest)
+	}
+	if !strings.Contains(buf.String(), "Status: 499") {
+		t.Errorf("log = %q, want the 499 to be logged", buf.String())
+	}
+}
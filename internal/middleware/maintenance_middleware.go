@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nouvadev/dropwise/internal/server/httputils"
+)
+
+// MaintenanceConfig controls MaintenanceMiddleware.
+type MaintenanceConfig struct {
+	// Enabled reports whether maintenance mode is on. It is checked on every request so
+	// the mode can be toggled at runtime.
+	Enabled func() bool
+	// RetryAfter is sent to rejected clients as the Retry-After header.
+	RetryAfter time.Duration
+	// ExemptPaths are served normally even in maintenance mode, so admins can still log in
+	// and turn the mode off.
+	ExemptPaths []string
+}
+
+// MaintenanceMiddleware puts the API in read-only mode while maintenance mode is on:
+// POST, PUT, PATCH and DELETE requests get 503 Service Unavailable, everything else proceeds.
+// It wraps a whole http.Handler and should sit inside the CORS handler so browsers can read the 503.
+func MaintenanceMiddleware(cfg MaintenanceConfig, next http.Handler) http.Handler {
+	retryAfter := strconv.Itoa(max(int(cfg.RetryAfter.Seconds()), 1))
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !cfg.Enabled() || !isMutatingMethod(r.Method) || isExemptPath(r.URL.Path, cfg.ExemptPaths) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		w.Header().Set("Retry-After", retryAfter)
+		httputils.RespondWithError(w, http.StatusServiceUnavailable, "The API is in read-only maintenance mode, please try again later")
+	})
+}
+
+// isMutatingMethod reports whether an HTTP method changes state.
+func isMutatingMethod(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+// isExemptPath reports whether path is one of exempt, ignoring a trailing slash.
+func isExemptPath(path string, exempt []string) bool {
+	path = strings.TrimSuffix(path, "/")
+	for _, p := range exempt {
+		if path == p {
+			return true
+		}
+	}
+	return false
+}
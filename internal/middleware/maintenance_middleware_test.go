@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestMaintenanceMiddleware(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	handler := MaintenanceMiddleware(MaintenanceConfig{
+		Enabled:     func() bool { return true },
+		RetryAfter:  time.Minute,
+		ExemptPaths: []string{"/api/v1/auth/introspect", "/api/v1/drops/batch-get"},
+	}, next)
+
+	tests := []struct {
+		method string
+		path   string
+		want   int
+	}{
+		{http.MethodGet, "/api/v1/drops", http.StatusOK},
+		{http.MethodPost, "/api/v1/drops", http.StatusServiceUnavailable},
+		{http.MethodDelete, "/api/v1/drops/1", http.StatusServiceUnavailable},
+		{http.MethodPost, "/api/v1/auth/introspect", http.StatusOK},
+		{http.MethodPost, "/api/v1/drops/batch-get", http.StatusOK},
+		{http.MethodPost, "/api/v1/drops/batch-get/", http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.path, nil))
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d", rec.Code, tt.want)
+			}
+			if tt.want == http.StatusServiceUnavailable && rec.Header().Get("Retry-After") != "60" {
+				t.Errorf("Retry-After = %q, want %q", rec.Header().Get("Retry-After"), "60")
+			}
+		})
+	}
+}
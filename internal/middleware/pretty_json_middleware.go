@@ -0,0 +1,22 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/nouvadev/dropwise/internal/server/httputils"
+)
+
+// PrettyJSONMiddleware makes JSON responses indented when the request carries ?pretty=true.
+// It is a debugging aid and does nothing unless enabled (DEBUG=true).
+func PrettyJSONMiddleware(enabled bool, next http.Handler) http.Handler {
+	if !enabled {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if pretty, err := strconv.ParseBool(r.URL.Query().Get("pretty")); err == nil && pretty {
+			w = httputils.WithPrettyJSON(w)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
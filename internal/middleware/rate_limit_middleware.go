@@ -0,0 +1,177 @@
+package middleware
+
+import (
+	"context"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/nouvadev/dropwise/internal/server/httputils"
+)
+
+// RateLimitStore keeps the request counts behind GlobalRateLimitMiddleware. Allow takes one
+// request for key and reports whether it is within the limit; when it is not, retryAfter is
+// how long the client should wait. An error means the store could not be reached.
+type RateLimitStore interface {
+	Allow(ctx context.Context, key string) (allowed bool, retryAfter time.Duration, err error)
+}
+
+// NewMemoryRateLimitStore returns a RateLimitStore that keeps its counters in this process,
+// allowing limit requests per window per key. Each API instance counts on its own, so use
+// a shared store such as Redis when running more than one.
+func NewMemoryRateLimitStore(limit int, window time.Duration) RateLimitStore {
+	return newRateLimiter(limit, window)
+}
+
+// rateLimiter is an in-memory token bucket per key. Each bucket holds up to limit tokens
+// and refills at limit tokens per window, so short bursts are allowed but the sustained
+// rate is capped.
+type rateLimiter struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	limit     float64
+	perSecond float64
+	lastSweep time.Time
+}
+
+type bucket struct {
+	tokens   float64
+	lastSeen time.Time
+}
+
+func newRateLimiter(limit int, window time.Duration) *rateLimiter {
+	return &rateLimiter{
+		buckets:   make(map[string]*bucket),
+		limit:     float64(limit),
+		perSecond: float64(limit) / window.Seconds(),
+		lastSweep: time.Now(),
+	}
+}
+
+// allow takes a token for key. When none is left it returns false and how long
+// the caller should wait before retrying.
+func (l *rateLimiter) allow(key string, now time.Time) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.sweep(now)
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: l.limit, lastSeen: now}
+		l.buckets[key] = b
+	}
+	b.tokens = math.Min(l.limit, b.tokens+now.Sub(b.lastSeen).Seconds()*l.perSecond)
+	b.lastSeen = now
+
+	if b.tokens < 1 {
+		wait := time.Duration((1 - b.tokens) / l.perSecond * float64(time.Second))
+		return false, wait
+	}
+	b.tokens--
+	return true, 0
+}
+
+// Allow implements RateLimitStore.
+func (l *rateLimiter) Allow(_ context.Context, key string) (bool, time.Duration, error) {
+	allowed, retryAfter := l.allow(key, time.Now())
+	return allowed, retryAfter, nil
+}
+
+// sweep drops buckets that have been idle long enough to be full again, at most once a minute.
+func (l *rateLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < time.Minute {
+		return
+	}
+	l.lastSweep = now
+	refill := time.Duration(l.limit / l.perSecond * float64(time.Second))
+	for key, b := range l.buckets {
+		if now.Sub(b.lastSeen) > refill {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// RateLimitConfig controls GlobalRateLimitMiddleware.
+type RateLimitConfig struct {
+	Enabled bool
+	// RequestsPerMinute is the sustained number of requests allowed per client IP.
+	RequestsPerMinute int
+	// Resolver determines the client IP, honouring forwarding headers from trusted proxies.
+	Resolver *ClientIPResolver
+	// Store keeps the counters; nil uses an in-memory store local to this process.
+	Store RateLimitStore
+}
+
+// GlobalRateLimitMiddleware limits every client IP to cfg.RequestsPerMinute requests,
+// answering 429 Too Many Requests with a Retry-After header once the limit is hit.
+// It wraps the whole http.Handler so it is the outermost layer for every route.
+// When cfg.Enabled is false it does nothing. If the store fails the request is let through,
+// so an outage of a shared store doesn't take the API down with it.
+func GlobalRateLimitMiddleware(cfg RateLimitConfig, next http.Handler) http.Handler {
+	if !cfg.Enabled || cfg.RequestsPerMinute <= 0 {
+		return next
+	}
+	resolver := cfg.Resolver
+	if resolver == nil {
+		resolver = NewClientIPResolver(nil)
+	}
+	store := cfg.Store
+	if store == nil {
+		store = NewMemoryRateLimitStore(cfg.RequestsPerMinute, time.Minute)
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		allowed, retryAfter, err := store.Allow(r.Context(), resolver.ClientIP(r))
+		if err != nil {
+			log.Printf("Rate limit store unavailable, allowing request: %v", err)
+			allowed = true
+		}
+		if !allowed {
+			respondRateLimited(w, retryAfter)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// RouteRateLimitMiddleware limits each client IP on the routes it wraps to what store allows,
+// for routes that need a tighter limit than the global one. name prefixes the keys so routes
+// sharing a store keep separate counters. A nil store turns the limit off. Like the global
+// limit, a failing store lets the request through.
+func RouteRateLimitMiddleware(name string, store RateLimitStore, resolver *ClientIPResolver) Middleware {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		if store == nil {
+			return next
+		}
+		if resolver == nil {
+			resolver = NewClientIPResolver(nil)
+		}
+		return func(w http.ResponseWriter, r *http.Request) {
+			allowed, retryAfter, err := store.Allow(r.Context(), name+":"+resolver.ClientIP(r))
+			if err != nil {
+				log.Printf("Rate limit store unavailable, allowing request to %s: %v", name, err)
+				allowed = true
+			}
+			if !allowed {
+				respondRateLimited(w, retryAfter)
+				return
+			}
+			next(w, r)
+		}
+	}
+}
+
+// respondRateLimited answers 429 Too Many Requests, telling the client to retry after
+// retryAfter rounded up to whole seconds.
+func respondRateLimited(w http.ResponseWriter, retryAfter time.Duration) {
+	seconds := int(math.Ceil(retryAfter.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	w.Header().Set("Retry-After", strconv.Itoa(seconds))
+	httputils.RespondWithError(w, http.StatusTooManyRequests, "Too many requests, please slow down")
+}
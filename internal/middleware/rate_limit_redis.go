@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// redisRateLimitKeyPrefix namespaces the counters so the Redis instance can be shared.
+const redisRateLimitKeyPrefix = "dropwise:ratelimit:"
+
+// redisIncrWithExpiry counts a request in a fixed window. The increment and the expiry are
+// set in one script so a counter can never be left behind without a TTL, and every
+// instance sharing the Redis server sees the same count.
+var redisIncrWithExpiry = redis.NewScript(`
+local count = redis.call("INCR", KEYS[1])
+local ttl = redis.call("PTTL", KEYS[1])
+if ttl < 0 then
+	redis.call("PEXPIRE", KEYS[1], ARGV[1])
+	ttl = tonumber(ARGV[1])
+end
+return {count, ttl}
+`)
+
+// redisRateLimitStore is a fixed-window counter per key kept in Redis, so the limit holds
+// across every API instance rather than per process.
+type redisRateLimitStore struct {
+	client *redis.Client
+	limit  int64
+	window time.Duration
+}
+
+// NewRedisRateLimitStore returns a RateLimitStore backed by client, allowing limit requests
+// per window per key across every instance that shares the Redis server.
+func NewRedisRateLimitStore(client *redis.Client, limit int, window time.Duration) RateLimitStore {
+	return &redisRateLimitStore{client: client, limit: int64(limit), window: window}
+}
+
+// Allow implements RateLimitStore.
+func (s *redisRateLimitStore) Allow(ctx context.Context, key string) (bool, time.Duration, error) {
+	res, err := redisIncrWithExpiry.Run(ctx, s.client, []string{redisRateLimitKeyPrefix + key}, s.window.Milliseconds()).Int64Slice()
+	if err != nil {
+		return false, 0, err
+	}
+	count, ttl := res[0], time.Duration(res[1])*time.Millisecond
+	if count > s.limit {
+		return false, ttl, nil
+	}
+	return true, 0, nil
+}
@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// SecurityHeadersConfig controls the headers set by SecurityHeadersMiddleware.
+type SecurityHeadersConfig struct {
+	// HSTSMaxAge enables Strict-Transport-Security when greater than zero.
+	// Only enable it once the API is guaranteed to be served over HTTPS.
+	HSTSMaxAge time.Duration
+	// ContentSecurityPolicy is sent as-is when non-empty.
+	ContentSecurityPolicy string
+}
+
+// SecurityHeadersMiddleware sets basic browser security headers on every response.
+// It wraps a whole http.Handler so it also covers responses written by the CORS handler.
+func SecurityHeadersMiddleware(cfg SecurityHeadersConfig, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h := w.Header()
+		h.Set("X-Content-Type-Options", "nosniff")
+		h.Set("X-Frame-Options", "DENY")
+		if cfg.ContentSecurityPolicy != "" {
+			h.Set("Content-Security-Policy", cfg.ContentSecurityPolicy)
+		}
+		if cfg.HSTSMaxAge > 0 {
+			h.Set("Strict-Transport-Security", fmt.Sprintf("max-age=%d; includeSubDomains", int64(cfg.HSTSMaxAge.Seconds())))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
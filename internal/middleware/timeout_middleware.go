@@ -0,0 +1,125 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/nouvadev/dropwise/internal/server/httputils"
+)
+
+// TimeoutMiddleware gives a handler timeout to respond. The request context is cancelled
+// at the deadline, so database calls are abandoned, and the client gets 503 Service
+// Unavailable naming the timeout. Until then the response is buffered, which makes the
+// middleware unsuitable for streaming endpoints. A timeout of 0 or less disables it.
+func TimeoutMiddleware(timeout time.Duration) Middleware {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		if timeout <= 0 {
+			return next
+		}
+		return func(w http.ResponseWriter, r *http.Request) {
+			ctx, cancel := context.WithTimeout(r.Context(), timeout)
+			defer cancel()
+
+			tw := &timeoutWriter{ResponseWriter: w, header: make(http.Header)}
+			done := make(chan struct{})
+			panicked := make(chan any, 1)
+			go func() {
+				defer func() {
+					if p := recover(); p != nil {
+						panicked <- p
+					}
+				}()
+				next(tw, r.WithContext(ctx))
+				close(done)
+			}()
+
+			select {
+			case p := <-panicked:
+				panic(p) // Re-raised in the serving goroutine so net/http handles it as usual
+			case <-done:
+				tw.flushTo(w)
+			case <-ctx.Done():
+				tw.mu.Lock()
+				tw.timedOut = true
+				tw.mu.Unlock()
+				if !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+					return // The client went away, nobody is left to answer
+				}
+				log.Printf("Request %s %s timed out after %v", r.Method, r.URL.Path, timeout)
+				httputils.RespondWithError(w, http.StatusServiceUnavailable, fmt.Sprintf("Request timed out after %v", timeout))
+			}
+		}
+	}
+}
+
+// timeoutWriter buffers a response until the handler finishes. Writes after the
+// timeout fail with http.ErrHandlerTimeout.
+type timeoutWriter struct {
+	http.ResponseWriter // Only reached through Unwrap; the methods below never write to it
+
+	mu          sync.Mutex
+	header      http.Header
+	buf         bytes.Buffer
+	code        int
+	wroteHeader bool
+	timedOut    bool
+}
+
+// Header returns the buffered response headers.
+func (tw *timeoutWriter) Header() http.Header {
+	return tw.header
+}
+
+// WriteHeader records the status code.
+func (tw *timeoutWriter) WriteHeader(code int) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.timedOut || tw.wroteHeader {
+		return
+	}
+	tw.code = code
+	tw.wroteHeader = true
+}
+
+// Write buffers the response body.
+func (tw *timeoutWriter) Write(p []byte) (int, error) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+	if !tw.wroteHeader {
+		tw.code = http.StatusOK
+		tw.wroteHeader = true
+	}
+	return tw.buf.Write(p)
+}
+
+// Unwrap returns the underlying ResponseWriter, so wrappers beneath it (such as the
+// pretty-JSON marker) can still be found.
+func (tw *timeoutWriter) Unwrap() http.ResponseWriter {
+	return tw.ResponseWriter
+}
+
+// flushTo copies the buffered response to w.
+func (tw *timeoutWriter) flushTo(w http.ResponseWriter) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	dst := w.Header()
+	for key, values := range tw.header {
+		dst[key] = values
+	}
+	if !tw.wroteHeader {
+		tw.code = http.StatusOK
+	}
+	w.WriteHeader(tw.code)
+	if _, err := w.Write(tw.buf.Bytes()); err != nil {
+		log.Printf("Error writing buffered response: %v", err)
+	}
+}
@@ -0,0 +1,84 @@
+package middleware
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"net/http"
+	"time"
+
+	db "github.com/nouvadev/dropwise/internal/database/sqlc"
+	"github.com/nouvadev/dropwise/internal/schedule"
+	"github.com/nouvadev/dropwise/internal/server/httputils"
+)
+
+// TimezoneHeader lets a client pick the time zone used for computed local fields per request.
+const TimezoneHeader = "X-Timezone"
+
+// TimezoneKey is the key used to store the request's time zone (a *time.Location) in the request context
+const TimezoneKey contextKey = "timezone"
+
+// UserTimezoneKey is the key used to store the user's own time zone (a *time.Location) in the request context
+const UserTimezoneKey contextKey = "userTimezone"
+
+// TimezoneMiddleware resolves the time zones of an authenticated request.
+// The user's time zone comes from their timezone preference, resolved through userLocation
+// (see config.APIConfig.UserLocation); schedules are evaluated in it. The request's time zone,
+// used for computed local fields in responses, is the X-Timezone header when present and the
+// user's time zone otherwise. An invalid header is rejected with 400. It must run after AuthMiddleware.
+func TimezoneMiddleware(queries *db.Queries, userLocation func(sql.NullString) *time.Location) Middleware {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			userID, ok := GetUserIDFromContext(r)
+			if !ok {
+				httputils.RespondWithError(w, http.StatusUnauthorized, "User not authenticated")
+				return
+			}
+
+			var requestLoc *time.Location
+			if name := r.Header.Get(TimezoneHeader); name != "" {
+				loc, err := schedule.LoadTimezone(name)
+				if err != nil {
+					httputils.RespondWithError(w, http.StatusBadRequest, "Invalid X-Timezone header: "+err.Error())
+					return
+				}
+				requestLoc = loc
+			}
+
+			var timezone sql.NullString
+			prefs, err := queries.GetUserPreferences(r.Context(), userID)
+			if err == nil {
+				timezone = prefs.Timezone
+			} else if err != sql.ErrNoRows {
+				// Local fields are a convenience, so fall back to the default rather than fail the request
+				log.Printf("Error fetching timezone preference for user %s, using the default: %v", userID, err)
+			}
+			userLoc := userLocation(timezone)
+			if requestLoc == nil {
+				requestLoc = userLoc
+			}
+
+			ctx := context.WithValue(r.Context(), UserTimezoneKey, userLoc)
+			ctx = context.WithValue(ctx, TimezoneKey, requestLoc)
+			next(w, r.WithContext(ctx))
+		}
+	}
+}
+
+// GetTimezoneFromContext retrieves the time zone for computed local fields from the request context.
+// It returns UTC when TimezoneMiddleware did not run.
+func GetTimezoneFromContext(r *http.Request) *time.Location {
+	if loc, ok := r.Context().Value(TimezoneKey).(*time.Location); ok {
+		return loc
+	}
+	return time.UTC
+}
+
+// GetUserTimezoneFromContext retrieves the user's own time zone from the request context.
+// It returns UTC when TimezoneMiddleware did not run.
+func GetUserTimezoneFromContext(r *http.Request) *time.Location {
+	if loc, ok := r.Context().Value(UserTimezoneKey).(*time.Location); ok {
+		return loc
+	}
+	return time.UTC
+}
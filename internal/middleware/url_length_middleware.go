@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/nouvadev/dropwise/internal/server/httputils"
+)
+
+// URLLengthConfig controls the limits enforced by URLLengthMiddleware. A limit of 0 turns it off.
+type URLLengthConfig struct {
+	// MaxURLLength caps the request target: the path and raw query string, in bytes.
+	MaxURLLength int
+	// MaxQueryParamLength caps each query parameter's name and each of its values, after decoding.
+	MaxQueryParamLength int
+}
+
+// URLLengthMiddleware rejects requests with pathological URLs before any handler parses them:
+// a request target over MaxURLLength gets 414 URI Too Long, a query parameter name or value
+// over MaxQueryParamLength gets 400. It wraps a whole http.Handler so it covers every route.
+func URLLengthMiddleware(cfg URLLengthConfig, next http.Handler) http.Handler {
+	if cfg.MaxURLLength <= 0 && cfg.MaxQueryParamLength <= 0 {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if cfg.MaxURLLength > 0 && len(r.URL.RequestURI()) > cfg.MaxURLLength {
+			httputils.RespondWithError(w, http.StatusRequestURITooLong, "URL is longer than "+strconv.Itoa(cfg.MaxURLLength)+" bytes")
+			return
+		}
+
+		if cfg.MaxQueryParamLength > 0 && r.URL.RawQuery != "" {
+			// An unparsable query is left to the handler, which reports it in its own terms
+			query, _ := url.ParseQuery(r.URL.RawQuery)
+			for name, values := range query {
+				if len(name) > cfg.MaxQueryParamLength {
+					httputils.RespondWithError(w, http.StatusBadRequest, "A query parameter name is longer than "+strconv.Itoa(cfg.MaxQueryParamLength)+" bytes")
+					return
+				}
+				for _, value := range values {
+					if len(value) > cfg.MaxQueryParamLength {
+						httputils.RespondWithError(w, http.StatusBadRequest, "Query parameter "+name+" is longer than "+strconv.Itoa(cfg.MaxQueryParamLength)+" bytes")
+						return
+					}
+				}
+			}
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
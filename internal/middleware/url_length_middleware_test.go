@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestURLLengthMiddleware(t *testing.T) {
+	var reached bool
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reached = true
+		w.WriteHeader(http.StatusOK)
+	})
+	handler := URLLengthMiddleware(URLLengthConfig{MaxURLLength: 100, MaxQueryParamLength: 20}, next)
+
+	tests := []struct {
+		name   string
+		target string
+		want   int
+	}{
+		{"normal URL", "/api/v1/drops?status=new", http.StatusOK},
+		{"URL at the limit", "/" + strings.Repeat("a", 99), http.StatusOK},
+		{"URL over the limit", "/" + strings.Repeat("a", 100), http.StatusRequestURITooLong},
+		{"long query string", "/api/v1/drops?" + strings.Repeat("a=1&", 30), http.StatusRequestURITooLong},
+		{"parameter value at the limit", "/api/v1/drops?q=" + strings.Repeat("x", 20), http.StatusOK},
+		{"parameter value over the limit", "/api/v1/drops?q=" + strings.Repeat("x", 21), http.StatusBadRequest},
+		{"parameter name over the limit", "/api/v1/drops?" + strings.Repeat("n", 21) + "=1", http.StatusBadRequest},
+		{"escaped value counted decoded", "/api/v1/drops?q=" + strings.Repeat("%20", 20), http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reached = false
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.target, nil))
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d", rec.Code, tt.want)
+			}
+			if reached != (tt.want == http.StatusOK) {
+				t.Errorf("next handler reached = %v, want %v", reached, tt.want == http.StatusOK)
+			}
+		})
+	}
+}
+
+func TestURLLengthMiddlewareDisabled(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	handler := URLLengthMiddleware(URLLengthConfig{}, next)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/"+strings.Repeat("a", 10000), nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("status = %d with no limits, want %d", rec.Code, http.StatusOK)
+	}
+}
@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"context"
+	"log"
+	"net/http"
+
+	"github.com/google/uuid"
+	db "github.com/nouvadev/dropwise/internal/database/sqlc"
+	"github.com/nouvadev/dropwise/internal/server/httputils"
+)
+
+// WorkspaceHeader lets a client pick the active workspace per request, overriding the token's workspace.
+const WorkspaceHeader = "X-Workspace-ID"
+
+// WorkspaceMiddleware resolves the active workspace for an authenticated request.
+// The X-Workspace-ID header takes precedence over the workspace stored in the JWT;
+// an empty header value selects the personal space. Membership is verified before
+// the workspace is stored in the context. It must run after AuthMiddleware.
+func WorkspaceMiddleware(queries *db.Queries) Middleware {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			userID, ok := GetUserIDFromContext(r)
+			if !ok {
+				httputils.RespondWithError(w, http.StatusUnauthorized, "User not authenticated")
+				return
+			}
+
+			workspaceID := GetWorkspaceIDFromContext(r)
+			if values, present := r.Header[http.CanonicalHeaderKey(WorkspaceHeader)]; present {
+				workspaceID = uuid.NullUUID{}
+				if len(values) > 0 && values[0] != "" {
+					parsed, err := uuid.Parse(values[0])
+					if err != nil {
+						httputils.RespondWithError(w, http.StatusBadRequest, "Invalid X-Workspace-ID header format")
+						return
+					}
+					workspaceID = uuid.NullUUID{UUID: parsed, Valid: true}
+				}
+			}
+
+			if workspaceID.Valid {
+				isMember, err := queries.IsWorkspaceMember(r.Context(), db.IsWorkspaceMemberParams{
+					WorkspaceID: workspaceID.UUID,
+					UserUuid:    userID,
+				})
+				if err != nil {
+					log.Printf("Error checking membership of user %s in workspace %s: %v", userID, workspaceID.UUID, err)
+					httputils.RespondWithServerError(w, err, "Failed to verify workspace membership")
+					return
+				}
+				if !isMember {
+					httputils.RespondWithError(w, http.StatusForbidden, "Not a member of this workspace")
+					return
+				}
+			}
+
+			ctx := context.WithValue(r.Context(), WorkspaceIDKey, workspaceID)
+			next(w, r.WithContext(ctx))
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package schedule
+
+import "time"
+
+// DueWindow decides which drops count as due at a given time.
+//
+// GraceWindow lets drops scheduled slightly in the future be sent now, so a worker running
+// every few minutes doesn't drift up to a full period behind each drop's intended time.
+// MinResendInterval keeps a drop that was just sent from being sent again, which could
+// otherwise happen when an early send and the next run both fall inside the grace window.
+type DueWindow struct {
+	GraceWindow       time.Duration
+	MinResendInterval time.Duration
+}
+
+// Cutoffs returns the bounds for the due-drop queries at now: drops scheduled at or before
+// dueBefore are due, unless they were last sent after lastSentBefore.
+// The resend guard is never shorter than the grace window.
+func (w DueWindow) Cutoffs(now time.Time) (dueBefore, lastSentBefore time.Time) {
+	now = now.UTC()
+	minResend := max(w.MinResendInterval, w.GraceWindow)
+	return now.Add(w.GraceWindow), now.Add(-minResend)
+}
+
+// ScheduleBase returns the time to compute a drop's next send from after sending it at sentAt.
+// A drop sent early inside the grace window is rescheduled from its intended time, so the
+// early send doesn't pull every later repetition forward or repeat the same cron slot.
+func ScheduleBase(sentAt time.Time, intended time.Time, hasIntended bool) time.Time {
+	if hasIntended && intended.After(sentAt) {
+		return intended.UTC()
+	}
+	return sentAt
+}
@@ -0,0 +1,92 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDueWindowCutoffs(t *testing.T) {
+	now := time.Date(2026, 4, 1, 12, 0, 0, 0, time.UTC)
+	w := DueWindow{GraceWindow: 5 * time.Minute, MinResendInterval: time.Hour}
+	dueBefore, lastSentBefore := w.Cutoffs(now)
+
+	// The queries treat a drop as due when next_send_date <= due_before
+	dueTests := []struct {
+		name      string
+		scheduled time.Time
+		want      bool
+	}{
+		{"exactly at the grace edge", now.Add(5 * time.Minute), true},
+		{"1s inside the grace window", now.Add(5*time.Minute - time.Second), true},
+		{"1s outside the grace window", now.Add(5*time.Minute + time.Second), false},
+		{"overdue", now.Add(-time.Hour), true},
+	}
+	for _, tt := range dueTests {
+		t.Run("due "+tt.name, func(t *testing.T) {
+			if got := !tt.scheduled.After(dueBefore); got != tt.want {
+				t.Errorf("drop scheduled at %v due = %v, want %v (due_before %v)", tt.scheduled, got, tt.want, dueBefore)
+			}
+		})
+	}
+
+	// ...and lets it be sent again when last_sent_date <= last_sent_before
+	resendTests := []struct {
+		name     string
+		lastSent time.Time
+		want     bool
+	}{
+		{"exactly at the resend edge", now.Add(-time.Hour), true},
+		{"1s inside the resend guard", now.Add(-time.Hour + time.Second), false},
+		{"1s outside the resend guard", now.Add(-time.Hour - time.Second), true},
+	}
+	for _, tt := range resendTests {
+		t.Run("resend "+tt.name, func(t *testing.T) {
+			if got := !tt.lastSent.After(lastSentBefore); got != tt.want {
+				t.Errorf("drop last sent at %v may be resent = %v, want %v (last_sent_before %v)", tt.lastSent, got, tt.want, lastSentBefore)
+			}
+		})
+	}
+}
+
+func TestDueWindowCutoffsResendGuardCoversGrace(t *testing.T) {
+	now := time.Date(2026, 4, 1, 12, 0, 0, 0, time.UTC)
+	w := DueWindow{GraceWindow: 10 * time.Minute, MinResendInterval: time.Minute}
+	_, lastSentBefore := w.Cutoffs(now)
+	if want := now.Add(-10 * time.Minute); !lastSentBefore.Equal(want) {
+		t.Errorf("last_sent_before = %v, want %v (never shorter than the grace window)", lastSentBefore, want)
+	}
+}
+
+func TestDueWindowCutoffsZero(t *testing.T) {
+	now := time.Date(2026, 4, 1, 12, 0, 0, 0, time.FixedZone("UTC+3", 3*3600))
+	dueBefore, lastSentBefore := DueWindow{}.Cutoffs(now)
+	if !dueBefore.Equal(now) || !lastSentBefore.Equal(now) {
+		t.Errorf("Cutoffs() = %v, %v, want both at now", dueBefore, lastSentBefore)
+	}
+	if dueBefore.Location() != time.UTC {
+		t.Errorf("Cutoffs() location = %v, want UTC", dueBefore.Location())
+	}
+}
+
+func TestScheduleBase(t *testing.T) {
+	sentAt := time.Date(2026, 4, 1, 8, 57, 0, 0, time.UTC)
+	tests := []struct {
+		name        string
+		intended    time.Time
+		hasIntended bool
+		want        time.Time
+	}{
+		{"sent 1s early", sentAt.Add(time.Second), true, sentAt.Add(time.Second)},
+		{"sent early inside the grace window", sentAt.Add(3 * time.Minute), true, sentAt.Add(3 * time.Minute)},
+		{"sent exactly on time", sentAt, true, sentAt},
+		{"sent 1s late", sentAt.Add(-time.Second), true, sentAt},
+		{"no intended time", time.Time{}, false, sentAt},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ScheduleBase(sentAt, tt.intended, tt.hasIntended); !got.Equal(tt.want) {
+				t.Errorf("ScheduleBase() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package schedule
+
+import (
+	"fmt"
+	"time"
+)
+
+// DefaultIntervals is the spaced-repetition sequence, in days, between consecutive sends of a drop.
+// After the first send the drop comes back in 1 day, after the second in 3 days, and so on.
+// Once every interval has been used the drop's schedule is finished.
+var DefaultIntervals = []int{1, 3, 7, 14, 30, 60}
+
+// Limits on a custom repetition sequence (see ValidateIntervals).
+const (
+	MaxIntervals    = 20
+	MaxIntervalDays = 3650
+)
+
+// Intervals is a spaced-repetition sequence, in days. A nil or empty Intervals means DefaultIntervals.
+type Intervals []int
+
+// NewIntervals converts a stored sequence, such as a user's repetition_intervals preference.
+func NewIntervals(days []int32) Intervals {
+	intervals := make(Intervals, len(days))
+	for i, d := range days {
+		intervals[i] = int(d)
+	}
+	return intervals
+}
+
+// days returns the sequence to follow, falling back to DefaultIntervals.
+func (iv Intervals) days() []int {
+	if len(iv) == 0 {
+		return DefaultIntervals
+	}
+	return iv
+}
+
+// ValidateIntervals checks a custom repetition sequence: between 1 and MaxIntervals
+// strictly increasing day counts, each between 1 and MaxIntervalDays.
+func ValidateIntervals(days []int) error {
+	if len(days) == 0 || len(days) > MaxIntervals {
+		return fmt.Errorf("must have between 1 and %d intervals", MaxIntervals)
+	}
+	for i, d := range days {
+		if d < 1 || d > MaxIntervalDays {
+			return fmt.Errorf("interval %d must be between 1 and %d days, got %d", i+1, MaxIntervalDays, d)
+		}
+		if i > 0 && d <= days[i-1] {
+			return fmt.Errorf("intervals must be strictly increasing, got %d after %d", d, days[i-1])
+		}
+	}
+	return nil
+}
+
+// NextSendDate returns when a drop that has been sent sendCount times should be sent next,
+// counting from the given time and following DefaultIntervals (see Intervals.NextSendDate).
+func NextSendDate(sendCount int32, from time.Time, permanent bool) (time.Time, bool) {
+	return Intervals(nil).NextSendDate(sendCount, from, permanent)
+}
+
+// Interval returns the number of days a drop waits after its sendCount-th send, following
+// DefaultIntervals (see Intervals.Interval).
+func Interval(sendCount int32, permanent bool) (int, bool) {
+	return Intervals(nil).Interval(sendCount, permanent)
+}
+
+// NextSendDate returns when a drop that has been sent sendCount times should be sent next,
+// counting from the given time. A drop that has never been sent is due immediately.
+// The boolean result is false when the repetition sequence is exhausted, unless the drop is
+// permanent: permanent drops keep coming back at the longest interval indefinitely.
+func (iv Intervals) NextSendDate(sendCount int32, from time.Time, permanent bool) (time.Time, bool) {
+	if sendCount <= 0 {
+		return from, true
+	}
+	days, ok := iv.Interval(sendCount, permanent)
+	if !ok {
+		return time.Time{}, false
+	}
+	return from.AddDate(0, 0, days), true
+}
+
+// Interval returns the number of days a drop waits after its sendCount-th send. A drop that
+// has never been sent doesn't wait at all. As with NextSendDate, the boolean result is false
+// once the sequence is exhausted for a drop that isn't permanent.
+func (iv Intervals) Interval(sendCount int32, permanent bool) (int, bool) {
+	days := iv.days()
+	if sendCount <= 0 {
+		return 0, true
+	}
+	if int(sendCount) > len(days) {
+		if permanent {
+			return days[len(days)-1], true
+		}
+		return 0, false
+	}
+	return days[sendCount-1], true
+}
+
+// Graduated reports whether a drop that has been sent sendCount times has reached the send
+// cap maxSendCount and should leave the due queue. Permanent drops never graduate, and a
+// maxSendCount of 0 means there is no cap.
+func Graduated(sendCount int32, permanent bool, maxSendCount int) bool {
+	return maxSendCount > 0 && !permanent && int(sendCount) >= maxSendCount
+}
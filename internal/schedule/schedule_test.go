@@ -0,0 +1,127 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNextSendDate(t *testing.T) {
+	from := time.Date(2026, 1, 10, 9, 0, 0, 0, time.UTC)
+	last := len(DefaultIntervals)
+	tests := []struct {
+		name      string
+		sendCount int32
+		permanent bool
+		want      time.Time
+		wantOK    bool
+	}{
+		{"never sent is due now", 0, false, from, true},
+		{"after the first send", 1, false, from.AddDate(0, 0, 1), true},
+		{"after the last send of the sequence", int32(last), false, from.AddDate(0, 0, 60), true},
+		{"past the end of the sequence", int32(last + 1), false, time.Time{}, false},
+		{"permanent drop at the end of the sequence", int32(last), true, from.AddDate(0, 0, 60), true},
+		{"permanent drop past the end of the sequence", int32(last + 1), true, from.AddDate(0, 0, 60), true},
+		{"permanent drop long past the end of the sequence", 100, true, from.AddDate(0, 0, 60), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := NextSendDate(tt.sendCount, from, tt.permanent)
+			if ok != tt.wantOK || !got.Equal(tt.want) {
+				t.Errorf("NextSendDate(%d, permanent=%v) = %v, %v, want %v, %v", tt.sendCount, tt.permanent, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestGraduated(t *testing.T) {
+	tests := []struct {
+		name         string
+		sendCount    int32
+		permanent    bool
+		maxSendCount int
+		want         bool
+	}{
+		{"one below the cap", 4, false, 5, false},
+		{"at the cap", 5, false, 5, true},
+		{"past the cap", 6, false, 5, true},
+		{"permanent at the cap", 5, true, 5, false},
+		{"no cap", 1000, false, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Graduated(tt.sendCount, tt.permanent, tt.maxSendCount); got != tt.want {
+				t.Errorf("Graduated(%d, %v, %d) = %v, want %v", tt.sendCount, tt.permanent, tt.maxSendCount, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateIntervals(t *testing.T) {
+	tooMany := make([]int, MaxIntervals+1)
+	for i := range tooMany {
+		tooMany[i] = i + 1
+	}
+	tests := []struct {
+		name    string
+		days    []int
+		wantErr bool
+	}{
+		{"default sequence", DefaultIntervals, false},
+		{"single interval", []int{5}, false},
+		{"longest allowed", tooMany[:MaxIntervals], false},
+		{"longest interval allowed", []int{1, MaxIntervalDays}, false},
+		{"empty", []int{}, true},
+		{"nil", nil, true},
+		{"too many", tooMany, true},
+		{"zero", []int{0, 3}, true},
+		{"negative", []int{-1, 3}, true},
+		{"too long", []int{1, MaxIntervalDays + 1}, true},
+		{"repeated interval", []int{1, 3, 3}, true},
+		{"decreasing", []int{1, 7, 3}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateIntervals(tt.days); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateIntervals(%v) error = %v, want error %v", tt.days, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestIntervalsNextSendDateWalksCustomSequence(t *testing.T) {
+	intervals := NewIntervals([]int32{2, 5, 10})
+	if err := ValidateIntervals(intervals); err != nil {
+		t.Fatalf("ValidateIntervals() error = %v", err)
+	}
+
+	// Send the drop each time it comes due and follow it through the whole sequence
+	sentAt := time.Date(2026, 2, 1, 9, 0, 0, 0, time.UTC)
+	var got []time.Time
+	for sendCount := int32(1); ; sendCount++ {
+		next, ok := intervals.NextSendDate(sendCount, sentAt, false)
+		if !ok {
+			break
+		}
+		got = append(got, next)
+		sentAt = next
+	}
+
+	want := []time.Time{
+		time.Date(2026, 2, 3, 9, 0, 0, 0, time.UTC),
+		time.Date(2026, 2, 8, 9, 0, 0, 0, time.UTC),
+		time.Date(2026, 2, 18, 9, 0, 0, 0, time.UTC),
+	}
+	if len(got) != len(want) {
+		t.Fatalf("schedule = %v, want %v", got, want)
+	}
+	for i := range want {
+		if !got[i].Equal(want[i]) {
+			t.Errorf("send %d comes back at %v, want %v", i+1, got[i], want[i])
+		}
+	}
+
+	// A permanent drop stays on the last interval after the sequence ends
+	if next, ok := intervals.NextSendDate(4, sentAt, true); !ok || !next.Equal(sentAt.AddDate(0, 0, 10)) {
+		t.Errorf("permanent drop past the sequence = %v, %v, want %v", next, ok, sentAt.AddDate(0, 0, 10))
+	}
+}
@@ -0,0 +1,59 @@
+package schedule
+
+import "math"
+
+// Ease factor bounds of the SM-2 algorithm. A drop starts at DefaultEaseFactor, and
+// forgetting it again and again never brings it below MinEaseFactor.
+const (
+	DefaultEaseFactor = 2.5
+	MinEaseFactor     = 1.3
+)
+
+// SM-2 response qualities (0 to 5) the two review outcomes count as. Remembering is a
+// perfect response; forgetting is an incorrect one where the answer seemed easy once seen,
+// since the user has the drop in front of them when they report it.
+const (
+	rememberedQuality = 5
+	forgotQuality     = 2
+)
+
+// Review is a drop's SM-2 state: its ease factor and the interval, in days, it waited
+// after its last review. IntervalDays is 0 for a drop that has never been reviewed.
+type Review struct {
+	EaseFactor   float64
+	IntervalDays int
+}
+
+// Next returns the state after reviewing the drop, following SM-2. A remembered drop's
+// interval grows: 1 day after a first review, 6 after a second, then the previous interval
+// times the ease factor. A forgotten drop starts over at 1 day. The ease factor then rises
+// by 0.1 when remembered and drops by 0.32 when forgotten, never below MinEaseFactor.
+// Intervals are capped at MaxIntervalDays.
+func (r Review) Next(remembered bool) Review {
+	ease := r.EaseFactor
+	if ease < MinEaseFactor {
+		ease = MinEaseFactor
+	}
+
+	quality := forgotQuality
+	interval := 1
+	if remembered {
+		quality = rememberedQuality
+		switch {
+		case r.IntervalDays <= 0:
+			interval = 1
+		case r.IntervalDays == 1:
+			interval = 6
+		default:
+			interval = int(math.Round(float64(r.IntervalDays) * ease))
+		}
+	}
+
+	miss := float64(5 - quality)
+	ease += 0.1 - miss*(0.08+miss*0.02)
+	ease = math.Round(ease*100) / 100 // Keeps repeated additions from drifting off two decimals
+	return Review{
+		EaseFactor:   math.Max(ease, MinEaseFactor),
+		IntervalDays: min(interval, MaxIntervalDays),
+	}
+}
@@ -0,0 +1,43 @@
+package schedule
+
+import "testing"
+
+func TestReviewNext(t *testing.T) {
+	tests := []struct {
+		name       string
+		state      Review
+		remembered bool
+		want       Review
+	}{
+		{"first review remembered", Review{EaseFactor: 2.5, IntervalDays: 0}, true, Review{EaseFactor: 2.6, IntervalDays: 1}},
+		{"second review remembered", Review{EaseFactor: 2.6, IntervalDays: 1}, true, Review{EaseFactor: 2.7, IntervalDays: 6}},
+		{"interval grows by the ease factor", Review{EaseFactor: 2.7, IntervalDays: 6}, true, Review{EaseFactor: 2.8, IntervalDays: 16}},
+		{"interval is rounded", Review{EaseFactor: 2.8, IntervalDays: 16}, true, Review{EaseFactor: 2.9, IntervalDays: 45}},
+		{"forgot starts over and costs 0.32 ease", Review{EaseFactor: 2.5, IntervalDays: 16}, false, Review{EaseFactor: 2.18, IntervalDays: 1}},
+		{"forgot on a first review", Review{EaseFactor: 2.5, IntervalDays: 0}, false, Review{EaseFactor: 2.18, IntervalDays: 1}},
+		{"ease is floored when forgetting", Review{EaseFactor: 1.4, IntervalDays: 6}, false, Review{EaseFactor: MinEaseFactor, IntervalDays: 1}},
+		{"ease stays at the floor", Review{EaseFactor: MinEaseFactor, IntervalDays: 1}, false, Review{EaseFactor: MinEaseFactor, IntervalDays: 1}},
+		{"ease below the floor is raised first", Review{EaseFactor: 1.0, IntervalDays: 10}, true, Review{EaseFactor: 1.4, IntervalDays: 13}},
+		{"interval is capped", Review{EaseFactor: 2.5, IntervalDays: 3000}, true, Review{EaseFactor: 2.6, IntervalDays: MaxIntervalDays}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.state.Next(tt.remembered); got != tt.want {
+				t.Errorf("%+v.Next(%t) = %+v, want %+v", tt.state, tt.remembered, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReviewNextRepeatedForgetsFloorEase(t *testing.T) {
+	state := Review{EaseFactor: DefaultEaseFactor}
+	for range 10 {
+		state = state.Next(false)
+		if state.EaseFactor < MinEaseFactor {
+			t.Fatalf("ease factor fell to %v, below %v", state.EaseFactor, MinEaseFactor)
+		}
+	}
+	if state != (Review{EaseFactor: MinEaseFactor, IntervalDays: 1}) {
+		t.Errorf("after repeated forgets got %+v, want ease %v and a 1 day interval", state, MinEaseFactor)
+	}
+}
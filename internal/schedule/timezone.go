@@ -0,0 +1,29 @@
+package schedule
+
+import (
+	"fmt"
+	"time"
+)
+
+// LoadTimezone loads an IANA time zone such as "Europe/Istanbul".
+// Unlike time.LoadLocation it rejects the empty name and "Local", which would silently
+// mean UTC or the server's own zone.
+func LoadTimezone(name string) (*time.Location, error) {
+	if name == "" || name == "Local" {
+		return nil, fmt.Errorf("unknown time zone %q", name)
+	}
+	return time.LoadLocation(name)
+}
+
+// StartOfNextDay returns midnight after t in loc.
+func StartOfNextDay(t time.Time, loc *time.Location) time.Time {
+	local := t.In(loc)
+	return time.Date(local.Year(), local.Month(), local.Day()+1, 0, 0, 0, 0, loc)
+}
+
+// LocalDate returns the calendar date of t in loc, as midnight UTC of that date.
+// Dates in this form compare and subtract without time zone surprises.
+func LocalDate(t time.Time, loc *time.Location) time.Time {
+	local := t.In(loc)
+	return time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, time.UTC)
+}
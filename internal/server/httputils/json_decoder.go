@@ -0,0 +1,72 @@
+package httputils
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// DecodeJSONBody decodes the JSON request body into dst.
+// On failure it writes a 400 response describing the problem (empty body, malformed JSON,
+// or a field of the wrong type) and returns false, in which case the caller should return.
+func DecodeJSONBody(w http.ResponseWriter, r *http.Request, dst interface{}) bool {
+	err := json.NewDecoder(r.Body).Decode(dst)
+	if err == nil {
+		return true
+	}
+
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.Is(err, io.EOF):
+		RespondWithError(w, http.StatusBadRequest, "Request body is required")
+	case errors.Is(err, io.ErrUnexpectedEOF), errors.As(err, &syntaxErr):
+		RespondWithError(w, http.StatusBadRequest, "Request body contains malformed JSON")
+	case errors.As(err, &typeErr) && typeErr.Field != "":
+		RespondWithError(w, http.StatusBadRequest, InvalidFieldMessage(typeErr.Field, err))
+	default:
+		RespondWithError(w, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+	}
+	return false
+}
+
+// InvalidFieldMessage describes why the JSON value of field could not be decoded. A number
+// that doesn't fit an integer field is reported as out of range, with the allowed bounds,
+// and a fraction as not a whole number, rather than as a generic invalid value.
+func InvalidFieldMessage(field string, err error) string {
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Type != nil {
+		if number, ok := strings.CutPrefix(typeErr.Value, "number "); ok {
+			if lo, hi, ok := integerBounds(typeErr.Type); ok {
+				// Integers that fit were decoded, so a number in range failed for being fractional
+				// or written with an exponent or decimal point
+				f, err := strconv.ParseFloat(number, 64)
+				loF, _ := strconv.ParseFloat(lo, 64)
+				hiF, _ := strconv.ParseFloat(hi, 64)
+				if strings.ContainsAny(number, ".eE") && err == nil && f >= loF && f <= hiF {
+					return fmt.Sprintf("Value for field '%s' must be a whole number", field)
+				}
+				return fmt.Sprintf("Value for field '%s' is out of range (%s to %s)", field, lo, hi)
+			}
+		}
+	}
+	return fmt.Sprintf("Invalid value for field '%s'", field)
+}
+
+// integerBounds returns the smallest and largest values of integer type t, formatted exactly
+// (as floats, the 64-bit bounds would be rounded).
+func integerBounds(t reflect.Type) (lo, hi string, ok bool) {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(math.MinInt64>>(64-t.Bits()), 10), strconv.FormatInt(math.MaxInt64>>(64-t.Bits()), 10), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "0", strconv.FormatUint(math.MaxUint64>>(64-t.Bits()), 10), true
+	}
+	return "", "", false
+}
@@ -0,0 +1,64 @@
+package httputils
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDecodeJSONBodyNumberBounds(t *testing.T) {
+	type payload struct {
+		Small int32  `json:"small"`
+		Big   int64  `json:"big"`
+		Count uint32 `json:"count"`
+	}
+	tests := []struct {
+		name    string
+		body    string
+		wantErr string // Empty when the body decodes
+	}{
+		{"max int32", `{"small": 2147483647}`, ""},
+		{"min int32", `{"small": -2147483648}`, ""},
+		{"max int32 + 1", `{"small": 2147483648}`, "Value for field 'small' is out of range (-2147483648 to 2147483647)"},
+		{"min int32 - 1", `{"small": -2147483649}`, "Value for field 'small' is out of range (-2147483648 to 2147483647)"},
+		{"max int64", `{"big": 9223372036854775807}`, ""},
+		{"max int64 + 1", `{"big": 9223372036854775808}`, "Value for field 'big' is out of range (-9223372036854775808 to 9223372036854775807)"},
+		{"negative uint32", `{"count": -1}`, "Value for field 'count' is out of range (0 to 4294967295)"},
+		{"max uint32 + 1", `{"count": 4294967296}`, "Value for field 'count' is out of range (0 to 4294967295)"},
+		{"fraction", `{"small": 1.5}`, "Value for field 'small' must be a whole number"},
+		{"whole number with a decimal point", `{"small": 3.0}`, "Value for field 'small' must be a whole number"},
+		{"exponent in range", `{"small": 1e3}`, "Value for field 'small' must be a whole number"},
+		{"exponent out of range", `{"small": 1e10}`, "Value for field 'small' is out of range (-2147483648 to 2147483647)"},
+		{"string for a number", `{"small": "5"}`, "Invalid value for field 'small'"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var dst payload
+			rec := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+			ok := DecodeJSONBody(rec, r, &dst)
+
+			if tt.wantErr == "" {
+				if !ok {
+					t.Fatalf("DecodeJSONBody() failed: %s", rec.Body.String())
+				}
+				return
+			}
+			if ok {
+				t.Fatalf("DecodeJSONBody() = true, want a 400 with %q", tt.wantErr)
+			}
+			if rec.Code != http.StatusBadRequest {
+				t.Errorf("status = %d, want %d", rec.Code, http.StatusBadRequest)
+			}
+			var resp map[string]string
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("decoding response: %v", err)
+			}
+			if resp["error"] != tt.wantErr {
+				t.Errorf("error = %q, want %q", resp["error"], tt.wantErr)
+			}
+		})
+	}
+}
@@ -13,7 +13,13 @@ func RespondWithError(w http.ResponseWriter, code int, message string) {
 
 // RespondWithJSON sends a JSON response with a specific status code and payload.
 func RespondWithJSON(w http.ResponseWriter, code int, payload interface{}) {
-	response, err := json.Marshal(payload)
+	var response []byte
+	var err error
+	if wantsPrettyJSON(w) {
+		response, err = json.MarshalIndent(payload, "", "  ")
+	} else {
+		response, err = json.Marshal(payload)
+	}
 	if err != nil {
 		log.Printf("Error marshalling JSON: %v", err)
 		http.Error(w, `{"error":"Internal Server Error"}`, http.StatusInternalServerError)
@@ -27,3 +33,34 @@ func RespondWithJSON(w http.ResponseWriter, code int, payload interface{}) {
 		log.Printf("Error writing JSON response: %v", err)
 	}
 }
+
+// prettyJSONWriter marks a response as wanting indented JSON.
+type prettyJSONWriter struct {
+	http.ResponseWriter
+}
+
+// Unwrap returns the underlying ResponseWriter.
+func (p prettyJSONWriter) Unwrap() http.ResponseWriter {
+	return p.ResponseWriter
+}
+
+// WithPrettyJSON returns a ResponseWriter for which RespondWithJSON writes indented JSON.
+// Wrappers added on top of it are looked through as long as they implement Unwrap.
+func WithPrettyJSON(w http.ResponseWriter) http.ResponseWriter {
+	return prettyJSONWriter{ResponseWriter: w}
+}
+
+// wantsPrettyJSON reports whether w, or any writer it wraps, was marked with WithPrettyJSON.
+func wantsPrettyJSON(w http.ResponseWriter) bool {
+	for w != nil {
+		if _, ok := w.(prettyJSONWriter); ok {
+			return true
+		}
+		u, ok := w.(interface{ Unwrap() http.ResponseWriter })
+		if !ok {
+			return false
+		}
+		w = u.Unwrap()
+	}
+	return false
+}
@@ -0,0 +1,165 @@
+package httputils
+
+import (
+	"encoding/csv"
+	"fmt"
+	"log"
+	"mime"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Response formats list endpoints can offer to NegotiateFormat.
+const (
+	FormatJSON = "application/json"
+	FormatCSV  = "text/csv"
+)
+
+// apiVersionMediaTypePattern matches the vendor media types selecting a version of the
+// response bodies, e.g. application/vnd.dropwise.v2+json.
+var apiVersionMediaTypePattern = regexp.MustCompile(`^application/vnd\.dropwise\.v([1-9][0-9]*)\+json$`)
+
+// APIVersionMediaType returns the vendor media type for version v of the response bodies.
+func APIVersionMediaType(v int) string {
+	return fmt.Sprintf("application/vnd.dropwise.v%d+json", v)
+}
+
+// NegotiateFormat picks the offered media type the client's Accept header prefers,
+// honouring q-values and wildcards. Ties go to the earlier offer. The first offer is the
+// default: it is used when there is no Accept header or the client accepts none of the
+// offers, so existing clients keep working. Vary: Accept is added to the response since
+// its format now depends on the header.
+func NegotiateFormat(w http.ResponseWriter, r *http.Request, defaultFormat string, offers ...string) string {
+	AddVary(w.Header(), "Accept")
+	accept := strings.TrimSpace(r.Header.Get("Accept"))
+	if accept == "" {
+		return defaultFormat
+	}
+
+	best, bestQ := defaultFormat, acceptQuality(accept, defaultFormat)
+	for _, offer := range offers {
+		if q := acceptQuality(accept, offer); q > bestQ {
+			best, bestQ = offer, q
+		}
+	}
+	return best
+}
+
+// NegotiateAPIVersion picks the version of the response bodies the client's Accept header
+// asks for through a vendor media type (see APIVersionMediaType), honouring q-values; ties go
+// to the newer version. Without a vendor media type in the header it returns defaultVersion,
+// so existing clients keep working. explicit reports whether the version came from the
+// header. ok is false when the header only accepts versions not in supported, which the
+// caller should answer with 406 Not Acceptable.
+func NegotiateAPIVersion(r *http.Request, defaultVersion int, supported ...int) (version int, explicit, ok bool) {
+	accept := strings.TrimSpace(r.Header.Get("Accept"))
+	if accept == "" {
+		return defaultVersion, false, true
+	}
+
+	bestQ, asked := 0.0, false
+	for _, part := range strings.Split(accept, ",") {
+		rangeType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		match := apiVersionMediaTypePattern.FindStringSubmatch(rangeType)
+		if match == nil {
+			continue
+		}
+		asked = true
+		v, err := strconv.Atoi(match[1])
+		if err != nil || !slices.Contains(supported, v) {
+			continue
+		}
+		q := 1.0
+		if raw, ok := params["q"]; ok {
+			if parsed, err := strconv.ParseFloat(raw, 64); err == nil && parsed >= 0 && parsed <= 1 {
+				q = parsed
+			}
+		}
+		if q > bestQ || (q == bestQ && q > 0 && v > version) {
+			version, bestQ = v, q
+		}
+	}
+
+	switch {
+	case bestQ > 0:
+		return version, true, true
+	case !asked || acceptQuality(accept, FormatJSON) > 0:
+		// Plain JSON (or anything) is acceptable too, which is the default version
+		return defaultVersion, false, true
+	default:
+		return 0, false, false
+	}
+}
+
+// AddVary adds field to the response's Vary header unless it is already listed.
+func AddVary(h http.Header, field string) {
+	for _, value := range h.Values("Vary") {
+		for _, listed := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(listed), field) {
+				return
+			}
+		}
+	}
+	h.Add("Vary", field)
+}
+
+// acceptQuality returns the q-value the Accept header gives mediaType, using the most
+// specific matching range: an exact match over type/* over */*.
+func acceptQuality(accept, mediaType string) float64 {
+	q, specificity := 0.0, -1
+	for _, part := range strings.Split(accept, ",") {
+		rangeType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+
+		s := -1
+		switch {
+		case rangeType == mediaType:
+			s = 2
+		case strings.HasSuffix(rangeType, "/*") && strings.HasPrefix(mediaType, strings.TrimSuffix(rangeType, "*")):
+			s = 1
+		case rangeType == "*/*":
+			s = 0
+		}
+		if s <= specificity {
+			continue
+		}
+
+		specificity, q = s, 1
+		if v, ok := params["q"]; ok {
+			if parsed, err := strconv.ParseFloat(v, 64); err == nil && parsed >= 0 && parsed <= 1 {
+				q = parsed
+			}
+		}
+	}
+	return q
+}
+
+// RespondWithCSV writes a header row followed by records as a CSV document.
+func RespondWithCSV(w http.ResponseWriter, code int, header []string, records [][]string) {
+	w.Header().Set("Content-Type", FormatCSV+"; charset=utf-8")
+	w.WriteHeader(code)
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write(header); err != nil {
+		log.Printf("Error writing CSV response: %v", err)
+		return
+	}
+	for _, record := range records {
+		if err := cw.Write(record); err != nil {
+			log.Printf("Error writing CSV response: %v", err)
+			return
+		}
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		log.Printf("Error writing CSV response: %v", err)
+	}
+}
@@ -0,0 +1,41 @@
+package httputils
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+)
+
+// Pagination describes the page of results returned by a list endpoint.
+type Pagination struct {
+	Limit  int   `json:"limit"`
+	Offset int   `json:"offset"`
+	Total  int64 `json:"total"`
+}
+
+// PaginatedResponse is the envelope used by paginated list endpoints.
+type PaginatedResponse struct {
+	Data       interface{} `json:"data"`
+	Pagination Pagination  `json:"pagination"`
+}
+
+// ParsePagination reads the ?limit= and ?offset= query parameters.
+// limit defaults to defaultLimit and may not exceed maxLimit; offset defaults to 0.
+func ParsePagination(r *http.Request, defaultLimit, maxLimit int) (limit, offset int, err error) {
+	limit = defaultLimit
+	if v := r.URL.Query().Get("limit"); v != "" {
+		limit, err = strconv.Atoi(v)
+		if err != nil || limit < 1 || limit > maxLimit {
+			return 0, 0, fmt.Errorf("limit must be an integer between 1 and %d", maxLimit)
+		}
+	}
+
+	if v := r.URL.Query().Get("offset"); v != "" {
+		offset, err = strconv.Atoi(v)
+		if err != nil || offset < 0 {
+			return 0, 0, fmt.Errorf("offset must be a non-negative integer")
+		}
+	}
+
+	return limit, offset, nil
+}
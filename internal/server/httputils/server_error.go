@@ -0,0 +1,26 @@
+package httputils
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+// StatusClientClosedRequest is the non-standard status (from nginx) for a request the
+// client gave up on before it was answered. It only ever reaches logs.
+const StatusClientClosedRequest = 499
+
+// RespondWithServerError writes the response for an unexpected error while serving a
+// request, typically from the database. If err comes from the request context, the raw
+// context error is not passed on: a passed deadline (see middleware.TimeoutMiddleware)
+// is a 503 and a client that went away a 499. Anything else is a 500 with message.
+func RespondWithServerError(w http.ResponseWriter, err error, message string) {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		RespondWithError(w, http.StatusServiceUnavailable, "Request timed out, please try again")
+	case errors.Is(err, context.Canceled):
+		RespondWithError(w, StatusClientClosedRequest, "Request was cancelled")
+	default:
+		RespondWithError(w, http.StatusInternalServerError, message)
+	}
+}
@@ -0,0 +1,58 @@
+package httputils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRespondWithServerError(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantBody   string
+	}{
+		{"cancelled", context.Canceled, StatusClientClosedRequest, "Request was cancelled"},
+		{"wrapped cancel", fmt.Errorf("listing drops: %w", context.Canceled), StatusClientClosedRequest, "Request was cancelled"},
+		{"deadline", context.DeadlineExceeded, http.StatusServiceUnavailable, "Request timed out, please try again"},
+		{"other error", errors.New("connection reset"), http.StatusInternalServerError, "Failed to list drops"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			RespondWithServerError(rec, tt.err, "Failed to list drops")
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if !strings.Contains(rec.Body.String(), tt.wantBody) {
+				t.Errorf("body = %s, want it to contain %q", rec.Body.String(), tt.wantBody)
+			}
+		})
+	}
+}
+
+// TestRespondWithServerErrorCancelledRequest serves a request whose client already went away
+// to a handler that, like a database call, fails with the context's error.
+func TestRespondWithServerErrorCancelledRequest(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+		RespondWithServerError(w, r.Context().Err(), "Failed to list drops")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodGet, "/api/v1/drops", nil).WithContext(ctx))
+
+	if rec.Code == http.StatusInternalServerError {
+		t.Fatal("a cancelled request was answered with 500")
+	}
+	if rec.Code != StatusClientClosedRequest {
+		t.Errorf("status = %d, want %d", rec.Code, StatusClientClosedRequest)
+	}
+}
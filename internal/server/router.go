@@ -2,6 +2,7 @@ package server
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/nouvadev/dropwise/internal/config"
 	"github.com/nouvadev/dropwise/internal/handlers"
@@ -17,10 +18,34 @@ func NewRouter(apiCfg *config.APIConfig) *http.ServeMux {
 	dropsHandler := handlers.NewDropsHandler(apiCfg)
 	tagsHandler := handlers.NewTagsHandler(apiCfg)
 	authHandler := handlers.NewAuthHandler(apiCfg) // New Auth Handler
+	workspacesHandler := handlers.NewWorkspacesHandler(apiCfg)
+	collectionsHandler := handlers.NewCollectionsHandler(apiCfg)
+	preferencesHandler := handlers.NewPreferencesHandler(apiCfg)
+	adminHandler := handlers.NewAdminHandler(apiCfg)
+	meHandler := handlers.NewMeHandler(apiCfg)
 
 	// Initialize middleware
-	authMiddleware := middleware.AuthMiddleware(apiCfg.JWTSecret)
-	loggingMiddleware := middleware.LoggingMiddleware
+	authMiddleware := middleware.AuthMiddleware(apiCfg.JWTKeys, apiCfg.JWTCache)
+	workspaceMiddleware := middleware.WorkspaceMiddleware(apiCfg.DB)
+	jsonMiddleware := middleware.RequireJSONContentType(apiCfg.EnforceJSONContentType)
+	loggingMiddleware := middleware.LoggingMiddleware(apiCfg.LogSampleRate, apiCfg.LogSlowThreshold)
+	adminMiddleware := middleware.AdminMiddleware(apiCfg.AdminUserIDs)
+	timezoneMiddleware := middleware.TimezoneMiddleware(apiCfg.DB, apiCfg.UserLocation)
+
+	// Token introspection gets its own per-IP limit, shared through Redis when it is configured
+	var introspectStore middleware.RateLimitStore
+	if limit := apiCfg.IntrospectRateLimitPerMinute; limit > 0 {
+		introspectStore = middleware.NewMemoryRateLimitStore(limit, time.Minute)
+		if apiCfg.Redis != nil {
+			introspectStore = middleware.NewRedisRateLimitStore(apiCfg.Redis, limit, time.Minute)
+		}
+	}
+	introspectRateLimit := middleware.RouteRateLimitMiddleware("introspect", introspectStore, middleware.NewClientIPResolver(apiCfg.TrustedProxies))
+
+	// Each route gets one of these timeouts, right after logging so timeouts are logged
+	readTimeout := middleware.TimeoutMiddleware(apiCfg.ReadRequestTimeout) // Simple reads
+	defaultTimeout := middleware.TimeoutMiddleware(apiCfg.RequestTimeout)  // Writes and everything else
+	longTimeout := middleware.TimeoutMiddleware(apiCfg.LongRequestTimeout) // Bulk, import and export
 
 	// --- Route Definitions ---
 
@@ -31,34 +56,267 @@ func NewRouter(apiCfg *config.APIConfig) *http.ServeMux {
 
 	// --- Authentication Endpoints ---
 	// These endpoints don't need authentication but should be logged
-	mux.HandleFunc("POST /api/v1/auth/signup", middleware.ApplyMiddleware(authHandler.SignupHandler, loggingMiddleware))
-	mux.HandleFunc("POST /api/v1/auth/login", middleware.ApplyMiddleware(authHandler.LoginHandler, loggingMiddleware))
+	mux.HandleFunc("POST /api/v1/auth/signup", middleware.Chain(authHandler.SignupHandler, loggingMiddleware, defaultTimeout, jsonMiddleware))
+	mux.HandleFunc("POST /api/v1/auth/login", middleware.Chain(authHandler.LoginHandler, loggingMiddleware, defaultTimeout, jsonMiddleware))
+
+	// POST /api/v1/auth/introspect - Validate a token and return its claims (no auth, tighter rate limit)
+	mux.HandleFunc("POST /api/v1/auth/introspect", middleware.Chain(authHandler.IntrospectTokenHandler,
+		loggingMiddleware, readTimeout, introspectRateLimit, jsonMiddleware))
+
+	// --- Inbound Email ---
+	// POST /api/v1/inbound/email - Mailgun webhook creating a drop from a forwarded email (signed, no token)
+	mux.HandleFunc("POST /api/v1/inbound/email", middleware.Chain(dropsHandler.InboundEmailHandler, loggingMiddleware, defaultTimeout))
 
 	// --- Drop Endpoints ---
+	// Drop and tag endpoints are scoped to the active workspace (see WorkspaceMiddleware)
 	// POST /api/v1/drops - Create a new drop (protected)
 	mux.HandleFunc("POST /api/v1/drops", middleware.Chain(dropsHandler.CreateDropHandler,
-		loggingMiddleware, authMiddleware))
+		loggingMiddleware, defaultTimeout, authMiddleware, workspaceMiddleware, timezoneMiddleware, jsonMiddleware))
+
+	// POST /api/v1/drops/batch-status - Set the status of several drops at once (protected)
+	mux.HandleFunc("POST /api/v1/drops/batch-status", middleware.Chain(dropsHandler.BatchStatusHandler,
+		loggingMiddleware, longTimeout, authMiddleware, workspaceMiddleware, timezoneMiddleware, jsonMiddleware))
+
+	// POST /api/v1/drops/batch-get - Fetch several drops by ID (protected)
+	mux.HandleFunc("POST /api/v1/drops/batch-get", middleware.Chain(dropsHandler.BatchGetDropsHandler,
+		loggingMiddleware, longTimeout, authMiddleware, workspaceMiddleware, timezoneMiddleware, jsonMiddleware))
+
+	// POST /api/v1/drops/import/bookmarks - Create drops from a browser or Pocket bookmarks export (protected)
+	mux.HandleFunc("POST /api/v1/drops/import/bookmarks", middleware.Chain(dropsHandler.ImportBookmarksHandler,
+		loggingMiddleware, longTimeout, authMiddleware, workspaceMiddleware))
+
+	// GET /api/v1/drops/due-count - Number of drops currently due (protected)
+	mux.HandleFunc("GET /api/v1/drops/due-count", middleware.Chain(dropsHandler.DueCountHandler,
+		loggingMiddleware, readTimeout, authMiddleware, workspaceMiddleware))
+
+	// POST /api/v1/drops/clear-due - Archive every currently due drop, requires ?confirm=true (protected)
+	mux.HandleFunc("POST /api/v1/drops/clear-due", middleware.Chain(dropsHandler.ClearDueHandler,
+		loggingMiddleware, longTimeout, authMiddleware, workspaceMiddleware))
+
+	// GET /api/v1/drops/status-summary - Number of drops per status (protected)
+	mux.HandleFunc("GET /api/v1/drops/status-summary", middleware.Chain(dropsHandler.StatusSummaryHandler,
+		loggingMiddleware, readTimeout, authMiddleware, workspaceMiddleware))
+
+	// GET /api/v1/drops/domains - Number of drops saved from each site (protected)
+	mux.HandleFunc("GET /api/v1/drops/domains", middleware.Chain(dropsHandler.DomainsHandler,
+		loggingMiddleware, readTimeout, authMiddleware, workspaceMiddleware))
+
+	// GET /api/v1/drops/events - Stream changes to the user's drops as server-sent events (protected)
+	// No timeout: the stream stays open until the client disconnects
+	mux.HandleFunc("GET /api/v1/drops/events", middleware.Chain(dropsHandler.DropEventsHandler,
+		loggingMiddleware, authMiddleware, workspaceMiddleware))
+
+	// GET /api/v1/drops/grouped-by-tag - The user's drops grouped under each of their tags (protected)
+	mux.HandleFunc("GET /api/v1/drops/grouped-by-tag", middleware.Chain(dropsHandler.GroupedByTagHandler,
+		loggingMiddleware, readTimeout, authMiddleware, workspaceMiddleware, timezoneMiddleware))
+
+	// GET /api/v1/drops/export - Download the user's drops as a Markdown document (protected)
+	mux.HandleFunc("GET /api/v1/drops/export", middleware.Chain(dropsHandler.ExportDropsHandler,
+		loggingMiddleware, longTimeout, authMiddleware, workspaceMiddleware))
+
+	// GET /api/v1/drops/search - Find the user's drops by topic or tag, optionally typo-tolerant (protected)
+	mux.HandleFunc("GET /api/v1/drops/search", middleware.Chain(dropsHandler.SearchDropsHandler,
+		loggingMiddleware, readTimeout, authMiddleware, workspaceMiddleware, timezoneMiddleware))
+
+	// GET /api/v1/drops/by-url - Find the user's drop saved with a URL (protected)
+	mux.HandleFunc("GET /api/v1/drops/by-url", middleware.Chain(dropsHandler.GetDropByURLHandler,
+		loggingMiddleware, readTimeout, authMiddleware, workspaceMiddleware, timezoneMiddleware))
 
 	// GET /api/v1/drops/{id} - Get a specific drop (protected)
 	mux.HandleFunc("GET /api/v1/drops/{id}", middleware.Chain(dropsHandler.GetDropHandler,
-		loggingMiddleware, authMiddleware))
+		loggingMiddleware, readTimeout, authMiddleware, workspaceMiddleware, timezoneMiddleware))
 
 	// GET /api/v1/drops - List all drops for a user (protected)
 	mux.HandleFunc("GET /api/v1/drops", middleware.Chain(dropsHandler.ListDropsHandler,
-		loggingMiddleware, authMiddleware))
+		loggingMiddleware, readTimeout, authMiddleware, workspaceMiddleware, timezoneMiddleware))
 
 	// PUT /api/v1/drops/{id} - Update a specific drop (protected)
 	mux.HandleFunc("PUT /api/v1/drops/{id}", middleware.Chain(dropsHandler.UpdateDropHandler,
-		loggingMiddleware, authMiddleware))
+		loggingMiddleware, defaultTimeout, authMiddleware, workspaceMiddleware, timezoneMiddleware, jsonMiddleware))
+
+	// PATCH /api/v1/drops/{id} - Partially update a drop with a JSON Merge Patch (protected)
+	mux.HandleFunc("PATCH /api/v1/drops/{id}", middleware.Chain(dropsHandler.PatchDropHandler,
+		loggingMiddleware, defaultTimeout, authMiddleware, workspaceMiddleware, timezoneMiddleware, jsonMiddleware))
 
 	// DELETE /api/v1/drops/{id} - Delete a specific drop (protected)
 	mux.HandleFunc("DELETE /api/v1/drops/{id}", middleware.Chain(dropsHandler.DeleteDropHandler,
-		loggingMiddleware, authMiddleware))
+		loggingMiddleware, defaultTimeout, authMiddleware, workspaceMiddleware))
+
+	// GET /api/v1/drops/{id}/related - Other drops sharing the most tags with a drop (protected)
+	mux.HandleFunc("GET /api/v1/drops/{id}/related", middleware.Chain(dropsHandler.RelatedDropsHandler,
+		loggingMiddleware, readTimeout, authMiddleware, workspaceMiddleware, timezoneMiddleware))
+
+	// GET /api/v1/drops/{id}/suggested-tags - The user's tags whose names appear in a drop (protected)
+	mux.HandleFunc("GET /api/v1/drops/{id}/suggested-tags", middleware.Chain(dropsHandler.SuggestedTagsHandler,
+		loggingMiddleware, readTimeout, authMiddleware, workspaceMiddleware))
+
+	// GET /api/v1/drops/{id}/next - When a drop will next be sent (protected)
+	mux.HandleFunc("GET /api/v1/drops/{id}/next", middleware.Chain(dropsHandler.NextSendHandler,
+		loggingMiddleware, readTimeout, authMiddleware, workspaceMiddleware))
+
+	// POST /api/v1/drops/{id}/reschedule - Reset or override a drop's repetition schedule (protected)
+	mux.HandleFunc("POST /api/v1/drops/{id}/reschedule", middleware.Chain(dropsHandler.RescheduleDropHandler,
+		loggingMiddleware, defaultTimeout, authMiddleware, workspaceMiddleware, timezoneMiddleware, jsonMiddleware))
+
+	// PUT /api/v1/drops/{id}/due-date - Pin a drop's next send to a date, or remove the pin (protected)
+	mux.HandleFunc("PUT /api/v1/drops/{id}/due-date", middleware.Chain(dropsHandler.DropDueDateHandler,
+		loggingMiddleware, defaultTimeout, authMiddleware, workspaceMiddleware, timezoneMiddleware, jsonMiddleware))
+
+	// POST /api/v1/drops/{id}/review - Record whether a drop was remembered or forgotten (protected)
+	mux.HandleFunc("POST /api/v1/drops/{id}/review", middleware.Chain(dropsHandler.ReviewDropHandler,
+		loggingMiddleware, defaultTimeout, authMiddleware, workspaceMiddleware, timezoneMiddleware, jsonMiddleware))
+
+	// POST /api/v1/drops/{id}/clone - Copy a drop into a new, unsent drop (protected)
+	mux.HandleFunc("POST /api/v1/drops/{id}/clone", middleware.Chain(dropsHandler.CloneDropHandler,
+		loggingMiddleware, defaultTimeout, authMiddleware, workspaceMiddleware, timezoneMiddleware))
+
+	// --- Review Session Endpoints ---
+	// POST /api/v1/review/start - Lock a batch of due drops for a review session and return them (protected)
+	mux.HandleFunc("POST /api/v1/review/start", middleware.Chain(dropsHandler.StartReviewHandler,
+		loggingMiddleware, defaultTimeout, authMiddleware, workspaceMiddleware, timezoneMiddleware))
+
+	// POST /api/v1/review/complete - Record remembered/forgot outcomes and release the drops (protected)
+	mux.HandleFunc("POST /api/v1/review/complete", middleware.Chain(dropsHandler.CompleteReviewHandler,
+		loggingMiddleware, defaultTimeout, authMiddleware, workspaceMiddleware, timezoneMiddleware, jsonMiddleware))
 
 	// --- Tag Endpoints ---
-	// GET /api/v1/tags - List all unique tags (protected)
+	// GET /api/v1/tags - List the tags used on the user's drops (protected)
 	mux.HandleFunc("GET /api/v1/tags", middleware.Chain(tagsHandler.ListTagsHandler,
-		loggingMiddleware, authMiddleware))
+		loggingMiddleware, readTimeout, authMiddleware, workspaceMiddleware))
+
+	// GET /api/v1/tags/search - Find the user's tags by a substring of their name (protected)
+	mux.HandleFunc("GET /api/v1/tags/search", middleware.Chain(tagsHandler.SearchTagsHandler,
+		loggingMiddleware, readTimeout, authMiddleware, workspaceMiddleware))
+
+	// GET /api/v1/tags/recent - The user's tags, most recently used first (protected)
+	mux.HandleFunc("GET /api/v1/tags/recent", middleware.Chain(tagsHandler.RecentTagsHandler,
+		loggingMiddleware, readTimeout, authMiddleware, workspaceMiddleware))
+
+	// POST /api/v1/tags/{id}/assign - Add a tag to several drops at once (protected)
+	mux.HandleFunc("POST /api/v1/tags/{id}/assign", middleware.Chain(tagsHandler.AssignTagHandler,
+		loggingMiddleware, defaultTimeout, authMiddleware, workspaceMiddleware, jsonMiddleware))
+
+	// POST /api/v1/tags/{id}/unassign - Remove a tag from several or all drops at once (protected)
+	mux.HandleFunc("POST /api/v1/tags/{id}/unassign", middleware.Chain(tagsHandler.UnassignTagHandler,
+		loggingMiddleware, longTimeout, authMiddleware, workspaceMiddleware, jsonMiddleware))
+
+	// GET /api/v1/tags/{id}/stats - Drop and send statistics for a tag (protected)
+	mux.HandleFunc("GET /api/v1/tags/{id}/stats", middleware.Chain(tagsHandler.TagStatsHandler,
+		loggingMiddleware, readTimeout, authMiddleware, workspaceMiddleware))
+
+	// --- Collection Endpoints ---
+	// POST /api/v1/collections - Create a collection (protected)
+	mux.HandleFunc("POST /api/v1/collections", middleware.Chain(collectionsHandler.CreateCollectionHandler,
+		loggingMiddleware, defaultTimeout, authMiddleware, workspaceMiddleware, jsonMiddleware))
+
+	// GET /api/v1/collections - List the user's collections (protected)
+	mux.HandleFunc("GET /api/v1/collections", middleware.Chain(collectionsHandler.ListCollectionsHandler,
+		loggingMiddleware, readTimeout, authMiddleware, workspaceMiddleware))
+
+	// DELETE /api/v1/collections/{id} - Delete a collection, keeping its drops (protected)
+	mux.HandleFunc("DELETE /api/v1/collections/{id}", middleware.Chain(collectionsHandler.DeleteCollectionHandler,
+		loggingMiddleware, defaultTimeout, authMiddleware, workspaceMiddleware))
+
+	// POST /api/v1/collections/{id}/drops/{dropId} - Add a drop to a collection (protected)
+	mux.HandleFunc("POST /api/v1/collections/{id}/drops/{dropId}", middleware.Chain(collectionsHandler.AddDropToCollectionHandler,
+		loggingMiddleware, defaultTimeout, authMiddleware, workspaceMiddleware))
+
+	// DELETE /api/v1/collections/{id}/drops/{dropId} - Remove a drop from a collection (protected)
+	mux.HandleFunc("DELETE /api/v1/collections/{id}/drops/{dropId}", middleware.Chain(collectionsHandler.RemoveDropFromCollectionHandler,
+		loggingMiddleware, defaultTimeout, authMiddleware, workspaceMiddleware))
+
+	// POST /api/v1/collections/{id}/publish - Publish a collection under a public slug (protected)
+	mux.HandleFunc("POST /api/v1/collections/{id}/publish", middleware.Chain(collectionsHandler.PublishCollectionHandler,
+		loggingMiddleware, defaultTimeout, authMiddleware, workspaceMiddleware))
+
+	// DELETE /api/v1/collections/{id}/publish - Unpublish a collection, invalidating its slug (protected)
+	mux.HandleFunc("DELETE /api/v1/collections/{id}/publish", middleware.Chain(collectionsHandler.UnpublishCollectionHandler,
+		loggingMiddleware, defaultTimeout, authMiddleware, workspaceMiddleware))
+
+	// GET /api/v1/public/collections/{slug} - Read a published collection (public, no token)
+	mux.HandleFunc("GET /api/v1/public/collections/{slug}", middleware.Chain(collectionsHandler.PublicCollectionHandler,
+		loggingMiddleware, readTimeout))
+
+	// --- Preference Endpoints ---
+	// GET /api/v1/preferences - Get the user's preferences (protected)
+	mux.HandleFunc("GET /api/v1/preferences", middleware.Chain(preferencesHandler.GetPreferencesHandler,
+		loggingMiddleware, readTimeout, authMiddleware))
+
+	// PUT /api/v1/preferences - Update the user's preferences (protected)
+	mux.HandleFunc("PUT /api/v1/preferences", middleware.Chain(preferencesHandler.UpdatePreferencesHandler,
+		loggingMiddleware, defaultTimeout, authMiddleware, jsonMiddleware))
+
+	// --- Current User Endpoints ---
+	// GET /api/v1/me/streak - Consecutive days with a completed drop (protected)
+	mux.HandleFunc("GET /api/v1/me/streak", middleware.Chain(meHandler.StreakHandler,
+		loggingMiddleware, readTimeout, authMiddleware, timezoneMiddleware))
+
+	// GET /api/v1/me/activity - Timeline of the user's drops being added, sent and archived (protected)
+	mux.HandleFunc("GET /api/v1/me/activity", middleware.Chain(meHandler.ActivityHandler,
+		loggingMiddleware, readTimeout, authMiddleware))
+
+	// GET /api/v1/me/forecast - Number of drops falling due on each of the next days (protected)
+	mux.HandleFunc("GET /api/v1/me/forecast", middleware.Chain(meHandler.ForecastHandler,
+		loggingMiddleware, readTimeout, authMiddleware, timezoneMiddleware))
+
+	// GET /api/v1/me/export - Download all of the user's data as JSON (protected)
+	mux.HandleFunc("GET /api/v1/me/export", middleware.Chain(meHandler.ExportAccountHandler,
+		loggingMiddleware, longTimeout, authMiddleware, timezoneMiddleware))
+
+	// POST /api/v1/me/reschedule-all - Spread the user's due drops over the coming days (protected)
+	mux.HandleFunc("POST /api/v1/me/reschedule-all", middleware.Chain(meHandler.RescheduleAllHandler,
+		loggingMiddleware, longTimeout, authMiddleware, jsonMiddleware))
+
+	// POST /api/v1/me/pause - Pause every reminder until unpaused or a given time (protected)
+	mux.HandleFunc("POST /api/v1/me/pause", middleware.Chain(meHandler.PauseRemindersHandler,
+		loggingMiddleware, defaultTimeout, authMiddleware, jsonMiddleware))
+
+	// POST /api/v1/me/unpause - Resume reminders, optionally shifting schedules by the pause (protected)
+	mux.HandleFunc("POST /api/v1/me/unpause", middleware.Chain(meHandler.UnpauseRemindersHandler,
+		loggingMiddleware, longTimeout, authMiddleware, jsonMiddleware))
+
+	// GET /api/v1/me/inbound-email - The user's secret address for creating drops by email (protected)
+	mux.HandleFunc("GET /api/v1/me/inbound-email", middleware.Chain(meHandler.InboundEmailAddressHandler,
+		loggingMiddleware, defaultTimeout, authMiddleware))
+
+	// POST /api/v1/me/inbound-email/rotate - Replace the user's inbound email address (protected)
+	mux.HandleFunc("POST /api/v1/me/inbound-email/rotate", middleware.Chain(meHandler.RotateInboundEmailAddressHandler,
+		loggingMiddleware, defaultTimeout, authMiddleware))
+
+	// DELETE /api/v1/me - Permanently delete the account and all its data (protected)
+	mux.HandleFunc("DELETE /api/v1/me", middleware.Chain(meHandler.DeleteAccountHandler,
+		loggingMiddleware, longTimeout, authMiddleware, jsonMiddleware))
+
+	// --- Workspace Endpoints ---
+	// POST /api/v1/workspaces - Create a workspace (protected)
+	mux.HandleFunc("POST /api/v1/workspaces", middleware.Chain(workspacesHandler.CreateWorkspaceHandler,
+		loggingMiddleware, defaultTimeout, authMiddleware, jsonMiddleware))
+
+	// GET /api/v1/workspaces - List the user's workspaces (protected)
+	mux.HandleFunc("GET /api/v1/workspaces", middleware.Chain(workspacesHandler.ListWorkspacesHandler,
+		loggingMiddleware, readTimeout, authMiddleware))
+
+	// POST /api/v1/workspaces/switch - Get a token for another active workspace (protected)
+	mux.HandleFunc("POST /api/v1/workspaces/switch", middleware.Chain(workspacesHandler.SwitchWorkspaceHandler,
+		loggingMiddleware, defaultTimeout, authMiddleware, jsonMiddleware))
+
+	// --- Admin Endpoints ---
+	// Restricted to the users listed in ADMIN_USER_IDS
+	// GET /api/v1/admin/maintenance - Report whether maintenance mode is on (admin)
+	mux.HandleFunc("GET /api/v1/admin/maintenance", middleware.Chain(adminHandler.GetMaintenanceHandler,
+		loggingMiddleware, readTimeout, authMiddleware, adminMiddleware))
+
+	// PUT /api/v1/admin/maintenance - Turn maintenance mode on or off (admin)
+	mux.HandleFunc("PUT /api/v1/admin/maintenance", middleware.Chain(adminHandler.SetMaintenanceHandler,
+		loggingMiddleware, defaultTimeout, authMiddleware, adminMiddleware, jsonMiddleware))
+
+	// GET /api/v1/admin/audit-log - Query the audit log of sensitive actions (admin)
+	mux.HandleFunc("GET /api/v1/admin/audit-log", middleware.Chain(adminHandler.ListAuditLogHandler,
+		loggingMiddleware, longTimeout, authMiddleware, adminMiddleware))
+
+	// GET /api/v1/admin/worker-runs - List the worker's run history (admin)
+	mux.HandleFunc("GET /api/v1/admin/worker-runs", middleware.Chain(adminHandler.ListWorkerRunsHandler,
+		loggingMiddleware, readTimeout, authMiddleware, adminMiddleware))
 
 	return mux
 }
@@ -0,0 +1,44 @@
+package testdb
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/google/uuid"
+	db "github.com/nouvadev/dropwise/internal/database/sqlc"
+)
+
+// CreateUser creates a user with a unique email and returns its ID.
+func CreateUser(t testing.TB, q *db.Queries) uuid.UUID {
+	t.Helper()
+	user, err := q.CreateUser(context.Background(), db.CreateUserParams{
+		Email:          uuid.NewString() + "@example.com",
+		HashedPassword: "not-a-real-hash",
+	})
+	if err != nil {
+		t.Fatalf("creating user: %v", err)
+	}
+	return user.ID
+}
+
+// CreateDrop creates a drop for the user in their personal space, after letting
+// modify adjust the defaults.
+func CreateDrop(t testing.TB, q *db.Queries, userID uuid.UUID, modify func(*db.CreateDropParams)) db.Drop {
+	t.Helper()
+	params := db.CreateDropParams{
+		UserUuid: uuid.NullUUID{UUID: userID, Valid: true},
+		Topic:    "Test drop",
+		Url:      "https://example.com/" + uuid.NewString(),
+		Channel:  "default",
+		Metadata: json.RawMessage(`{}`),
+	}
+	if modify != nil {
+		modify(&params)
+	}
+	drop, err := q.CreateDrop(context.Background(), params)
+	if err != nil {
+		t.Fatalf("creating drop: %v", err)
+	}
+	return drop
+}
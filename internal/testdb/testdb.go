@@ -0,0 +1,130 @@
+// Package testdb gives tests a migrated Postgres schema of their own.
+package testdb
+
+import (
+	"database/sql"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	_ "github.com/lib/pq" // PostgreSQL driver
+	db "github.com/nouvadev/dropwise/internal/database/sqlc"
+)
+
+// EnvURL names the variable holding the test database's connection string.
+// Tests that need a database are skipped when it is unset.
+const EnvURL = "DROPWISE_TEST_DATABASE_URL"
+
+// Database is a freshly migrated schema, dropped again when the test ends.
+type Database struct {
+	Conn    *sql.DB
+	Queries *db.Queries
+	// URL connects to the schema, e.g. for a second connection or a listener.
+	URL string
+}
+
+// New creates a schema in the database at DROPWISE_TEST_DATABASE_URL, applies every
+// migration to it and returns a connection whose search_path points at it.
+// Each call gets its own schema, so tests can run in parallel.
+func New(t testing.TB) *Database {
+	t.Helper()
+	baseURL := os.Getenv(EnvURL)
+	if baseURL == "" {
+		t.Skipf("%s is not set", EnvURL)
+	}
+
+	admin, err := sql.Open("postgres", baseURL)
+	if err != nil {
+		t.Fatalf("opening test database: %v", err)
+	}
+	defer admin.Close()
+
+	schema := "test_" + strings.ReplaceAll(uuid.NewString(), "-", "")
+	// Extensions are per database, so they go in public where every test schema finds them
+	if _, err := admin.Exec("CREATE EXTENSION IF NOT EXISTS pg_trgm SCHEMA public"); err != nil {
+		t.Fatalf("creating pg_trgm: %v", err)
+	}
+	if _, err := admin.Exec("CREATE SCHEMA " + schema); err != nil {
+		t.Fatalf("creating schema: %v", err)
+	}
+	t.Cleanup(func() {
+		cleanup, err := sql.Open("postgres", baseURL)
+		if err != nil {
+			t.Errorf("dropping schema %s: %v", schema, err)
+			return
+		}
+		defer cleanup.Close()
+		if _, err := cleanup.Exec("DROP SCHEMA " + schema + " CASCADE"); err != nil {
+			t.Errorf("dropping schema %s: %v", schema, err)
+		}
+	})
+
+	dsn := WithParam(baseURL, "search_path", schema+",public")
+	conn, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatalf("opening test schema: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	for _, path := range migrations(t) {
+		up, err := upSection(path)
+		if err != nil {
+			t.Fatalf("reading migration %s: %v", filepath.Base(path), err)
+		}
+		if _, err := conn.Exec(up); err != nil {
+			t.Fatalf("applying migration %s: %v", filepath.Base(path), err)
+		}
+	}
+
+	return &Database{Conn: conn, Queries: db.New(conn), URL: dsn}
+}
+
+// migrations lists the migration files in the order goose applies them.
+func migrations(t testing.TB) []string {
+	t.Helper()
+	_, file, _, ok := runtime.Caller(0)
+	if !ok {
+		t.Fatal("locating migrations: no caller information")
+	}
+	paths, err := filepath.Glob(filepath.Join(filepath.Dir(file), "..", "..", "sql", "migrations", "*.sql"))
+	if err != nil || len(paths) == 0 {
+		t.Fatalf("locating migrations: %v", err)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// upSection returns the statements between a migration's "-- +goose Up" and "-- +goose Down" markers.
+func upSection(path string) (string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	up := string(content)
+	if _, after, ok := strings.Cut(up, "-- +goose Up"); ok {
+		up = after
+	}
+	up, _, _ = strings.Cut(up, "-- +goose Down")
+	return up, nil
+}
+
+// WithParam sets a connection parameter on a URL or key=value connection string.
+// lib/pq sends parameters it doesn't know, like search_path, to the server as settings.
+func WithParam(dsn, key, value string) string {
+	if strings.HasPrefix(dsn, "postgres://") || strings.HasPrefix(dsn, "postgresql://") {
+		u, err := url.Parse(dsn)
+		if err != nil {
+			return dsn
+		}
+		q := u.Query()
+		q.Set(key, value)
+		u.RawQuery = q.Encode()
+		return u.String()
+	}
+	return dsn + " " + key + "='" + value + "'"
+}
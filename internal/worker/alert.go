@@ -0,0 +1,89 @@
+package worker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/nouvadev/dropwise/internal/config"
+)
+
+// alertWebhookTimeout bounds the call to the operator's alert webhook.
+const alertWebhookTimeout = 10 * time.Second
+
+// FailureAlert is the JSON body posted to WORKER_ALERT_WEBHOOK_URL for a degraded run.
+type FailureAlert struct {
+	Event          string    `json:"event"`
+	ProcessedCount int       `json:"processed_count"`
+	FailedCount    int       `json:"failed_count"`
+	FailureRatio   float64   `json:"failure_ratio"`
+	Threshold      float64   `json:"threshold"`
+	OccurredAt     time.Time `json:"occurred_at"`
+}
+
+// isDegraded reports whether a run failed too many of its sends to count as healthy.
+func isDegraded(summary RunSummary, cfg config.WorkerAlertConfig) bool {
+	if cfg.FailureRatio <= 0 || summary.ProcessedCount+summary.FailedCount < cfg.MinAttempts {
+		return false
+	}
+	return summary.FailureRatio() > cfg.FailureRatio
+}
+
+// alertHighFailureRate reports a degraded run with an error-level log entry and, when
+// WORKER_ALERT_WEBHOOK_URL is set, a webhook call. Webhook errors are only logged.
+func alertHighFailureRate(ctx context.Context, cfg config.WorkerAlertConfig, summary RunSummary) {
+	slog.Error("WorkerLogic: High send failure rate in worker run",
+		"event", "worker_high_failure_rate",
+		"processed_count", summary.ProcessedCount,
+		"failed_count", summary.FailedCount,
+		"failure_ratio", summary.FailureRatio(),
+		"threshold", cfg.FailureRatio,
+	)
+	if cfg.WebhookURL == "" {
+		return
+	}
+
+	alert := FailureAlert{
+		Event:          "worker_high_failure_rate",
+		ProcessedCount: summary.ProcessedCount,
+		FailedCount:    summary.FailedCount,
+		FailureRatio:   summary.FailureRatio(),
+		Threshold:      cfg.FailureRatio,
+		OccurredAt:     time.Now().UTC(),
+	}
+	if err := postFailureAlert(ctx, cfg.WebhookURL, alert); err != nil {
+		log.Printf("WorkerLogic: Failure rate alert webhook failed: %v", err)
+	}
+}
+
+// postFailureAlert posts alert as JSON to the operator-configured webhook URL.
+// Unlike user webhooks, it may point at internal services such as an alert manager.
+func postFailureAlert(ctx context.Context, webhookURL string, alert FailureAlert) error {
+	body, err := json.Marshal(alert)
+	if err != nil {
+		return fmt.Errorf("failed to encode alert: %w", err)
+	}
+	ctx, cancel := context.WithTimeout(ctx, alertWebhookTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "dropwise-api webhook")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package worker
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nouvadev/dropwise/internal/config"
+	db "github.com/nouvadev/dropwise/internal/database/sqlc"
+	"github.com/nouvadev/dropwise/internal/email"
+	"github.com/nouvadev/dropwise/internal/safehttp"
+)
+
+// caughtUpWebhookTimeout bounds a single "all caught up" webhook call.
+const caughtUpWebhookTimeout = 5 * time.Second
+
+// CaughtUpEvent is the JSON body posted to a user's webhook once their queue is empty.
+type CaughtUpEvent struct {
+	Event      string    `json:"event"`
+	UserID     uuid.UUID `json:"user_id"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+// checkCaughtUp fires the "all caught up" event for a user who has just been sent a drop,
+// if that left them with no due drops and they opted in. The state kept in
+// user_preferences.caught_up_at makes it fire once per transition to an empty queue.
+func checkCaughtUp(ctx context.Context, apiCfg *config.APIConfig, client *http.Client, sender email.Sender, userUUID uuid.UUID, userEmail string) {
+	dueBefore, lastSentBefore := apiCfg.DueWindow.Cutoffs(time.Now())
+	hasDue, err := apiCfg.DB.HasDueDropsByUserUUID(ctx, db.HasDueDropsByUserUUIDParams{
+		UserUuid:       uuid.NullUUID{UUID: userUUID, Valid: true},
+		DueBefore:      dueBefore,
+		LastSentBefore: lastSentBefore,
+	})
+	if err != nil {
+		log.Printf("WorkerLogic: Error checking remaining due drops for user %s: %v", userUUID, err)
+		return
+	}
+	if hasDue {
+		return
+	}
+
+	prefs, err := apiCfg.DB.MarkUserCaughtUp(ctx, userUUID)
+	if err == sql.ErrNoRows {
+		return // Not opted in, or the event already fired for this empty queue
+	}
+	if err != nil {
+		log.Printf("WorkerLogic: Error recording caught-up state for user %s: %v", userUUID, err)
+		return
+	}
+
+	if !prefs.CaughtUpWebhookUrl.Valid {
+		if err := sender.Send(ctx, email.Message{
+			To:      userEmail,
+			Subject: "Dropwise: all caught up!",
+			Body:    "You have no more drops due right now. New ones will show up as they come back around.\n",
+		}); err != nil {
+			log.Printf("WorkerLogic: 'All caught up' email for user %s failed: %v", userUUID, err)
+		}
+		return
+	}
+
+	event := CaughtUpEvent{Event: "all_caught_up", UserID: userUUID, OccurredAt: prefs.CaughtUpAt.Time.UTC()}
+	if err := postCaughtUpWebhook(ctx, client, prefs.CaughtUpWebhookUrl.String, event); err != nil {
+		// The event is not retried: the queue being empty again later fires a new one.
+		log.Printf("WorkerLogic: 'All caught up' webhook for user %s failed: %v", userUUID, err)
+		return
+	}
+	log.Printf("WorkerLogic: Sent 'all caught up' webhook for user %s.", userUUID)
+}
+
+// postCaughtUpWebhook posts event as JSON to a user-supplied URL.
+func postCaughtUpWebhook(ctx context.Context, client *http.Client, webhookURL string, event CaughtUpEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to build request: %w", err)
+	}
+	if err := safehttp.CheckScheme(req.URL); err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "dropwise-api webhook")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// newWebhookClient returns the client used for user-supplied webhook URLs.
+// It shares the metadata fetcher's blocklist so webhooks cannot target internal services.
+func newWebhookClient(apiCfg *config.APIConfig) *http.Client {
+	return safehttp.NewClient(safehttp.Config{
+		Timeout:      caughtUpWebhookTimeout,
+		MaxRedirects: 0,
+		BlockedCIDRs: apiCfg.MetadataFetcher.BlockedCIDRs,
+	})
+}
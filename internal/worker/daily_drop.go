@@ -0,0 +1,174 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nouvadev/dropwise/internal/config"
+	db "github.com/nouvadev/dropwise/internal/database/sqlc"
+	"github.com/nouvadev/dropwise/internal/delivery"
+	"github.com/nouvadev/dropwise/internal/email"
+	"github.com/nouvadev/dropwise/internal/schedule"
+)
+
+// processDailyDrops sends the next upcoming drop early to users who opted in to the daily
+// drop and haven't been sent anything yet on their current local date. Users in skip had
+// due drops this run and are left to the regular pass. When limit is positive, at most
+// limit users are served. It returns the number of drops sent and of users that failed.
+func processDailyDrops(ctx context.Context, apiCfg *config.APIConfig, sender email.Sender, slack *delivery.SlackSender, skip map[uuid.UUID]bool, limit int) (sent, failed int) {
+	now := time.Now()
+	dueBefore, _ := apiCfg.DueWindow.Cutoffs(now)
+	candidates, err := apiCfg.DB.ListDailyDropCandidates(ctx, dueBefore)
+	if err != nil {
+		log.Printf("WorkerLogic: Error fetching users for the daily drop: %v", err)
+		return 0, 1
+	}
+
+	var users []db.ListDailyDropCandidatesRow
+	for _, candidate := range candidates {
+		if skip[candidate.UserUuid] {
+			continue
+		}
+		if dailyDropDue(now, apiCfg.UserLocation(candidate.Timezone), candidate.DailyDropHour, candidate.FirstDropDate) {
+			users = append(users, candidate)
+		}
+	}
+	if len(users) == 0 {
+		return 0, 0
+	}
+	if limit > 0 && len(users) > limit {
+		users = users[:limit]
+	}
+	log.Printf("WorkerLogic: Sending the daily drop to %d user(s) without a drop today.", len(users))
+
+	var (
+		mu  sync.Mutex
+		wg  sync.WaitGroup
+		sem = make(chan struct{}, max(apiCfg.EmailThrottle.Concurrency, 1))
+	)
+	for _, candidate := range users {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(userUUID uuid.UUID) {
+			defer func() { <-sem; wg.Done() }()
+			processed, ok := processDailyDropUser(ctx, apiCfg, sender, slack, userUUID, dueBefore)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if processed {
+				sent++
+			}
+			if !ok {
+				failed++
+			}
+		}(candidate.UserUuid)
+	}
+	wg.Wait()
+	return sent, failed
+}
+
+// processDailyDropUser claims the user's daily drop for today and sends their next upcoming drop.
+// The claim comes first so concurrent workers never send two. If the drop can't be sent the
+// claim is given back, so a later run tries again the same day.
+func processDailyDropUser(ctx context.Context, apiCfg *config.APIConfig, sender email.Sender, slack *delivery.SlackSender, userUUID uuid.UUID, dueBefore time.Time) (processed bool, ok bool) {
+	prefs, err := apiCfg.DB.GetUserPreferences(ctx, userUUID)
+	if err != nil {
+		log.Printf("WorkerLogic: Error fetching preferences for user %s: %v", userUUID.String(), err)
+		return false, false
+	}
+
+	claimedAt := time.Now()
+	claimed, err := recordFirstDropOfDay(ctx, apiCfg, userUUID, prefs, claimedAt)
+	if err != nil {
+		return false, false
+	}
+	if !claimed {
+		log.Printf("WorkerLogic: User %s already received a drop today, skipping the daily drop.", userUUID.String())
+		return false, true
+	}
+
+	drop, err := apiCfg.DB.GetNextUpcomingDrop(ctx, db.GetNextUpcomingDropParams{
+		UserUuid:  uuid.NullUUID{UUID: userUUID, Valid: true},
+		DueBefore: dueBefore,
+	})
+	if err == sql.ErrNoRows {
+		log.Printf("WorkerLogic: No upcoming drop found for user %s's daily drop (unexpected after listing).", userUUID.String())
+		return false, true
+	}
+	if err != nil {
+		log.Printf("WorkerLogic: Error fetching the next upcoming drop for user %s: %v", userUUID.String(), err)
+		releaseFirstDropOfDay(ctx, apiCfg, userUUID, prefs, claimedAt)
+		return false, false
+	}
+
+	user, err := apiCfg.DB.GetUserByID(ctx, userUUID)
+	if err != nil {
+		log.Printf("WorkerLogic: Error fetching user %s: %v", userUUID.String(), err)
+		releaseFirstDropOfDay(ctx, apiCfg, userUUID, prefs, claimedAt)
+		return false, false
+	}
+
+	allowed, err := reserveDailySend(ctx, apiCfg, userUUID, prefs, time.Now())
+	if err != nil {
+		releaseFirstDropOfDay(ctx, apiCfg, userUUID, prefs, claimedAt)
+		return false, false
+	}
+	if !allowed {
+		log.Printf("WorkerLogic: User %s reached their daily limit of %d drops, skipping the daily drop.", userUUID.String(), prefs.DailyDropLimit.Int32)
+		return false, true
+	}
+
+	log.Printf("WorkerLogic: Sending drop ID %s early as user %s's daily drop (scheduled for %v).", drop.ID.String(), userUUID.String(), drop.NextSendDate.Time)
+	if _, ok := sendDrop(ctx, apiCfg, sender, slack, user.Email, prefs, drop); !ok {
+		releaseDailySend(ctx, apiCfg, userUUID, prefs)
+		releaseFirstDropOfDay(ctx, apiCfg, userUUID, prefs, claimedAt)
+		return false, false
+	}
+	return true, true
+}
+
+// recordFirstDropOfDay records that the user was sent a drop on their local date at sentAt.
+// It reports whether this was the day's first drop.
+func recordFirstDropOfDay(ctx context.Context, apiCfg *config.APIConfig, userUUID uuid.UUID, prefs db.UserPreference, sentAt time.Time) (bool, error) {
+	today := schedule.LocalDate(sentAt, apiCfg.UserLocation(prefs.Timezone))
+	rows, err := apiCfg.DB.RecordFirstDropOfDay(ctx, db.RecordFirstDropOfDayParams{
+		FirstDropDate: sql.NullTime{Time: today, Valid: true},
+		UserUuid:      userUUID,
+	})
+	if err != nil {
+		log.Printf("WorkerLogic: Error recording the first drop of the day for user %s: %v", userUUID.String(), err)
+		return false, err
+	}
+	return rows > 0, nil
+}
+
+// releaseFirstDropOfDay gives back a claim made with recordFirstDropOfDay at claimedAt when the
+// daily drop wasn't sent, restoring the date in prefs, which were read before the claim.
+func releaseFirstDropOfDay(ctx context.Context, apiCfg *config.APIConfig, userUUID uuid.UUID, prefs db.UserPreference, claimedAt time.Time) {
+	today := schedule.LocalDate(claimedAt, apiCfg.UserLocation(prefs.Timezone))
+	if err := apiCfg.DB.ReleaseFirstDropOfDay(ctx, db.ReleaseFirstDropOfDayParams{
+		PreviousFirstDropDate: prefs.FirstDropDate,
+		UserUuid:              userUUID,
+		FirstDropDate:         sql.NullTime{Time: today, Valid: true},
+	}); err != nil {
+		log.Printf("WorkerLogic: Error releasing the daily drop claim for user %s: %v", userUUID.String(), err)
+	}
+}
+
+// dailyDropDue reports whether a user's daily drop should go out at now: their chosen hour
+// has come in loc and no drop was recorded yet for their current local date.
+func dailyDropDue(now time.Time, loc *time.Location, hour int32, firstDropDate sql.NullTime) bool {
+	if now.In(loc).Hour() < int(hour) {
+		return false
+	}
+	if !firstDropDate.Valid {
+		return true
+	}
+	last := firstDropDate.Time
+	lastDate := time.Date(last.Year(), last.Month(), last.Day(), 0, 0, 0, 0, time.UTC)
+	return lastDate.Before(schedule.LocalDate(now, loc))
+}
@@ -0,0 +1,170 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	db "github.com/nouvadev/dropwise/internal/database/sqlc"
+	"github.com/nouvadev/dropwise/internal/email"
+	"github.com/nouvadev/dropwise/internal/schedule"
+	"github.com/nouvadev/dropwise/internal/testdb"
+)
+
+func TestDailyDropDue(t *testing.T) {
+	tokyo, err := schedule.LoadTimezone("Asia/Tokyo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	newYork, err := schedule.LoadTimezone("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	date := func(year int, month time.Month, day int) sql.NullTime {
+		return sql.NullTime{Time: time.Date(year, month, day, 0, 0, 0, 0, time.UTC), Valid: true}
+	}
+
+	tests := []struct {
+		name          string
+		now           time.Time
+		loc           *time.Location
+		hour          int32
+		firstDropDate sql.NullTime
+		want          bool
+	}{
+		{
+			name:          "never sent, hour reached",
+			now:           time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC), // 09:00 in Tokyo
+			loc:           tokyo,
+			hour:          9,
+			firstDropDate: sql.NullTime{},
+			want:          true,
+		},
+		{
+			name:          "local day already started while the UTC day hasn't",
+			now:           time.Date(2026, 5, 1, 23, 30, 0, 0, time.UTC), // 08:30 on May 2 in Tokyo
+			loc:           tokyo,
+			hour:          8,
+			firstDropDate: date(2026, 5, 1),
+			want:          true,
+		},
+		{
+			name:          "already sent on the new local day",
+			now:           time.Date(2026, 5, 1, 23, 30, 0, 0, time.UTC),
+			loc:           tokyo,
+			hour:          8,
+			firstDropDate: date(2026, 5, 2),
+			want:          false,
+		},
+		{
+			name:          "just after local midnight, before the hour",
+			now:           time.Date(2026, 5, 1, 15, 30, 0, 0, time.UTC), // 00:30 on May 2 in Tokyo
+			loc:           tokyo,
+			hour:          8,
+			firstDropDate: date(2026, 5, 1),
+			want:          false,
+		},
+		{
+			name:          "late evening locally, next day in UTC",
+			now:           time.Date(2026, 3, 9, 3, 30, 0, 0, time.UTC), // 23:30 on March 8 in New York
+			loc:           newYork,
+			hour:          9,
+			firstDropDate: date(2026, 3, 8),
+			want:          false,
+		},
+		{
+			name:          "DST start, hour reached at the new offset",
+			now:           time.Date(2026, 3, 8, 13, 0, 0, 0, time.UTC), // 09:00 EDT
+			loc:           newYork,
+			hour:          9,
+			firstDropDate: date(2026, 3, 7),
+			want:          true,
+		},
+		{
+			name:          "DST start, before the hour at the new offset",
+			now:           time.Date(2026, 3, 8, 12, 30, 0, 0, time.UTC), // 08:30 EDT
+			loc:           newYork,
+			hour:          9,
+			firstDropDate: date(2026, 3, 7),
+			want:          false,
+		},
+		{
+			name:          "DST end, hour reached at the new offset",
+			now:           time.Date(2026, 11, 1, 14, 0, 0, 0, time.UTC), // 09:00 EST
+			loc:           newYork,
+			hour:          9,
+			firstDropDate: date(2026, 10, 31),
+			want:          true,
+		},
+		{
+			name:          "DST end, before the hour at the new offset",
+			now:           time.Date(2026, 11, 1, 13, 30, 0, 0, time.UTC), // 08:30 EST, 09:30 under EDT
+			loc:           newYork,
+			hour:          9,
+			firstDropDate: date(2026, 10, 31),
+			want:          false,
+		},
+		{
+			name:          "midnight hour on the DST end day",
+			now:           time.Date(2026, 11, 1, 4, 30, 0, 0, time.UTC), // 00:30 EDT
+			loc:           newYork,
+			hour:          0,
+			firstDropDate: date(2026, 10, 31),
+			want:          true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := dailyDropDue(tt.now, tt.loc, tt.hour, tt.firstDropDate); got != tt.want {
+				t.Errorf("dailyDropDue(%v in %v, hour %d, first %v) = %v, want %v",
+					tt.now, tt.loc, tt.hour, tt.firstDropDate.Time.Format(time.DateOnly), got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDailyDropFailedSendKeepsTheDay(t *testing.T) {
+	apiCfg, database := newTestConfig(t)
+	q := database.Queries
+	ctx := context.Background()
+
+	userID := testdb.CreateUser(t, q)
+	if _, err := q.UpsertUserPreferences(ctx, db.UpsertUserPreferencesParams{
+		UserUuid:       userID,
+		DefaultChannel: "email",
+		DailyDrop:      true,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	testdb.CreateDrop(t, q, userID, func(p *db.CreateDropParams) {
+		p.NextSendDate = sql.NullTime{Time: time.Now().Add(72 * time.Hour), Valid: true}
+	})
+
+	tests := []struct {
+		name          string
+		smtp          email.SMTPConfig
+		wantProcessed bool
+		wantOK        bool
+		wantClaimed   bool
+	}{
+		{"failed send gives the day back", unreachableSMTP(t), false, false, false},
+		{"retry the same day is sent", email.SMTPConfig{}, true, true, true},
+		{"only one daily drop a day", email.SMTPConfig{}, false, true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			processed, ok := processDailyDropUser(ctx, apiCfg, email.NewSender(tt.smtp), nil, userID, time.Now())
+			if processed != tt.wantProcessed || ok != tt.wantOK {
+				t.Fatalf("processDailyDropUser() = %v, %v, want %v, %v", processed, ok, tt.wantProcessed, tt.wantOK)
+			}
+			prefs, err := q.GetUserPreferences(ctx, userID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if prefs.FirstDropDate.Valid != tt.wantClaimed {
+				t.Errorf("first_drop_date = %v, want set %v", prefs.FirstDropDate, tt.wantClaimed)
+			}
+		})
+	}
+}
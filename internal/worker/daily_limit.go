@@ -0,0 +1,41 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nouvadev/dropwise/internal/config"
+	db "github.com/nouvadev/dropwise/internal/database/sqlc"
+	"github.com/nouvadev/dropwise/internal/schedule"
+)
+
+// reserveDailySend counts a send towards the user's daily_drop_limit before the drop goes out.
+// It reports whether the send may go ahead: always for users without a limit, and for users
+// with one only while they are under it. The count resets at the user's local midnight.
+func reserveDailySend(ctx context.Context, apiCfg *config.APIConfig, userUUID uuid.UUID, prefs db.UserPreference, now time.Time) (bool, error) {
+	if !prefs.DailyDropLimit.Valid {
+		return true, nil
+	}
+	rows, err := apiCfg.DB.ReserveDailySend(ctx, db.ReserveDailySendParams{
+		NextResetAt: schedule.StartOfNextDay(now, apiCfg.UserLocation(prefs.Timezone)),
+		UserUuid:    userUUID,
+	})
+	if err != nil {
+		log.Printf("WorkerLogic: Error reserving a send under the daily limit for user %s: %v", userUUID.String(), err)
+		return false, err
+	}
+	return rows > 0, nil
+}
+
+// releaseDailySend gives back a send reserved with reserveDailySend when the drop wasn't sent,
+// so failed attempts don't use up the user's daily limit.
+func releaseDailySend(ctx context.Context, apiCfg *config.APIConfig, userUUID uuid.UUID, prefs db.UserPreference) {
+	if !prefs.DailyDropLimit.Valid {
+		return
+	}
+	if err := apiCfg.DB.ReleaseDailySend(ctx, userUUID); err != nil {
+		log.Printf("WorkerLogic: Error releasing a reserved send for user %s: %v", userUUID.String(), err)
+	}
+}
@@ -0,0 +1,66 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	db "github.com/nouvadev/dropwise/internal/database/sqlc"
+	"github.com/nouvadev/dropwise/internal/email"
+	"github.com/nouvadev/dropwise/internal/testdb"
+)
+
+func TestDailyDropLimitHoldsAcrossRuns(t *testing.T) {
+	apiCfg, database := newTestConfig(t)
+	ctx := context.Background()
+
+	userID := testdb.CreateUser(t, database.Queries)
+	if _, err := database.Queries.UpsertUserPreferences(ctx, db.UpsertUserPreferencesParams{
+		UserUuid:       userID,
+		DefaultChannel: "email",
+		DailyDropLimit: sql.NullInt32{Int32: 2, Valid: true},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	for range 4 {
+		testdb.CreateDrop(t, database.Queries, userID, nil)
+	}
+
+	sendsToday := func() int32 {
+		t.Helper()
+		prefs, err := database.Queries.GetUserPreferences(ctx, userID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return prefs.SendsToday
+	}
+
+	// A failed send must not use up the limit: point SMTP at a port nothing listens on
+	apiCfg.SMTP = unreachableSMTP(t)
+
+	summary, err := ProcessDropsLogic(ctx, apiCfg)
+	if err != nil {
+		t.Fatalf("ProcessDropsLogic() error = %v", err)
+	}
+	if summary.ProcessedCount != 0 || summary.FailedCount != 1 {
+		t.Fatalf("failing run processed %d and failed %d, want 0 and 1", summary.ProcessedCount, summary.FailedCount)
+	}
+	if got := sendsToday(); got != 0 {
+		t.Fatalf("sends_today = %d after a failed send, want 0", got)
+	}
+
+	// With sending simulated, each run sends one drop until the limit of 2 is reached
+	apiCfg.SMTP = email.SMTPConfig{}
+	for run, want := range []int{1, 1, 0, 0} {
+		summary, err := ProcessDropsLogic(ctx, apiCfg)
+		if err != nil {
+			t.Fatalf("run %d: ProcessDropsLogic() error = %v", run+1, err)
+		}
+		if summary.ProcessedCount != want {
+			t.Errorf("run %d: processed %d drops, want %d", run+1, summary.ProcessedCount, want)
+		}
+	}
+	if got := sendsToday(); got != 2 {
+		t.Errorf("sends_today = %d, want 2", got)
+	}
+}
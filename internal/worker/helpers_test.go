@@ -0,0 +1,40 @@
+package worker
+
+import (
+	"net"
+	"testing"
+
+	"github.com/nouvadev/dropwise/internal/config"
+	"github.com/nouvadev/dropwise/internal/email"
+	"github.com/nouvadev/dropwise/internal/testdb"
+)
+
+// newTestConfig returns an APIConfig backed by a fresh test database, with the default email
+// templates and sends only logged, skipping the test when no database is configured.
+func newTestConfig(t *testing.T) (*config.APIConfig, *testdb.Database) {
+	t.Helper()
+	database := testdb.New(t)
+	templates, err := email.LoadTemplates("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &config.APIConfig{
+		DB:             database.Queries,
+		DBConn:         database.Conn,
+		DBRead:         database.Queries,
+		EmailTemplates: templates,
+	}, database
+}
+
+// unreachableSMTP returns an SMTP config pointing at a local port nothing listens on, so every
+// send fails.
+func unreachableSMTP(t *testing.T) email.SMTPConfig {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := ln.Addr().(*net.TCPAddr).Port
+	ln.Close()
+	return email.SMTPConfig{Host: "127.0.0.1", Port: port, From: "dropwise@example.com"}
+}
@@ -0,0 +1,44 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"time"
+
+	"github.com/nouvadev/dropwise/internal/config"
+	db "github.com/nouvadev/dropwise/internal/database/sqlc"
+)
+
+// runRecordTimeout bounds saving a run to the worker_runs history.
+const runRecordTimeout = 5 * time.Second
+
+// recordRun saves a run to the worker_runs history, including runs that stopped on a
+// critical error runErr, so a missing reminder can be traced to the worker not running.
+// The record is saved even when ctx was cancelled; failing to save it is only logged.
+func recordRun(ctx context.Context, apiCfg *config.APIConfig, start time.Time, summary RunSummary, runErr error) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), runRecordTimeout)
+	defer cancel()
+
+	duration := summary.Duration
+	if duration == 0 {
+		duration = time.Since(start)
+	}
+	var errText sql.NullString
+	if runErr != nil {
+		errText = sql.NullString{String: runErr.Error(), Valid: true}
+	}
+
+	if err := apiCfg.DB.CreateWorkerRun(ctx, db.CreateWorkerRunParams{
+		StartedAt:      start.UTC(),
+		DurationMs:     duration.Milliseconds(),
+		ProcessedCount: int32(summary.ProcessedCount),
+		FailedCount:    int32(summary.FailedCount),
+		RemainingCount: int32(summary.RemainingCount),
+		DailyDropCount: int32(summary.DailyDropCount),
+		Degraded:       summary.Degraded,
+		Error:          errText,
+	}); err != nil {
+		log.Printf("WorkerLogic: Error recording the run in the worker run history: %v", err)
+	}
+}
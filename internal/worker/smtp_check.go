@@ -0,0 +1,47 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/nouvadev/dropwise/internal/config"
+	"github.com/nouvadev/dropwise/internal/email"
+)
+
+// smtpCheckTimeout bounds the startup check of the SMTP server.
+const smtpCheckTimeout = 10 * time.Second
+
+var (
+	smtpCheckOnce sync.Once // The check runs when the process starts, not on every run
+	smtpCheckErr  error
+)
+
+// checkSMTP runs the EMAIL_SELF_TEST check of the SMTP server once per process, so credential
+// and host mistakes show up at deploy time instead of as failed sends in the next run. The
+// outcome is logged either way. It returns an error only in the "fail" mode, for every run of
+// the process, so a misconfigured worker keeps refusing to send. Without an SMTP server
+// (emails are only logged) there is nothing to check.
+func checkSMTP(ctx context.Context, apiCfg *config.APIConfig) error {
+	if apiCfg.EmailSelfTest == email.SelfTestOff || !apiCfg.SMTP.Configured() {
+		return nil
+	}
+
+	smtpCheckOnce.Do(func() {
+		checkCtx, cancel := context.WithTimeout(ctx, smtpCheckTimeout)
+		defer cancel()
+		if err := email.VerifySMTP(checkCtx, apiCfg.SMTP); err != nil {
+			log.Printf("WorkerLogic: ERROR email self-test failed, reminders will not be delivered: %v", err)
+			smtpCheckErr = err
+			return
+		}
+		log.Printf("WorkerLogic: Email self-test passed, SMTP server %s:%d accepted the connection.", apiCfg.SMTP.Host, apiCfg.SMTP.Port)
+	})
+
+	if smtpCheckErr != nil && apiCfg.EmailSelfTest == email.SelfTestFail {
+		return fmt.Errorf("email self-test failed: %w", smtpCheckErr)
+	}
+	return nil
+}
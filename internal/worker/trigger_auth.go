@@ -0,0 +1,37 @@
+package worker
+
+import (
+	"crypto/subtle"
+	"log"
+	"net/http"
+
+	"github.com/nouvadev/dropwise/internal/config"
+	"github.com/nouvadev/dropwise/internal/server/httputils"
+)
+
+// WorkerSecretHeader carries WORKER_TRIGGER_SECRET on requests to ProcessDueDropsHTTP.
+const WorkerSecretHeader = "X-Worker-Secret"
+
+// triggerAuthorized checks that a request to run the worker carries the configured trigger
+// secret and writes the error response when it doesn't. Without a secret, triggers are only
+// accepted in DEBUG mode for local runs; anywhere else the endpoint refuses to run at all
+// rather than being open to whoever finds its URL.
+func triggerAuthorized(w http.ResponseWriter, r *http.Request, cfg *config.APIConfig) bool {
+	if cfg.WorkerTriggerSecret == "" {
+		if cfg.Debug {
+			log.Println("WorkerHTTP: WORKER_TRIGGER_SECRET is not set, accepting an unauthenticated trigger because DEBUG is on.")
+			return true
+		}
+		log.Println("WorkerHTTP: WORKER_TRIGGER_SECRET is not set, refusing to run.")
+		httputils.RespondWithError(w, http.StatusInternalServerError, "Worker trigger secret is not configured")
+		return false
+	}
+
+	provided := r.Header.Get(WorkerSecretHeader)
+	if subtle.ConstantTimeCompare([]byte(provided), []byte(cfg.WorkerTriggerSecret)) != 1 {
+		log.Printf("WorkerHTTP: Rejected trigger with a missing or wrong %s header.", WorkerSecretHeader)
+		httputils.RespondWithError(w, http.StatusUnauthorized, "Missing or invalid worker secret")
+		return false
+	}
+	return true
+}
@@ -3,108 +3,409 @@ package worker
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"html"
+	"html/template"
 	"log"
 	"net/http"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/nouvadev/dropwise/internal/config"
 	db "github.com/nouvadev/dropwise/internal/database/sqlc"
+	"github.com/nouvadev/dropwise/internal/delivery"
+	"github.com/nouvadev/dropwise/internal/email"
+	"github.com/nouvadev/dropwise/internal/events"
+	"github.com/nouvadev/dropwise/internal/markdown"
+	"github.com/nouvadev/dropwise/internal/schedule"
 	"github.com/nouvadev/dropwise/internal/server/httputils"
 )
 
-// / ProcessDropsLogic contains the core logic for fetching and "sending" due drops.
-// It now fetches distinct users with due drops and processes one drop per user.
-// It returns the total number of drops processed and any critical error encountered during the overall process.
-func ProcessDropsLogic(ctx context.Context, apiCfg *config.APIConfig) (totalProcessedCount int, err error) {
+// RunSummary describes the outcome of one worker run.
+type RunSummary struct {
+	ProcessedCount int           // Drops sent and marked as sent
+	FailedCount    int           // Users whose due drop could not be sent or recorded
+	RemainingCount int           // Due drops still waiting once the run is over, the backlog for later runs
+	DailyDropCount int           // Drops sent early as a user's daily drop, included in ProcessedCount
+	Duration       time.Duration // Wall-clock time of the run
+	Degraded       bool          // Failures exceeded WORKER_ALERT_FAILURE_RATIO and an alert was raised
+}
+
+// FailureRatio returns the share of the run's users whose drop could not be sent or recorded.
+func (s RunSummary) FailureRatio() float64 {
+	attempts := s.ProcessedCount + s.FailedCount
+	if attempts == 0 {
+		return 0
+	}
+	return float64(s.FailedCount) / float64(attempts)
+}
+
+// SendRate returns the achieved number of emails sent per second over the run.
+func (s RunSummary) SendRate() float64 {
+	if s.Duration <= 0 {
+		return 0
+	}
+	return float64(s.ProcessedCount) / s.Duration.Seconds()
+}
+
+// ProcessDropsLogic contains the core logic for fetching and sending due drops.
+// It fetches distinct users with due drops and processes one drop per user, with up to
+// EmailThrottle.Concurrency users in flight and sends paced by the email throttle.
+// At most WorkerMaxSendsPerRun users are served per run, those waiting longest first;
+// the rest are counted in the summary and left for the next scheduled run.
+// Users who opted in to the daily drop and got nothing yet today are then sent their next
+// upcoming drop early, within what is left of the per-run limit.
+// A run in which too many sends failed is marked degraded and alerted on (see WorkerAlertConfig).
+// The process's first run checks the SMTP server first when EMAIL_SELF_TEST is on (see checkSMTP).
+// Every run, including one that fails, is saved to the worker_runs history (see recordRun).
+// It returns a summary of the run and any critical error encountered during the overall process.
+func ProcessDropsLogic(ctx context.Context, apiCfg *config.APIConfig) (summary RunSummary, err error) {
 	log.Println("WorkerLogic: Starting batch processing for due drops.")
-	totalProcessedCount = 0
-	overallSuccess := true // Tracks if any non-critical error occurred
+	start := time.Now()
+	defer func() { recordRun(ctx, apiCfg, start, summary, err) }()
+
+	if err := checkSMTP(ctx, apiCfg); err != nil {
+		return summary, err
+	}
 
-	// Step 1: Get all distinct user UUIDs with 'new' drops
-	userUUIDs, err := apiCfg.DB.ListUserUUIDsWithDueDrops(ctx)
+	// Step 1: Get all distinct user UUIDs with due drops
+	dueBefore, lastSentBefore := apiCfg.DueWindow.Cutoffs(start)
+	userUUIDs, err := apiCfg.DB.ListUserUUIDsWithDueDrops(ctx, db.ListUserUUIDsWithDueDropsParams{
+		DueBefore:      dueBefore,
+		LastSentBefore: lastSentBefore,
+	})
 	if err != nil {
 		log.Printf("WorkerLogic: Critical error fetching users with due drops: %v", err)
-		return 0, fmt.Errorf("failed to fetch users with due drops: %w", err) // Stop if we can't get the user list
+		return summary, fmt.Errorf("failed to fetch users with due drops: %w", err) // Stop if we can't get the user list
 	}
 
 	if len(userUUIDs) == 0 {
 		log.Println("WorkerLogic: No users found with due drops at this time.")
-		return 0, nil
+	} else {
+		log.Printf("WorkerLogic: Found %d distinct user identifier(s) with due drops.", len(userUUIDs))
+	}
+	dueUsers := make(map[uuid.UUID]bool, len(userUUIDs))
+	for _, userUUID := range userUUIDs {
+		dueUsers[userUUID.UUID] = true
+	}
+	if limit := apiCfg.WorkerMaxSendsPerRun; limit > 0 && len(userUUIDs) > limit {
+		log.Printf("WorkerLogic: Serving %d user(s) this run (WORKER_MAX_SENDS_PER_RUN), leaving %d for later runs.", limit, len(userUUIDs)-limit)
+		userUUIDs = userUUIDs[:limit]
 	}
 
-	log.Printf("WorkerLogic: Found %d distinct user identifier(s) with due drops.", len(userUUIDs))
+	sender := email.NewThrottledSender(email.NewSender(apiCfg.SMTP), apiCfg.EmailThrottle)
+	webhookClient := newWebhookClient(apiCfg)
+	slack := delivery.NewSlackSender(webhookClient)
 
-	// Step 2: Loop through each user UUID
+	// Step 2: Process the users concurrently, bounded by the email send concurrency
+	var (
+		mu  sync.Mutex
+		wg  sync.WaitGroup
+		sem = make(chan struct{}, max(apiCfg.EmailThrottle.Concurrency, 1))
+	)
 	for _, userUUID := range userUUIDs {
 		if !userUUID.Valid {
 			log.Println("WorkerLogic: Skipping invalid or empty user UUID from ListUserUUIDsWithDueDrops.")
 			continue
 		}
-		currentUserUUID := userUUID
 
-		log.Printf("WorkerLogic: Checking for due drops for user: %s", currentUserUUID.UUID.String())
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(userUUID uuid.UUID) {
+			defer func() { <-sem; wg.Done() }()
+			processed, ok := processUser(ctx, apiCfg, sender, slack, webhookClient, userUUID)
 
-		// Step 2a: Get one due drop for the current user
-		getParams := db.GetDueDropsByUserUUIDParams{
-			UserUuid: currentUserUUID,
-			Limit:    1, // Process one drop per user per run
-		}
+			mu.Lock()
+			defer mu.Unlock()
+			if processed {
+				summary.ProcessedCount++
+			}
+			if !ok {
+				summary.FailedCount++
+			}
+		}(userUUID.UUID)
+	}
+	wg.Wait()
 
-		dueDrops, err := apiCfg.DB.GetDueDropsByUserUUID(ctx, getParams)
-		if err != nil {
-			log.Printf("WorkerLogic: Error fetching due drops for user %s: %v", currentUserUUID.UUID.String(), err)
-			overallSuccess = false
-			continue // Move to the next user
-		}
+	// Step 3: Send the daily drop to opted-in users who had nothing due
+	dailyLimit := 0
+	if limit := apiCfg.WorkerMaxSendsPerRun; limit > 0 {
+		dailyLimit = limit - len(userUUIDs)
+	}
+	if apiCfg.WorkerMaxSendsPerRun == 0 || dailyLimit > 0 {
+		sent, failed := processDailyDrops(ctx, apiCfg, sender, slack, dueUsers, dailyLimit)
+		summary.DailyDropCount = sent
+		summary.ProcessedCount += sent
+		summary.FailedCount += failed
+	}
 
-		if len(dueDrops) == 0 {
-			// This case should ideally not happen if ListUserUUIDsWithDueDrops returned this user,
-			// but it's a good safeguard (e.g., if a drop was processed/deleted by another instance).
-			log.Printf("WorkerLogic: No due drops found for user %s at this time (unexpected after listing).", currentUserUUID.UUID.String())
-			continue // Move to the next user
-		}
+	// Step 4: Count what is left. Users get one drop per run, so this can be far more than the
+	// number of users still waiting.
+	remaining, err := apiCfg.DB.CountDueDrops(ctx, db.CountDueDropsParams{
+		DueBefore:      dueBefore,
+		LastSentBefore: lastSentBefore,
+	})
+	if err != nil {
+		log.Printf("WorkerLogic: Error counting the due drops left after the run: %v", err)
+	} else {
+		summary.RemainingCount = int(remaining)
+		log.Printf("WorkerLogic: %d due drop(s) left for later runs.", remaining)
+	}
+	summary.Duration = time.Since(start)
 
-		// Process the first due drop found
-		dueDrop := dueDrops[0]
-		log.Printf("WorkerLogic: Found due drop for user %s: ID=%s, Topic='%s', URL='%s'",
-			currentUserUUID.UUID.String(), dueDrop.ID.String(), dueDrop.Topic, dueDrop.Url)
-
-		// Step 2b: Simulate sending the drop (placeholder for actual email logic)
-		log.Printf("WorkerLogic: Simulating sending drop ID %s (Topic: %s) to user %s...", dueDrop.ID.String(), dueDrop.Topic, currentUserUUID.UUID.String())
-		// In a real scenario, you might have a function like:
-		// emailSent, err := emailService.SendDropReminder(currentUserID, dueDrop)
-		// For now, we simulate success.
-		time.Sleep(500 * time.Millisecond) // Reduced sleep time for faster batch processing simulation
-		log.Printf("WorkerLogic: Drop ID %s (Topic: %s) 'sent' successfully to user %s (simulation).", dueDrop.ID.String(), dueDrop.Topic, currentUserUUID.UUID.String())
-
-		// Step 2c: Mark the drop as sent
-		markParams := db.MarkDropAsSentParams{
-			ID:           dueDrop.ID,
-			LastSentDate: sql.NullTime{Time: time.Now().UTC(), Valid: true}, // Use UTC for consistency
-		}
+	log.Printf("WorkerLogic: Batch processing finished. Total drops processed in this run: %d in %v (%.2f emails/s, limit %d/s)",
+		summary.ProcessedCount, summary.Duration.Round(time.Millisecond), summary.SendRate(), apiCfg.EmailThrottle.RatePerSecond)
+	if summary.FailedCount > 0 {
+		log.Printf("WorkerLogic: Non-critical errors occurred for %d user(s). Check logs for details.", summary.FailedCount)
+		// The function still returns nil for the error if it completed the loop,
+		// as individual errors are logged and handled per user/drop.
+	}
+	if isDegraded(summary, apiCfg.WorkerAlert) {
+		summary.Degraded = true
+		alertHighFailureRate(ctx, apiCfg.WorkerAlert, summary)
+	}
+	return summary, nil
+}
+
+// processUser sends one due drop to a user and schedules its next repetition.
+// It reports whether a drop was sent and whether processing went without errors.
+func processUser(ctx context.Context, apiCfg *config.APIConfig, sender email.Sender, slack *delivery.SlackSender, webhookClient *http.Client, userUUID uuid.UUID) (processed bool, ok bool) {
+	log.Printf("WorkerLogic: Checking for due drops for user: %s", userUUID.String())
+
+	// The user has due drops, so a later empty queue counts as a new "all caught up" transition
+	if err := apiCfg.DB.ClearUserCaughtUp(ctx, userUUID); err != nil {
+		log.Printf("WorkerLogic: Error clearing caught-up state for user %s: %v", userUUID.String(), err)
+	}
+
+	// Step 2a: Get one due drop for the current user
+	dueBefore, lastSentBefore := apiCfg.DueWindow.Cutoffs(time.Now())
+	getParams := db.GetDueDropsByUserUUIDParams{
+		UserUuid:       uuid.NullUUID{UUID: userUUID, Valid: true},
+		DueBefore:      dueBefore,
+		LastSentBefore: lastSentBefore,
+		Limit:          1, // Process one drop per user per run
+	}
+
+	dueDrops, err := apiCfg.DB.GetDueDropsByUserUUID(ctx, getParams)
+	if err != nil {
+		log.Printf("WorkerLogic: Error fetching due drops for user %s: %v", userUUID.String(), err)
+		return false, false
+	}
+
+	if len(dueDrops) == 0 {
+		// This case should ideally not happen if ListUserUUIDsWithDueDrops returned this user,
+		// but it's a good safeguard (e.g., if a drop was processed/deleted by another instance).
+		log.Printf("WorkerLogic: No due drops found for user %s at this time (unexpected after listing).", userUUID.String())
+		return false, true
+	}
+
+	// Process the first due drop found
+	dueDrop := dueDrops[0]
+	log.Printf("WorkerLogic: Found due drop for user %s: ID=%s, Topic='%s', URL='%s'",
+		userUUID.String(), dueDrop.ID.String(), dueDrop.Topic, dueDrop.Url)
+
+	user, err := apiCfg.DB.GetUserByID(ctx, userUUID)
+	if err != nil {
+		log.Printf("WorkerLogic: Error fetching user %s: %v", userUUID.String(), err)
+		return false, false
+	}
+
+	prefs, err := apiCfg.DB.GetUserPreferences(ctx, userUUID)
+	if err != nil && err != sql.ErrNoRows {
+		log.Printf("WorkerLogic: Error fetching preferences for user %s, using the defaults: %v", userUUID.String(), err)
+	}
+
+	allowed, err := reserveDailySend(ctx, apiCfg, userUUID, prefs, time.Now())
+	if err != nil {
+		return false, false
+	}
+	if !allowed {
+		log.Printf("WorkerLogic: User %s reached their daily limit of %d drops, skipping until their next day.", userUUID.String(), prefs.DailyDropLimit.Int32)
+		return false, true
+	}
 
-		updatedDrop, err := apiCfg.DB.MarkDropAsSent(ctx, markParams)
+	sentAt, ok := sendDrop(ctx, apiCfg, sender, slack, user.Email, prefs, dueDrop)
+	if !ok {
+		releaseDailySend(ctx, apiCfg, userUUID, prefs)
+		return false, false
+	}
+	if prefs.DailyDrop {
+		// A regular send counts as the day's drop, so the daily pass leaves the user alone today
+		_, _ = recordFirstDropOfDay(ctx, apiCfg, userUUID, prefs, sentAt)
+	}
+
+	checkCaughtUp(ctx, apiCfg, webhookClient, sender, userUUID, user.Email)
+	return true, true
+}
+
+// sendDrop sends a drop on its channel, marks it as sent and schedules its next repetition,
+// or archives it when the user has auto_archive_after_send on. Permanent drops are never archived.
+// It returns the time the drop was recorded as sent and whether both steps succeeded.
+func sendDrop(ctx context.Context, apiCfg *config.APIConfig, sender email.Sender, slack *delivery.SlackSender, userEmail string, prefs db.UserPreference, drop db.Drop) (time.Time, bool) {
+	userUUID := drop.UserUuid.UUID
+
+	// Step 2b: Send the drop on its channel
+	channel := delivery.Resolve(drop.Channel, prefs.DefaultChannel)
+	if err := deliverDrop(ctx, apiCfg, sender, slack, userEmail, prefs, channel, drop); err != nil {
+		log.Printf("WorkerLogic: Error sending drop ID %s to user %s: %v", drop.ID.String(), userUUID.String(), err)
+		return time.Time{}, false
+	}
+	log.Printf("WorkerLogic: Drop ID %s (Topic: %s) sent successfully to user %s via %s.", drop.ID.String(), drop.Topic, userUUID.String(), channel)
+
+	// Step 2c: Mark the drop as sent and schedule its next repetition
+	sentAt := time.Now().UTC() // Use UTC for consistency
+	markParams := sentDropParams(drop, prefs, sentAt, apiCfg.UserLocation(prefs.Timezone), apiCfg.MaxSendCount)
+	switch markParams.Status {
+	case "archived":
+		log.Printf("WorkerLogic: Drop ID %s is archived after its send (auto_archive_after_send).", drop.ID.String())
+	case "graduated":
+		log.Printf("WorkerLogic: Drop ID %s reached the send cap of %d and graduates.", drop.ID.String(), apiCfg.MaxSendCount)
+	}
+
+	updatedDrop, err := apiCfg.DB.MarkDropAsSent(ctx, markParams)
+	if err != nil {
+		// The drop was sent but not recorded, so it will be sent again on the next run
+		log.Printf("WorkerLogic: Error marking drop ID %s as sent for user %s: %v", drop.ID.String(), userUUID.String(), err)
+		return time.Time{}, false
+	}
+
+	log.Printf("WorkerLogic: Successfully marked drop ID %s as sent for user %s. New status: %s, Send count: %d, Last sent: %v, Next send: %v",
+		updatedDrop.ID.String(), userUUID.String(), updatedDrop.Status, updatedDrop.SendCount, updatedDrop.LastSentDate.Time, updatedDrop.NextSendDate.Time)
+	apiCfg.Events.Publish(userUUID, events.Event{Type: events.DropSent, DropID: updatedDrop.ID, WorkspaceID: updatedDrop.WorkspaceID})
+	return sentAt, true
+}
+
+// deliverDrop sends a drop on the resolved channel. With "both", the drop counts as
+// delivered when either channel succeeds, so a Slack outage doesn't resend the email.
+// A Slack delivery without a configured webhook URL falls back to email.
+func deliverDrop(ctx context.Context, apiCfg *config.APIConfig, sender email.Sender, slack *delivery.SlackSender, userEmail string, prefs db.UserPreference, channel string, drop db.Drop) error {
+	if delivery.IncludesSlack(channel) && !prefs.SlackWebhookUrl.Valid {
+		log.Printf("WorkerLogic: Drop ID %s should go to Slack but user %s has no Slack webhook URL, sending by email.", drop.ID.String(), drop.UserUuid.UUID.String())
+		channel = delivery.Email
+	}
+
+	var errs []error
+	if delivery.IncludesEmail(channel) {
+		msg, err := apiCfg.EmailTemplates.Reminder(userEmail, dropReminderData(drop, apiCfg.AppBaseURL))
+		if err == nil {
+			err = sender.Send(ctx, msg)
+		}
 		if err != nil {
-			log.Printf("WorkerLogic: Error marking drop ID %s as sent for user %s: %v", dueDrop.ID.String(), currentUserUUID.UUID.String(), err)
-			overallSuccess = false
-			// Continue to next user, but this drop processing failed after "sending"
-			continue
+			errs = append(errs, err)
 		}
+	}
+	if delivery.IncludesSlack(channel) {
+		if err := slack.Send(ctx, prefs.SlackWebhookUrl.String, dropSlackMessage(drop)); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if channel == delivery.Both && len(errs) == 1 {
+		log.Printf("WorkerLogic: Drop ID %s was only delivered on one channel: %v", drop.ID.String(), errs[0])
+		return nil
+	}
+	return errors.Join(errs...)
+}
+
+// dropSlackMessage builds the Slack message reminding a user of a drop.
+func dropSlackMessage(drop db.Drop) string {
+	var text strings.Builder
+	fmt.Fprintf(&text, "*Time to revisit:* <%s|%s>", drop.Url, slackEscape(drop.Topic))
+	if drop.Excerpt.Valid {
+		fmt.Fprintf(&text, "\n>%s", slackEscape(drop.Excerpt.String))
+	}
+	if drop.UserNotes.Valid {
+		fmt.Fprintf(&text, "\n_Your notes:_ %s", slackEscape(drop.UserNotes.String))
+	}
+	return text.String()
+}
 
-		log.Printf("WorkerLogic: Successfully marked drop ID %s as sent for user %s. New status: %s, Send count: %d, Last sent: %v",
-			updatedDrop.ID.String(), currentUserUUID.UUID.String(), updatedDrop.Status, updatedDrop.SendCount, updatedDrop.LastSentDate.Time)
-		totalProcessedCount++
+// slackEscape escapes the characters Slack treats as control sequences in message text.
+func slackEscape(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
+
+// dropReminderData exposes a drop to the reminder email templates.
+// Notes are rendered from markdown for the HTML template; if that fails they are shown escaped.
+func dropReminderData(drop db.Drop, baseURL string) email.ReminderData {
+	notesHTML, err := markdown.ToHTML(drop.UserNotes.String)
+	if err != nil {
+		log.Printf("WorkerLogic: Error rendering notes of drop ID %s as markdown: %v", drop.ID.String(), err)
+		notesHTML = "<p>" + html.EscapeString(drop.UserNotes.String) + "</p>"
 	}
+	return email.ReminderData{
+		Drop: email.ReminderDrop{
+			ID:            drop.ID.String(),
+			Topic:         drop.Topic,
+			URL:           drop.Url,
+			Excerpt:       drop.Excerpt.String,
+			UserNotes:     drop.UserNotes.String,
+			UserNotesHTML: template.HTML(notesHTML),
+			Priority:      drop.Priority.Int32,
+			SendCount:     drop.SendCount,
+		},
+		BaseURL: baseURL,
+	}
+}
 
-	log.Printf("WorkerLogic: Batch processing finished. Total drops processed in this run: %d", totalProcessedCount)
-	if !overallSuccess {
-		log.Println("WorkerLogic: Some non-critical errors occurred during processing for one or more users/drops. Check logs for details.")
-		// The function still returns nil for the error if it completed the loop,
-		// as individual errors are logged and handled per user/drop.
-		// A more sophisticated error aggregation could be added if needed for the caller.
+// sentDropParams returns the update recording that drop was sent at sentAt. The drop is
+// archived when the user has auto_archive_after_send on, graduates once it reaches the send cap
+// maxSendCount, and is otherwise scheduled by nextSendDateFor. Permanent drops are never
+// archived or graduated.
+func sentDropParams(drop db.Drop, prefs db.UserPreference, sentAt time.Time, loc *time.Location, maxSendCount int) db.MarkDropAsSentParams {
+	params := db.MarkDropAsSentParams{
+		ID:           drop.ID,
+		LastSentDate: sql.NullTime{Time: sentAt, Valid: true},
+		Status:       "sent",
+	}
+	if prefs.AutoArchiveAfterSend && !drop.Permanent {
+		// The user only wants one-off reminders, so the drop leaves the review cycle
+		params.Status = "archived"
+	} else if schedule.Graduated(drop.SendCount+1, drop.Permanent, maxSendCount) {
+		// The drop reached MAX_SEND_COUNT, so this was its last send
+		params.Status = "graduated"
+	} else if next, ok := nextSendDateFor(drop, sentAt, loc, schedule.NewIntervals(prefs.RepetitionIntervals)); ok {
+		params.NextSendDate = sql.NullTime{Time: next, Valid: true}
+	}
+	return params
+}
+
+// nextSendDateFor computes when a drop that was just sent should come back.
+// A drop with a cron schedule follows it. A drop reviewed with an outcome waits its SM-2
+// interval_days: a send reports no outcome, so the interval stays as the last review set it.
+// Other drops follow the user's spaced-repetition intervals.
+// All count from the drop's intended send time when it was sent early within the grace window.
+// Cron schedules are matched in the user's time zone loc.
+func nextSendDateFor(drop db.Drop, sentAt time.Time, loc *time.Location, intervals schedule.Intervals) (time.Time, bool) {
+	sentAt = schedule.ScheduleBase(sentAt, drop.NextSendDate.Time, drop.NextSendDate.Valid)
+	if drop.Schedule.Valid {
+		cron, err := schedule.ParseCron(drop.Schedule.String)
+		if err == nil {
+			return cron.NextIn(sentAt, loc)
+		}
+		log.Printf("WorkerLogic: Drop ID %s has an invalid schedule '%s', falling back to spaced repetition: %v", drop.ID.String(), drop.Schedule.String, err)
 	}
-	return totalProcessedCount, nil
+	if drop.IntervalDays.Valid && drop.IntervalDays.Int32 > 0 {
+		return sentAt.AddDate(0, 0, int(drop.IntervalDays.Int32)), true
+	}
+	return intervals.NextSendDate(drop.SendCount+1, sentAt, drop.Permanent)
+}
+
+var (
+	workerConfigOnce sync.Once // Loaded on cold start and reused by warm invocations
+	workerConfig     *config.APIConfig
+	workerConfigErr  error
+)
+
+// loadWorkerConfig loads the configuration, and with it the database connection, once per
+// instance. A failed load is not retried; the platform replaces the instance instead.
+func loadWorkerConfig() (*config.APIConfig, error) {
+	workerConfigOnce.Do(func() {
+		workerConfig, workerConfigErr = config.LoadConfig()
+	})
+	return workerConfig, workerConfigErr
 }
 
 // ProcessDueDropsHTTP is an HTTP handler that triggers the drop processing logic.
@@ -117,21 +418,23 @@ func ProcessDueDropsHTTP(w http.ResponseWriter, r *http.Request) {
 
 	log.Println("WorkerHTTP: Received request to process due drops.")
 
-	// It's crucial to initialize the database connection if it hasn't been already.
-	// LoadConfig ensures GetDBQueries is called, which uses sync.Once for initialization.
-	cfg, err := config.LoadConfig()
+	cfg, err := loadWorkerConfig()
 	if err != nil {
 		log.Printf("WorkerHTTP: Error loading configuration: %v", err)
 		httputils.RespondWithError(w, http.StatusInternalServerError, "Configuration error")
 		return
 	}
 
+	if !triggerAuthorized(w, r, cfg) {
+		return
+	}
+
 	// Ensure the database connection is closed eventually if this function is the sole manager.
 	// However, for Cloud Functions, the global connection is typically managed across invocations.
 	// If this were a standalone app, defer config.CloseDB() might be here.
 	// For Cloud Functions, explicit closing is less critical as the environment manages instance lifecycle.
 
-	processedCount, err := ProcessDropsLogic(r.Context(), cfg)
+	summary, err := ProcessDropsLogic(r.Context(), cfg)
 	if err != nil {
 		// This error from ProcessDropsLogic is for critical failures (e.g., can't list users).
 		// Individual drop processing errors are logged within ProcessDropsLogic but don't cause it to return an error.
@@ -140,10 +443,23 @@ func ProcessDueDropsHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	status, httpStatus := "ok", http.StatusOK
+	if summary.Degraded {
+		// Some sends went through and many didn't, so report a partial success like 207 Multi-Status
+		status, httpStatus = "degraded", http.StatusMultiStatus
+	}
 	responseMessage := map[string]interface{}{
-		"message":         "Drop processing finished.",
-		"processed_count": processedCount,
+		"message":              "Drop processing finished.",
+		"status":               status,
+		"processed_count":      summary.ProcessedCount,
+		"failed_count":         summary.FailedCount,
+		"remaining_count":      summary.RemainingCount,
+		"daily_drop_count":     summary.DailyDropCount,
+		"failure_ratio":        summary.FailureRatio(),
+		"duration_ms":          summary.Duration.Milliseconds(),
+		"send_rate_per_second": summary.SendRate(),
+		"send_rate_limit":      cfg.EmailThrottle.RatePerSecond,
 	}
-	log.Printf("WorkerHTTP: Finished processing. Drops processed in this invocation: %d", processedCount)
-	httputils.RespondWithJSON(w, http.StatusOK, responseMessage)
+	log.Printf("WorkerHTTP: Finished processing. Drops processed in this invocation: %d", summary.ProcessedCount)
+	httputils.RespondWithJSON(w, httpStatus, responseMessage)
 }
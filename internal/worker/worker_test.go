@@ -0,0 +1,149 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	db "github.com/nouvadev/dropwise/internal/database/sqlc"
+	"github.com/nouvadev/dropwise/internal/testdb"
+)
+
+func TestSentDropParamsFollowsSM2Interval(t *testing.T) {
+	sentAt := time.Date(2026, 3, 10, 8, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		drop db.Drop
+		want time.Time
+	}{
+		{
+			name: "reviewed drop waits its interval_days",
+			drop: db.Drop{SendCount: 1, IntervalDays: sql.NullInt32{Int32: 16, Valid: true}},
+			want: sentAt.AddDate(0, 0, 16),
+		},
+		{
+			name: "reviewed drop past the fixed sequence keeps coming back",
+			drop: db.Drop{SendCount: 10, IntervalDays: sql.NullInt32{Int32: 45, Valid: true}},
+			want: sentAt.AddDate(0, 0, 45),
+		},
+		{
+			name: "unreviewed drop follows the fixed sequence",
+			drop: db.Drop{SendCount: 1},
+			want: sentAt.AddDate(0, 0, 3),
+		},
+		{
+			name: "cron schedule wins over the SM-2 interval",
+			drop: db.Drop{SendCount: 1, Schedule: sql.NullString{String: "0 9 * * *", Valid: true}, IntervalDays: sql.NullInt32{Int32: 16, Valid: true}},
+			want: time.Date(2026, 3, 10, 9, 0, 0, 0, time.UTC),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params := sentDropParams(tt.drop, db.UserPreference{}, sentAt, time.UTC, 0)
+			if params.Status != "sent" {
+				t.Fatalf("status = %q, want sent", params.Status)
+			}
+			if !params.NextSendDate.Valid || !params.NextSendDate.Time.Equal(tt.want) {
+				t.Errorf("next send = %v, want %v", params.NextSendDate, tt.want)
+			}
+		})
+	}
+}
+
+func TestSentDropParamsGraduatesAtSendCap(t *testing.T) {
+	sentAt := time.Date(2026, 3, 10, 8, 0, 0, 0, time.UTC)
+	const maxSendCount = 5
+	tests := []struct {
+		name         string
+		drop         db.Drop
+		maxSendCount int
+		wantStatus   string
+	}{
+		{"send cap-1 stays in rotation", db.Drop{SendCount: maxSendCount - 2}, maxSendCount, "sent"},
+		{"send cap graduates", db.Drop{SendCount: maxSendCount - 1}, maxSendCount, "graduated"},
+		{"send cap+1 graduates", db.Drop{SendCount: maxSendCount}, maxSendCount, "graduated"},
+		{"permanent drop at the cap stays in rotation", db.Drop{SendCount: maxSendCount - 1, Permanent: true}, maxSendCount, "sent"},
+		{"no cap", db.Drop{SendCount: 2}, 0, "sent"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params := sentDropParams(tt.drop, db.UserPreference{}, sentAt, time.UTC, tt.maxSendCount)
+			if params.Status != tt.wantStatus {
+				t.Errorf("status = %q, want %q", params.Status, tt.wantStatus)
+			}
+			if tt.wantStatus == "graduated" && params.NextSendDate.Valid {
+				t.Errorf("graduated drop is scheduled for %v", params.NextSendDate.Time)
+			}
+			if tt.wantStatus == "sent" && !params.NextSendDate.Valid {
+				t.Error("drop in rotation has no next send date")
+			}
+		})
+	}
+}
+
+func TestSentDropParamsAutoArchive(t *testing.T) {
+	sentAt := time.Date(2026, 3, 10, 8, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name        string
+		autoArchive bool
+		drop        db.Drop
+		wantStatus  string
+	}{
+		{"off keeps the drop in rotation", false, db.Drop{SendCount: 1}, "sent"},
+		{"on archives the drop", true, db.Drop{SendCount: 1}, "archived"},
+		{"on archives a first send", true, db.Drop{}, "archived"},
+		{"on leaves permanent drops in rotation", true, db.Drop{SendCount: 1, Permanent: true}, "sent"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prefs := db.UserPreference{AutoArchiveAfterSend: tt.autoArchive}
+			params := sentDropParams(tt.drop, prefs, sentAt, time.UTC, 0)
+			if params.Status != tt.wantStatus {
+				t.Errorf("status = %q, want %q", params.Status, tt.wantStatus)
+			}
+			if params.Status == "archived" && params.NextSendDate.Valid {
+				t.Errorf("archived drop is scheduled for %v", params.NextSendDate.Time)
+			}
+			if params.Status == "sent" && !params.NextSendDate.Valid {
+				t.Error("drop in rotation has no next send date")
+			}
+			if !params.LastSentDate.Valid || !params.LastSentDate.Time.Equal(sentAt) {
+				t.Errorf("last sent = %v, want %v", params.LastSentDate, sentAt)
+			}
+		})
+	}
+}
+
+func TestRunReportsRemainingDueDrops(t *testing.T) {
+	apiCfg, database := newTestConfig(t)
+	apiCfg.WorkerMaxSendsPerRun = 1
+	ctx := context.Background()
+
+	// Two users with four due drops between them; a run serves one user and sends one drop
+	for _, drops := range []int{3, 1} {
+		userID := testdb.CreateUser(t, database.Queries)
+		for range drops {
+			testdb.CreateDrop(t, database.Queries, userID, nil)
+		}
+	}
+
+	tests := []struct {
+		run           int
+		wantProcessed int
+		wantRemaining int
+	}{
+		{1, 1, 3},
+		{2, 1, 2},
+	}
+	for _, tt := range tests {
+		summary, err := ProcessDropsLogic(ctx, apiCfg)
+		if err != nil {
+			t.Fatalf("run %d: ProcessDropsLogic() error = %v", tt.run, err)
+		}
+		if summary.ProcessedCount != tt.wantProcessed || summary.RemainingCount != tt.wantRemaining {
+			t.Errorf("run %d: processed %d with %d remaining, want %d with %d remaining",
+				tt.run, summary.ProcessedCount, summary.RemainingCount, tt.wantProcessed, tt.wantRemaining)
+		}
+	}
+}
@@ -0,0 +1,6 @@
+-- +goose Up
+-- Short description pulled from the page's meta description when the drop is created.
+ALTER TABLE drops ADD COLUMN excerpt TEXT;
+
+-- +goose Down
+ALTER TABLE drops DROP COLUMN IF EXISTS excerpt;
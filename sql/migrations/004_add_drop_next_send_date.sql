@@ -0,0 +1,10 @@
+-- +goose Up
+-- When a drop should next be sent by the worker. NULL on a 'new' drop means it is due
+-- immediately; NULL on a 'sent' drop means its repetition schedule is finished.
+ALTER TABLE drops ADD COLUMN next_send_date TIMESTAMPTZ;
+
+CREATE INDEX idx_drops_status_next_send_date ON drops (status, next_send_date);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_drops_status_next_send_date;
+ALTER TABLE drops DROP COLUMN IF EXISTS next_send_date;
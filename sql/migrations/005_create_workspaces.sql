@@ -0,0 +1,29 @@
+-- +goose Up
+CREATE TABLE workspaces (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name TEXT NOT NULL,
+    owner_uuid UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE workspace_members (
+    workspace_id UUID NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+    user_uuid UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role VARCHAR(50) NOT NULL DEFAULT 'member' CHECK (role IN ('owner', 'member')),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (workspace_id, user_uuid)
+);
+
+CREATE INDEX idx_workspace_members_user_uuid ON workspace_members (user_uuid);
+
+-- Drops without a workspace belong to the user's personal space.
+ALTER TABLE drops
+ADD COLUMN workspace_id UUID NULL REFERENCES workspaces(id) ON DELETE CASCADE;
+
+CREATE INDEX idx_drops_user_uuid_workspace_id ON drops (user_uuid, workspace_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_drops_user_uuid_workspace_id;
+ALTER TABLE drops DROP COLUMN IF EXISTS workspace_id;
+DROP TABLE IF EXISTS workspace_members;
+DROP TABLE IF EXISTS workspaces;
@@ -0,0 +1,7 @@
+-- +goose Up
+-- Optional cron expression giving a drop a fixed cadence. When set, the worker uses it
+-- to compute next_send_date instead of the spaced-repetition intervals.
+ALTER TABLE drops ADD COLUMN schedule TEXT;
+
+-- +goose Down
+ALTER TABLE drops DROP COLUMN IF EXISTS schedule;
@@ -0,0 +1,7 @@
+-- +goose Up
+-- Permanent drops are exempt from the end of the repetition sequence and keep
+-- coming back at the longest interval.
+ALTER TABLE drops ADD COLUMN permanent BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- +goose Down
+ALTER TABLE drops DROP COLUMN IF EXISTS permanent;
@@ -0,0 +1,26 @@
+-- +goose Up
+-- Collections are user-made folders for manually grouping drops. Like drops they live
+-- in the personal space (workspace_id NULL) or in a workspace.
+CREATE TABLE collections (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_uuid UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    workspace_id UUID NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_collections_user_uuid_workspace_id ON collections (user_uuid, workspace_id);
+
+-- A drop can belong to any number of collections.
+CREATE TABLE collection_drops (
+    collection_id UUID NOT NULL REFERENCES collections(id) ON DELETE CASCADE,
+    drops_id UUID NOT NULL REFERENCES drops(id) ON DELETE CASCADE,
+    added_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (collection_id, drops_id)
+);
+
+CREATE INDEX idx_collection_drops_drops_id ON collection_drops (drops_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS collection_drops;
+DROP TABLE IF EXISTS collections;
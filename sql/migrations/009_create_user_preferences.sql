@@ -0,0 +1,15 @@
+-- +goose Up
+-- Per-user settings. A user without a row has the defaults.
+CREATE TABLE user_preferences (
+    user_uuid UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    -- Opt-in "all caught up" event, sent to caught_up_webhook_url when set, by email otherwise.
+    notify_when_caught_up BOOLEAN NOT NULL DEFAULT FALSE,
+    caught_up_webhook_url TEXT NULL,
+    -- Set when the user's queue was emptied and the event fired, cleared once drops are due
+    -- again. It makes the event fire once per transition to an empty queue.
+    caught_up_at TIMESTAMPTZ NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS user_preferences;
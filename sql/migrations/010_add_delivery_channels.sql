@@ -0,0 +1,15 @@
+-- +goose Up
+-- Where drops are delivered: 'email', 'slack' or 'both'. The user's default_channel applies
+-- to every drop whose channel is 'default'; any other drop channel overrides it.
+ALTER TABLE user_preferences
+    ADD COLUMN default_channel TEXT NOT NULL DEFAULT 'email',
+    ADD COLUMN slack_webhook_url TEXT NULL;
+
+ALTER TABLE drops ADD COLUMN channel TEXT NOT NULL DEFAULT 'default';
+
+-- +goose Down
+ALTER TABLE drops DROP COLUMN IF EXISTS channel;
+
+ALTER TABLE user_preferences
+    DROP COLUMN IF EXISTS slack_webhook_url,
+    DROP COLUMN IF EXISTS default_channel;
@@ -0,0 +1,21 @@
+-- +goose Up
+-- Append-only record of security-sensitive actions. actor_uuid has no foreign key so
+-- entries outlive the users they describe.
+CREATE TABLE audit_log (
+    id BIGSERIAL PRIMARY KEY,
+    occurred_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    -- NULL for anonymous actions such as a failed login for an unknown email.
+    actor_uuid UUID NULL,
+    action TEXT NOT NULL,
+    -- What was acted on, e.g. a drop ID.
+    target TEXT NULL,
+    source_ip TEXT NULL,
+    metadata JSONB NOT NULL DEFAULT '{}'
+);
+
+CREATE INDEX idx_audit_log_occurred_at ON audit_log (occurred_at DESC);
+CREATE INDEX idx_audit_log_actor_uuid ON audit_log (actor_uuid, occurred_at DESC);
+CREATE INDEX idx_audit_log_action ON audit_log (action, occurred_at DESC);
+
+-- +goose Down
+DROP TABLE IF EXISTS audit_log;
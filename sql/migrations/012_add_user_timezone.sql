@@ -0,0 +1,6 @@
+-- +goose Up
+-- IANA time zone name used for cron schedules and local dates. NULL means DEFAULT_TIMEZONE.
+ALTER TABLE user_preferences ADD COLUMN timezone TEXT NULL;
+
+-- +goose Down
+ALTER TABLE user_preferences DROP COLUMN IF EXISTS timezone;
@@ -0,0 +1,13 @@
+-- +goose Up
+-- Consecutive days on which a user completed at least one drop (marked it sent or archived).
+-- Dates are calendar days in the user's time zone.
+CREATE TABLE review_streaks (
+    user_uuid UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    current_streak INTEGER NOT NULL DEFAULT 0,
+    longest_streak INTEGER NOT NULL DEFAULT 0,
+    last_review_date DATE NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS review_streaks;
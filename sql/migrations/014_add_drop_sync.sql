@@ -0,0 +1,41 @@
+-- +goose Up
+-- Delta sync (GET /api/v1/drops?updated_since=...) lists drops by updated_at.
+CREATE INDEX idx_drops_user_uuid_updated_at ON drops (user_uuid, updated_at);
+
+-- Deleted drops leave a tombstone so syncing clients learn about the deletion.
+-- workspace_id has no foreign key: the tombstones of a deleted workspace's drops are
+-- written while the workspace itself is being deleted.
+CREATE TABLE drop_tombstones (
+    drop_id UUID PRIMARY KEY,
+    user_uuid UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    workspace_id UUID NULL,
+    deleted_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_drop_tombstones_user_uuid_deleted_at ON drop_tombstones (user_uuid, deleted_at);
+
+-- A trigger catches every deletion, including drops removed by a workspace cascade.
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION record_drop_tombstone()
+RETURNS TRIGGER AS $$
+BEGIN
+   IF OLD.user_uuid IS NOT NULL THEN
+      INSERT INTO drop_tombstones (drop_id, user_uuid, workspace_id)
+      VALUES (OLD.id, OLD.user_uuid, OLD.workspace_id)
+      ON CONFLICT (drop_id) DO UPDATE SET deleted_at = NOW();
+   END IF;
+   RETURN OLD;
+END;
+$$ language 'plpgsql';
+-- +goose StatementEnd
+
+CREATE TRIGGER record_drops_tombstone
+AFTER DELETE ON drops
+FOR EACH ROW
+EXECUTE FUNCTION record_drop_tombstone();
+
+-- +goose Down
+DROP TRIGGER IF EXISTS record_drops_tombstone ON drops;
+DROP FUNCTION IF EXISTS record_drop_tombstone();
+DROP TABLE IF EXISTS drop_tombstones;
+DROP INDEX IF EXISTS idx_drops_user_uuid_updated_at;
@@ -0,0 +1,11 @@
+-- +goose Up
+-- Free-form JSON object for integrations (source app, highlight color, ...). The GIN index
+-- serves the metadata containment filter on the drop list.
+ALTER TABLE drops ADD COLUMN metadata JSONB NOT NULL DEFAULT '{}'::jsonb;
+
+CREATE INDEX idx_drops_metadata ON drops USING GIN (metadata jsonb_path_ops);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_drops_metadata;
+
+ALTER TABLE drops DROP COLUMN IF EXISTS metadata;
@@ -0,0 +1,14 @@
+-- +goose Up
+-- Opt-in daily drop: on a day with nothing due, the worker sends the user's next upcoming
+-- drop early, once daily_drop_hour has passed in the user's time zone. first_drop_date is
+-- the local date the user was last sent a drop, so each day gets at most one early send.
+ALTER TABLE user_preferences
+    ADD COLUMN daily_drop BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN daily_drop_hour INTEGER NOT NULL DEFAULT 9 CHECK (daily_drop_hour BETWEEN 0 AND 23),
+    ADD COLUMN first_drop_date DATE NULL;
+
+-- +goose Down
+ALTER TABLE user_preferences
+    DROP COLUMN IF EXISTS first_drop_date,
+    DROP COLUMN IF EXISTS daily_drop_hour,
+    DROP COLUMN IF EXISTS daily_drop;
@@ -0,0 +1,8 @@
+-- +goose Up
+-- Trigram index so tag search can match substrings of tag names with ILIKE.
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX idx_tags_name_trgm ON tags USING GIN (name gin_trgm_ops);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_tags_name_trgm;
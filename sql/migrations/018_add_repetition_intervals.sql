@@ -0,0 +1,7 @@
+-- +goose Up
+-- Custom spaced-repetition sequence, in days, used instead of the built-in one. NULL means
+-- the default sequence.
+ALTER TABLE user_preferences ADD COLUMN repetition_intervals INTEGER[] NULL;
+
+-- +goose Down
+ALTER TABLE user_preferences DROP COLUMN IF EXISTS repetition_intervals;
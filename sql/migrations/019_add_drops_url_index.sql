@@ -0,0 +1,6 @@
+-- +goose Up
+-- Serves looking up a user's drop by its URL.
+CREATE INDEX idx_drops_user_uuid_url ON drops (user_uuid, url);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_drops_user_uuid_url;
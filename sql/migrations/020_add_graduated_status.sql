@@ -0,0 +1,9 @@
+-- +goose Up
+-- Drops that reach MAX_SEND_COUNT sends graduate and leave the due queue.
+ALTER TABLE drops DROP CONSTRAINT IF EXISTS drops_status_check;
+ALTER TABLE drops ADD CONSTRAINT drops_status_check CHECK (status IN ('new', 'sent', 'archived', 'snoozed', 'graduated'));
+
+-- +goose Down
+UPDATE drops SET status = 'sent' WHERE status = 'graduated';
+ALTER TABLE drops DROP CONSTRAINT IF EXISTS drops_status_check;
+ALTER TABLE drops ADD CONSTRAINT drops_status_check CHECK (status IN ('new', 'sent', 'archived', 'snoozed'));
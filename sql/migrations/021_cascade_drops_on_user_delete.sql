@@ -0,0 +1,43 @@
+-- +goose Up
+-- Deleting a user deletes their drops too, instead of leaving them behind without an owner.
+-- Tag and collection links already cascade from drops.
+ALTER TABLE drops DROP CONSTRAINT IF EXISTS fk_drops_user_uuid;
+ALTER TABLE drops
+ADD CONSTRAINT fk_drops_user_uuid
+FOREIGN KEY (user_uuid) REFERENCES users(id) ON DELETE CASCADE;
+
+-- Drops deleted along with their user need no tombstone, and one would reference the
+-- deleted user.
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION record_drop_tombstone()
+RETURNS TRIGGER AS $$
+BEGIN
+   IF OLD.user_uuid IS NOT NULL AND EXISTS (SELECT 1 FROM users WHERE id = OLD.user_uuid) THEN
+      INSERT INTO drop_tombstones (drop_id, user_uuid, workspace_id)
+      VALUES (OLD.id, OLD.user_uuid, OLD.workspace_id)
+      ON CONFLICT (drop_id) DO UPDATE SET deleted_at = NOW();
+   END IF;
+   RETURN OLD;
+END;
+$$ language 'plpgsql';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION record_drop_tombstone()
+RETURNS TRIGGER AS $$
+BEGIN
+   IF OLD.user_uuid IS NOT NULL THEN
+      INSERT INTO drop_tombstones (drop_id, user_uuid, workspace_id)
+      VALUES (OLD.id, OLD.user_uuid, OLD.workspace_id)
+      ON CONFLICT (drop_id) DO UPDATE SET deleted_at = NOW();
+   END IF;
+   RETURN OLD;
+END;
+$$ language 'plpgsql';
+-- +goose StatementEnd
+
+ALTER TABLE drops DROP CONSTRAINT IF EXISTS fk_drops_user_uuid;
+ALTER TABLE drops
+ADD CONSTRAINT fk_drops_user_uuid
+FOREIGN KEY (user_uuid) REFERENCES users(id) ON DELETE SET NULL;
@@ -0,0 +1,13 @@
+-- +goose Up
+-- Pausing reminders stops every send to the user until they unpause, or until paused_until
+-- when set. paused_at is when the current pause started, used to shift schedules on unpause.
+ALTER TABLE user_preferences
+    ADD COLUMN reminders_paused BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN paused_at TIMESTAMPTZ NULL,
+    ADD COLUMN paused_until TIMESTAMPTZ NULL;
+
+-- +goose Down
+ALTER TABLE user_preferences
+    DROP COLUMN IF EXISTS paused_until,
+    DROP COLUMN IF EXISTS paused_at,
+    DROP COLUMN IF EXISTS reminders_paused;
@@ -0,0 +1,12 @@
+-- +goose Up
+-- A due date pins a drop's next send to a chosen day. next_send_date is set to the start of
+-- that day, and scheduled_send_date keeps the date the schedule had set, to restore it when
+-- the due date is removed. Both are cleared once the drop is sent.
+ALTER TABLE drops
+    ADD COLUMN due_date DATE NULL,
+    ADD COLUMN scheduled_send_date TIMESTAMPTZ NULL;
+
+-- +goose Down
+ALTER TABLE drops
+    DROP COLUMN IF EXISTS scheduled_send_date,
+    DROP COLUMN IF EXISTS due_date;
@@ -0,0 +1,9 @@
+-- +goose Up
+-- A review session locks the drops it hands out until reviewing_until, so the worker and
+-- other sessions leave them alone. An expired lock no longer counts.
+ALTER TABLE drops
+    ADD COLUMN reviewing_until TIMESTAMPTZ NULL;
+
+-- +goose Down
+ALTER TABLE drops
+    DROP COLUMN IF EXISTS reviewing_until;
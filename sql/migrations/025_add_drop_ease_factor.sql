@@ -0,0 +1,11 @@
+-- +goose Up
+-- SM-2 state for reviews with an outcome: how easily the drop is remembered and the
+-- interval, in days, it waited after its last review. NULL until it is first reviewed.
+ALTER TABLE drops
+    ADD COLUMN ease_factor DOUBLE PRECISION NOT NULL DEFAULT 2.5,
+    ADD COLUMN interval_days INTEGER NULL;
+
+-- +goose Down
+ALTER TABLE drops
+    DROP COLUMN IF EXISTS interval_days,
+    DROP COLUMN IF EXISTS ease_factor;
@@ -0,0 +1,9 @@
+-- +goose Up
+-- A published collection can be read by anyone who has its slug, without signing in.
+-- NULL while the collection is private; unpublishing clears it, so old links stop working.
+ALTER TABLE collections
+    ADD COLUMN public_slug TEXT NULL UNIQUE;
+
+-- +goose Down
+ALTER TABLE collections
+    DROP COLUMN IF EXISTS public_slug;
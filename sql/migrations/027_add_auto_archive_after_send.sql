@@ -0,0 +1,9 @@
+-- +goose Up
+-- Users who only want one-off reminders can have each drop archived once it is sent,
+-- instead of being rescheduled for spaced repetition.
+ALTER TABLE user_preferences
+    ADD COLUMN auto_archive_after_send BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- +goose Down
+ALTER TABLE user_preferences
+    DROP COLUMN IF EXISTS auto_archive_after_send;
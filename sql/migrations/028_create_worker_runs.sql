@@ -0,0 +1,19 @@
+-- +goose Up
+-- One row per worker run, so operators can see whether and how the worker ran without
+-- digging through logs. error is set when the run stopped on a critical error.
+CREATE TABLE worker_runs (
+    id BIGSERIAL PRIMARY KEY,
+    started_at TIMESTAMPTZ NOT NULL,
+    duration_ms BIGINT NOT NULL,
+    processed_count INTEGER NOT NULL,
+    failed_count INTEGER NOT NULL,
+    remaining_count INTEGER NOT NULL,
+    daily_drop_count INTEGER NOT NULL,
+    degraded BOOLEAN NOT NULL,
+    error TEXT NULL
+);
+
+CREATE INDEX idx_worker_runs_started_at ON worker_runs (started_at DESC);
+
+-- +goose Down
+DROP TABLE IF EXISTS worker_runs;
@@ -0,0 +1,14 @@
+-- +goose Up
+-- Caps how many drops a user is sent per local day, across every worker run.
+-- sends_today counts the sends since the cap last reset; sends_reset_at is the user's
+-- next local midnight at the time counting started, after which the count starts over.
+ALTER TABLE user_preferences
+    ADD COLUMN daily_drop_limit INTEGER CHECK (daily_drop_limit > 0),
+    ADD COLUMN sends_today INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN sends_reset_at TIMESTAMPTZ;
+
+-- +goose Down
+ALTER TABLE user_preferences
+    DROP COLUMN IF EXISTS sends_reset_at,
+    DROP COLUMN IF EXISTS sends_today,
+    DROP COLUMN IF EXISTS daily_drop_limit;
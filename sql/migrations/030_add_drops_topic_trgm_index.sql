@@ -0,0 +1,9 @@
+-- +goose Up
+-- Trigram index so drop search can match substrings of topics with ILIKE and rank
+-- near-misses by similarity. pg_trgm was enabled for tag search (017).
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX idx_drops_topic_trgm ON drops USING GIN (topic gin_trgm_ops);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_drops_topic_trgm;
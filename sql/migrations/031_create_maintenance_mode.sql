@@ -0,0 +1,14 @@
+-- +goose Up
+-- Holds the maintenance mode switch so every API instance sees the same mode.
+-- The table only ever has one row; the CHECK on id keeps it that way.
+CREATE TABLE maintenance_mode (
+    id BOOLEAN PRIMARY KEY DEFAULT TRUE CHECK (id),
+    enabled BOOLEAN NOT NULL DEFAULT FALSE,
+    updated_by TEXT NOT NULL DEFAULT '',
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+INSERT INTO maintenance_mode DEFAULT VALUES;
+
+-- +goose Down
+DROP TABLE IF EXISTS maintenance_mode;
@@ -0,0 +1,11 @@
+-- +goose Up
+-- Each user's secret inbound email address. Mail sent to INBOUND_EMAIL_ADDRESS with the
+-- token after a "+" becomes a drop of that user; the From address proves nothing.
+CREATE TABLE inbound_email_tokens (
+    user_uuid UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    token TEXT NOT NULL UNIQUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS inbound_email_tokens;
@@ -0,0 +1,28 @@
+-- name: CreateAuditLogEntry :exec
+INSERT INTO audit_log (
+    actor_uuid,
+    action,
+    target,
+    source_ip,
+    metadata
+) VALUES (
+    $1, $2, $3, $4, $5
+);
+
+-- name: ListAuditLogEntries :many
+-- Lists audit entries, newest first. Every filter is optional.
+SELECT * FROM audit_log
+WHERE (sqlc.narg('actor_uuid')::uuid IS NULL OR actor_uuid = sqlc.narg('actor_uuid')::uuid)
+  AND (sqlc.narg('action')::text IS NULL OR action = sqlc.narg('action')::text)
+  AND (sqlc.narg('since')::timestamptz IS NULL OR occurred_at >= sqlc.narg('since')::timestamptz)
+  AND (sqlc.narg('until')::timestamptz IS NULL OR occurred_at < sqlc.narg('until')::timestamptz)
+ORDER BY occurred_at DESC, id DESC
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: CountAuditLogEntries :one
+-- Counts the audit entries matching the same filters as ListAuditLogEntries.
+SELECT COUNT(*) FROM audit_log
+WHERE (sqlc.narg('actor_uuid')::uuid IS NULL OR actor_uuid = sqlc.narg('actor_uuid')::uuid)
+  AND (sqlc.narg('action')::text IS NULL OR action = sqlc.narg('action')::text)
+  AND (sqlc.narg('since')::timestamptz IS NULL OR occurred_at >= sqlc.narg('since')::timestamptz)
+  AND (sqlc.narg('until')::timestamptz IS NULL OR occurred_at < sqlc.narg('until')::timestamptz);
@@ -0,0 +1,73 @@
+-- name: CreateCollection :one
+INSERT INTO collections (
+    user_uuid,
+    workspace_id,
+    name
+) VALUES (
+    $1, $2, $3
+)
+RETURNING *;
+
+-- name: GetCollection :one
+SELECT * FROM collections
+WHERE id = $1;
+
+-- name: ListCollectionsForUser :many
+-- Lists the user's collections in a workspace with the number of drops in each.
+SELECT c.id, c.name, c.created_at, c.public_slug, COUNT(cd.drops_id) AS drop_count
+FROM collections c
+LEFT JOIN collection_drops cd ON c.id = cd.collection_id
+WHERE c.user_uuid = $1
+  AND c.workspace_id IS NOT DISTINCT FROM $2
+GROUP BY c.id, c.name, c.created_at, c.public_slug
+ORDER BY c.name;
+
+-- name: DeleteCollection :execrows
+DELETE FROM collections
+WHERE id = $1 AND user_uuid = $2;
+
+-- name: AddDropToCollection :exec
+-- Adding a drop that is already in the collection is a no-op.
+INSERT INTO collection_drops (collection_id, drops_id)
+VALUES ($1, $2)
+ON CONFLICT (collection_id, drops_id) DO NOTHING;
+
+-- name: RemoveDropFromCollection :execrows
+DELETE FROM collection_drops
+WHERE collection_id = $1 AND drops_id = $2;
+
+-- name: PublishCollection :one
+-- Gives the collection a public slug. A collection that is already published keeps its slug,
+-- so links shared earlier go on working.
+UPDATE collections
+SET public_slug = COALESCE(public_slug, sqlc.arg(public_slug)::text)
+WHERE id = sqlc.arg(id) AND user_uuid = sqlc.arg(user_uuid)
+RETURNING *;
+
+-- name: UnpublishCollection :execrows
+UPDATE collections
+SET public_slug = NULL
+WHERE id = $1 AND user_uuid = $2 AND public_slug IS NOT NULL;
+
+-- name: GetCollectionByPublicSlug :one
+SELECT * FROM collections
+WHERE public_slug = $1;
+
+-- name: ListPublicCollectionDrops :many
+-- Only the fields a public reader may see, oldest addition first.
+SELECT d.topic, d.url, d.user_notes
+FROM collection_drops cd
+JOIN drops d ON d.id = cd.drops_id
+WHERE cd.collection_id = $1
+ORDER BY cd.added_at, d.id
+LIMIT $2;
+
+-- name: GetCollectionsForDrops :many
+-- Retrieves the user's collections containing each of several drops in one query, avoiding
+-- a query per drop.
+SELECT cd.drops_id, c.id, c.name
+FROM collection_drops cd
+JOIN collections c ON c.id = cd.collection_id
+WHERE cd.drops_id = ANY(sqlc.arg('drop_ids')::uuid[])
+  AND c.user_uuid = sqlc.arg('user_uuid')
+ORDER BY cd.drops_id, c.name, c.id;
@@ -0,0 +1,7 @@
+-- name: ListDropTombstonesSince :many
+-- Drops deleted after a point in time, for delta sync.
+SELECT * FROM drop_tombstones
+WHERE user_uuid = $1
+  AND workspace_id IS NOT DISTINCT FROM $2 -- NULL selects the personal space
+  AND deleted_at > sqlc.arg('since')
+ORDER BY deleted_at, drop_id;
@@ -4,9 +4,16 @@ INSERT INTO drops (
     topic,
     url,
     user_notes,
-    priority
+    priority,
+    excerpt,
+    workspace_id,
+    schedule,
+    next_send_date,
+    permanent,
+    channel,
+    metadata
 ) VALUES (
-    $1, $2, $3, $4, $5
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12
 )
 RETURNING *;
 
@@ -16,20 +23,40 @@ SELECT * FROM drops
 WHERE id = $1;
 
 
+-- name: GetDropByURLForUser :one
+-- Finds the user's drop with the given URL in a workspace. URLs aren't unique, so the most
+-- recently added drop wins.
+SELECT * FROM drops
+WHERE url = sqlc.arg('url')
+  AND user_uuid = sqlc.arg('user_uuid')
+  AND workspace_id IS NOT DISTINCT FROM sqlc.narg('workspace_id')
+ORDER BY added_date DESC
+LIMIT 1;
+
 -- name: ListDropsByUserUUID :many
 SELECT * FROM drops
 WHERE user_uuid = $1 -- Changed from user_id
+  AND workspace_id IS NOT DISTINCT FROM $2 -- NULL selects the personal space
+  AND (sqlc.narg('collection_id')::uuid IS NULL
+       OR id IN (SELECT drops_id FROM collection_drops WHERE collection_id = sqlc.narg('collection_id')))
+  AND (sqlc.narg('metadata')::jsonb IS NULL OR metadata @> sqlc.narg('metadata')::jsonb)
 ORDER BY added_date DESC;
 
 
 -- name: UpdateDrop :one
+-- An empty schedule clears the drop's cron schedule.
 UPDATE drops
 SET
     topic = COALESCE(sqlc.narg('topic'), topic),
     url = COALESCE(sqlc.narg('url'), url),
     user_notes = COALESCE(sqlc.narg('user_notes'), user_notes),
     priority = COALESCE(sqlc.narg('priority'), priority),
-    status = COALESCE(sqlc.narg('status'), status)
+    status = COALESCE(sqlc.narg('status'), status),
+    schedule = NULLIF(COALESCE(sqlc.narg('schedule'), schedule), ''),
+    next_send_date = COALESCE(sqlc.narg('next_send_date'), next_send_date),
+    permanent = COALESCE(sqlc.narg('permanent'), permanent),
+    channel = COALESCE(sqlc.narg('channel'), channel),
+    metadata = COALESCE(sqlc.narg('metadata'), metadata)
     -- updated_at is handled by the database trigger
 WHERE id = $1 AND user_uuid = $2 -- Changed from user_id
 RETURNING *;
@@ -40,30 +67,373 @@ DELETE FROM drops
 WHERE id = $1 AND user_uuid = $2;
 
 
+-- name: ListAllDropsByUserUUID :many
+-- Every drop the user owns, across the personal space and all workspaces.
+SELECT * FROM drops
+WHERE user_uuid = $1
+ORDER BY added_date;
+
+
+-- name: GetDropsUpdatedSince :many
+-- Drops created or changed after a point in time, oldest change first, for delta sync.
+SELECT * FROM drops
+WHERE user_uuid = $1
+  AND workspace_id IS NOT DISTINCT FROM $2 -- NULL selects the personal space
+  AND updated_at > sqlc.arg('since')
+ORDER BY updated_at, id;
+
+
 -- name: GetDueDropsByUserUUID :many
 -- Selects drops that are due to be sent for a specific user.
--- Drops are considered due if their status is 'new'.
--- They are ordered by priority (descending) and then by added_date (ascending).
+-- Drops are considered due if they are 'new' or 'sent' and their next_send_date is before due_before
+-- (a 'new' drop without a next_send_date is due immediately), unless they were sent after last_sent_before
+-- or a review session holds them. They are ordered by priority (highest first, a NULL priority counts as 0), then by how overdue
+-- they are (earliest next_send_date, or added_date when unscheduled), then by added_date.
 SELECT *
 FROM drops
-WHERE user_uuid = $1 -- Changed from user_id
-  AND status = 'new'
-ORDER BY priority DESC, added_date ASC
-LIMIT $2;
+WHERE user_uuid = sqlc.arg('user_uuid') -- Changed from user_id
+  AND (
+    (status = 'new' AND (next_send_date IS NULL OR next_send_date <= sqlc.arg('due_before')::timestamptz))
+    OR (status = 'sent' AND next_send_date <= sqlc.arg('due_before')::timestamptz)
+  )
+  AND (last_sent_date IS NULL OR last_sent_date <= sqlc.arg('last_sent_before')::timestamptz)
+  AND (reviewing_until IS NULL OR reviewing_until <= NOW()) -- Drops in a review session are left to it
+ORDER BY COALESCE(priority, 0) DESC, COALESCE(next_send_date, added_date) ASC, added_date ASC
+LIMIT sqlc.arg('limit');
+
+-- name: GetNextUpcomingDrop :one
+-- Selects the user's drop that comes up next among those not due before due_before,
+-- preferring the higher priority when two are scheduled for the same time.
+SELECT *
+FROM drops
+WHERE user_uuid = sqlc.arg('user_uuid')
+  AND status IN ('new', 'sent')
+  AND next_send_date > sqlc.arg('due_before')::timestamptz
+ORDER BY next_send_date ASC, COALESCE(priority, 0) DESC
+LIMIT 1;
 
 -- name: MarkDropAsSent :one
--- Updates a drop's status to 'sent', sets the last_sent_date, and increments the send_count.
+-- Updates a drop's status to 'sent' (or 'graduated' once it reached the send cap, or 'archived'
+-- for users with auto_archive_after_send), sets the last_sent_date, increments the send_count,
+-- and stores when the next repetition is due.
 UPDATE drops
 SET
-    status = 'sent',
+    status = $4, -- $4 is 'sent', or 'graduated'/'archived' for the drop's last send
     last_sent_date = $2, -- $2 will be the timestamp when it was sent
-    send_count = send_count + 1
+    send_count = send_count + 1,
+    next_send_date = $3, -- $3 is the next repetition, NULL once the schedule is finished
+    due_date = NULL, -- A pinned due date only applies to one send
+    scheduled_send_date = NULL,
+    reviewing_until = NULL -- A review session that outlived its lock can't complete the drop any more
     -- updated_at is handled by the database trigger
 WHERE id = $1 -- $1 will be the drop's ID
 RETURNING *;
 
 -- name: ListUserUUIDsWithDueDrops :many
-SELECT DISTINCT user_uuid -- Changed from user_id
+-- Users who were sent something longest ago (or never) come first, so a run that
+-- can only serve some of them doesn't keep favouring the same users.
+SELECT d.user_uuid
+FROM drops d
+WHERE (
+    (d.status = 'new' AND (d.next_send_date IS NULL OR d.next_send_date <= sqlc.arg('due_before')::timestamptz))
+    OR (d.status = 'sent' AND d.next_send_date <= sqlc.arg('due_before')::timestamptz)
+  )
+  AND (d.last_sent_date IS NULL OR d.last_sent_date <= sqlc.arg('last_sent_before')::timestamptz)
+  AND d.user_uuid IS NOT NULL
+  AND (d.reviewing_until IS NULL OR d.reviewing_until <= NOW())
+  AND NOT EXISTS ( -- Users who paused their reminders are skipped
+    SELECT 1 FROM user_preferences p
+    WHERE p.user_uuid = d.user_uuid
+      AND p.reminders_paused
+      AND (p.paused_until IS NULL OR p.paused_until > NOW())
+  )
+  AND NOT EXISTS ( -- So are users who reached their daily_drop_limit for the day
+    SELECT 1 FROM user_preferences p
+    WHERE p.user_uuid = d.user_uuid
+      AND p.sends_today >= p.daily_drop_limit
+      AND p.sends_reset_at > NOW()
+  )
+GROUP BY d.user_uuid
+ORDER BY (SELECT MAX(s.last_sent_date) FROM drops s WHERE s.user_uuid = d.user_uuid) ASC NULLS FIRST, d.user_uuid;
+
+-- name: CountDueDrops :one
+-- Counts the due drops of every user the worker would serve, using the same criteria as
+-- ListUserUUIDsWithDueDrops. Each run sends one per user, so this is the worker's backlog.
+SELECT COUNT(*)
+FROM drops d
+WHERE (
+    (d.status = 'new' AND (d.next_send_date IS NULL OR d.next_send_date <= sqlc.arg('due_before')::timestamptz))
+    OR (d.status = 'sent' AND d.next_send_date <= sqlc.arg('due_before')::timestamptz)
+  )
+  AND (d.last_sent_date IS NULL OR d.last_sent_date <= sqlc.arg('last_sent_before')::timestamptz)
+  AND d.user_uuid IS NOT NULL
+  AND (d.reviewing_until IS NULL OR d.reviewing_until <= NOW())
+  AND NOT EXISTS ( -- Users who paused their reminders are skipped
+    SELECT 1 FROM user_preferences p
+    WHERE p.user_uuid = d.user_uuid
+      AND p.reminders_paused
+      AND (p.paused_until IS NULL OR p.paused_until > NOW())
+  )
+  AND NOT EXISTS ( -- So are users who reached their daily_drop_limit for the day
+    SELECT 1 FROM user_preferences p
+    WHERE p.user_uuid = d.user_uuid
+      AND p.sends_today >= p.daily_drop_limit
+      AND p.sends_reset_at > NOW()
+  );
+
+-- name: ResetDropSchedule :one
+-- Restarts a drop's repetition schedule: the send count goes back to zero
+-- and the next send is set to the given time. A pinned due date is dropped, and the
+-- next review with an outcome starts from the first interval again.
+UPDATE drops
+SET
+    send_count = 0,
+    next_send_date = $3,
+    interval_days = NULL,
+    due_date = NULL,
+    scheduled_send_date = NULL
+WHERE id = $1 AND user_uuid = $2
+RETURNING *;
+
+-- name: ReviewDrop :one
+-- Records the outcome of reviewing a drop outside a review session, the same way as
+-- CompleteDropReview. Any review lock is released. Returns no row unless the drop is
+-- 'new' or 'sent'.
+UPDATE drops
+SET
+    status = sqlc.arg('status'),
+    last_sent_date = sqlc.arg('last_sent_date'),
+    send_count = sqlc.arg('send_count'),
+    next_send_date = sqlc.narg('next_send_date'),
+    ease_factor = sqlc.arg('ease_factor'),
+    interval_days = sqlc.narg('interval_days'),
+    due_date = NULL,
+    scheduled_send_date = NULL,
+    reviewing_until = NULL
+WHERE id = sqlc.arg('id')
+  AND user_uuid = sqlc.arg('user_uuid')
+  AND status IN ('new', 'sent')
+RETURNING *;
+
+-- name: SetDropNextSendDate :one
+-- Overrides when a drop is next sent without touching its send count. A pinned due date is dropped.
+UPDATE drops
+SET next_send_date = $3, due_date = NULL, scheduled_send_date = NULL
+WHERE id = $1 AND user_uuid = $2
+RETURNING *;
+
+-- name: SetDropDueDate :one
+-- Pins a drop's next send to due_date, sent from next_send_date. The date the schedule had
+-- set is kept in scheduled_send_date, also when a pinned drop is pinned again.
+UPDATE drops
+SET
+    scheduled_send_date = CASE WHEN due_date IS NULL THEN next_send_date ELSE scheduled_send_date END,
+    due_date = sqlc.arg('due_date'),
+    next_send_date = sqlc.arg('next_send_date')
+WHERE id = sqlc.arg('id') AND user_uuid = sqlc.arg('user_uuid')
+RETURNING *;
+
+-- name: ClearDropDueDate :one
+-- Removes a drop's pinned due date and restores the next send its schedule had set.
+-- Returns no row when the drop has no due date.
+UPDATE drops
+SET next_send_date = scheduled_send_date, due_date = NULL, scheduled_send_date = NULL
+WHERE id = $1 AND user_uuid = $2 AND due_date IS NOT NULL
+RETURNING *;
+
+-- name: ShiftDropSchedules :many
+-- Moves every pending send of the user's drops later by the given number of seconds,
+-- in all workspaces. Drops without a pending send or pinned to a due date are left alone.
+UPDATE drops
+SET next_send_date = next_send_date + make_interval(secs => sqlc.arg('seconds')::float8)
+WHERE user_uuid = sqlc.arg('user_uuid')
+  AND status IN ('new', 'sent')
+  AND next_send_date IS NOT NULL
+  AND due_date IS NULL
+RETURNING *;
+
+-- name: GetDropsByIDs :many
+-- Fetches the user's drops in a workspace among the given IDs.
+-- IDs that don't exist or belong to someone else are left out.
+SELECT * FROM drops
+WHERE id = ANY(sqlc.arg('ids')::uuid[])
+  AND user_uuid = sqlc.arg('user_uuid')
+  AND workspace_id IS NOT DISTINCT FROM sqlc.narg('workspace_id');
+
+-- name: UpdateDropsStatus :many
+-- Sets the status of several of the user's drops at once and returns the IDs that were updated.
+-- Only drops whose current status is in from_statuses are changed.
+UPDATE drops
+SET status = sqlc.arg('status')
+WHERE id = ANY(sqlc.arg('ids')::uuid[])
+  AND user_uuid = sqlc.arg('user_uuid')
+  AND workspace_id IS NOT DISTINCT FROM sqlc.narg('workspace_id')
+  AND status = ANY(sqlc.arg('from_statuses')::text[])
+RETURNING id;
+
+-- name: StartDropReview :many
+-- Locks up to limit of the user's due drops in a workspace for a review session until
+-- reviewing_until and returns them, using the same criteria and order as GetDueDropsByUserUUID.
+-- Drops another session holds are skipped; SKIP LOCKED keeps two sessions started at the
+-- same moment from taking the same drops.
+UPDATE drops
+SET reviewing_until = sqlc.arg('reviewing_until')
+WHERE id IN (
+    SELECT d.id FROM drops d
+    WHERE d.user_uuid = sqlc.arg('user_uuid')
+      AND d.workspace_id IS NOT DISTINCT FROM sqlc.narg('workspace_id')
+      AND (
+        (d.status = 'new' AND (d.next_send_date IS NULL OR d.next_send_date <= sqlc.arg('due_before')::timestamptz))
+        OR (d.status = 'sent' AND d.next_send_date <= sqlc.arg('due_before')::timestamptz)
+      )
+      AND (d.last_sent_date IS NULL OR d.last_sent_date <= sqlc.arg('last_sent_before')::timestamptz)
+      AND (d.reviewing_until IS NULL OR d.reviewing_until <= NOW())
+    ORDER BY COALESCE(d.priority, 0) DESC, COALESCE(d.next_send_date, d.added_date) ASC, d.added_date ASC
+    LIMIT sqlc.arg('limit')
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+-- name: CompleteDropReview :one
+-- Records the outcome of reviewing a drop in a review session and releases its lock, like a
+-- send: last_sent_date is the review time and send_count the drop's new count, and the SM-2
+-- state is stored. Returns no row when the drop isn't in a review session, e.g. because the
+-- worker sent it meanwhile.
+UPDATE drops
+SET
+    status = sqlc.arg('status'),
+    last_sent_date = sqlc.arg('last_sent_date'),
+    send_count = sqlc.arg('send_count'),
+    next_send_date = sqlc.narg('next_send_date'),
+    ease_factor = sqlc.arg('ease_factor'),
+    interval_days = sqlc.narg('interval_days'),
+    due_date = NULL,
+    scheduled_send_date = NULL,
+    reviewing_until = NULL
+WHERE id = sqlc.arg('id')
+  AND user_uuid = sqlc.arg('user_uuid')
+  AND reviewing_until IS NOT NULL
+  AND status IN ('new', 'sent')
+RETURNING *;
+
+-- name: CountDueDropsByUserUUID :one
+-- Counts the user's drops in a workspace that are due, using the same criteria as GetDueDropsByUserUUID.
+SELECT COUNT(*)
+FROM drops
+WHERE user_uuid = sqlc.arg('user_uuid')
+  AND workspace_id IS NOT DISTINCT FROM sqlc.narg('workspace_id')
+  AND (
+    (status = 'new' AND (next_send_date IS NULL OR next_send_date <= sqlc.arg('due_before')::timestamptz))
+    OR (status = 'sent' AND next_send_date <= sqlc.arg('due_before')::timestamptz)
+  )
+  AND (last_sent_date IS NULL OR last_sent_date <= sqlc.arg('last_sent_before')::timestamptz)
+  AND (reviewing_until IS NULL OR reviewing_until <= NOW());
+
+-- name: PatchDrop :one
+-- Writes the editable fields of a drop that a merge patch sets, leaving the others as they
+-- are now, so a patch can't write back values the worker or another request changed since
+-- the drop was read. A status change only applies while the drop still has expected_status,
+-- the status the transition was checked against; otherwise no row is returned.
+UPDATE drops
+SET
+    topic = CASE WHEN sqlc.arg('set_topic')::boolean THEN sqlc.arg('topic') ELSE topic END,
+    url = CASE WHEN sqlc.arg('set_url')::boolean THEN sqlc.arg('url') ELSE url END,
+    user_notes = CASE WHEN sqlc.arg('set_user_notes')::boolean THEN sqlc.narg('user_notes') ELSE user_notes END,
+    priority = CASE WHEN sqlc.arg('set_priority')::boolean THEN sqlc.narg('priority') ELSE priority END,
+    status = CASE WHEN sqlc.arg('set_status')::boolean THEN sqlc.arg('status') ELSE status END,
+    schedule = CASE WHEN sqlc.arg('set_schedule')::boolean THEN sqlc.narg('schedule') ELSE schedule END,
+    next_send_date = CASE WHEN sqlc.arg('set_next_send_date')::boolean THEN sqlc.narg('next_send_date') ELSE next_send_date END,
+    permanent = CASE WHEN sqlc.arg('set_permanent')::boolean THEN sqlc.arg('permanent') ELSE permanent END,
+    channel = CASE WHEN sqlc.arg('set_channel')::boolean THEN sqlc.arg('channel') ELSE channel END,
+    metadata = CASE WHEN sqlc.arg('set_metadata')::boolean THEN sqlc.arg('metadata') ELSE metadata END
+WHERE id = sqlc.arg('id') AND user_uuid = sqlc.arg('user_uuid')
+  AND (NOT sqlc.arg('set_status')::boolean OR status = sqlc.arg('expected_status'))
+RETURNING *;
+
+-- name: CountDropsByStatus :many
+-- Counts the user's drops in a workspace per status.
+SELECT status, COUNT(*) AS count
+FROM drops
+WHERE user_uuid = $1
+  AND workspace_id IS NOT DISTINCT FROM $2
+GROUP BY status
+ORDER BY status;
+
+-- name: CountDropsByDomain :many
+-- Counts the user's drops in a workspace per site, most saved first. The domain is the
+-- lower-cased host of the drop's URL without a leading "www."; URLs without a host are left out.
+SELECT domain, COUNT(*) AS count
+FROM (
+    SELECT regexp_replace(
+        lower(substring(url FROM '^[A-Za-z][A-Za-z0-9+.-]*://(?:[^/?#@]*@)?([^/?#:]+)')),
+        '^www\.', ''
+    ) AS domain
+    FROM drops
+    WHERE user_uuid = $1
+      AND workspace_id IS NOT DISTINCT FROM $2
+) hosts
+WHERE domain <> ''
+GROUP BY domain
+ORDER BY count DESC, domain
+LIMIT $3;
+
+-- name: HasDueDropsByUserUUID :one
+-- Reports whether the user has any due drop in any workspace, using the same criteria as GetDueDropsByUserUUID.
+SELECT EXISTS (
+    SELECT 1 FROM drops
+    WHERE user_uuid = sqlc.arg('user_uuid')
+      AND (
+        (status = 'new' AND (next_send_date IS NULL OR next_send_date <= sqlc.arg('due_before')::timestamptz))
+        OR (status = 'sent' AND next_send_date <= sqlc.arg('due_before')::timestamptz)
+      )
+      AND (last_sent_date IS NULL OR last_sent_date <= sqlc.arg('last_sent_before')::timestamptz)
+      AND (reviewing_until IS NULL OR reviewing_until <= NOW())
+);
+
+-- name: ListExistingDropURLs :many
+-- Returns which of the given URLs the user already has a drop for in a workspace.
+SELECT DISTINCT url FROM drops
+WHERE url = ANY(sqlc.arg('urls')::text[])
+  AND user_uuid = sqlc.arg('user_uuid')
+  AND workspace_id IS NOT DISTINCT FROM sqlc.narg('workspace_id');
+
+-- name: ListUserActivity :many
+-- Builds the user's activity timeline from drop timestamps, newest first. Each drop gives an
+-- 'added' entry, a 'sent' entry for its latest send and, while archived, an 'archived' entry.
+-- No completion time is stored, so 'archived' is dated by the drop's last update.
+SELECT a.type::text AS type, a.drop_id, a.topic, a.workspace_id, a.occurred_at::timestamptz AS occurred_at
+FROM (
+    SELECT 'added' AS type, id AS drop_id, topic, workspace_id, added_date AS occurred_at
+    FROM drops WHERE user_uuid = sqlc.arg('user_uuid')
+    UNION ALL
+    SELECT 'sent', id, topic, workspace_id, last_sent_date
+    FROM drops WHERE user_uuid = sqlc.arg('user_uuid') AND last_sent_date IS NOT NULL
+    UNION ALL
+    SELECT 'archived', id, topic, workspace_id, updated_at
+    FROM drops WHERE user_uuid = sqlc.arg('user_uuid') AND status = 'archived'
+) a
+ORDER BY a.occurred_at DESC, a.drop_id ASC, a.type ASC
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: CountUserActivity :one
+-- Counts the entries of the user's activity timeline (see ListUserActivity).
+SELECT (COUNT(*) + COUNT(last_sent_date) + COUNT(*) FILTER (WHERE status = 'archived'))::bigint
+FROM drops
+WHERE user_uuid = $1;
+
+-- name: ForecastDueDropsByDay :many
+-- Counts the user's drops in every workspace that fall due on each local day, in time zone tz,
+-- up to until. Overdue drops and new drops without a date count toward from_date (today).
+SELECT GREATEST(
+        COALESCE((next_send_date AT TIME ZONE sqlc.arg('tz')::text)::date, sqlc.arg('from_date')::date),
+        sqlc.arg('from_date')::date
+    )::date AS day,
+    COUNT(*) AS count
 FROM drops
-WHERE status = 'new'
-  AND user_uuid IS NOT NULL; -- Simplified condition for UUID
\ No newline at end of file
+WHERE user_uuid = sqlc.arg('user_uuid')
+  AND (
+    (status = 'new' AND (next_send_date IS NULL OR next_send_date < sqlc.arg('until')::timestamptz))
+    OR (status = 'sent' AND next_send_date < sqlc.arg('until')::timestamptz)
+  )
+GROUP BY day
+ORDER BY day;
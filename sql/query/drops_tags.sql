@@ -13,6 +13,14 @@ JOIN drops_item_tags dit ON t.id = dit.tag_id
 WHERE dit.drops_id = $1
 ORDER BY t.name;
 
+-- name: GetTagsForDrops :many
+-- Retrieves the tags of several drops in one query, avoiding a query per drop.
+SELECT dit.drops_id, t.id, t.name
+FROM tags t
+JOIN drops_item_tags dit ON t.id = dit.tag_id
+WHERE dit.drops_id = ANY(sqlc.arg('drop_ids')::uuid[])
+ORDER BY dit.drops_id, t.name;
+
 -- name: RemoveTagFromDrop :exec
 -- Removes a specific tag association from a drop.
 DELETE FROM drops_item_tags
@@ -22,4 +30,148 @@ WHERE drops_id = $1 AND tag_id = $2;
 -- Removes all tag associations for a specific drop.
 -- Useful when updating a drop's tags to clear existing ones first.
 DELETE FROM drops_item_tags
-WHERE drops_id = $1;
\ No newline at end of file
+WHERE drops_id = $1;
+
+-- name: GetTagStatsForUser :one
+-- Aggregates the number of drops and total sends for a tag, limited to the given user's drops.
+SELECT
+    COUNT(*) AS drop_count,
+    COALESCE(SUM(d.send_count), 0)::bigint AS total_sends
+FROM drops d
+JOIN drops_item_tags dit ON d.id = dit.drops_id
+WHERE dit.tag_id = $1 AND d.user_uuid = $2
+  AND d.workspace_id IS NOT DISTINCT FROM $3;
+
+-- name: CountTagDropsByStatusForUser :many
+-- Counts the given user's drops carrying a tag, grouped by status.
+SELECT d.status, COUNT(*) AS count
+FROM drops d
+JOIN drops_item_tags dit ON d.id = dit.drops_id
+WHERE dit.tag_id = $1 AND d.user_uuid = $2
+  AND d.workspace_id IS NOT DISTINCT FROM $3
+GROUP BY d.status
+ORDER BY d.status;
+
+-- name: ListRelatedDrops :many
+-- Lists the user's other drops in the same workspace that share tags with the given drop,
+-- ranked by the number of shared tags.
+SELECT sqlc.embed(d), COUNT(*) AS shared_tags
+FROM drops d
+JOIN drops_item_tags dit ON d.id = dit.drops_id
+WHERE dit.tag_id IN (
+    SELECT src.tag_id FROM drops_item_tags src WHERE src.drops_id = sqlc.arg('drop_id')
+  )
+  AND d.id <> sqlc.arg('drop_id')
+  AND d.user_uuid = sqlc.arg('user_uuid')
+  AND d.workspace_id IS NOT DISTINCT FROM sqlc.narg('workspace_id')
+GROUP BY d.id
+ORDER BY shared_tags DESC, d.added_date DESC
+LIMIT sqlc.arg('limit');
+
+-- name: AssignTagToDrops :many
+-- Associates a tag with those of the given drops that belong to the user in a workspace,
+-- skipping drops that already carry it. The drops that got the tag are touched so sync
+-- clients pick up the change, and their IDs are returned.
+WITH assigned AS (
+    INSERT INTO drops_item_tags (drops_id, tag_id)
+    SELECT d.id, sqlc.arg('tag_id')
+    FROM drops d
+    WHERE d.id = ANY(sqlc.arg('drop_ids')::uuid[])
+      AND d.user_uuid = sqlc.arg('user_uuid')
+      AND d.workspace_id IS NOT DISTINCT FROM sqlc.narg('workspace_id')
+    ON CONFLICT (drops_id, tag_id) DO NOTHING
+    RETURNING drops_id
+)
+UPDATE drops
+SET updated_at = NOW()
+WHERE id IN (SELECT drops_id FROM assigned)
+RETURNING id;
+
+-- name: UnassignTagFromDrops :many
+-- Removes a tag from those of the given drops that belong to the user in a workspace, or with
+-- all_drops from every such drop, skipping drops without it. The drops that lost the tag are
+-- touched so sync clients pick up the change, and their IDs are returned.
+WITH unassigned AS (
+    DELETE FROM drops_item_tags dit
+    USING drops d
+    WHERE dit.drops_id = d.id
+      AND dit.tag_id = sqlc.arg('tag_id')
+      AND (sqlc.arg('all_drops')::bool OR d.id = ANY(sqlc.arg('drop_ids')::uuid[]))
+      AND d.user_uuid = sqlc.arg('user_uuid')
+      AND d.workspace_id IS NOT DISTINCT FROM sqlc.narg('workspace_id')
+    RETURNING dit.drops_id
+)
+UPDATE drops
+SET updated_at = NOW()
+WHERE id IN (SELECT drops_id FROM unassigned)
+RETURNING id;
+
+-- name: UserHasTag :one
+-- Reports whether any of the user's drops, in any workspace, carries the tag.
+SELECT EXISTS (
+    SELECT 1
+    FROM drops_item_tags dit
+    JOIN drops d ON d.id = dit.drops_id
+    WHERE dit.tag_id = $1 AND d.user_uuid = $2
+);
+
+-- name: ListDropsGroupedByTag :many
+-- Lists the user's drops in a workspace under each of their tags, newest first, keeping at
+-- most per_group drops per tag. A drop with several tags is returned once per tag; untagged
+-- drops come with a NULL tag name.
+SELECT sqlc.embed(d), g.tag_name
+FROM (
+    SELECT d.id AS drop_id, t.name AS tag_name,
+        ROW_NUMBER() OVER (PARTITION BY t.name ORDER BY d.added_date DESC, d.id) AS rank
+    FROM drops d
+    LEFT JOIN drops_item_tags dit ON dit.drops_id = d.id
+    LEFT JOIN tags t ON t.id = dit.tag_id
+    WHERE d.user_uuid = sqlc.arg('user_uuid')
+      AND d.workspace_id IS NOT DISTINCT FROM sqlc.narg('workspace_id')
+) g
+JOIN drops d ON d.id = g.drop_id
+WHERE g.rank <= sqlc.arg('per_group')
+ORDER BY g.tag_name NULLS LAST, d.added_date DESC, d.id;
+
+-- name: SearchDrops :many
+-- Finds the user's drops in a workspace whose topic, or the name of one of whose tags,
+-- contains query. pattern is query as an ILIKE pattern ('%query%' with wildcards escaped).
+-- Topic matches rank before drops matching only by tag, newest first.
+SELECT sqlc.embed(d)
+FROM drops d
+WHERE d.user_uuid = sqlc.arg('user_uuid')
+  AND d.workspace_id IS NOT DISTINCT FROM sqlc.narg('workspace_id')
+  AND (
+    d.topic ILIKE sqlc.arg('pattern')::text
+    OR EXISTS (
+      SELECT 1 FROM drops_item_tags dit
+      JOIN tags t ON t.id = dit.tag_id
+      WHERE dit.drops_id = d.id AND t.name ILIKE sqlc.arg('pattern')::text
+    )
+  )
+ORDER BY d.topic ILIKE sqlc.arg('pattern')::text DESC, d.added_date DESC
+LIMIT sqlc.arg('limit');
+
+-- name: FuzzySearchDrops :many
+-- Finds the user's drops in a workspace whose topic or tags are similar to query, so typos
+-- still match. score is the better of the topic's word similarity and its best tag's
+-- similarity to query (pg_trgm, 0 to 1). Drops scoring below min_score are left out and
+-- the rest are ranked by score.
+SELECT sqlc.embed(d), s.score::float8 AS score
+FROM drops d
+CROSS JOIN LATERAL (
+    SELECT GREATEST(
+        word_similarity(sqlc.arg('query')::text, d.topic),
+        COALESCE((
+            SELECT MAX(similarity(sqlc.arg('query')::text, t.name))
+            FROM drops_item_tags dit
+            JOIN tags t ON t.id = dit.tag_id
+            WHERE dit.drops_id = d.id
+        ), 0)
+    ) AS score
+) s
+WHERE d.user_uuid = sqlc.arg('user_uuid')
+  AND d.workspace_id IS NOT DISTINCT FROM sqlc.narg('workspace_id')
+  AND s.score >= sqlc.arg('min_score')::float8
+ORDER BY s.score DESC, d.added_date DESC
+LIMIT sqlc.arg('limit');
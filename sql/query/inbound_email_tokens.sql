@@ -0,0 +1,16 @@
+-- name: EnsureInboundEmailToken :one
+-- Returns the user's inbound email token, storing the given one if they have none yet.
+INSERT INTO inbound_email_tokens (user_uuid, token)
+VALUES ($1, $2)
+ON CONFLICT (user_uuid) DO UPDATE SET token = inbound_email_tokens.token
+RETURNING token;
+
+-- name: GetUserUUIDByInboundEmailToken :one
+SELECT user_uuid FROM inbound_email_tokens WHERE token = $1;
+
+-- name: RotateInboundEmailToken :one
+-- Replaces the user's inbound email token, so mail to the old address is refused.
+INSERT INTO inbound_email_tokens (user_uuid, token)
+VALUES ($1, $2)
+ON CONFLICT (user_uuid) DO UPDATE SET token = EXCLUDED.token, created_at = NOW()
+RETURNING token;
@@ -0,0 +1,10 @@
+-- name: GetMaintenanceMode :one
+-- Reports whether maintenance mode is on.
+SELECT enabled FROM maintenance_mode WHERE id;
+
+-- name: SetMaintenanceMode :execrows
+-- Turns maintenance mode on or off for every API instance. No row is affected when the
+-- mode is already set that way, so only actual changes are recorded.
+UPDATE maintenance_mode
+SET enabled = $1, updated_by = $2, updated_at = NOW()
+WHERE id AND enabled <> $1;
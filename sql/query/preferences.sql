@@ -0,0 +1,124 @@
+-- name: GetUserPreferences :one
+SELECT * FROM user_preferences
+WHERE user_uuid = $1;
+
+-- name: UpsertUserPreferences :one
+INSERT INTO user_preferences (
+    user_uuid,
+    notify_when_caught_up,
+    caught_up_webhook_url,
+    default_channel,
+    slack_webhook_url,
+    timezone,
+    daily_drop,
+    daily_drop_hour,
+    repetition_intervals,
+    auto_archive_after_send,
+    daily_drop_limit
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11
+)
+ON CONFLICT (user_uuid) DO UPDATE SET
+    notify_when_caught_up = EXCLUDED.notify_when_caught_up,
+    caught_up_webhook_url = EXCLUDED.caught_up_webhook_url,
+    default_channel = EXCLUDED.default_channel,
+    slack_webhook_url = EXCLUDED.slack_webhook_url,
+    timezone = EXCLUDED.timezone,
+    daily_drop = EXCLUDED.daily_drop,
+    daily_drop_hour = EXCLUDED.daily_drop_hour,
+    repetition_intervals = EXCLUDED.repetition_intervals,
+    auto_archive_after_send = EXCLUDED.auto_archive_after_send,
+    daily_drop_limit = EXCLUDED.daily_drop_limit,
+    updated_at = NOW()
+RETURNING *;
+
+-- name: ClearUserCaughtUp :exec
+-- Records that the user has due drops again, re-arming the "all caught up" event.
+UPDATE user_preferences
+SET caught_up_at = NULL
+WHERE user_uuid = $1 AND caught_up_at IS NOT NULL;
+
+-- name: MarkUserCaughtUp :one
+-- Records that the user's queue is empty. Returns a row only for an opted-in user
+-- who was not already caught up, so concurrent workers fire the event at most once.
+UPDATE user_preferences
+SET caught_up_at = NOW()
+WHERE user_uuid = $1
+  AND notify_when_caught_up
+  AND caught_up_at IS NULL
+RETURNING *;
+
+-- name: ListDailyDropCandidates :many
+-- Users opted in to the daily drop who have an upcoming drop that isn't due yet.
+SELECT p.user_uuid, p.timezone, p.daily_drop_hour, p.first_drop_date
+FROM user_preferences p
+WHERE p.daily_drop
+  AND NOT (p.reminders_paused AND (p.paused_until IS NULL OR p.paused_until > NOW()))
+  AND EXISTS (
+    SELECT 1 FROM drops d
+    WHERE d.user_uuid = p.user_uuid
+      AND d.status IN ('new', 'sent')
+      AND d.next_send_date > sqlc.arg('due_before')::timestamptz
+  );
+
+-- name: RecordFirstDropOfDay :execrows
+-- Records that the user was sent a drop on their local date first_drop_date. Only the day's
+-- first drop affects a row, so concurrent workers send at most one early drop a day.
+UPDATE user_preferences
+SET first_drop_date = sqlc.arg('first_drop_date')
+WHERE user_uuid = sqlc.arg('user_uuid')
+  AND (first_drop_date IS NULL OR first_drop_date < sqlc.arg('first_drop_date'));
+
+-- name: ReleaseFirstDropOfDay :exec
+-- Gives back a claim made with RecordFirstDropOfDay when the daily drop could not be sent,
+-- restoring the date it replaced. Only a claim for first_drop_date is undone.
+UPDATE user_preferences
+SET first_drop_date = sqlc.narg('previous_first_drop_date')
+WHERE user_uuid = sqlc.arg('user_uuid')
+  AND first_drop_date = sqlc.arg('first_drop_date');
+
+-- name: ReserveDailySend :execrows
+-- Counts a send towards the user's daily_drop_limit before it goes out. Once sends_reset_at
+-- has passed the count starts over, with next_reset_at as the new reset time. No row is
+-- affected when the user has no limit or already reached it, so concurrent workers and
+-- repeated runs never send past the cap.
+UPDATE user_preferences
+SET sends_today = CASE WHEN sends_reset_at > NOW() THEN sends_today + 1 ELSE 1 END,
+    sends_reset_at = CASE WHEN sends_reset_at > NOW() THEN sends_reset_at ELSE sqlc.arg('next_reset_at')::timestamptz END
+WHERE user_uuid = sqlc.arg('user_uuid')
+  AND daily_drop_limit IS NOT NULL
+  AND (sends_reset_at IS NULL OR sends_reset_at <= NOW() OR sends_today < daily_drop_limit);
+
+-- name: ReleaseDailySend :exec
+-- Gives back a send reserved with ReserveDailySend when the drop could not be sent.
+UPDATE user_preferences
+SET sends_today = sends_today - 1
+WHERE user_uuid = $1 AND sends_today > 0 AND sends_reset_at > NOW();
+
+-- name: PauseUserReminders :one
+-- Pauses every send to the user, until paused_until when given. Pausing again while paused
+-- only changes paused_until, so paused_at keeps marking the start of the pause.
+INSERT INTO user_preferences (user_uuid, reminders_paused, paused_at, paused_until)
+VALUES (sqlc.arg('user_uuid'), TRUE, NOW(), sqlc.narg('paused_until'))
+ON CONFLICT (user_uuid) DO UPDATE SET
+    reminders_paused = TRUE,
+    paused_at = CASE
+        WHEN user_preferences.reminders_paused
+             AND (user_preferences.paused_until IS NULL OR user_preferences.paused_until > NOW())
+        THEN user_preferences.paused_at
+        ELSE NOW()
+    END,
+    paused_until = EXCLUDED.paused_until,
+    updated_at = NOW()
+RETURNING *;
+
+-- name: UnpauseUserReminders :one
+-- Resumes sends to a user who paused them. Returns when the pause started and was set to end,
+-- and no row if the user hadn't paused.
+UPDATE user_preferences p
+SET reminders_paused = FALSE, paused_at = NULL, paused_until = NULL, updated_at = NOW()
+FROM user_preferences old
+WHERE p.user_uuid = sqlc.arg('user_uuid')
+  AND old.user_uuid = p.user_uuid
+  AND p.reminders_paused
+RETURNING old.paused_at, old.paused_until;
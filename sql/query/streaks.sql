@@ -0,0 +1,24 @@
+-- name: GetReviewStreak :one
+SELECT * FROM review_streaks
+WHERE user_uuid = $1;
+
+-- name: RecordReview :one
+-- Records that the user completed a drop on review_date. A review the day after the last one
+-- extends the streak, a later one starts a new streak, and further reviews on the same day
+-- (or an earlier day, after a time zone change) leave it as it is.
+INSERT INTO review_streaks (user_uuid, current_streak, longest_streak, last_review_date)
+VALUES (sqlc.arg('user_uuid'), 1, 1, sqlc.arg('review_date')::date)
+ON CONFLICT (user_uuid) DO UPDATE SET
+    current_streak = CASE
+        WHEN review_streaks.last_review_date >= EXCLUDED.last_review_date THEN review_streaks.current_streak
+        WHEN review_streaks.last_review_date = EXCLUDED.last_review_date - 1 THEN review_streaks.current_streak + 1
+        ELSE 1
+    END,
+    longest_streak = GREATEST(review_streaks.longest_streak, CASE
+        WHEN review_streaks.last_review_date >= EXCLUDED.last_review_date THEN review_streaks.current_streak
+        WHEN review_streaks.last_review_date = EXCLUDED.last_review_date - 1 THEN review_streaks.current_streak + 1
+        ELSE 1
+    END),
+    last_review_date = GREATEST(review_streaks.last_review_date, EXCLUDED.last_review_date),
+    updated_at = NOW()
+RETURNING *;
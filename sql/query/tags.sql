@@ -11,6 +11,74 @@ RETURNING *;
 SELECT * FROM tags
 WHERE name = $1;
 
--- name: ListTags :many
-SELECT * FROM tags
-ORDER BY name;
\ No newline at end of file
+-- name: ListTagsForUser :many
+-- Tags are shared between users, so listings must always go through the user's drops.
+-- Lists the tags used on the user's drops in a workspace, with how many drops carry each.
+-- sort_by is 'name' or 'count' and sort_order is 'asc' or 'desc'; ties are broken by name.
+SELECT t.id, t.name, COUNT(*) AS drop_count
+FROM tags t
+JOIN drops_item_tags dit ON t.id = dit.tag_id
+JOIN drops d ON d.id = dit.drops_id
+WHERE d.user_uuid = sqlc.arg('user_uuid')
+  AND d.workspace_id IS NOT DISTINCT FROM sqlc.narg('workspace_id')
+GROUP BY t.id, t.name
+ORDER BY
+    CASE WHEN sqlc.arg('sort_by')::text = 'name' AND sqlc.arg('sort_order')::text = 'asc' THEN t.name END ASC,
+    CASE WHEN sqlc.arg('sort_by')::text = 'name' AND sqlc.arg('sort_order')::text = 'desc' THEN t.name END DESC,
+    CASE WHEN sqlc.arg('sort_by')::text = 'count' AND sqlc.arg('sort_order')::text = 'asc' THEN COUNT(*) END ASC,
+    CASE WHEN sqlc.arg('sort_by')::text = 'count' AND sqlc.arg('sort_order')::text = 'desc' THEN COUNT(*) END DESC,
+    t.name ASC
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: ListRecentTagsForUser :many
+-- Lists the tags on the user's drops in a workspace by when they were last used: the latest
+-- update of any drop carrying the tag, which includes tagging it. Ties are broken by name.
+SELECT t.id, t.name, MAX(d.updated_at)::timestamptz AS last_used_at
+FROM tags t
+JOIN drops_item_tags dit ON t.id = dit.tag_id
+JOIN drops d ON d.id = dit.drops_id
+WHERE d.user_uuid = sqlc.arg('user_uuid')
+  AND d.workspace_id IS NOT DISTINCT FROM sqlc.narg('workspace_id')
+GROUP BY t.id, t.name
+ORDER BY last_used_at DESC, t.name ASC
+LIMIT sqlc.arg('limit');
+
+-- name: SearchTagsForUser :many
+-- Finds the tags on the user's drops in a workspace whose name contains query. pattern is
+-- query as an ILIKE pattern ('%query%' with wildcards escaped). An exact match ranks first,
+-- then the most used tags, so a capped result keeps the useful ones; ties go to names where
+-- query appears earlier.
+SELECT t.id, t.name, COUNT(*) AS drop_count
+FROM tags t
+JOIN drops_item_tags dit ON t.id = dit.tag_id
+JOIN drops d ON d.id = dit.drops_id
+WHERE d.user_uuid = sqlc.arg('user_uuid')
+  AND d.workspace_id IS NOT DISTINCT FROM sqlc.narg('workspace_id')
+  AND t.name ILIKE sqlc.arg('pattern')::text
+GROUP BY t.id, t.name
+ORDER BY
+    lower(t.name) = lower(sqlc.arg('query')::text) DESC,
+    COUNT(*) DESC,
+    strpos(lower(t.name), lower(sqlc.arg('query')::text)) ASC,
+    t.name ASC
+LIMIT sqlc.arg('limit');
+
+-- name: ListTagCandidatesForDrop :many
+-- Lists the tags on the user's drops in a workspace that the given drop doesn't carry yet,
+-- with how many drops carry each. These are the tags that can be suggested for the drop.
+SELECT t.id, t.name, COUNT(*) AS drop_count
+FROM tags t
+JOIN drops_item_tags dit ON t.id = dit.tag_id
+JOIN drops d ON d.id = dit.drops_id
+WHERE d.user_uuid = sqlc.arg('user_uuid')
+  AND d.workspace_id IS NOT DISTINCT FROM sqlc.narg('workspace_id')
+  AND t.id NOT IN (SELECT src.tag_id FROM drops_item_tags src WHERE src.drops_id = sqlc.arg('drop_id'))
+GROUP BY t.id, t.name;
+
+-- name: CountTagsForUser :one
+-- Counts the distinct tags used on the user's drops in a workspace.
+SELECT COUNT(DISTINCT dit.tag_id)
+FROM drops_item_tags dit
+JOIN drops d ON d.id = dit.drops_id
+WHERE d.user_uuid = $1
+  AND d.workspace_id IS NOT DISTINCT FROM $2;
@@ -15,4 +15,20 @@ WHERE email = $1;
 -- name: GetUserByID :one
 SELECT id, email, created_at, updated_at
 FROM users
-WHERE id = $1;
\ No newline at end of file
+WHERE id = $1;
+
+-- name: UpdateUserPassword :exec
+UPDATE users
+SET hashed_password = $2, updated_at = NOW()
+WHERE id = $1;
+
+-- name: GetUserPasswordHash :one
+SELECT hashed_password
+FROM users
+WHERE id = $1;
+
+-- name: DeleteUser :execrows
+-- Drops (with their tag and collection links), preferences, streaks, collections,
+-- workspace memberships and owned workspaces cascade.
+DELETE FROM users
+WHERE id = $1;
@@ -0,0 +1,22 @@
+-- name: CreateWorkerRun :exec
+INSERT INTO worker_runs (
+    started_at,
+    duration_ms,
+    processed_count,
+    failed_count,
+    remaining_count,
+    daily_drop_count,
+    degraded,
+    error
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8
+);
+
+-- name: ListWorkerRuns :many
+-- Lists worker runs, newest first.
+SELECT * FROM worker_runs
+ORDER BY started_at DESC, id DESC
+LIMIT $1 OFFSET $2;
+
+-- name: CountWorkerRuns :one
+SELECT COUNT(*) FROM worker_runs;
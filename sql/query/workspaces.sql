@@ -0,0 +1,28 @@
+-- name: CreateWorkspace :one
+INSERT INTO workspaces (
+    name,
+    owner_uuid
+) VALUES (
+    $1, $2
+)
+RETURNING *;
+
+-- name: AddWorkspaceMember :exec
+-- Adds a user to a workspace. Re-adding an existing member is a no-op.
+INSERT INTO workspace_members (workspace_id, user_uuid, role)
+VALUES ($1, $2, $3)
+ON CONFLICT (workspace_id, user_uuid) DO NOTHING;
+
+-- name: ListWorkspacesForUser :many
+-- Lists the workspaces the user is a member of, with their role in each.
+SELECT w.id, w.name, w.owner_uuid, w.created_at, wm.role
+FROM workspaces w
+JOIN workspace_members wm ON w.id = wm.workspace_id
+WHERE wm.user_uuid = $1
+ORDER BY w.name;
+
+-- name: IsWorkspaceMember :one
+SELECT EXISTS (
+    SELECT 1 FROM workspace_members
+    WHERE workspace_id = $1 AND user_uuid = $2
+);